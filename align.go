@@ -0,0 +1,126 @@
+package obj
+
+import (
+	"math"
+
+	dmat4 "github.com/flywave/go3d/float64/mat4"
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+)
+
+// AlignToPrincipalAxes moves the centroid of the vertices to the origin and
+// rotates them so that the directions in which they spread the most, the
+// second most and the least become X, Y and Z. The direction of each axis
+// is chosen so that the vertices are skewed towards its positive side, so
+// that copies of a mesh rotated in any way end up aligned the same.
+// Normals are rotated along. Offset is left unchanged.
+//
+// It returns the transform applied to the positions. A buffer without
+// vertices is left as is and the identity is returned.
+func (b *ObjBuffer) AlignToPrincipalAxes() dmat4.T {
+	if len(b.V) == 0 {
+		return dmat4.Ident
+	}
+	positions := b.allPositions()
+	var centroid dvec3.T
+	for i := range positions {
+		centroid.Add(&positions[i])
+	}
+	centroid.Scale(1 / float64(len(positions)))
+	axes := principalAxes(positions)
+	for k := 0; k < 2; k++ {
+		skew := 0.0
+		for _, p := range positions {
+			d := dvec3.Sub(&p, &centroid)
+			along := dvec3.Dot(&d, &axes[k])
+			skew += along * along * along
+		}
+		if skew < 0 {
+			axes[k].Invert()
+		}
+	}
+	axes[2] = dvec3.Cross(&axes[0], &axes[1])
+
+	var transform dmat4.T
+	transform.AssignCoordinateSystem(&axes[0], &axes[1], &axes[2])
+	translation := transform.MulVec3(&centroid)
+	translation.Invert()
+	transform.SetTranslation(&translation)
+
+	double := b.hasDoublePrecision()
+	for i := range positions {
+		p := transform.MulVec3(&positions[i])
+		b.V[i] = vec3.T{float32(p[0]), float32(p[1]), float32(p[2])}
+		if double {
+			b.VD[i] = p
+		}
+	}
+	for i, vn := range b.VN {
+		n := dvec3.T{float64(vn[0]), float64(vn[1]), float64(vn[2])}
+		n = transform.MulVec3W(&n, 0)
+		b.VN[i] = vec3.T{float32(n[0]), float32(n[1]), float32(n[2])}
+	}
+	return transform
+}
+
+// Center moves the vertices so that the center of their bounding box is the
+// origin, and returns the translation applied. Normals are unaffected by a
+// translation. Offset is left unchanged. A buffer without vertices is left
+// as is.
+func (b *ObjBuffer) Center() dvec3.T {
+	if b.IsEmpty() {
+		return dvec3.T{}
+	}
+	positions := b.allPositions()
+	box := positionBounds(positions)
+	translation := box.Center()
+	translation.Invert()
+	b.setPositions(positions, func(p *dvec3.T) { p.Add(&translation) })
+	return translation
+}
+
+// NormalizeScale scales the vertices uniformly about the origin so that the
+// largest side of their bounding box is targetSize, and returns the scale
+// factor applied. Following Center, NormalizeScale(1) fits the mesh in the
+// unit cube centered on the origin. A uniform scale keeps the directions of
+// the normals, which are left unchanged. Offset is left unchanged too. A
+// buffer without vertices or without extent, or a targetSize that is not
+// positive, leaves the buffer as is and returns 1.
+func (b *ObjBuffer) NormalizeScale(targetSize float64) float64 {
+	if b.IsEmpty() || targetSize <= 0 {
+		return 1
+	}
+	positions := b.allPositions()
+	box := positionBounds(positions)
+	size := math.Max(box.Max[0]-box.Min[0], math.Max(box.Max[1]-box.Min[1], box.Max[2]-box.Min[2]))
+	if size == 0 {
+		return 1
+	}
+	scale := targetSize / size
+	b.setPositions(positions, func(p *dvec3.T) { p.Scale(scale) })
+	return scale
+}
+
+// positionBounds returns the box bounding positions, which must not be
+// empty.
+func positionBounds(positions []dvec3.T) dvec3.Box {
+	box := dvec3.Box{Min: positions[0], Max: positions[0]}
+	for i := range positions[1:] {
+		box.Extend(&positions[i+1])
+	}
+	return box
+}
+
+// setPositions sets the positions of the vertices to positions moved by fn,
+// in V and, when the buffer has double precision, in VD.
+func (b *ObjBuffer) setPositions(positions []dvec3.T, fn func(p *dvec3.T)) {
+	double := b.hasDoublePrecision()
+	for i := range positions {
+		p := positions[i]
+		fn(&p)
+		b.V[i] = vec3.T{float32(p[0]), float32(p[1]), float32(p[2])}
+		if double {
+			b.VD[i] = p
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package obj
+
+import (
+	"math"
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_AlignToPrincipalAxes_RotatedBox_AlignsOnAxes(t *testing.T) {
+	// Arrange
+	buffer := createRotatedBox(dvec3.T{10, -5, 3}, dvec3.T{4, 2, 1})
+	buffer.VN = []vec3.T{{0, 0, 1}}
+	original := append([]dvec3.T(nil), buffer.VD...)
+
+	// Act
+	transform := buffer.AlignToPrincipalAxes()
+
+	// Assert
+	for i, p := range buffer.VD {
+		assert.InDeltaSlice(t, []float64{4, 2, 1}, []float64{math.Abs(p[0]), math.Abs(p[1]), math.Abs(p[2])}, 1e-9)
+		q := transform.MulVec3(&original[i])
+		assert.InDeltaSlice(t, p[:], q[:], 1e-9)
+		assert.InDeltaSlice(t, []float32{float32(p[0]), float32(p[1]), float32(p[2])}, buffer.V[i][:], 1e-5)
+	}
+	assert.InDelta(t, 1, buffer.VN[0].Length(), 1e-6)
+}
+
+func TestObjBuffer_AlignToPrincipalAxes_RotatedCopies_AlignTheSame(t *testing.T) {
+	// Arrange
+	a := createRotatedBox(dvec3.T{0, 0, 0}, dvec3.T{4, 2, 1})
+	b := createRotatedBox(dvec3.T{0, 0, 0}, dvec3.T{4, 2, 1})
+	b.Mirror(AxisX)
+	b.Mirror(AxisY)
+	for _, buffer := range []*ObjBuffer{a, b} {
+		// A vertex towards one corner makes the shape asymmetric.
+		p := buffer.VD[7].Scaled(0.8)
+		buffer.VD = append(buffer.VD, p)
+		buffer.V = append(buffer.V, vec3.T{float32(p[0]), float32(p[1]), float32(p[2])})
+	}
+
+	// Act
+	a.AlignToPrincipalAxes()
+	b.AlignToPrincipalAxes()
+
+	// Assert
+	for i := range a.VD {
+		assert.InDeltaSlice(t, a.VD[i][:], b.VD[i][:], 1e-9)
+	}
+}
+
+func TestObjBuffer_Center_OffCenterCube_MovesBoxCenterToOrigin(t *testing.T) {
+	// Arrange
+	buffer := createCube(vec3.T{2, 4, -6}, vec3.T{4, 8, -2}, "")
+	buffer.VN = []vec3.T{{0, 0, 1}}
+	buffer.Offset = dvec3.T{100, 0, 0}
+
+	// Act
+	translation := buffer.Center()
+
+	// Assert
+	assert.Equal(t, dvec3.T{-3, -6, 4}, translation)
+	box := buffer.BoundingBox()
+	assert.Equal(t, vec3.Box{Min: vec3.T{-1, -2, -2}, Max: vec3.T{1, 2, 2}}, box)
+	assert.Equal(t, []vec3.T{{0, 0, 1}}, buffer.VN)
+	assert.Equal(t, dvec3.T{100, 0, 0}, buffer.Offset)
+}
+
+func TestObjBuffer_NormalizeScale_Cube_FitsTargetSize(t *testing.T) {
+	// Arrange
+	buffer := createCube(vec3.T{-1, -2, -4}, vec3.T{1, 2, 4}, "")
+	buffer.VD = buffer.allPositions()
+	buffer.VN = []vec3.T{{0, 1, 0}}
+
+	// Act
+	scale := buffer.NormalizeScale(1)
+
+	// Assert
+	assert.Equal(t, 0.125, scale)
+	box := buffer.BoundingBox()
+	assert.Equal(t, vec3.Box{Min: vec3.T{-0.125, -0.25, -0.5}, Max: vec3.T{0.125, 0.25, 0.5}}, box)
+	assert.Equal(t, dvec3.Box{Min: dvec3.T{-0.125, -0.25, -0.5}, Max: dvec3.T{0.125, 0.25, 0.5}}, positionBounds(buffer.VD))
+	assert.Equal(t, []vec3.T{{0, 1, 0}}, buffer.VN)
+}
+
+func TestObjBuffer_NormalizeScale_NoExtent_LeavesBuffer(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{V: []vec3.T{{1, 1, 1}}}
+
+	// Act
+	scale := buffer.NormalizeScale(2)
+	empty := (&ObjBuffer{}).NormalizeScale(2)
+
+	// Assert
+	assert.Equal(t, 1.0, scale)
+	assert.Equal(t, 1.0, empty)
+	assert.Equal(t, []vec3.T{{1, 1, 1}}, buffer.V)
+}
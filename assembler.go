@@ -0,0 +1,155 @@
+package obj
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+)
+
+// assemblerShards is the number of locks of a BufferAssembler. Parts
+// hashing to different shards are filled without contention.
+const assemblerShards = 16
+
+// BufferAssembler collects elements produced concurrently, such as tiles
+// generated in parallel, into named parts, and assembles them into a
+// single buffer. All its methods may be called from any goroutine. The
+// zero value is ready to use.
+//
+// Faces reference the vertices, normals and texture coordinates of their
+// part by the indices returned when adding them, which start at 0 in every
+// part. Whole buffers may be added to a part too; they keep their own
+// indices.
+type BufferAssembler struct {
+	shards [assemblerShards]assemblerShard
+}
+
+type assemblerShard struct {
+	mu    sync.Mutex
+	parts map[string]*assemblerPart
+}
+
+// assemblerPart holds the elements added one by one to a part, and the
+// buffers added whole.
+type assemblerPart struct {
+	elements ObjBuffer
+	buffers  []*ObjBuffer
+}
+
+// part locks the shard of the part name and returns the part, created if
+// needed, and the function unlocking the shard.
+func (a *BufferAssembler) part(name string) (*assemblerPart, func()) {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	shard := &a.shards[h.Sum32()%assemblerShards]
+	shard.mu.Lock()
+	if shard.parts == nil {
+		shard.parts = make(map[string]*assemblerPart)
+	}
+	p, ok := shard.parts[name]
+	if !ok {
+		p = &assemblerPart{}
+		shard.parts[name] = p
+	}
+	return p, shard.mu.Unlock
+}
+
+// AddVertices adds vertices to part name and returns the index of the first
+// one in the part.
+func (a *BufferAssembler) AddVertices(name string, positions ...dvec3.T) int {
+	p, unlock := a.part(name)
+	defer unlock()
+	first := len(p.elements.V)
+	for _, position := range positions {
+		p.elements.V = append(p.elements.V, vec3.T{float32(position[0]), float32(position[1]), float32(position[2])})
+		p.elements.VD = append(p.elements.VD, position)
+	}
+	return first
+}
+
+// AddNormals adds normals to part name and returns the index of the first
+// one in the part.
+func (a *BufferAssembler) AddNormals(name string, normals ...vec3.T) int {
+	p, unlock := a.part(name)
+	defer unlock()
+	first := len(p.elements.VN)
+	p.elements.VN = append(p.elements.VN, normals...)
+	return first
+}
+
+// AddTexcoords adds texture coordinates to part name and returns the index
+// of the first one in the part.
+func (a *BufferAssembler) AddTexcoords(name string, texcoords ...vec2.T) int {
+	p, unlock := a.part(name)
+	defer unlock()
+	first := len(p.elements.VT)
+	p.elements.VT = append(p.elements.VT, texcoords...)
+	return first
+}
+
+// AddFaces adds faces to part name. Their corners reference the elements
+// added to the part.
+func (a *BufferAssembler) AddFaces(name string, faces ...Face) {
+	p, unlock := a.part(name)
+	defer unlock()
+	p.elements.F = append(p.elements.F, cloneFaces(faces)...)
+}
+
+// AddBuffer adds buffer b to part name. The assembler keeps b, which must
+// not be modified afterwards. Buffers added to the same part by different
+// goroutines follow each other in no particular order.
+func (a *BufferAssembler) AddBuffer(name string, b *ObjBuffer) {
+	p, unlock := a.part(name)
+	defer unlock()
+	p.buffers = append(p.buffers, b)
+}
+
+// Assemble returns a buffer holding all parts, sorted by name, with the
+// references of their faces shifted to the elements of the part. In every
+// part, the elements added one by one come first, in a group named after
+// the part, followed by the buffers added whole, in the order they were
+// added. Buffers without groups get one named after the part. Positions are
+// moved into the offset of the first buffer, as by Merge.
+//
+// Assemble may be called while producers are still adding elements; it then
+// holds the elements added so far.
+func (a *BufferAssembler) Assemble() *ObjBuffer {
+	type named struct {
+		name    string
+		buffers []*ObjBuffer
+	}
+	var parts []named
+	for i := range a.shards {
+		shard := &a.shards[i]
+		shard.mu.Lock()
+		for name, p := range shard.parts {
+			var buffers []*ObjBuffer
+			if e := &p.elements; len(e.V) > 0 || len(e.VN) > 0 || len(e.VT) > 0 || len(e.F) > 0 {
+				// Later additions only append to the slices, past the
+				// elements copied here.
+				elements := *e
+				buffers = append(buffers, &elements)
+			}
+			buffers = append(buffers, p.buffers...)
+			parts = append(parts, named{name, buffers})
+		}
+		shard.mu.Unlock()
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].name < parts[j].name })
+
+	var buffers []*ObjBuffer
+	for _, p := range parts {
+		for _, b := range p.buffers {
+			if len(b.G) == 0 && len(b.F) > 0 {
+				named := *b
+				named.G = []Group{{Name: p.name, FaceCount: len(b.F)}}
+				b = &named
+			}
+			buffers = append(buffers, b)
+		}
+	}
+	return Merge(buffers...)
+}
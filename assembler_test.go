@@ -0,0 +1,84 @@
+package obj
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferAssembler_ConcurrentTiles_AssemblesConsistentBuffer(t *testing.T) {
+	// Arrange
+	var assembler BufferAssembler
+	var wg sync.WaitGroup
+
+	// Act
+	for tile := 0; tile < 8; tile++ {
+		wg.Add(1)
+		go func(tile int) {
+			defer wg.Done()
+			name := fmt.Sprintf("tile_%d", tile)
+			for i := 0; i < 50; i++ {
+				x := float64(tile*100 + i)
+				first := assembler.AddVertices(name, dvec3.T{x, 0, 0}, dvec3.T{x + 1, 0, 0}, dvec3.T{x, 1, 0})
+				assembler.AddFaces(name, Face{Corners: []FaceCorner{{first, -1, -1}, {first + 1, -1, -1}, {first + 2, -1, -1}}})
+			}
+		}(tile)
+	}
+	wg.Wait()
+	buffer := assembler.Assemble()
+
+	// Assert
+	assert.Len(t, buffer.V, 8*50*3)
+	assert.Len(t, buffer.F, 8*50)
+	assert.Len(t, buffer.G, 8)
+	for tile, g := range buffer.G {
+		assert.Equal(t, fmt.Sprintf("tile_%d", tile), g.Name)
+		assert.Equal(t, 50, g.FaceCount)
+		for i := g.FirstFaceIndex; i < g.FirstFaceIndex+g.FaceCount; i++ {
+			c := buffer.F[i].Corners
+			p, q := buffer.VD[c[0].VertexIndex], buffer.VD[c[1].VertexIndex]
+			assert.Equal(t, p[0]+1, q[0])
+			assert.Equal(t, tile, int(p[0])/100)
+		}
+	}
+}
+
+func TestBufferAssembler_AddBuffer_KeepsOwnIndicesAndOffset(t *testing.T) {
+	// Arrange
+	var assembler BufferAssembler
+	first := createGrid(1, 1)
+	second := createGrid(1, 1)
+	second.Offset = dvec3.T{10, 0, 0}
+	second.G = nil
+
+	// Act
+	assembler.AddBuffer("a", first)
+	assembler.AddBuffer("b", second)
+	assembler.AddVertices("b", dvec3.T{0, 0, 5})
+	buffer := assembler.Assemble()
+
+	// Assert
+	assert.Len(t, buffer.V, 9)
+	assert.Equal(t, []string{"floor", "b"}, []string{buffer.G[0].Name, buffer.G[1].Name})
+	assert.Equal(t, dvec3.T{0, 0, 5}, buffer.VD[4])
+	assert.Equal(t, 5, buffer.F[1].Corners[0].VertexIndex)
+	assert.Equal(t, dvec3.T{10, 0, 0}, buffer.positionD(5))
+}
+
+func TestBufferAssembler_AddFaces_CopiesMetadata(t *testing.T) {
+	// Arrange
+	var assembler BufferAssembler
+	assembler.AddVertices("a", dvec3.T{0, 0, 0}, dvec3.T{1, 0, 0}, dvec3.T{0, 1, 0})
+	face := Face{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {2, -1, -1}}, Metadata: map[string]uint32{"building": 7}}
+
+	// Act
+	assembler.AddFaces("a", face)
+	face.Metadata["building"] = 8
+	buffer := assembler.Assemble()
+
+	// Assert
+	assert.Equal(t, map[string]uint32{"building": 7}, buffer.F[0].Metadata)
+}
@@ -0,0 +1,236 @@
+package obj
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AttributeType is the type of the values of an AttributeBuffer.
+type AttributeType int
+
+const (
+	// AttributeFloat attributes hold floating point values, such as LiDAR
+	// intensities.
+	AttributeFloat AttributeType = iota
+	// AttributeInt attributes hold integers, such as classification codes.
+	AttributeInt
+)
+
+func (t AttributeType) String() string {
+	if t == AttributeInt {
+		return "int"
+	}
+	return "float"
+}
+
+// MaxAttributeSize is the largest number of values per vertex of a custom
+// attribute the reader accepts. Attribute headers declaring more are read
+// as plain comments, so that a short file cannot make the reader allocate
+// the values of a huge attribute for every vertex.
+const MaxAttributeSize = 256
+
+// AttributeBuffer holds a custom attribute of the vertices of a buffer,
+// with Size values per vertex.
+//
+// Attributes are written to OBJ files as comments, so that other readers
+// ignore them: an "# attribute NAME TYPE SIZE" header declares each
+// attribute, and a "#va" comment after each vertex lists its values, for
+// all attributes in the order they were declared. The reader restores
+// them; vertices without values get zeros.
+type AttributeBuffer struct {
+	Type AttributeType
+	Size int
+	// Floats holds the values of float attributes, Ints those of int
+	// attributes.
+	Floats []float64
+	Ints   []int64
+}
+
+// Len returns the number of vertices the attribute has values for.
+func (a AttributeBuffer) Len() int {
+	if a.Size <= 0 {
+		return 0
+	}
+	if a.Type == AttributeInt {
+		return len(a.Ints) / a.Size
+	}
+	return len(a.Floats) / a.Size
+}
+
+// Float returns the component k of the value of vertex i as a float.
+func (a AttributeBuffer) Float(i, k int) float64 {
+	if a.Type == AttributeInt {
+		return float64(a.Ints[i*a.Size+k])
+	}
+	return a.Floats[i*a.Size+k]
+}
+
+// appendVertex returns a with the value of vertex i of src appended, or
+// zeros if src does not have the same layout.
+func (a AttributeBuffer) appendVertex(src AttributeBuffer, i int) AttributeBuffer {
+	if src.Type != a.Type || src.Size != a.Size || i < 0 || i >= src.Len() {
+		return a.appendZero()
+	}
+	if a.Type == AttributeInt {
+		a.Ints = append(a.Ints, src.Ints[i*a.Size:(i+1)*a.Size]...)
+	} else {
+		a.Floats = append(a.Floats, src.Floats[i*a.Size:(i+1)*a.Size]...)
+	}
+	return a
+}
+
+// appendZero returns a with a zero value appended.
+func (a AttributeBuffer) appendZero() AttributeBuffer {
+	for k := 0; k < a.Size; k++ {
+		if a.Type == AttributeInt {
+			a.Ints = append(a.Ints, 0)
+		} else {
+			a.Floats = append(a.Floats, 0)
+		}
+	}
+	return a
+}
+
+// emptyCopy returns an attribute with the layout of a and no values.
+func (a AttributeBuffer) emptyCopy() AttributeBuffer {
+	return AttributeBuffer{Type: a.Type, Size: a.Size}
+}
+
+// SetAttribute adds the attribute name to the buffer, replacing any
+// attribute of the same name. It must have a value per vertex, of at most
+// MaxAttributeSize components.
+func (b *ObjBuffer) SetAttribute(name string, a AttributeBuffer) error {
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return fmt.Errorf("Invalid attribute name '%s'", name)
+	}
+	if a.Size <= 0 || a.Size > MaxAttributeSize {
+		return fmt.Errorf("Attribute '%s' has size %d", name, a.Size)
+	}
+	if a.Len() != len(b.V) {
+		return fmt.Errorf("Attribute '%s' has %d values, but the buffer has %d vertices", name, a.Len(), len(b.V))
+	}
+	if b.Attributes == nil {
+		b.Attributes = map[string]AttributeBuffer{}
+	}
+	b.Attributes[name] = a
+	return nil
+}
+
+// attributeNames returns the sorted names of the attributes.
+func (b *ObjBuffer) attributeNames() []string {
+	names := make([]string, 0, len(b.Attributes))
+	for name := range b.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeAttributeHeader declares the attributes of the buffer.
+func (b *ObjBuffer) writeAttributeHeader(w io.Writer) error {
+	for _, name := range b.attributeNames() {
+		a := b.Attributes[name]
+		if _, err := fmt.Fprintf(w, "# attribute %s %s %d\n", name, a.Type, a.Size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatAttributes returns the "#va" comment holding the attribute values
+// of vertex i, or "" if the buffer has no attributes.
+func (b *ObjBuffer) formatAttributes(i int) string {
+	if len(b.Attributes) == 0 {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString("#va")
+	for _, name := range b.attributeNames() {
+		a := b.Attributes[name]
+		for k := 0; k < a.Size; k++ {
+			if i >= a.Len() {
+				s.WriteString(" 0")
+			} else if a.Type == AttributeInt {
+				fmt.Fprintf(&s, " %d", a.Ints[i*a.Size+k])
+			} else {
+				fmt.Fprintf(&s, " %g", a.Floats[i*a.Size+k])
+			}
+		}
+	}
+	return s.String()
+}
+
+// processAttributeComment handles the attribute comments written by
+// ObjBuffer.Write, and reports whether line is one.
+func (l *ObjReader) processAttributeComment(lineNumber int, line string) bool {
+	fields := strings.Fields(line[1:])
+	switch {
+	case len(fields) == 4 && fields[0] == "attribute":
+		a := AttributeBuffer{}
+		switch fields[2] {
+		case "float":
+			a.Type = AttributeFloat
+		case "int":
+			a.Type = AttributeInt
+		default:
+			return false
+		}
+		size, err := strconv.Atoi(fields[3])
+		if err != nil || size <= 0 || size > MaxAttributeSize {
+			return false
+		}
+		a.Size = size
+		if l.Attributes == nil {
+			l.Attributes = map[string]AttributeBuffer{}
+		}
+		name := l.keep(fields[1])
+		if _, ok := l.Attributes[name]; !ok {
+			l.attributeOrder = append(l.attributeOrder, name)
+		}
+		l.Attributes[name] = a
+		return true
+
+	case strings.HasPrefix(line, "#va ") && len(l.attributeOrder) > 0:
+		values := fields[1:]
+		vertex := len(l.V) - 1
+		l.padAttributes(vertex)
+		for _, name := range l.attributeOrder {
+			a := l.Attributes[name]
+			for k := 0; k < a.Size; k++ {
+				value := "0"
+				if len(values) > 0 {
+					value, values = values[0], values[1:]
+				}
+				if a.Type == AttributeInt {
+					v, err := strconv.ParseInt(value, 10, 64)
+					if err != nil {
+						l.warn(Warning{Line: lineNumber, Keyword: "#va", Text: line})
+					}
+					a.Ints = append(a.Ints, v)
+				} else {
+					v, err := parseFloat(value, 64)
+					if err != nil {
+						l.warn(Warning{Line: lineNumber, Keyword: "#va", Text: line})
+					}
+					a.Floats = append(a.Floats, v)
+				}
+			}
+			l.Attributes[name] = a
+		}
+		return true
+	}
+	return false
+}
+
+// padAttributes appends zeros to the attributes up to vertex n, excluded.
+func (l *ObjReader) padAttributes(n int) {
+	for name, a := range l.Attributes {
+		for a.Len() < n {
+			a = a.appendZero()
+		}
+		l.Attributes[name] = a
+	}
+}
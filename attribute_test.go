@@ -0,0 +1,103 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_SetAttribute_WrongLength_ReturnsError(t *testing.T) {
+	// Arrange
+	b := createTriangle()
+
+	// Act
+	err := b.SetAttribute("intensity", AttributeBuffer{Type: AttributeFloat, Size: 1, Floats: []float64{1, 2}})
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestObjBuffer_Write_Attributes_RoundTrip(t *testing.T) {
+	// Arrange
+	b := createTriangle()
+	assert.NoError(t, b.SetAttribute("intensity", AttributeBuffer{Type: AttributeFloat, Size: 1, Floats: []float64{0.5, 0.25, 1}}))
+	assert.NoError(t, b.SetAttribute("class", AttributeBuffer{Type: AttributeInt, Size: 2, Ints: []int64{2, 6, 2, 6, 9, 6}}))
+
+	// Act
+	var out bytes.Buffer
+	err := b.Write(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "# attribute class int 2\n# attribute intensity float 1\n")
+	assert.Contains(t, out.String(), "v 1 0 0\n#va 2 6 0.25\n")
+	loader := &ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader(out.String())))
+	assert.Equal(t, b.Attributes, loader.Attributes)
+	assert.Equal(t, b.Hash(), loader.Hash())
+}
+
+func TestObjReader_Read_MissingAttributeValues_PadsWithZeros(t *testing.T) {
+	// Arrange
+	input := "# attribute intensity float 1\nv 0 0 0\nv 1 0 0\n#va 0.5\nv 0 1 0\n"
+
+	// Act
+	loader := &ObjReader{}
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0, 0.5, 0}, loader.Attributes["intensity"].Floats)
+}
+
+func TestObjReader_Read_AttributeSizeAboveMax_ReadsPlainComment(t *testing.T) {
+	// Arrange
+	input := "# attribute a float 20000000\nv 0 0 0\n#va 1\nv 0 0 0\nv 0 0 0\n"
+
+	// Act
+	loader := &ObjReader{}
+	loader.SetOptions(ReadOptions{KeepComments: true})
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, loader.Attributes)
+	assert.Equal(t, "attribute a float 20000000", loader.Comments[0].Text)
+}
+
+func TestMerge_Attributes_ZeroFillsMissing(t *testing.T) {
+	// Arrange
+	a, b := createTriangle(), createTriangle()
+	assert.NoError(t, a.SetAttribute("class", AttributeBuffer{Type: AttributeInt, Size: 1, Ints: []int64{1, 2, 3}}))
+
+	// Act
+	merged := Merge(a, b)
+
+	// Assert
+	assert.Equal(t, []int64{1, 2, 3, 0, 0, 0}, merged.Attributes["class"].Ints)
+}
+
+func TestScene_WriteGLTF_Attributes_ExportsCustomAttribute(t *testing.T) {
+	// Arrange
+	b := createTriangle()
+	assert.NoError(t, b.SetAttribute("intensity", AttributeBuffer{Type: AttributeFloat, Size: 1, Floats: []float64{0.5, 0.25, 1}}))
+	scene := &Scene{Buffer: b}
+
+	// Act
+	var out bytes.Buffer
+	err := scene.WriteGLTF(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	var doc gltfDocument
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+	accessor, ok := doc.Meshes[0].Primitives[0].Attributes["_INTENSITY"]
+	if assert.True(t, ok) {
+		assert.Equal(t, "SCALAR", doc.Accessors[accessor].Type)
+		assert.Equal(t, 3, doc.Accessors[accessor].Count)
+	}
+	assert.Equal(t, map[string]string{"intensity": "_INTENSITY"}, doc.Meshes[0].Extras.Attributes)
+}
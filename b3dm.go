@@ -0,0 +1,141 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// B3DMOptions controls the export of a scene as a 3D Tiles batched model.
+type B3DMOptions struct {
+	// BatchKey names the face metadata identifying the features, such as
+	// buildings, that are picked individually.
+	BatchKey string
+}
+
+// ExportB3DM writes the scene as a 3D Tiles 1.0 batched 3D model: the
+// binary glTF written by WriteGLB, with a _BATCHID vertex attribute and a
+// batch table.
+//
+// Every distinct value of the BatchKey metadata of the faces is a batch, in
+// ascending order, and faces without it share a last batch. The batch table
+// has a column per metadata name, holding for each batch the value of its
+// first face, or null. The column of the BatchKey holds the values the
+// batches were made from.
+func (s *Scene) ExportB3DM(w io.Writer, options B3DMOptions) error {
+	if options.BatchKey == "" {
+		return fmt.Errorf("Missing batch key")
+	}
+	g := s.newGLTFBuilder()
+	g.batchKey = options.BatchKey
+
+	// Collect the batches and the metadata of their first face.
+	buffers := s.buffers()
+	var ids []uint32
+	first := map[uint32]*Face{}
+	var firstUnbatched *Face
+	names := map[string]bool{}
+	for _, b := range buffers {
+		for i := range b.F {
+			f := &b.F[i]
+			for name := range f.Metadata {
+				names[name] = true
+			}
+			id, ok := f.Metadata[options.BatchKey]
+			if !ok {
+				if firstUnbatched == nil {
+					firstUnbatched = f
+				}
+				continue
+			}
+			if _, ok := first[id]; !ok {
+				first[id] = f
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	g.batches = make(map[uint32]int, len(ids))
+	faces := make([]*Face, len(ids))
+	for i, id := range ids {
+		g.batches[id] = i
+		faces[i] = first[id]
+	}
+	g.nullBatch = len(ids)
+	if firstUnbatched != nil {
+		faces = append(faces, firstUnbatched)
+	}
+
+	batchTable := map[string][]interface{}{}
+	for name := range names {
+		column := make([]interface{}, len(faces))
+		for i, f := range faces {
+			if id, ok := f.Metadata[name]; ok {
+				column[i] = id
+			}
+		}
+		batchTable[name] = column
+	}
+
+	glb, err := encodeGLB(g.build())
+	if err != nil {
+		return err
+	}
+	featureJSON, err := json.Marshal(map[string]int{"BATCH_LENGTH": len(faces)})
+	if err != nil {
+		return err
+	}
+	batchJSON := []byte{}
+	if len(batchTable) > 0 {
+		if batchJSON, err = json.Marshal(batchTable); err != nil {
+			return err
+		}
+	}
+	// The binary glTF must start on an 8-byte boundary.
+	const headerLength = 28
+	featureJSON = padJSON(featureJSON, headerLength)
+	batchJSON = padJSON(batchJSON, headerLength+len(featureJSON))
+
+	var out bytes.Buffer
+	out.WriteString("b3dm")
+	binary.Write(&out, binary.LittleEndian, []uint32{
+		1,
+		uint32(headerLength + len(featureJSON) + len(batchJSON) + len(glb)),
+		uint32(len(featureJSON)), 0,
+		uint32(len(batchJSON)), 0,
+	})
+	out.Write(featureJSON)
+	out.Write(batchJSON)
+	out.Write(glb)
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+// buffers returns the distinct buffers of the scene.
+func (s *Scene) buffers() []*ObjBuffer {
+	var buffers []*ObjBuffer
+	seen := map[*ObjBuffer]bool{}
+	if s.Buffer != nil {
+		buffers = append(buffers, s.Buffer)
+		seen[s.Buffer] = true
+	}
+	for _, n := range s.Nodes {
+		if !seen[n.Buffer] {
+			seen[n.Buffer] = true
+			buffers = append(buffers, n.Buffer)
+		}
+	}
+	return buffers
+}
+
+// padJSON pads js with spaces so that it ends on an 8-byte boundary when
+// it starts at offset.
+func padJSON(js []byte, offset int) []byte {
+	for (offset+len(js))%8 != 0 {
+		js = append(js, ' ')
+	}
+	return js
+}
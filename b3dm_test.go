@@ -0,0 +1,57 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScene_ExportB3DM_FaceMetadata_WritesBatchTable(t *testing.T) {
+	// Arrange
+	loader := &ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader("v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\n"+
+		"f 1 2 3\n#fm building=42 floor=1\nf 1 3 4\n#fm building=7\nf 2 3 4\n")))
+	scene := &Scene{Buffer: &loader.ObjBuffer}
+
+	// Act
+	var out bytes.Buffer
+	err := scene.ExportB3DM(&out, B3DMOptions{BatchKey: "building"})
+
+	// Assert
+	assert.NoError(t, err)
+	data := out.Bytes()
+	assert.Equal(t, "b3dm", string(data[:4]))
+	var header [6]uint32
+	assert.NoError(t, binary.Read(bytes.NewReader(data[4:28]), binary.LittleEndian, &header))
+	assert.Equal(t, uint32(len(data)), header[1])
+
+	var features map[string]int
+	assert.NoError(t, json.Unmarshal(data[28:28+header[2]], &features))
+	assert.Equal(t, 3, features["BATCH_LENGTH"])
+	batchStart := 28 + header[2]
+	var batchTable map[string][]interface{}
+	assert.NoError(t, json.Unmarshal(data[batchStart:batchStart+header[4]], &batchTable))
+	assert.Equal(t, []interface{}{7.0, 42.0, nil}, batchTable["building"])
+	assert.Equal(t, []interface{}{nil, 1.0, nil}, batchTable["floor"])
+
+	glbStart := batchStart + header[4]
+	assert.Equal(t, uint32(0), glbStart%8)
+	assert.Equal(t, "glTF", string(data[glbStart:glbStart+4]))
+	assert.Contains(t, string(data[glbStart:]), `"_BATCHID"`)
+	assert.NotContains(t, string(data[glbStart:]), "EXT_mesh_features")
+}
+
+func TestScene_ExportB3DM_NoBatchKey_ReturnsError(t *testing.T) {
+	// Arrange
+	scene := &Scene{Buffer: createTriangle()}
+
+	// Act
+	err := scene.ExportB3DM(&bytes.Buffer{}, B3DMOptions{})
+
+	// Assert
+	assert.Error(t, err)
+}
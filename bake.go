@@ -0,0 +1,321 @@
+package obj
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+)
+
+// BakeOptions controls how Bake renders the colors of a buffer into a
+// texture.
+type BakeOptions struct {
+	// Width and Height are the size of the texture in pixels. They default
+	// to 1024.
+	Width  int
+	Height int
+	// Padding is the number of pixels the colors are extended beyond the
+	// edges of the charts, so that sampling near a seam does not pick up
+	// the background.
+	Padding int
+	// Materials holds the materials referenced by the faces. The diffuse
+	// color of the material of a face is baked when the buffer has no
+	// vertex colors.
+	Materials map[string]*Material
+	// MaterialName is the name of the returned material. It defaults to
+	// "baked".
+	MaterialName string
+	// TextureName is the file name the returned material refers to as its
+	// diffuse texture. It defaults to MaterialName followed by ".png".
+	TextureName string
+	// KeepUVs bakes into the texture coordinates of the buffer instead of
+	// generating new ones. The texture coordinates must not overlap.
+	KeepUVs bool
+}
+
+// defaultDiffuse is the diffuse color of faces without a known material, the
+// default of the Kd statement.
+var defaultDiffuse = vec3.T{0.8, 0.8, 0.8}
+
+// Bake renders the vertex colors of the buffer or, when it has none, the
+// diffuse colors of the face materials into a texture. Texture coordinates
+// are generated with UVLSCM unless options.KeepUVs is set. Every face is then
+// assigned the returned material, which uses the texture as its diffuse map;
+// the caller is responsible for saving the image under
+// options.TextureName.
+func (b *ObjBuffer) Bake(options BakeOptions) (*image.RGBA, *Material, error) {
+	if options.Width <= 0 {
+		options.Width = 1024
+	}
+	if options.Height <= 0 {
+		options.Height = 1024
+	}
+	if options.MaterialName == "" {
+		options.MaterialName = "baked"
+	}
+	if options.TextureName == "" {
+		options.TextureName = options.MaterialName + ".png"
+	}
+	if !options.KeepUVs {
+		if err := b.GenerateUVs(UVLSCM); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	r := newBakeRaster(options.Width, options.Height)
+	b.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		var uvs [3]vec2.T
+		var colors [3]vec3.T
+		for k, c := range corners {
+			if c.TexcoordIndex < 0 || c.TexcoordIndex >= len(b.VT) {
+				return true
+			}
+			uvs[k] = b.VT[c.TexcoordIndex]
+			colors[k] = b.bakeColor(c.VertexIndex, faceIdx, options.Materials)
+		}
+		r.fillTriangle(uvs, colors)
+		return true
+	})
+	for i := 0; i < options.Padding; i++ {
+		r.dilate()
+	}
+
+	material := &Material{
+		Name:               options.MaterialName,
+		Ambient:            []float32{0.0, 0.0, 0.0, 1.0},
+		Diffuse:            []float32{1.0, 1.0, 1.0, 1.0},
+		Specular:           []float32{0.0, 0.0, 0.0, 1.0},
+		TransmissionFilter: []float32{1.0, 1.0, 1.0},
+		Emissive:           []float32{0.0, 0.0, 0.0, 1.0},
+		DiffuseTexture:     options.TextureName,
+		Opacity:            1,
+	}
+	for i := range b.F {
+		b.F[i].Material = material.Name
+	}
+	b.FaceGroup = faceGroupsOf(b.F)
+	return r.image(), material, nil
+}
+
+// AlphaAttribute is the name of the float attribute BakeMaterialColors
+// stores the opacity of the vertices in, and SamplePoints reads it from.
+const AlphaAttribute = "alpha"
+
+// BakeMaterialColors replaces the vertex colors with the diffuse colors of
+// the materials of the faces, for viewers and formats that ignore material
+// libraries, such as PLY. The opacity of the materials is stored in the
+// AlphaAttribute attribute when a material is not opaque or the buffer
+// already has it. A vertex shared by
+// faces of different colors is duplicated, so that every face keeps its
+// color. Faces without a known material get the default diffuse color;
+// vertices not used by faces keep their color, or get the default one.
+func (b *ObjBuffer) BakeMaterialColors(materials map[string]*Material) {
+	type vertexColor struct {
+		color vec3.T
+		alpha float32
+	}
+	colors := make([]vec3.T, len(b.V))
+	for i := range colors {
+		colors[i] = defaultDiffuse
+		if b.hasVertexColors() {
+			colors[i] = b.VC[i]
+		}
+	}
+	alphas := make([]float32, len(b.V))
+	for i := range alphas {
+		alphas[i] = 1
+	}
+	assigned := make([]bool, len(b.V))
+	duplicates := map[int]map[vertexColor]int{}
+	double := b.hasDoublePrecision()
+	opaque := true
+
+	for i := range b.F {
+		want := vertexColor{color: defaultDiffuse, alpha: 1}
+		if m, ok := materials[b.F[i].Material]; ok {
+			if len(m.Diffuse) >= 3 {
+				want.color = vec3.T{m.Diffuse[0], m.Diffuse[1], m.Diffuse[2]}
+			}
+			want.alpha = float32(math.Max(0, math.Min(1, m.Opacity)))
+		}
+		opaque = opaque && want.alpha == 1
+		recolor := func(corners []FaceCorner) {
+			for j := range corners {
+				v := corners[j].VertexIndex
+				if v < 0 || v >= len(assigned) {
+					continue
+				}
+				if !assigned[v] {
+					assigned[v] = true
+					colors[v], alphas[v] = want.color, want.alpha
+					continue
+				}
+				if (vertexColor{colors[v], alphas[v]}) == want {
+					continue
+				}
+				if duplicates[v] == nil {
+					duplicates[v] = map[vertexColor]int{}
+				}
+				d, ok := duplicates[v][want]
+				if !ok {
+					d = len(b.V)
+					b.V = append(b.V, b.V[v])
+					if double {
+						b.VD = append(b.VD, b.VD[v])
+					}
+					for name, a := range b.Attributes {
+						b.Attributes[name] = a.appendVertex(a, v)
+					}
+					colors = append(colors, want.color)
+					alphas = append(alphas, want.alpha)
+					duplicates[v][want] = d
+				}
+				corners[j].VertexIndex = d
+			}
+		}
+		recolor(b.F[i].Corners)
+		for _, hole := range b.F[i].Holes {
+			recolor(hole)
+		}
+	}
+
+	b.VC = colors
+	if _, ok := b.Attributes[AlphaAttribute]; ok || !opaque {
+		alpha := AttributeBuffer{Type: AttributeFloat, Size: 1, Floats: make([]float64, len(alphas))}
+		for i, a := range alphas {
+			alpha.Floats[i] = float64(a)
+		}
+		b.SetAttribute(AlphaAttribute, alpha)
+	}
+}
+
+// bakeColor returns the color of a vertex of face faceIdx.
+func (b *ObjBuffer) bakeColor(vertex, faceIdx int, materials map[string]*Material) vec3.T {
+	if b.hasVertexColors() && vertex >= 0 && vertex < len(b.VC) {
+		return b.VC[vertex]
+	}
+	if m, ok := materials[b.F[faceIdx].Material]; ok && len(m.Diffuse) >= 3 {
+		return vec3.T{m.Diffuse[0], m.Diffuse[1], m.Diffuse[2]}
+	}
+	return defaultDiffuse
+}
+
+// bakeRaster is a floating point color buffer with a coverage mask.
+type bakeRaster struct {
+	width, height int
+	colors        []vec3.T
+	covered       []bool
+}
+
+func newBakeRaster(width, height int) *bakeRaster {
+	return &bakeRaster{
+		width:   width,
+		height:  height,
+		colors:  make([]vec3.T, width*height),
+		covered: make([]bool, width*height),
+	}
+}
+
+// fillTriangle sets the pixels whose center lies in the triangle to the
+// interpolated vertex colors. Texture space has v pointing up while image
+// rows go down.
+func (r *bakeRaster) fillTriangle(uvs [3]vec2.T, colors [3]vec3.T) {
+	var xs, ys [3]float64
+	for k, uv := range uvs {
+		xs[k] = float64(uv[0]) * float64(r.width)
+		ys[k] = (1 - float64(uv[1])) * float64(r.height)
+	}
+	area := (xs[1]-xs[0])*(ys[2]-ys[0]) - (xs[2]-xs[0])*(ys[1]-ys[0])
+	if area == 0 {
+		return
+	}
+	minX := clampInt(int(math.Floor(math.Min(xs[0], math.Min(xs[1], xs[2])))), 0, r.width-1)
+	maxX := clampInt(int(math.Ceil(math.Max(xs[0], math.Max(xs[1], xs[2])))), 0, r.width-1)
+	minY := clampInt(int(math.Floor(math.Min(ys[0], math.Min(ys[1], ys[2])))), 0, r.height-1)
+	maxY := clampInt(int(math.Ceil(math.Max(ys[0], math.Max(ys[1], ys[2])))), 0, r.height-1)
+
+	const eps = 1e-9
+	for y := minY; y <= maxY; y++ {
+		py := float64(y) + 0.5
+		for x := minX; x <= maxX; x++ {
+			px := float64(x) + 0.5
+			w0 := ((xs[1]-px)*(ys[2]-py) - (xs[2]-px)*(ys[1]-py)) / area
+			w1 := ((xs[2]-px)*(ys[0]-py) - (xs[0]-px)*(ys[2]-py)) / area
+			w2 := 1 - w0 - w1
+			if w0 < -eps || w1 < -eps || w2 < -eps {
+				continue
+			}
+			var c vec3.T
+			for k := range c {
+				c[k] = float32(w0)*colors[0][k] + float32(w1)*colors[1][k] + float32(w2)*colors[2][k]
+			}
+			r.colors[y*r.width+x] = c
+			r.covered[y*r.width+x] = true
+		}
+	}
+}
+
+// dilate extends the covered area by one pixel, setting every uncovered
+// pixel next to covered ones to their average color.
+func (r *bakeRaster) dilate() {
+	var grown []int
+	for y := 0; y < r.height; y++ {
+		for x := 0; x < r.width; x++ {
+			i := y*r.width + x
+			if r.covered[i] {
+				continue
+			}
+			var sum vec3.T
+			n := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= r.width || ny >= r.height || !r.covered[ny*r.width+nx] {
+						continue
+					}
+					sum.Add(&r.colors[ny*r.width+nx])
+					n++
+				}
+			}
+			if n > 0 {
+				r.colors[i] = sum.Scaled(1 / float32(n))
+				grown = append(grown, i)
+			}
+		}
+	}
+	for _, i := range grown {
+		r.covered[i] = true
+	}
+}
+
+// image returns the raster as an image, with uncovered pixels transparent.
+func (r *bakeRaster) image() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, r.width, r.height))
+	for y := 0; y < r.height; y++ {
+		for x := 0; x < r.width; x++ {
+			i := y*r.width + x
+			if !r.covered[i] {
+				continue
+			}
+			c := r.colors[i]
+			img.SetRGBA(x, y, color.RGBA{colorByte(c[0]), colorByte(c[1]), colorByte(c[2]), 255})
+		}
+	}
+	return img
+}
+
+func colorByte(c float32) uint8 {
+	return uint8(clampInt(int(math.Round(float64(c)*255)), 0, 255))
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
@@ -0,0 +1,119 @@
+package obj
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_Bake_VertexColors_RendersInterpolatedColors(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0 1 0 0\nv 1 0 0 1 0 0\nv 1 1 0 1 0 0\nv 0 1 0 1 0 0\n" +
+		"vt 0 0\nvt 1 0\nvt 1 1\nvt 0 1\n" +
+		"usemtl a\nf 1/1 2/2 3/3\nusemtl b\nf 1/1 3/3 4/4\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+
+	// Act
+	img, material, err := loader.Bake(BakeOptions{Width: 8, Height: 8, KeepUVs: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "baked", material.Name)
+	assert.Equal(t, "baked.png", material.DiffuseTexture)
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, img.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, img.RGBAAt(7, 7))
+	assert.Equal(t, []*FaceGroup{{Offset: 0, Size: 2, Material: "baked"}}, loader.FaceGroup)
+}
+
+func TestObjBuffer_Bake_Materials_RendersDiffuseColors(t *testing.T) {
+	// Arrange
+	cube := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "stone")
+	cube.F[0].Material = "moss"
+	materials := map[string]*Material{
+		"stone": {Name: "stone", Diffuse: []float32{0.5, 0.5, 0.5, 1}},
+		"moss":  {Name: "moss", Diffuse: []float32{0, 1, 0, 1}},
+	}
+
+	// Act
+	img, material, err := cube.Bake(BakeOptions{Width: 64, Height: 64, Padding: 2, Materials: materials, MaterialName: "atlas"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "atlas.png", material.DiffuseTexture)
+	counts := map[color.RGBA]int{}
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			counts[img.RGBAAt(x, y)]++
+		}
+	}
+	assert.True(t, counts[color.RGBA{0, 255, 0, 255}] > 0)
+	assert.True(t, counts[color.RGBA{128, 128, 128, 255}] > 5*counts[color.RGBA{0, 255, 0, 255}]/2)
+	for _, f := range cube.F {
+		assert.Equal(t, "atlas", f.Material)
+		for _, c := range f.Corners {
+			assert.True(t, c.TexcoordIndex >= 0)
+		}
+	}
+}
+
+func TestObjBuffer_BakeMaterialColors_SharedVertices_Duplicated(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\n" +
+		"usemtl red\nf 1 2 3\nusemtl glass\nf 1 3 4\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+	materials := map[string]*Material{
+		"red":   {Name: "red", Diffuse: []float32{1, 0, 0}, Opacity: 1},
+		"glass": {Name: "glass", Diffuse: []float32{0, 0, 1}, Opacity: 0.25},
+	}
+
+	// Act
+	loader.BakeMaterialColors(materials)
+
+	// Assert
+	assert.Len(t, loader.V, 6)
+	assert.Len(t, loader.VC, 6)
+	alpha := loader.Attributes[AlphaAttribute]
+	for _, c := range loader.F[0].Corners {
+		assert.Equal(t, vec3.T{1, 0, 0}, loader.VC[c.VertexIndex])
+		assert.Equal(t, 1.0, alpha.Float(c.VertexIndex, 0))
+	}
+	for _, c := range loader.F[1].Corners {
+		assert.Equal(t, vec3.T{0, 0, 1}, loader.VC[c.VertexIndex])
+		assert.Equal(t, 0.25, alpha.Float(c.VertexIndex, 0))
+	}
+	assert.Equal(t, loader.V[loader.F[0].Corners[0].VertexIndex], loader.V[loader.F[1].Corners[0].VertexIndex])
+}
+
+func TestObjBuffer_BakeMaterialColors_Opaque_NoAlpha(t *testing.T) {
+	// Arrange
+	cube := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "stone")
+	vertices := len(cube.V)
+	materials := map[string]*Material{"stone": {Name: "stone", Diffuse: []float32{0.5, 0.5, 0.5}, Opacity: 1}}
+
+	// Act
+	cube.BakeMaterialColors(materials)
+
+	// Assert
+	assert.Len(t, cube.V, vertices)
+	assert.Equal(t, vec3.T{0.5, 0.5, 0.5}, cube.VC[0])
+	assert.NotContains(t, cube.Attributes, AlphaAttribute)
+}
+
+func TestBakeRaster_Dilate_ExtendsCoveredPixels(t *testing.T) {
+	// Arrange
+	r := newBakeRaster(3, 1)
+	r.colors[0] = vec3.T{1, 1, 1}
+	r.covered[0] = true
+
+	// Act
+	r.dilate()
+
+	// Assert
+	assert.Equal(t, []bool{true, true, false}, r.covered)
+	assert.Equal(t, vec3.T{1, 1, 1}, r.colors[1])
+}
@@ -0,0 +1,224 @@
+package obj
+
+import (
+	"math"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+)
+
+// Sphere is a bounding sphere, in the frame of the buffer it was computed
+// from.
+type Sphere struct {
+	Center dvec3.T
+	Radius float64
+}
+
+// OBB is an oriented bounding box, in the frame of the buffer it was
+// computed from. The box spans HalfSize[k] on either side of Center along
+// Axes[k]. The axes are orthonormal and right-handed, the first one being
+// the direction in which the vertices spread the most.
+type OBB struct {
+	Center   dvec3.T
+	Axes     [3]dvec3.T
+	HalfSize dvec3.T
+}
+
+// Corners returns the 8 corners of the box.
+func (o *OBB) Corners() [8]dvec3.T {
+	var corners [8]dvec3.T
+	for i := range corners {
+		corners[i] = o.Center
+		for k := 0; k < 3; k++ {
+			d := o.Axes[k].Scaled(o.HalfSize[k])
+			if i>>uint(k)&1 == 0 {
+				d.Invert()
+			}
+			corners[i].Add(&d)
+		}
+	}
+	return corners
+}
+
+// Volume returns the volume of the box.
+func (o *OBB) Volume() float64 {
+	return 8 * o.HalfSize[0] * o.HalfSize[1] * o.HalfSize[2]
+}
+
+// BoundingSphere returns a sphere containing all vertices. It is computed
+// with Ritter's algorithm, which is fast but may be a few percent larger
+// than the smallest sphere. An empty buffer has an empty sphere at the
+// origin.
+func (b *ObjBuffer) BoundingSphere() Sphere {
+	return boundingSphere(b.allPositions())
+}
+
+// OBB returns a box containing all vertices, aligned on their principal
+// axes.
+func (b *ObjBuffer) OBB() OBB {
+	return orientedBox(b.allPositions())
+}
+
+// BoundingSphere returns a sphere containing the vertices of the faces of
+// the group, like ObjBuffer.BoundingSphere.
+func (g *Group) BoundingSphere(b *ObjBuffer) Sphere {
+	return boundingSphere(g.positions(b))
+}
+
+// OBB returns a box containing the vertices of the faces of the group, like
+// ObjBuffer.OBB.
+func (g *Group) OBB(b *ObjBuffer) OBB {
+	return orientedBox(g.positions(b))
+}
+
+func (b *ObjBuffer) allPositions() []dvec3.T {
+	positions := make([]dvec3.T, len(b.V))
+	for i := range b.V {
+		positions[i] = b.positionD(i)
+	}
+	return positions
+}
+
+// positions returns the positions of the vertices the faces of the group
+// reference, each once.
+func (g *Group) positions(b *ObjBuffer) []dvec3.T {
+	seen := make(map[int]bool)
+	var positions []dvec3.T
+	for i := g.FirstFaceIndex; i < g.FirstFaceIndex+g.FaceCount && i < len(b.F); i++ {
+		for _, loop := range b.F[i].loops() {
+			for _, c := range loop {
+				if c.VertexIndex >= 0 && c.VertexIndex < len(b.V) && !seen[c.VertexIndex] {
+					seen[c.VertexIndex] = true
+					positions = append(positions, b.positionD(c.VertexIndex))
+				}
+			}
+		}
+	}
+	return positions
+}
+
+func boundingSphere(points []dvec3.T) Sphere {
+	if len(points) == 0 {
+		return Sphere{}
+	}
+	farthest := func(from dvec3.T) dvec3.T {
+		best, distance := from, -1.0
+		for _, p := range points {
+			if d := dvec3.Distance(&p, &from); d > distance {
+				best, distance = p, d
+			}
+		}
+		return best
+	}
+	// Start from the sphere over two distant points, then grow it to
+	// include the points left outside.
+	a := farthest(points[0])
+	c := farthest(a)
+	s := Sphere{Center: dvec3.Interpolate(&a, &c, 0.5), Radius: dvec3.Distance(&a, &c) / 2}
+	for _, p := range points {
+		d := dvec3.Distance(&p, &s.Center)
+		if d <= s.Radius {
+			continue
+		}
+		radius := (s.Radius + d) / 2
+		s.Center = dvec3.Interpolate(&s.Center, &p, (radius-s.Radius)/d)
+		s.Radius = radius
+	}
+	return s
+}
+
+func orientedBox(points []dvec3.T) OBB {
+	o := OBB{Axes: [3]dvec3.T{dvec3.UnitX, dvec3.UnitY, dvec3.UnitZ}}
+	if len(points) == 0 {
+		return o
+	}
+	o.Axes = principalAxes(points)
+	min := dvec3.T{math.Inf(1), math.Inf(1), math.Inf(1)}
+	max := dvec3.T{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+	for _, p := range points {
+		for k := 0; k < 3; k++ {
+			d := dvec3.Dot(&p, &o.Axes[k])
+			min[k] = math.Min(min[k], d)
+			max[k] = math.Max(max[k], d)
+		}
+	}
+	for k := 0; k < 3; k++ {
+		o.HalfSize[k] = (max[k] - min[k]) / 2
+		along := o.Axes[k].Scaled((max[k] + min[k]) / 2)
+		o.Center.Add(&along)
+	}
+	return o
+}
+
+// principalAxes returns the eigenvectors of the covariance of the points,
+// by decreasing eigenvalue, forming a right-handed frame.
+func principalAxes(points []dvec3.T) [3]dvec3.T {
+	var mean dvec3.T
+	for i := range points {
+		mean.Add(&points[i])
+	}
+	mean.Scale(1 / float64(len(points)))
+	var covariance [3][3]float64
+	for _, p := range points {
+		d := dvec3.Sub(&p, &mean)
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				covariance[i][j] += d[i] * d[j]
+			}
+		}
+	}
+
+	values, vectors := jacobiEigen(covariance)
+	order := [3]int{0, 1, 2}
+	for i := 0; i < 3; i++ {
+		for j := i + 1; j < 3; j++ {
+			if values[order[j]] > values[order[i]] {
+				order[i], order[j] = order[j], order[i]
+			}
+		}
+	}
+	var axes [3]dvec3.T
+	for k := 0; k < 3; k++ {
+		axes[k] = dvec3.T{vectors[0][order[k]], vectors[1][order[k]], vectors[2][order[k]]}
+		axes[k].Normalize()
+	}
+	axes[2] = dvec3.Cross(&axes[0], &axes[1])
+	axes[2].Normalize()
+	return axes
+}
+
+// jacobiEigen returns the eigenvalues of the symmetric matrix m and its
+// eigenvectors as the columns of the second result, using Jacobi rotations.
+func jacobiEigen(m [3][3]float64) ([3]float64, [3][3]float64) {
+	v := [3][3]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	for sweep := 0; sweep < 50; sweep++ {
+		off := m[0][1]*m[0][1] + m[0][2]*m[0][2] + m[1][2]*m[1][2]
+		if off < 1e-30*(m[0][0]*m[0][0]+m[1][1]*m[1][1]+m[2][2]*m[2][2]) || off == 0 {
+			break
+		}
+		for p := 0; p < 2; p++ {
+			for q := p + 1; q < 3; q++ {
+				if m[p][q] == 0 {
+					continue
+				}
+				// Rotate in the (p, q) plane to zero m[p][q].
+				theta := (m[q][q] - m[p][p]) / (2 * m[p][q])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < 3; k++ {
+					mkp, mkq := m[k][p], m[k][q]
+					m[k][p], m[k][q] = c*mkp-s*mkq, s*mkp+c*mkq
+				}
+				for k := 0; k < 3; k++ {
+					mpk, mqk := m[p][k], m[q][k]
+					m[p][k], m[q][k] = c*mpk-s*mqk, s*mpk+c*mqk
+				}
+				for k := 0; k < 3; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p], v[k][q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+	return [3]float64{m[0][0], m[1][1], m[2][2]}, v
+}
@@ -0,0 +1,107 @@
+package obj
+
+import (
+	"math"
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+// createRotatedBox returns the 8 corners of a box of the given half size
+// centered on center, rotated by 30 degrees around Z then 45 degrees around
+// X, with the 6 faces.
+func createRotatedBox(center dvec3.T, halfSize dvec3.T) *ObjBuffer {
+	buffer := &ObjBuffer{}
+	a, c := math.Pi/6, math.Pi/4
+	for i := 0; i < 8; i++ {
+		p := dvec3.T{halfSize[0], halfSize[1], halfSize[2]}
+		for k := 0; k < 3; k++ {
+			if i>>uint(k)&1 == 0 {
+				p[k] = -p[k]
+			}
+		}
+		p = dvec3.T{p[0]*math.Cos(a) - p[1]*math.Sin(a), p[0]*math.Sin(a) + p[1]*math.Cos(a), p[2]}
+		p = dvec3.T{p[0], p[1]*math.Cos(c) - p[2]*math.Sin(c), p[1]*math.Sin(c) + p[2]*math.Cos(c)}
+		p.Add(&center)
+		buffer.VD = append(buffer.VD, p)
+		buffer.V = append(buffer.V, vec3.T{float32(p[0]), float32(p[1]), float32(p[2])})
+	}
+	for _, quad := range [][4]int{{0, 2, 3, 1}, {4, 5, 7, 6}, {0, 1, 5, 4}, {2, 6, 7, 3}, {0, 4, 6, 2}, {1, 3, 7, 5}} {
+		var corners []FaceCorner
+		for _, v := range quad {
+			corners = append(corners, FaceCorner{VertexIndex: v, NormalIndex: -1, TexcoordIndex: -1})
+		}
+		buffer.F = append(buffer.F, Face{Corners: corners})
+	}
+	buffer.G = []Group{{Name: "box", FaceCount: len(buffer.F)}}
+	buffer.FaceGroup = faceGroupsOf(buffer.F)
+	return buffer
+}
+
+func TestObjBuffer_OBB_RotatedBox_FindsBox(t *testing.T) {
+	// Arrange
+	buffer := createRotatedBox(dvec3.T{10, -5, 3}, dvec3.T{4, 2, 1})
+
+	// Act
+	box := buffer.OBB()
+
+	// Assert
+	assert.InDeltaSlice(t, []float64{4, 2, 1}, box.HalfSize[:], 1e-9)
+	assert.InDeltaSlice(t, []float64{10, -5, 3}, box.Center[:], 1e-9)
+	assert.InDelta(t, 64, box.Volume(), 1e-8)
+	cross := dvec3.Cross(&box.Axes[0], &box.Axes[1])
+	assert.InDelta(t, 1, dvec3.Dot(&cross, &box.Axes[2]), 1e-9)
+	corners := box.Corners()
+	for _, p := range buffer.VD {
+		found := false
+		for _, c := range corners {
+			found = found || dvec3.Distance(&p, &c) < 1e-9
+		}
+		assert.True(t, found, "vertex %v is not a corner", p)
+	}
+}
+
+func TestObjBuffer_BoundingSphere_Box_ContainsAllVertices(t *testing.T) {
+	// Arrange
+	buffer := createRotatedBox(dvec3.T{1, 2, 3}, dvec3.T{1, 1, 1})
+
+	// Act
+	sphere := buffer.BoundingSphere()
+
+	// Assert
+	assert.InDelta(t, math.Sqrt(3), sphere.Radius, math.Sqrt(3)*0.05)
+	for _, p := range buffer.VD {
+		assert.True(t, dvec3.Distance(&p, &sphere.Center) <= sphere.Radius+1e-9)
+	}
+}
+
+func TestGroup_BoundingSphere_OnlyCoversGroupFaces(t *testing.T) {
+	// Arrange
+	buffer := createGrid(4, 2)
+	buffer.G = []Group{{Name: "low", FaceCount: 2}, {Name: "high", FirstFaceIndex: 2, FaceCount: 2}}
+
+	// Act
+	sphere := buffer.G[0].BoundingSphere(buffer)
+	box := buffer.G[1].OBB(buffer)
+
+	// Assert
+	assert.InDeltaSlice(t, []float64{2, 1, 0}, sphere.Center[:], 1e-9)
+	assert.InDelta(t, math.Sqrt(5), sphere.Radius, 1e-9)
+	assert.InDeltaSlice(t, []float64{2, 3, 0}, box.Center[:], 1e-9)
+	assert.InDeltaSlice(t, []float64{2, 1, 0}, box.HalfSize[:], 1e-9)
+}
+
+func TestObjBuffer_BoundingSphere_Empty_IsEmpty(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{}
+
+	// Act
+	sphere := buffer.BoundingSphere()
+	box := buffer.OBB()
+
+	// Assert
+	assert.Equal(t, Sphere{}, sphere)
+	assert.Equal(t, dvec3.T{}, box.HalfSize)
+}
@@ -0,0 +1,170 @@
+package obj
+
+import (
+	"sort"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+)
+
+// BufferView is a range of faces of a parent buffer, seen as a buffer of its
+// own without copying it: the vertices, normals and texture coordinates of
+// the view are those the faces reference, numbered from 0 in the order of
+// the parent, and the corners of the faces are translated to these numbers
+// when they are read. It holds only the parent indices of the elements it
+// uses, so processing a large buffer group by group does not copy it the
+// way ExtractGroups does.
+//
+// The parent must not be modified while the view is in use.
+type BufferView struct {
+	parent    *ObjBuffer
+	first     int
+	count     int
+	vertices  []int
+	normals   []int
+	texcoords []int
+}
+
+// View returns the view of count faces starting at face first. The range is
+// clipped to the faces of the buffer.
+func (b *ObjBuffer) View(first, count int) *BufferView {
+	if first < 0 {
+		count += first
+		first = 0
+	}
+	if first > len(b.F) {
+		first = len(b.F)
+	}
+	if first+count > len(b.F) {
+		count = len(b.F) - first
+	}
+	if count < 0 {
+		count = 0
+	}
+	v := &BufferView{parent: b, first: first, count: count}
+	used := func(c FaceCorner) {
+		if c.VertexIndex >= 0 && c.VertexIndex < len(b.V) {
+			v.vertices = append(v.vertices, c.VertexIndex)
+		}
+		if c.NormalIndex >= 0 && c.NormalIndex < len(b.VN) {
+			v.normals = append(v.normals, c.NormalIndex)
+		}
+		if c.TexcoordIndex >= 0 && c.TexcoordIndex < len(b.VT) {
+			v.texcoords = append(v.texcoords, c.TexcoordIndex)
+		}
+	}
+	for _, f := range b.F[first : first+count] {
+		for _, loop := range f.loops() {
+			for _, c := range loop {
+				used(c)
+			}
+		}
+	}
+	v.vertices = uniqueInts(v.vertices)
+	v.normals = uniqueInts(v.normals)
+	v.texcoords = uniqueInts(v.texcoords)
+	return v
+}
+
+// View returns the view of the faces of the group in b.
+func (g *Group) View(b *ObjBuffer) *BufferView {
+	return b.View(g.FirstFaceIndex, g.FaceCount)
+}
+
+// Parent returns the buffer the view is a part of.
+func (v *BufferView) Parent() *ObjBuffer { return v.parent }
+
+// NumFaces returns the number of faces.
+func (v *BufferView) NumFaces() int { return v.count }
+
+// ParentFace returns the index in the parent of face i.
+func (v *BufferView) ParentFace(i int) int { return v.first + i }
+
+// Face returns face i with its corners translated to the view. The corners
+// are copied; the metadata is shared with the parent.
+func (v *BufferView) Face(i int) Face {
+	f := v.parent.F[v.first+i]
+	translated := Face{Material: f.Material, SmoothingGroup: f.SmoothingGroup, Metadata: f.Metadata}
+	translated.Corners = v.translate(f.Corners)
+	for _, hole := range f.Holes {
+		translated.Holes = append(translated.Holes, v.translate(hole))
+	}
+	return translated
+}
+
+// FaceMaterial returns the material of face i.
+func (v *BufferView) FaceMaterial(i int) string { return v.parent.F[v.first+i].Material }
+
+// NumVertices returns the number of vertices used by the faces.
+func (v *BufferView) NumVertices() int { return len(v.vertices) }
+
+// Vertex returns the position of vertex i.
+func (v *BufferView) Vertex(i int) vec3.T { return v.parent.V[v.vertices[i]] }
+
+// VertexD returns the position of vertex i in double precision, relative to
+// the offset of the parent.
+func (v *BufferView) VertexD(i int) dvec3.T { return v.parent.positionD(v.vertices[i]) }
+
+// ParentVertex returns the index in the parent of vertex i.
+func (v *BufferView) ParentVertex(i int) int { return v.vertices[i] }
+
+// NumNormals returns the number of normals used by the faces.
+func (v *BufferView) NumNormals() int { return len(v.normals) }
+
+// Normal returns normal i.
+func (v *BufferView) Normal(i int) vec3.T { return v.parent.VN[v.normals[i]] }
+
+// NumTexCoords returns the number of texture coordinates used by the faces.
+func (v *BufferView) NumTexCoords() int { return len(v.texcoords) }
+
+// TexCoord returns texture coordinate i.
+func (v *BufferView) TexCoord(i int) vec2.T { return v.parent.VT[v.texcoords[i]] }
+
+// EachTriangle calls fn for every triangle of the view like
+// ObjBuffer.EachTriangle, with the corners translated to the view and the
+// index of the face in the view.
+func (v *BufferView) EachTriangle(fn func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool) {
+	faces := ObjBuffer{V: v.parent.V, F: v.parent.F[v.first : v.first+v.count]}
+	faces.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		for k := range corners {
+			corners[k] = v.translateCorner(corners[k])
+		}
+		return fn(tri, corners, faceIdx)
+	})
+}
+
+// Buffer copies the view into a new buffer with ObjBuffer.CloneSubset. The
+// copy numbers its elements in the order the faces first use them, which
+// may differ from the view.
+func (v *BufferView) Buffer() *ObjBuffer {
+	return v.parent.CloneSubset(v.first, v.count)
+}
+
+func (v *BufferView) translate(corners []FaceCorner) []FaceCorner {
+	translated := make([]FaceCorner, len(corners))
+	for j, c := range corners {
+		translated[j] = v.translateCorner(c)
+	}
+	return translated
+}
+
+// translateCorner translates the indices of a corner of the parent to the
+// view. Like subset, it keeps indices that do not reference an element as
+// they are.
+func (v *BufferView) translateCorner(c FaceCorner) FaceCorner {
+	return FaceCorner{
+		VertexIndex:   localIndex(v.vertices, c.VertexIndex),
+		NormalIndex:   localIndex(v.normals, c.NormalIndex),
+		TexcoordIndex: localIndex(v.texcoords, c.TexcoordIndex),
+	}
+}
+
+// localIndex returns the position of idx in the sorted indices, or idx if
+// it is not one of them.
+func localIndex(indices []int, idx int) int {
+	if i := sort.SearchInts(indices, idx); i < len(indices) && indices[i] == idx {
+		return i
+	}
+	return idx
+}
@@ -0,0 +1,74 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_View_TranslatesCornersToUsedVertices(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\nv 1 1 0\nv 2 2 0\n" +
+		"g a\nf 1 2 3\ng b\nf 2 4 3\nf 4 5 3\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+
+	// Act
+	view := loader.G[1].View(&loader.ObjBuffer)
+
+	// Assert
+	assert.Equal(t, 2, view.NumFaces())
+	assert.Equal(t, 4, view.NumVertices())
+	assert.Equal(t, 1, view.ParentFace(0))
+	assert.Equal(t, []int{0, 2, 1}, vertexIndices(view.Face(0).Corners))
+	assert.Equal(t, []int{2, 3, 1}, vertexIndices(view.Face(1).Corners))
+	assert.Equal(t, vec3.T{2, 2, 0}, view.Vertex(3))
+	assert.Equal(t, 4, view.ParentVertex(3))
+}
+
+func TestBufferView_EachTriangle_MatchesCopy(t *testing.T) {
+	// Arrange
+	b := createGrid(4, 4)
+	view := b.View(5, 6)
+	copied := view.Buffer()
+	var fromView, fromCopy [][3]vec3.T
+
+	// Act
+	view.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, _ int) bool {
+		for k := range corners {
+			assert.Equal(t, tri[k], view.Vertex(corners[k].VertexIndex))
+		}
+		fromView = append(fromView, tri)
+		return true
+	})
+	copied.EachTriangle(func(tri [3]vec3.T, _ [3]FaceCorner, _ int) bool {
+		fromCopy = append(fromCopy, tri)
+		return true
+	})
+
+	// Assert
+	assert.Equal(t, fromCopy, fromView)
+	assert.Equal(t, len(copied.V), view.NumVertices())
+}
+
+func TestObjBuffer_View_OutOfRange_Empty(t *testing.T) {
+	// Arrange
+	b := createGrid(1, 2)
+
+	// Act
+	view := b.View(10, 3)
+
+	// Assert
+	assert.Equal(t, 0, view.NumFaces())
+	assert.Equal(t, 0, view.NumVertices())
+}
+
+func vertexIndices(corners []FaceCorner) []int {
+	var indices []int
+	for _, c := range corners {
+		indices = append(indices, c.VertexIndex)
+	}
+	return indices
+}
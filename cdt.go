@@ -0,0 +1,127 @@
+package obj
+
+import (
+	"math"
+
+	"github.com/flywave/go3d/vec3"
+)
+
+// TriangulationMethod selects how faces are split into triangles.
+type TriangulationMethod int
+
+const (
+	// EarClip clips the corners of the face one after the other. It is fast
+	// but produces fans of long, thin triangles on large faces.
+	EarClip TriangulationMethod = iota
+	// CDT computes the constrained Delaunay triangulation of the face,
+	// which maximizes the smallest angle of the triangles while keeping the
+	// edges of the face and of its holes.
+	CDT
+)
+
+// TriangulateOptions controls how faces are split into triangles.
+type TriangulateOptions struct {
+	Method TriangulationMethod
+}
+
+// TriangulateWith splits the face into triangles like Triangulate, using
+// the given method. The corners of the face are left unchanged.
+func (f *Face) TriangulateWith(V []vec3.T, options TriangulateOptions) [][]FaceCorner {
+	if options.Method != CDT || len(f.Corners) <= 3 && len(f.Holes) == 0 {
+		return f.Triangulate(V)
+	}
+	loops, ok := f.projectLoops(V)
+	if !ok {
+		return f.Triangulate(V)
+	}
+	triangles := earClip(bridgeHoles(loops[0], loops[1:]))
+	constrained := make(map[Edge]bool)
+	for _, loop := range loops {
+		for i := range loop {
+			constrained[NewEdge(loop[i].id, loop[(i+1)%len(loop)].id)] = true
+		}
+	}
+	flipToDelaunay(triangles, constrained)
+	return triangleCorners(triangles)
+}
+
+// flipToDelaunay flips the unconstrained edges shared by two triangles
+// until no triangle has a corner of its neighbour inside its circumcircle,
+// turning any triangulation of the polygon into its constrained Delaunay
+// triangulation. The triangles are counterclockwise and stay so.
+func flipToDelaunay(triangles [][3]polygonNode, constrained map[Edge]bool) {
+	// edges maps every directed edge to the triangle it belongs to.
+	edges := make(map[[2]int]int, 3*len(triangles))
+	index := func(t int) {
+		for k := 0; k < 3; k++ {
+			edges[[2]int{triangles[t][k].id, triangles[t][(k+1)%3].id}] = t
+		}
+	}
+	unindex := func(t int) {
+		for k := 0; k < 3; k++ {
+			delete(edges, [2]int{triangles[t][k].id, triangles[t][(k+1)%3].id})
+		}
+	}
+	for t, tri := range triangles {
+		if tri[0].id == tri[1].id || tri[1].id == tri[2].id || tri[2].id == tri[0].id {
+			// Bridging collapsed a triangle; the mesh cannot be walked.
+			return
+		}
+		index(t)
+	}
+
+	// Every flip strictly improves the triangulation, the limit only guards
+	// against rounding errors.
+	for flips := 0; flips < len(triangles)*len(triangles)+1; {
+		flipped := false
+		for t := range triangles {
+			for k := 0; k < 3; k++ {
+				a, b, c := triangles[t][k], triangles[t][(k+1)%3], triangles[t][(k+2)%3]
+				if constrained[NewEdge(a.id, b.id)] {
+					continue
+				}
+				u, ok := edges[[2]int{b.id, a.id}]
+				if !ok || u == t {
+					continue
+				}
+				var d polygonNode
+				for _, n := range triangles[u] {
+					if n.id != a.id && n.id != b.id {
+						d = n
+					}
+				}
+				if !inCircumcircle(a.p, b.p, c.p, d.p) ||
+					cross2(a.p, d.p, c.p) <= 0 || cross2(d.p, b.p, c.p) <= 0 {
+					continue
+				}
+				unindex(t)
+				unindex(u)
+				triangles[t] = [3]polygonNode{a, d, c}
+				triangles[u] = [3]polygonNode{d, b, c}
+				index(t)
+				index(u)
+				flipped = true
+				flips++
+				break
+			}
+		}
+		if !flipped {
+			return
+		}
+	}
+}
+
+// inCircumcircle reports whether d lies strictly inside the circumcircle of
+// the counterclockwise triangle a, b, c. Points on the circle, up to
+// rounding, are outside so that cocircular corners do not flip forever.
+func inCircumcircle(a, b, c, d [2]float64) bool {
+	adx, ady := a[0]-d[0], a[1]-d[1]
+	bdx, bdy := b[0]-d[0], b[1]-d[1]
+	cdx, cdy := c[0]-d[0], c[1]-d[1]
+	ad := adx*adx + ady*ady
+	bd := bdx*bdx + bdy*bdy
+	cd := cdx*cdx + cdy*cdy
+	det := ad*(bdx*cdy-cdx*bdy) - bd*(adx*cdy-cdx*ady) + cd*(adx*bdy-bdx*ady)
+	magnitude := ad*math.Abs(bdx*cdy-cdx*bdy) + bd*math.Abs(adx*cdy-cdx*ady) + cd*math.Abs(adx*bdy-bdx*ady)
+	return det > magnitude*1e-10
+}
@@ -0,0 +1,108 @@
+package obj
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+// createStrip returns a buffer with a single 10x1 face whose long sides are
+// split every unit, the kind of face ear clipping turns into a fan.
+func createStrip() *ObjBuffer {
+	buffer := &ObjBuffer{}
+	var corners []FaceCorner
+	for x := 0; x <= 10; x++ {
+		buffer.V = append(buffer.V, vec3.T{float32(x), 0, 0})
+	}
+	for x := 10; x >= 0; x-- {
+		buffer.V = append(buffer.V, vec3.T{float32(x), 1, 0})
+	}
+	for i := range buffer.V {
+		corners = append(corners, FaceCorner{VertexIndex: i, NormalIndex: -1, TexcoordIndex: -1})
+	}
+	buffer.F = []Face{{Corners: corners}}
+	buffer.G = []Group{{Name: "floor", FaceCount: 1}}
+	buffer.FaceGroup = faceGroupsOf(buffer.F)
+	return buffer
+}
+
+// smallestAngle returns the smallest angle, in radians, of the triangles.
+func smallestAngle(V []vec3.T, triangles [][]FaceCorner) float64 {
+	smallest := math.Pi
+	for _, t := range triangles {
+		for k := 0; k < 3; k++ {
+			a, b, c := V[t[k].VertexIndex], V[t[(k+1)%3].VertexIndex], V[t[(k+2)%3].VertexIndex]
+			e1, e2 := vec3.Sub(&b, &a), vec3.Sub(&c, &a)
+			angle := float64(vec3.Angle(&e1, &e2))
+			smallest = math.Min(smallest, angle)
+		}
+	}
+	return smallest
+}
+
+func TestFace_TriangulateWith_CDT_ImprovesSmallestAngle(t *testing.T) {
+	// Arrange
+	buffer := createStrip()
+	f := buffer.F[0]
+
+	// Act
+	earClipped := f.TriangulateWith(buffer.V, TriangulateOptions{Method: EarClip})
+	delaunay := f.TriangulateWith(buffer.V, TriangulateOptions{Method: CDT})
+
+	// Assert
+	assert.Len(t, delaunay, len(buffer.V)-2)
+	assert.True(t, smallestAngle(buffer.V, delaunay) > smallestAngle(buffer.V, earClipped))
+	assert.InDelta(t, math.Pi/4, smallestAngle(buffer.V, delaunay), 1e-5)
+	area := float32(0)
+	for _, tri := range delaunay {
+		a, b, c := buffer.V[tri[0].VertexIndex], buffer.V[tri[1].VertexIndex], buffer.V[tri[2].VertexIndex]
+		e1, e2 := vec3.Sub(&b, &a), vec3.Sub(&c, &a)
+		cross := vec3.Cross(&e1, &e2)
+		assert.True(t, cross[2] > 0, "triangle is not front facing")
+		area += cross[2] / 2
+	}
+	assert.InDelta(t, 10, area, 1e-5)
+}
+
+func TestFace_TriangulateWith_CDTHole_KeepsHoleEdges(t *testing.T) {
+	// Arrange
+	buffer := createSquareWithHole()
+
+	// Act
+	triangles := buffer.F[0].TriangulateWith(buffer.V, TriangulateOptions{Method: CDT})
+
+	// Assert
+	assert.Len(t, triangles, 8)
+	edges := make(map[Edge]bool)
+	area := float32(0)
+	for _, tri := range triangles {
+		for k := 0; k < 3; k++ {
+			edges[NewEdge(tri[k].VertexIndex, tri[(k+1)%3].VertexIndex)] = true
+		}
+		a, b, c := buffer.V[tri[0].VertexIndex], buffer.V[tri[1].VertexIndex], buffer.V[tri[2].VertexIndex]
+		e1, e2 := vec3.Sub(&b, &a), vec3.Sub(&c, &a)
+		cross := vec3.Cross(&e1, &e2)
+		area += cross[2] / 2
+	}
+	assert.InDelta(t, 12, area, 1e-5)
+	for _, loop := range buffer.F[0].loops() {
+		for i := range loop {
+			assert.True(t, edges[NewEdge(loop[i].VertexIndex, loop[(i+1)%len(loop)].VertexIndex)])
+		}
+	}
+}
+
+func TestObjBuffer_TriangulateWith_CDT_ReplacesFaces(t *testing.T) {
+	// Arrange
+	buffer := createStrip()
+
+	// Act
+	buffer.TriangulateWith(TriangulateOptions{Method: CDT})
+
+	// Assert
+	assert.Len(t, buffer.F, 20)
+	assert.Equal(t, 20, buffer.G[0].FaceCount)
+	assert.Equal(t, 20, buffer.FaceGroup[0].Size)
+}
@@ -0,0 +1,197 @@
+package obj
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+)
+
+// CityJSONOptions controls WriteCityJSON.
+type CityJSONOptions struct {
+	// ObjectType is the type of the city objects, "Building" if empty.
+	ObjectType string
+	// LOD is the level of detail of the geometries, "2" if empty.
+	LOD string
+	// SemanticKey names the face metadata holding the semantic surface of
+	// every face, mapped to a surface type by Semantics.
+	SemanticKey string
+	// Semantics maps the values of the SemanticKey metadata to semantic
+	// surface types, such as "RoofSurface", "WallSurface" and
+	// "GroundSurface". Faces without the metadata, or with a value missing
+	// from Semantics, have no semantic surface.
+	Semantics map[uint32]string
+	// Scale is the precision of the vertex coordinates, which CityJSON
+	// stores as integers, 0.001 if zero.
+	Scale float64
+	// ReferenceSystem, when set, is the URL of the coordinate reference
+	// system of the positions, such as
+	// "https://www.opengis.net/def/crs/EPSG/0/7415".
+	ReferenceSystem string
+}
+
+type cityJSONDocument struct {
+	Type        string                    `json:"type"`
+	Version     string                    `json:"version"`
+	Metadata    *cityJSONMetadata         `json:"metadata,omitempty"`
+	Transform   cityJSONTransform         `json:"transform"`
+	CityObjects map[string]cityJSONObject `json:"CityObjects"`
+	Vertices    [][3]int64                `json:"vertices"`
+}
+
+type cityJSONMetadata struct {
+	ReferenceSystem string `json:"referenceSystem"`
+}
+
+type cityJSONTransform struct {
+	Scale     [3]float64 `json:"scale"`
+	Translate [3]float64 `json:"translate"`
+}
+
+type cityJSONObject struct {
+	Type     string             `json:"type"`
+	Geometry []cityJSONGeometry `json:"geometry"`
+}
+
+type cityJSONGeometry struct {
+	Type       string             `json:"type"`
+	LOD        string             `json:"lod"`
+	Boundaries [][][]int          `json:"boundaries"`
+	Semantics  *cityJSONSemantics `json:"semantics,omitempty"`
+}
+
+type cityJSONSemantics struct {
+	Surfaces []cityJSONSurface `json:"surfaces"`
+	// Values holds the index in Surfaces of the surface of every face, or
+	// nil.
+	Values []*int `json:"values"`
+}
+
+type cityJSONSurface struct {
+	Type string `json:"type"`
+}
+
+// WriteCityJSON writes the buffer as a CityJSON 1.1 document, with a city
+// object per group, such as a building, holding its faces as a
+// MultiSurface geometry; faces outside of any group make a "default group"
+// object, and objects sharing a name get a numbered suffix. The semantic
+// surfaces of the faces, roofs or walls for example, are read from the face
+// metadata as set by options. Faces keep their holes. Positions are written
+// as they are, offset included, so buffers that are not Z up must be
+// converted first.
+func (b *ObjBuffer) WriteCityJSON(w io.Writer, options CityJSONOptions) error {
+	objectType := options.ObjectType
+	if objectType == "" {
+		objectType = "Building"
+	}
+	lod := options.LOD
+	if lod == "" {
+		lod = "2"
+	}
+	scale := options.Scale
+	if scale <= 0 {
+		scale = 0.001
+	}
+
+	doc := cityJSONDocument{
+		Type:        "CityJSON",
+		Version:     "1.1",
+		CityObjects: map[string]cityJSONObject{},
+		Vertices:    [][3]int64{},
+	}
+	if options.ReferenceSystem != "" {
+		doc.Metadata = &cityJSONMetadata{ReferenceSystem: options.ReferenceSystem}
+	}
+
+	var positions []dvec3.T
+	vertexIndex := make([]int, len(b.V))
+	FillIntSlice(vertexIndex, -1)
+	vertex := func(i int) int {
+		if vertexIndex[i] < 0 {
+			vertexIndex[i] = len(positions)
+			p := b.positionD(i)
+			p.Add(&b.Offset)
+			positions = append(positions, p)
+		}
+		return vertexIndex[i]
+	}
+
+	groupOf := b.groupOfFaces()
+	objects := make(map[int]string)
+	var order []int
+	used := make(map[string]bool)
+	geometries := make(map[int]*cityJSONGeometry)
+	surfaces := make(map[int]map[string]int)
+	for i := range b.F {
+		f := &b.F[i]
+		var surface [][]int
+		for _, loop := range f.loops() {
+			ring := make([]int, 0, len(loop))
+			for _, c := range loop {
+				if c.VertexIndex < 0 || c.VertexIndex >= len(b.V) {
+					return badStatement(ErrBadIndex, "Face %d references vertex %d of %d", i+1, c.VertexIndex+1, len(b.V))
+				}
+				ring = append(ring, vertex(c.VertexIndex))
+			}
+			surface = append(surface, ring)
+		}
+
+		g := groupOf[i]
+		geometry, ok := geometries[g]
+		if !ok {
+			name := defaultGroupName
+			if g >= 0 {
+				name = b.G[g].Name
+			}
+			id := name
+			for n := 2; used[id]; n++ {
+				id = fmt.Sprintf("%s_%d", name, n)
+			}
+			used[id] = true
+			objects[g] = id
+			order = append(order, g)
+			geometry = &cityJSONGeometry{Type: "MultiSurface", LOD: lod}
+			geometries[g] = geometry
+			surfaces[g] = make(map[string]int)
+		}
+		geometry.Boundaries = append(geometry.Boundaries, surface)
+
+		var value *int
+		if id, ok := f.Metadata[options.SemanticKey]; ok && options.SemanticKey != "" {
+			if surfaceType, ok := options.Semantics[id]; ok {
+				if geometry.Semantics == nil {
+					geometry.Semantics = &cityJSONSemantics{Values: make([]*int, len(geometry.Boundaries)-1)}
+				}
+				index, ok := surfaces[g][surfaceType]
+				if !ok {
+					index = len(geometry.Semantics.Surfaces)
+					surfaces[g][surfaceType] = index
+					geometry.Semantics.Surfaces = append(geometry.Semantics.Surfaces, cityJSONSurface{Type: surfaceType})
+				}
+				value = &index
+			}
+		}
+		if geometry.Semantics != nil {
+			geometry.Semantics.Values = append(geometry.Semantics.Values, value)
+		}
+	}
+	for _, g := range order {
+		doc.CityObjects[objects[g]] = cityJSONObject{Type: objectType, Geometry: []cityJSONGeometry{*geometries[g]}}
+	}
+
+	doc.Transform.Scale = [3]float64{scale, scale, scale}
+	if len(positions) > 0 {
+		min := positionBounds(positions).Min
+		doc.Transform.Translate = [3]float64{min[0], min[1], min[2]}
+	}
+	for _, p := range positions {
+		var v [3]int64
+		for k := range v {
+			v[k] = int64(math.Round((p[k] - doc.Transform.Translate[k]) / scale))
+		}
+		doc.Vertices = append(doc.Vertices, v)
+	}
+	return json.NewEncoder(w).Encode(doc)
+}
@@ -0,0 +1,84 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_WriteCityJSON_Buildings_WritesSemanticSurfaces(t *testing.T) {
+	// Arrange
+	buffer := Merge(
+		createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, ""),
+		createCube(vec3.T{2, 0, 0}, vec3.T{3, 1, 2}, ""),
+	)
+	buffer.Offset = dvec3.T{1000, 2000, 0}
+	for i := range buffer.F {
+		if i%6 < 5 && i != 0 {
+			buffer.F[i].Metadata = map[string]uint32{"surface": uint32(i % 6 / 4)}
+		}
+	}
+	buffer.F[5].Metadata = map[string]uint32{"surface": 2}
+	buffer.F[11].Metadata = map[string]uint32{"surface": 2}
+	options := CityJSONOptions{
+		SemanticKey:     "surface",
+		Semantics:       map[uint32]string{0: "WallSurface", 1: "GroundSurface", 2: "RoofSurface"},
+		ReferenceSystem: "https://www.opengis.net/def/crs/EPSG/0/7415",
+	}
+
+	// Act
+	var buf bytes.Buffer
+	err := buffer.WriteCityJSON(&buf, options)
+
+	// Assert
+	assert.NoError(t, err)
+	var doc cityJSONDocument
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "1.1", doc.Version)
+	assert.Equal(t, options.ReferenceSystem, doc.Metadata.ReferenceSystem)
+	assert.Equal(t, [3]float64{1000, 2000, 0}, doc.Transform.Translate)
+	assert.Len(t, doc.Vertices, 16)
+	assert.Contains(t, doc.Vertices, [3]int64{3000, 1000, 2000})
+	if assert.Contains(t, doc.CityObjects, "cube") && assert.Contains(t, doc.CityObjects, "cube_2") {
+		first := doc.CityObjects["cube"].Geometry[0]
+		assert.Equal(t, "Building", doc.CityObjects["cube"].Type)
+		assert.Equal(t, "MultiSurface", first.Type)
+		assert.Equal(t, "2", first.LOD)
+		assert.Len(t, first.Boundaries, 6)
+		assert.Equal(t, []cityJSONSurface{{Type: "WallSurface"}, {Type: "GroundSurface"}, {Type: "RoofSurface"}}, first.Semantics.Surfaces)
+		var values []interface{}
+		for _, v := range first.Semantics.Values {
+			if v == nil {
+				values = append(values, nil)
+			} else {
+				values = append(values, *v)
+			}
+		}
+		assert.Equal(t, []interface{}{nil, 0, 0, 0, 1, 2}, values)
+		assert.Len(t, doc.CityObjects["cube_2"].Geometry[0].Semantics.Values, 6)
+	}
+}
+
+func TestObjBuffer_WriteCityJSON_HoleAndNoSemantics_WritesRings(t *testing.T) {
+	// Arrange
+	buffer := createSquareWithHole()
+
+	// Act
+	var buf bytes.Buffer
+	err := buffer.WriteCityJSON(&buf, CityJSONOptions{ObjectType: "LandUse", LOD: "1", Scale: 0.5})
+
+	// Assert
+	assert.NoError(t, err)
+	var doc cityJSONDocument
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	geometry := doc.CityObjects["plate"].Geometry[0]
+	assert.Equal(t, "LandUse", doc.CityObjects["plate"].Type)
+	assert.Equal(t, [][][]int{{{0, 1, 2, 3}, {4, 5, 6, 7}}}, geometry.Boundaries)
+	assert.Nil(t, geometry.Semantics)
+	assert.Equal(t, [3]int64{8, 8, 0}, doc.Vertices[2])
+	assert.Nil(t, doc.Metadata)
+}
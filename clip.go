@@ -0,0 +1,148 @@
+package obj
+
+import (
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+)
+
+// filePosition returns the position of vertex i as written in the input,
+// before any recentering.
+func (l *ObjReader) filePosition(i int) dvec3.T {
+	p := l.positionD(i)
+	if l.options.AutoRecenter == RecenterFirstVertex {
+		p.Add(&l.recenterOrigin)
+	}
+	return p
+}
+
+// isClipped reports whether the bounding box of the n vertices returned by
+// vertex lies entirely outside ReadOptions.ClipBox. Elements referencing
+// vertices that are not read yet are kept.
+func (l *ObjReader) isClipped(n int, vertex func(j int) int) bool {
+	clip := l.options.ClipBox
+	if clip == nil || l.options.Lossless || n == 0 {
+		return false
+	}
+	box := dvec3.Box{Min: dvec3.MaxVal, Max: dvec3.MinVal}
+	for j := 0; j < n; j++ {
+		v := vertex(j)
+		if v < 0 || v >= len(l.V) {
+			return false
+		}
+		p := l.filePosition(v)
+		box.Extend(&p)
+	}
+	return !box.Intersects(clip)
+}
+
+// finishClip drops the vertices, normals and texture coordinates the faces
+// and lines kept by ReadOptions.ClipBox do not reference.
+func (l *ObjReader) finishClip() {
+	if l.options.ClipBox != nil && !l.options.Lossless {
+		l.removeUnreferenced()
+	}
+}
+
+// removeUnreferenced drops the vertices, normals and texture coordinates no
+// face or line references, and remaps the references to the others.
+func (b *ObjBuffer) removeUnreferenced() {
+	vertices := make([]int, len(b.V))
+	normals := make([]int, len(b.VN))
+	texcoords := make([]int, len(b.VT))
+	FillIntSlice(vertices, -1)
+	FillIntSlice(normals, -1)
+	FillIntSlice(texcoords, -1)
+	mark := func(mapping []int, idx int) {
+		if idx >= 0 && idx < len(mapping) {
+			mapping[idx] = 0
+		}
+	}
+	for i := range b.F {
+		for _, loop := range b.F[i].loops() {
+			for _, c := range loop {
+				mark(vertices, c.VertexIndex)
+				mark(normals, c.NormalIndex)
+				mark(texcoords, c.TexcoordIndex)
+			}
+		}
+	}
+	for _, l := range b.L {
+		for _, v := range l.Corners {
+			mark(vertices, v)
+		}
+	}
+
+	keepVertices := numberReferenced(vertices)
+	keepNormals := numberReferenced(normals)
+	keepTexcoords := numberReferenced(texcoords)
+	remap := func(mapping []int, idx int) int {
+		if idx < 0 || idx >= len(mapping) {
+			return idx
+		}
+		return mapping[idx]
+	}
+	for i := range b.F {
+		for _, loop := range b.F[i].loops() {
+			for j := range loop {
+				loop[j].VertexIndex = remap(vertices, loop[j].VertexIndex)
+				loop[j].NormalIndex = remap(normals, loop[j].NormalIndex)
+				loop[j].TexcoordIndex = remap(texcoords, loop[j].TexcoordIndex)
+			}
+		}
+	}
+	for _, l := range b.L {
+		for j := range l.Corners {
+			l.Corners[j] = remap(vertices, l.Corners[j])
+		}
+	}
+
+	double := b.hasDoublePrecision()
+	colors := b.hasVertexColors()
+	attributes := make(map[string]AttributeBuffer, len(b.Attributes))
+	for name, a := range b.Attributes {
+		attributes[name] = a.emptyCopy()
+	}
+	for _, i := range keepVertices {
+		b.V[vertices[i]] = b.V[i]
+		if double {
+			b.VD[vertices[i]] = b.VD[i]
+		}
+		if colors {
+			b.VC[vertices[i]] = b.VC[i]
+		}
+		for name, a := range b.Attributes {
+			attributes[name] = attributes[name].appendVertex(a, i)
+		}
+	}
+	b.V = b.V[:len(keepVertices)]
+	if double {
+		b.VD = b.VD[:len(keepVertices)]
+	}
+	if colors {
+		b.VC = b.VC[:len(keepVertices)]
+	}
+	if b.Attributes != nil {
+		b.Attributes = attributes
+	}
+	for _, i := range keepNormals {
+		b.VN[normals[i]] = b.VN[i]
+	}
+	b.VN = b.VN[:len(keepNormals)]
+	for _, i := range keepTexcoords {
+		b.VT[texcoords[i]] = b.VT[i]
+	}
+	b.VT = b.VT[:len(keepTexcoords)]
+}
+
+// numberReferenced assigns consecutive new indices to the marked entries of
+// mapping, leaving the others at -1, and returns the old indices of the
+// marked entries in order.
+func numberReferenced(mapping []int) []int {
+	var kept []int
+	for i := range mapping {
+		if mapping[i] == 0 {
+			mapping[i] = len(kept)
+			kept = append(kept, i)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,54 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+const clipInput = "v 0 0 0\nv 1 0 0\nv 0 1 0\nv 10 0 0\nv 11 0 0\nv 10 1 0\nv 4 0 0\nv 6 1 0\n" +
+	"vn 0 0 1\nvn 0 0 -1\nvt 0 0\nvt 1 1\n" +
+	"g near\nf 1/1/1 2/1/1 3/1/1\ng far\nf 4/2/2 5/2/2 6/2/2\n" +
+	"g across\nf 1//1 7//1 8//1\nl 4 5\nl 2 7\n"
+
+func TestObjReader_Read_ClipBox_DropsElementsOutside(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{ClipBox: &dvec3.Box{Min: dvec3.T{-1, -1, -1}, Max: dvec3.T{2, 2, 1}}})
+
+	// Act
+	err := loader.Read(strings.NewReader(clipInput))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, loader.F, 2)
+	assert.Equal(t, []string{"near", "across"}, loader.GroupNames())
+	assert.Equal(t, []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {4, 0, 0}, {6, 1, 0}}, loader.V)
+	assert.Equal(t, []vec3.T{{0, 0, 1}}, loader.VN)
+	assert.Len(t, loader.VT, 1)
+	assert.Equal(t, []FaceCorner{{0, 0, -1}, {3, 0, -1}, {4, 0, -1}}, loader.F[1].Corners)
+	assert.Len(t, loader.L, 1)
+	assert.Equal(t, []int{1, 3}, loader.L[0].Corners)
+}
+
+func TestObjReader_Read_ClipBoxRecentered_UsesInputCoordinates(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{
+		ClipBox:      &dvec3.Box{Min: dvec3.T{9, -1, -1}, Max: dvec3.T{12, 2, 1}},
+		AutoRecenter: RecenterFirstVertex,
+	})
+
+	// Act
+	err := loader.Read(strings.NewReader(clipInput))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, loader.F, 1)
+	assert.Equal(t, []string{"far"}, loader.GroupNames())
+	assert.Len(t, loader.V, 3)
+	assert.Len(t, loader.L, 1)
+}
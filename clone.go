@@ -0,0 +1,95 @@
+package obj
+
+import (
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+)
+
+// Clone returns a deep copy of the buffer: changing the copy, including the
+// corners and metadata of its faces, leaves the buffer unchanged. Assigning
+// an ObjBuffer only copies the slice headers, so both values then share
+// their elements.
+func (b *ObjBuffer) Clone() *ObjBuffer {
+	clone := &ObjBuffer{
+		activeMaterial: b.activeMaterial,
+		MTL:            b.MTL,
+		V:              append([]vec3.T(nil), b.V...),
+		VN:             append([]vec3.T(nil), b.VN...),
+		VT:             append([]vec2.T(nil), b.VT...),
+		F:              cloneFaces(b.F),
+		G:              append([]Group(nil), b.G...),
+		VD:             append([]dvec3.T(nil), b.VD...),
+		VC:             append([]vec3.T(nil), b.VC...),
+		Offset:         b.Offset,
+		Comments:       append([]Comment(nil), b.Comments...),
+		Statements:     append([]Statement(nil), b.Statements...),
+	}
+	for _, l := range b.L {
+		clone.L = append(clone.L, line{Corners: append([]int(nil), l.Corners...), Material: l.Material})
+	}
+	for _, fg := range b.FaceGroup {
+		copied := *fg
+		clone.FaceGroup = append(clone.FaceGroup, &copied)
+	}
+	if b.Attributes != nil {
+		clone.Attributes = make(map[string]AttributeBuffer, len(b.Attributes))
+		for name, a := range b.Attributes {
+			a.Floats = append([]float64(nil), a.Floats...)
+			a.Ints = append([]int64(nil), a.Ints...)
+			clone.Attributes[name] = a
+		}
+	}
+	return clone
+}
+
+// CloneSubset returns a deep copy of count faces starting at face first,
+// with only the vertices, normals and texture coordinates they reference.
+// The groups overlapping the range are kept, clipped to it. Lines are not
+// copied. The range is clipped to the faces of the buffer.
+func (b *ObjBuffer) CloneSubset(first, count int) *ObjBuffer {
+	if first < 0 {
+		count += first
+		first = 0
+	}
+	if first+count > len(b.F) {
+		count = len(b.F) - first
+	}
+	var faces []int
+	for i := first; i < first+count; i++ {
+		faces = append(faces, i)
+	}
+	clone := b.subset(faces)
+	for _, g := range b.G {
+		start := maxInt(g.FirstFaceIndex, first)
+		end := minInt(g.FirstFaceIndex+g.FaceCount, first+count)
+		if start < end {
+			g.FirstFaceIndex, g.FaceCount = start-first, end-start
+			clone.G = append(clone.G, g)
+		}
+	}
+	return clone
+}
+
+// Clone returns a deep copy of the material.
+func (m *Material) Clone() *Material {
+	clone := *m
+	clone.Ambient = append([]float32(nil), m.Ambient...)
+	clone.Diffuse = append([]float32(nil), m.Diffuse...)
+	clone.Specular = append([]float32(nil), m.Specular...)
+	clone.Emissive = append([]float32(nil), m.Emissive...)
+	clone.TransmissionFilter = append([]float32(nil), m.TransmissionFilter...)
+	return &clone
+}
+
+// cloneMetadata returns a copy of the metadata of a face.
+func cloneMetadata(metadata map[string]uint32) map[string]uint32 {
+	if metadata == nil {
+		return nil
+	}
+	clone := make(map[string]uint32, len(metadata))
+	for name, id := range metadata {
+		clone[name] = id
+	}
+	return clone
+}
@@ -0,0 +1,63 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_Clone_MutatingCopy_LeavesOriginal(t *testing.T) {
+	// Arrange
+	loader := readTestObj(t, editTestObj, ReadOptions{})
+	loader.F[0].Metadata = map[string]uint32{"building": 7}
+	loader.SetAttribute("id", AttributeBuffer{Type: AttributeInt, Size: 1, Ints: []int64{1, 2, 3}})
+	loader.L = []line{{Corners: []int{0, 1}}}
+	original := loader.ObjBuffer.Clone()
+
+	// Act
+	clone := loader.Clone()
+	clone.V[0][0] = 9
+	clone.F[0].Corners[0].VertexIndex = 2
+	clone.F[0].Metadata["building"] = 8
+	clone.G[0].Name = "changed"
+	clone.FaceGroup[0].Material = "changed"
+	clone.L[0].Corners[0] = 2
+	clone.Attributes["id"].Ints[0] = 9
+
+	// Assert
+	assert.Equal(t, original, &loader.ObjBuffer)
+	assert.Equal(t, uint32(7), loader.F[0].Metadata["building"])
+}
+
+func TestObjBuffer_CloneSubset_ClipsGroupsAndCompactsVertices(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\nv 1 1 0\n" +
+		"g a\nf 1 2 3\nf 2 4 3\ng b\nf 2 4 3\nf 1 2 3\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+
+	// Act
+	subset := loader.CloneSubset(1, 2)
+	subset.V[0][0] = 9
+
+	// Assert
+	assert.Len(t, subset.F, 2)
+	assert.Len(t, subset.V, 3)
+	assert.Equal(t, []Group{{Name: "a", FaceCount: 1}, {Name: "b", FirstFaceIndex: 1, FaceCount: 1}}, subset.G)
+	assert.Equal(t, float32(1), loader.V[1][0])
+}
+
+func TestMaterial_Clone_CopiesColors(t *testing.T) {
+	// Arrange
+	m := &Material{Name: "red", Diffuse: []float32{1, 0, 0}, Opacity: 0.5}
+
+	// Act
+	clone := m.Clone()
+	clone.Diffuse[1] = 1
+
+	// Assert
+	assert.Equal(t, []float32{1, 0, 0}, m.Diffuse)
+	assert.Equal(t, "red", clone.Name)
+	assert.Equal(t, 0.5, clone.Opacity)
+}
@@ -0,0 +1,351 @@
+// Command objtool inspects and transforms OBJ files.
+//
+// Usage:
+//
+//	objtool info FILE
+//	objtool validate FILE
+//	objtool triangulate IN OUT
+//	objtool simplify [-triangles N] [-cell SIZE] IN OUT
+//	objtool center IN OUT
+//	objtool convert [-textures png|jpeg] IN OUT
+//	objtool split-by-group IN DIR
+//	objtool merge -o OUT IN...
+//	objtool diff [-tolerance T] A B
+//
+// Output formats are selected by the extension of OUT: .obj, .gltf, .glb,
+// .stl or .ply. convert -textures converts the TGA, BMP and TIFF textures of
+// glTF outputs to PNG or JPEG, next to OUT.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	obj "github.com/flywave/go-obj"
+)
+
+// command is an objtool subcommand.
+type command struct {
+	name  string
+	usage string
+	run   func(args []string, stdout io.Writer) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"info", "FILE", runInfo},
+		{"validate", "FILE", runValidate},
+		{"triangulate", "IN OUT", runTriangulate},
+		{"simplify", "[-triangles N] [-cell SIZE] IN OUT", runSimplify},
+		{"center", "IN OUT", runCenter},
+		{"convert", "[-textures png|jpeg] IN OUT", runConvert},
+		{"split-by-group", "IN DIR", runSplitByGroup},
+		{"merge", "-o OUT IN...", runMerge},
+		{"diff", "[-tolerance T] A B", runDiff},
+	}
+}
+
+// errUsage is returned for invalid command lines.
+var errUsage = errors.New("invalid arguments")
+
+// errDiffer is returned by diff when the files differ.
+var errDiffer = errors.New("files differ")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "objtool:", err)
+		if err == errUsage {
+			usage(os.Stderr)
+		}
+		os.Exit(1)
+	}
+}
+
+// run executes the command line args, without program name.
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:], stdout)
+		}
+	}
+	return errUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  objtool %s %s\n", c.name, c.usage)
+	}
+}
+
+func runInfo(args []string, stdout io.Writer) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	b, err := obj.ReadFile(args[0], obj.ReadOptions{DoublePrecision: true})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "vertices:  %d\n", len(b.V))
+	fmt.Fprintf(stdout, "normals:   %d\n", len(b.VN))
+	fmt.Fprintf(stdout, "texcoords: %d\n", len(b.VT))
+	fmt.Fprintf(stdout, "faces:     %d\n", len(b.F))
+	fmt.Fprintf(stdout, "lines:     %d\n", len(b.L))
+	fmt.Fprintf(stdout, "groups:    %s\n", strings.Join(b.GroupNames(), ", "))
+	fmt.Fprintf(stdout, "materials: %s\n", strings.Join(materialNames(b), ", "))
+	if b.MTL != "" {
+		fmt.Fprintf(stdout, "mtllib:    %s\n", b.MTL)
+	}
+	if !b.IsEmpty() {
+		box := b.BoundingBox()
+		fmt.Fprintf(stdout, "bounds:    (%g %g %g) - (%g %g %g)\n",
+			box.Min[0], box.Min[1], box.Min[2], box.Max[0], box.Max[1], box.Max[2])
+	}
+	if !b.Offset.IsZero() {
+		fmt.Fprintf(stdout, "offset:    %g %g %g\n", b.Offset[0], b.Offset[1], b.Offset[2])
+	}
+	fmt.Fprintf(stdout, "hash:      %s\n", b.Hash())
+	return nil
+}
+
+func runValidate(args []string, stdout io.Writer) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	file, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	loader := obj.ObjReader{}
+	loader.SetOptions(obj.ReadOptions{ValidateIndices: true})
+	if err := loader.Read(file); err != nil {
+		return err
+	}
+	for _, w := range loader.Warnings {
+		fmt.Fprintln(stdout, "warning:", w)
+	}
+	topology := loader.BuildTopology()
+	if !topology.IsManifold() {
+		fmt.Fprintln(stdout, "warning: mesh is not manifold")
+	}
+	if boundary := topology.BoundaryEdges(); len(boundary) > 0 {
+		fmt.Fprintf(stdout, "warning: mesh is open, %d boundary edges\n", len(boundary))
+	}
+	fmt.Fprintln(stdout, "ok")
+	return nil
+}
+
+func runTriangulate(args []string, stdout io.Writer) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	scene, err := readScene(args[0])
+	if err != nil {
+		return err
+	}
+	scene.Buffer.Triangulate()
+	return writeScene(args[1], scene)
+}
+
+func runSimplify(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("simplify", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	triangles := flags.Int("triangles", 0, "largest number of triangles of the result")
+	cell := flags.Float64("cell", 0, "size of the cells vertices are clustered in")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() != 2 {
+		return errUsage
+	}
+	scene, err := readScene(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	scene.Buffer = scene.Buffer.CollisionProxy(obj.CollisionProxyOptions{CellSize: *cell, MaxTriangles: *triangles})
+	return writeScene(flags.Arg(1), scene)
+}
+
+func runCenter(args []string, stdout io.Writer) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	scene, err := readScene(args[0])
+	if err != nil {
+		return err
+	}
+	scene.Buffer.Center()
+	return writeScene(args[1], scene)
+}
+
+func runConvert(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	textures := flags.String("textures", "", "format to convert TGA, BMP and TIFF textures of glTF outputs to, png or jpeg")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() != 2 {
+		return errUsage
+	}
+	var options obj.GLTFOptions
+	switch *textures {
+	case "":
+	case "png":
+		options.TextureFormat = obj.TexturePNG
+	case "jpeg", "jpg":
+		options.TextureFormat = obj.TextureJPEG
+	default:
+		return fmt.Errorf("Unsupported texture format '%s'", *textures)
+	}
+	options.TextureDir = filepath.Dir(flags.Arg(1))
+	scene, err := readScene(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return writeSceneWith(flags.Arg(1), scene, options)
+}
+
+func runSplitByGroup(args []string, stdout io.Writer) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	b, err := obj.ReadFile(args[0], obj.ReadOptions{DoublePrecision: true})
+	if err != nil {
+		return err
+	}
+	paths, err := b.WriteGroupsToDir(args[1], obj.WriteOptions{})
+	for _, path := range paths {
+		fmt.Fprintln(stdout, path)
+	}
+	return err
+}
+
+func runMerge(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("merge", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	out := flags.String("o", "", "output file")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if *out == "" || flags.NArg() == 0 {
+		return errUsage
+	}
+	buffers := make([]*obj.ObjBuffer, flags.NArg())
+	for i, path := range flags.Args() {
+		b, err := obj.ReadFile(path, obj.ReadOptions{DoublePrecision: true})
+		if err != nil {
+			return err
+		}
+		buffers[i] = b
+	}
+	return writeBuffer(*out, obj.Merge(buffers...))
+}
+
+func runDiff(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	tolerance := flags.Float64("tolerance", 1e-5, "largest distance between equal positions")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() != 2 {
+		return errUsage
+	}
+	options := obj.ReadOptions{DoublePrecision: true}
+	a, err := obj.ReadFile(flags.Arg(0), options)
+	if err != nil {
+		return err
+	}
+	b, err := obj.ReadFile(flags.Arg(1), options)
+	if err != nil {
+		return err
+	}
+	diff := obj.DiffBuffers(a, b, *tolerance)
+	if diff.Empty() {
+		return nil
+	}
+	io.WriteString(stdout, diff.String())
+	return errDiffer
+}
+
+// readScene reads an OBJ file with its materials, if its material library
+// can be read.
+func readScene(path string) (*obj.Scene, error) {
+	scene, err := obj.ReadScene(path, obj.ReadOptions{DoublePrecision: true})
+	if err == nil {
+		return scene, nil
+	}
+	b, errBuffer := obj.ReadFile(path, obj.ReadOptions{DoublePrecision: true})
+	if errBuffer != nil {
+		return nil, errBuffer
+	}
+	return &obj.Scene{Buffer: b, Materials: map[string]*obj.Material{}, Dir: filepath.Dir(path)}, nil
+}
+
+func writeBuffer(path string, b *obj.ObjBuffer) error {
+	return writeScene(path, &obj.Scene{Buffer: b, Materials: map[string]*obj.Material{}})
+}
+
+// writeScene writes the scene in the format selected by the extension of
+// path.
+func writeScene(path string, scene *obj.Scene) error {
+	return writeSceneWith(path, scene, obj.GLTFOptions{})
+}
+
+// writeSceneWith writes the scene like writeScene, with options for glTF
+// outputs.
+func writeSceneWith(path string, scene *obj.Scene, options obj.GLTFOptions) error {
+	var write func(w io.Writer) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".obj":
+		write = scene.Buffer.Write
+	case ".gltf":
+		write = func(w io.Writer) error { return scene.WriteGLTFWith(w, options) }
+	case ".glb":
+		write = func(w io.Writer) error { return scene.WriteGLBWith(w, options) }
+	case ".stl":
+		write = scene.Buffer.WriteSTL
+	case ".ply":
+		write = scene.Buffer.WritePLY
+	default:
+		return fmt.Errorf("Unsupported output format '%s'", filepath.Ext(path))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// materialNames returns the sorted names of the materials used by faces.
+func materialNames(b *obj.ObjBuffer) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, f := range b.F {
+		if f.Material != "" && !seen[f.Material] {
+			seen[f.Material] = true
+			names = append(names, f.Material)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	obj "github.com/flywave/go-obj"
+	"github.com/stretchr/testify/assert"
+)
+
+const quadsObj = "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nv 2 0 0\nv 2 1 0\n" +
+	"g left\nusemtl red\nf 1 2 3 4\ng right\nusemtl blue\nf 2 5 6 3\n"
+
+func writeInput(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun_Info_PrintsCounts(t *testing.T) {
+	// Arrange
+	in := writeInput(t, "quads.obj", quadsObj)
+
+	// Act
+	var out bytes.Buffer
+	err := run([]string{"info", in}, &out)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "vertices:  6\n")
+	assert.Contains(t, out.String(), "faces:     2\n")
+	assert.Contains(t, out.String(), "groups:    left, right\n")
+	assert.Contains(t, out.String(), "materials: blue, red\n")
+	assert.Contains(t, out.String(), "bounds:    (0 0 0) - (2 1 0)\n")
+}
+
+func TestRun_Validate_ReportsWarningsAndBadIndices(t *testing.T) {
+	// Arrange
+	valid := writeInput(t, "valid.obj", "o quad\nmg 1\n"+quadsObj)
+	invalid := writeInput(t, "invalid.obj", "v 0 0 0\nf 1 2 3\n")
+
+	// Act
+	var out bytes.Buffer
+	errValid := run([]string{"validate", valid}, &out)
+	errInvalid := run([]string{"validate", invalid}, &bytes.Buffer{})
+
+	// Assert
+	assert.NoError(t, errValid)
+	assert.Contains(t, out.String(), "warning: Line #2: ignored 'mg' statement ('mg 1')\n")
+	assert.Contains(t, out.String(), "warning: mesh is open, 6 boundary edges\n")
+	assert.Error(t, errInvalid)
+}
+
+func TestRun_Triangulate_WritesTriangles(t *testing.T) {
+	// Arrange
+	in := writeInput(t, "quads.obj", quadsObj)
+	out := filepath.Join(t.TempDir(), "tris.obj")
+
+	// Act
+	err := run([]string{"triangulate", in, out}, &bytes.Buffer{})
+
+	// Assert
+	assert.NoError(t, err)
+	b, err := obj.ReadFile(out, obj.ReadOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(b.F))
+	assert.Equal(t, []obj.Group{{Name: "left", FirstFaceIndex: 0, FaceCount: 2}, {Name: "right", FirstFaceIndex: 2, FaceCount: 2}}, b.G)
+}
+
+func TestRun_Convert_SelectsFormatByExtension(t *testing.T) {
+	// Arrange
+	in := writeInput(t, "quads.obj", quadsObj)
+	dir := t.TempDir()
+
+	// Act
+	errPLY := run([]string{"convert", in, filepath.Join(dir, "quads.ply")}, &bytes.Buffer{})
+	errGLB := run([]string{"convert", in, filepath.Join(dir, "quads.glb")}, &bytes.Buffer{})
+	errUnknown := run([]string{"convert", in, filepath.Join(dir, "quads.fbx")}, &bytes.Buffer{})
+
+	// Assert
+	assert.NoError(t, errPLY)
+	assert.NoError(t, errGLB)
+	assert.Error(t, errUnknown)
+	ply, _ := ioutil.ReadFile(filepath.Join(dir, "quads.ply"))
+	assert.True(t, strings.HasPrefix(string(ply), "ply\n"))
+	glb, _ := ioutil.ReadFile(filepath.Join(dir, "quads.glb"))
+	assert.Equal(t, "glTF", string(glb[:4]))
+}
+
+func TestRun_ConvertTextures_InvalidFormat_Fails(t *testing.T) {
+	// Arrange
+	in := writeInput(t, "quads.obj", quadsObj)
+	dir := t.TempDir()
+
+	// Act
+	errPNG := run([]string{"convert", "-textures", "png", in, filepath.Join(dir, "quads.gltf")}, &bytes.Buffer{})
+	errGIF := run([]string{"convert", "-textures", "gif", in, filepath.Join(dir, "quads.gltf")}, &bytes.Buffer{})
+
+	// Assert
+	assert.NoError(t, errPNG)
+	assert.EqualError(t, errGIF, "Unsupported texture format 'gif'")
+}
+
+func TestRun_SplitByGroupAndMerge_RoundTrips(t *testing.T) {
+	// Arrange
+	in := writeInput(t, "quads.obj", quadsObj)
+	dir := t.TempDir()
+	merged := filepath.Join(dir, "merged.obj")
+
+	// Act
+	var out bytes.Buffer
+	errSplit := run([]string{"split-by-group", in, dir}, &out)
+	errMerge := run([]string{"merge", "-o", merged, filepath.Join(dir, "left.obj"), filepath.Join(dir, "right.obj")}, &bytes.Buffer{})
+
+	// Assert
+	assert.NoError(t, errSplit)
+	assert.NoError(t, errMerge)
+	assert.Equal(t, filepath.Join(dir, "left.obj")+"\n"+filepath.Join(dir, "right.obj")+"\n", out.String())
+	b, err := obj.ReadFile(merged, obj.ReadOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 8, len(b.V))
+	assert.Equal(t, 2, len(b.F))
+	assert.Equal(t, "blue", b.F[1].Material)
+	assert.Equal(t, []string{"left", "right"}, b.GroupNames())
+}
+
+func TestRun_UnknownCommand_ReturnsUsageError(t *testing.T) {
+	assert.Equal(t, errUsage, run([]string{"explode"}, &bytes.Buffer{}))
+	assert.Equal(t, errUsage, run(nil, &bytes.Buffer{}))
+}
+
+func TestRun_Diff_ReportsDifferences(t *testing.T) {
+	// Arrange
+	a := writeInput(t, "a.obj", quadsObj)
+	b := writeInput(t, "b.obj", strings.Replace(quadsObj, "usemtl blue", "usemtl green", 1))
+
+	// Act
+	var same, changed bytes.Buffer
+	errSame := run([]string{"diff", a, a}, &same)
+	errChanged := run([]string{"diff", a, b}, &changed)
+
+	// Assert
+	assert.NoError(t, errSame)
+	assert.Equal(t, "", same.String())
+	assert.Equal(t, errDiffer, errChanged)
+	assert.Equal(t, "~ face 2\n~ group right\n- material blue\n+ material green\n", changed.String())
+}
@@ -0,0 +1,319 @@
+package obj
+
+import (
+	"math"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+)
+
+// hullEpsilon is the distance, relative to the size of the point cloud,
+// below which a point is considered to lie on a hull face.
+const hullEpsilon = 1e-9
+
+// ConvexHull returns the convex hull of the vertices of the buffer as a
+// closed triangle mesh with outward facing triangles. The hull is expressed
+// in the frame of the buffer, with the same Offset. The returned buffer has
+// no faces when the vertices do not span a volume.
+func (b *ObjBuffer) ConvexHull() *ObjBuffer {
+	points := make([]dvec3.T, 0, len(b.V))
+	seen := make(map[dvec3.T]bool, len(b.V))
+	for i := range b.V {
+		p := b.positionD(i)
+		if !seen[p] {
+			seen[p] = true
+			points = append(points, p)
+		}
+	}
+	return newTriangleBuffer(b, points, quickHull(points))
+}
+
+type hullFace struct {
+	v       [3]int
+	normal  dvec3.T
+	offset  float64
+	outside []int
+	removed bool
+}
+
+func newHullFace(points []dvec3.T, a, b, c int) *hullFace {
+	e1 := dvec3.Sub(&points[b], &points[a])
+	e2 := dvec3.Sub(&points[c], &points[a])
+	n := dvec3.Cross(&e1, &e2)
+	n.Normalize()
+	return &hullFace{v: [3]int{a, b, c}, normal: n, offset: dvec3.Dot(&n, &points[a])}
+}
+
+func (f *hullFace) distance(p *dvec3.T) float64 {
+	return dvec3.Dot(&f.normal, p) - f.offset
+}
+
+// quickHull returns the triangles of the convex hull of points as indices
+// into points, or nil if the points are coplanar.
+func quickHull(points []dvec3.T) [][3]int {
+	if len(points) < 4 {
+		return nil
+	}
+	box := dvec3.Box{Min: points[0], Max: points[0]}
+	for i := range points {
+		box.Extend(&points[i])
+	}
+	size := box.Diagonal()
+	eps := hullEpsilon * math.Max(size.Length(), 1)
+
+	// Build the initial tetrahedron from extreme points.
+	a, b := 0, 0
+	for axis := 0; axis < 3; axis++ {
+		lo, hi := 0, 0
+		for i := range points {
+			if points[i][axis] < points[lo][axis] {
+				lo = i
+			}
+			if points[i][axis] > points[hi][axis] {
+				hi = i
+			}
+		}
+		if dvec3.Distance(&points[lo], &points[hi]) > dvec3.Distance(&points[a], &points[b]) {
+			a, b = lo, hi
+		}
+	}
+	if dvec3.Distance(&points[a], &points[b]) <= eps {
+		return nil
+	}
+	ab := dvec3.Sub(&points[b], &points[a])
+	c, best := -1, eps
+	for i := range points {
+		ap := dvec3.Sub(&points[i], &points[a])
+		cross := dvec3.Cross(&ab, &ap)
+		if d := cross.Length() / ab.Length(); d > best {
+			c, best = i, d
+		}
+	}
+	if c < 0 {
+		return nil
+	}
+	base := newHullFace(points, a, b, c)
+	d, best := -1, eps
+	for i := range points {
+		if dist := math.Abs(base.distance(&points[i])); dist > best {
+			d, best = i, dist
+		}
+	}
+	if d < 0 {
+		return nil
+	}
+	if base.distance(&points[d]) > 0 {
+		b, c = c, b
+	}
+	faces := []*hullFace{
+		newHullFace(points, a, b, c),
+		newHullFace(points, a, d, b),
+		newHullFace(points, b, d, c),
+		newHullFace(points, c, d, a),
+	}
+	assignOutside(points, faces, allIndices(len(points)), eps)
+
+	for {
+		var face *hullFace
+		for _, f := range faces {
+			if !f.removed && len(f.outside) > 0 {
+				face = f
+				break
+			}
+		}
+		if face == nil {
+			break
+		}
+		eye, far := face.outside[0], face.distance(&points[face.outside[0]])
+		for _, i := range face.outside[1:] {
+			if dist := face.distance(&points[i]); dist > far {
+				eye, far = i, dist
+			}
+		}
+
+		// Remove the faces the eye point sees and connect their horizon to
+		// it.
+		visibleEdges := make(map[[2]int]bool)
+		var visible []*hullFace
+		var orphans []int
+		for _, f := range faces {
+			if f.removed || f.distance(&points[eye]) <= eps {
+				continue
+			}
+			f.removed = true
+			visible = append(visible, f)
+			orphans = append(orphans, f.outside...)
+			for k := 0; k < 3; k++ {
+				visibleEdges[[2]int{f.v[k], f.v[(k+1)%3]}] = true
+			}
+		}
+		var created []*hullFace
+		for _, f := range visible {
+			for k := 0; k < 3; k++ {
+				from, to := f.v[k], f.v[(k+1)%3]
+				if !visibleEdges[[2]int{to, from}] {
+					created = append(created, newHullFace(points, from, to, eye))
+				}
+			}
+		}
+		assignOutside(points, created, orphans, eps)
+
+		alive := faces[:0]
+		for _, f := range faces {
+			if !f.removed {
+				alive = append(alive, f)
+			}
+		}
+		faces = append(alive, created...)
+	}
+
+	triangles := make([][3]int, len(faces))
+	for i, f := range faces {
+		triangles[i] = f.v
+	}
+	return triangles
+}
+
+// assignOutside adds every point to the outside set of the first face it
+// lies above. Points below every face are inside the hull and dropped.
+func assignOutside(points []dvec3.T, faces []*hullFace, candidates []int, eps float64) {
+	for _, i := range candidates {
+		for _, f := range faces {
+			if f.distance(&points[i]) > eps {
+				f.outside = append(f.outside, i)
+				break
+			}
+		}
+	}
+}
+
+func allIndices(n int) []int {
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices
+}
+
+// CollisionProxyOptions controls the simplification done by CollisionProxy.
+type CollisionProxyOptions struct {
+	// CellSize is the size of the grid cells whose vertices are merged. It
+	// defaults to 1/32 of the diagonal of the bounding box.
+	CellSize float64
+	// MaxTriangles, if positive, doubles the cell size until the proxy has
+	// at most that many triangles.
+	MaxTriangles int
+}
+
+// CollisionProxy returns a simplified copy of the buffer for use as a
+// physics mesh. Vertices falling into the same grid cell are merged into
+// their average and the triangles that collapse are dropped. Materials,
+// normals and texture coordinates are not kept.
+func (b *ObjBuffer) CollisionProxy(options CollisionProxyOptions) *ObjBuffer {
+	cellSize := options.CellSize
+	if len(b.V) == 0 {
+		return newTriangleBuffer(b, nil, nil)
+	}
+	if cellSize <= 0 {
+		box := b.BoundingBox()
+		diagonal := box.Diagonal()
+		cellSize = float64(diagonal.Length()) / 32
+		if cellSize == 0 {
+			cellSize = 1
+		}
+	}
+	for {
+		points, triangles := b.clusterVertices(cellSize)
+		if options.MaxTriangles <= 0 || len(triangles) <= options.MaxTriangles || len(triangles) == 0 {
+			return newTriangleBuffer(b, points, triangles)
+		}
+		cellSize *= 2
+	}
+}
+
+// clusterVertices merges the vertices falling into the same cell of a grid
+// and returns the merged points and the triangles that did not collapse.
+func (b *ObjBuffer) clusterVertices(cellSize float64) ([]dvec3.T, [][3]int) {
+	cellOf := make(map[[3]int64]int)
+	vertexCell := make([]int, len(b.V))
+	var sums []dvec3.T
+	var counts []int
+	for i := range b.V {
+		p := b.positionD(i)
+		key := [3]int64{
+			int64(math.Floor(p[0] / cellSize)),
+			int64(math.Floor(p[1] / cellSize)),
+			int64(math.Floor(p[2] / cellSize)),
+		}
+		cell, ok := cellOf[key]
+		if !ok {
+			cell = len(sums)
+			cellOf[key] = cell
+			sums = append(sums, dvec3.T{})
+			counts = append(counts, 0)
+		}
+		sums[cell].Add(&p)
+		counts[cell]++
+		vertexCell[i] = cell
+	}
+	points := make([]dvec3.T, len(sums))
+	for i := range sums {
+		points[i] = sums[i].Scaled(1 / float64(counts[i]))
+	}
+
+	var triangles [][3]int
+	seen := make(map[[3]int]bool)
+	b.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		t := [3]int{
+			vertexCell[corners[0].VertexIndex],
+			vertexCell[corners[1].VertexIndex],
+			vertexCell[corners[2].VertexIndex],
+		}
+		if t[0] == t[1] || t[1] == t[2] || t[2] == t[0] {
+			return true
+		}
+		// Rotate the smallest index first so that equal triangles share a
+		// key regardless of their first corner.
+		for t[0] > t[1] || t[0] > t[2] {
+			t = [3]int{t[1], t[2], t[0]}
+		}
+		if !seen[t] {
+			seen[t] = true
+			triangles = append(triangles, t)
+		}
+		return true
+	})
+	return points, triangles
+}
+
+// newTriangleBuffer returns a buffer holding the given triangles, keeping the
+// material library and Offset of b. Only the referenced points are kept.
+func newTriangleBuffer(b *ObjBuffer, points []dvec3.T, triangles [][3]int) *ObjBuffer {
+	buffer := &ObjBuffer{MTL: b.MTL, Offset: b.Offset}
+	double := b.hasDoublePrecision()
+	mapping := make([]int, len(points))
+	FillIntSlice(mapping, -1)
+	for _, t := range triangles {
+		f := Face{Corners: make([]FaceCorner, 3)}
+		for k, p := range t {
+			f.Corners[k] = FaceCorner{
+				VertexIndex: remapIndex(mapping, p, func(idx int) int {
+					v := points[idx]
+					buffer.V = append(buffer.V, vec3.T{float32(v[0]), float32(v[1]), float32(v[2])})
+					if double {
+						buffer.VD = append(buffer.VD, v)
+					}
+					return len(buffer.V) - 1
+				}),
+				NormalIndex:   -1,
+				TexcoordIndex: -1,
+			}
+		}
+		buffer.F = append(buffer.F, f)
+	}
+	if len(buffer.F) > 0 {
+		buffer.G = []Group{{Name: defaultGroupName, FirstFaceIndex: 0, FaceCount: len(buffer.F)}}
+	}
+	buffer.FaceGroup = faceGroupsOf(buffer.F)
+	return buffer
+}
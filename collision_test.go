@@ -0,0 +1,113 @@
+package obj
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_ConvexHull_CubeWithInteriorPoints_ReturnsCube(t *testing.T) {
+	// Arrange
+	cube := createCube(vec3.T{0, 0, 0}, vec3.T{2, 2, 2}, "rock")
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		cube.V = append(cube.V, vec3.T{0.1 + 1.8*rng.Float32(), 0.1 + 1.8*rng.Float32(), 0.1 + 1.8*rng.Float32()})
+	}
+
+	// Act
+	hull := cube.ConvexHull()
+
+	// Assert
+	assert.Equal(t, 8, len(hull.V))
+	assert.Equal(t, 12, len(hull.F))
+	assert.InDelta(t, 8, meshVolume(hull), 1e-5)
+	assert.True(t, hull.BuildTopology().IsManifold())
+	assert.Equal(t, 2, hull.BuildTopology().EulerCharacteristic())
+}
+
+func TestObjBuffer_ConvexHull_OutwardFacingTriangles(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{}
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 500; i++ {
+		buffer.V = append(buffer.V, vec3.T{rng.Float32(), rng.Float32(), rng.Float32()})
+	}
+
+	// Act
+	hull := buffer.ConvexHull()
+
+	// Assert
+	assert.True(t, len(hull.F) > 4)
+	box := hull.BoundingBox()
+	center := box.Center()
+	hull.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		e1 := vec3.Sub(&tri[1], &tri[0])
+		e2 := vec3.Sub(&tri[2], &tri[0])
+		n := vec3.Cross(&e1, &e2)
+		toCenter := vec3.Sub(&center, &tri[0])
+		assert.True(t, vec3.Dot(&n, &toCenter) < 0, "face %d points inwards", faceIdx)
+		return true
+	})
+	for _, v := range buffer.V {
+		for _, f := range hull.F {
+			a, b, c := hull.V[f.Corners[0].VertexIndex], hull.V[f.Corners[1].VertexIndex], hull.V[f.Corners[2].VertexIndex]
+			e1, e2 := vec3.Sub(&b, &a), vec3.Sub(&c, &a)
+			n := vec3.Cross(&e1, &e2)
+			d := vec3.Sub(&v, &a)
+			assert.True(t, vec3.Dot(&n, &d) <= 1e-5)
+		}
+	}
+}
+
+func TestObjBuffer_ConvexHull_CoplanarVertices_ReturnsNoFaces(t *testing.T) {
+	buffer := &ObjBuffer{V: []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}}}
+
+	hull := buffer.ConvexHull()
+
+	assert.Equal(t, 0, len(hull.F))
+	assert.Equal(t, 0, len(hull.G))
+}
+
+func TestObjBuffer_CollisionProxy_MergesCloseVertices(t *testing.T) {
+	// Arrange: a cube with a tiny sliver face near one corner.
+	cube := createCube(vec3.T{0, 0, 0}, vec3.T{4, 4, 4}, "rock")
+	cube.V = append(cube.V, vec3.T{0.01, 0.01, 0}, vec3.T{0.02, 0, 0.01})
+	cube.F = append(cube.F, Face{Corners: []FaceCorner{{0, -1, -1}, {8, -1, -1}, {9, -1, -1}}})
+
+	// Act
+	proxy := cube.CollisionProxy(CollisionProxyOptions{CellSize: 1})
+
+	// Assert
+	assert.Equal(t, 8, len(proxy.V))
+	assert.Equal(t, 12, len(proxy.F))
+	assert.Equal(t, "", proxy.F[0].Material)
+	assert.Equal(t, []Group{{Name: "default group", FirstFaceIndex: 0, FaceCount: 12}}, proxy.G)
+}
+
+func TestObjBuffer_CollisionProxy_MaxTriangles_CoarsensGrid(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{}
+	const n = 16
+	for y := 0; y <= n; y++ {
+		for x := 0; x <= n; x++ {
+			buffer.V = append(buffer.V, vec3.T{float32(x), float32(y), 0})
+		}
+	}
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			i := y*(n+1) + x
+			buffer.F = append(buffer.F, Face{Corners: []FaceCorner{
+				{i, -1, -1}, {i + 1, -1, -1}, {i + n + 2, -1, -1}, {i + n + 1, -1, -1},
+			}})
+		}
+	}
+
+	// Act
+	proxy := buffer.CollisionProxy(CollisionProxyOptions{CellSize: 1, MaxTriangles: 100})
+
+	// Assert
+	assert.True(t, len(proxy.F) > 0)
+	assert.True(t, len(proxy.F) <= 100)
+}
@@ -0,0 +1,98 @@
+package obj
+
+import (
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+)
+
+// CoordinateConversion converts a buffer from the conventions of OBJ, which
+// is right-handed with Y up, counter-clockwise front faces and texture
+// coordinates with their origin at the bottom left, to those of another
+// system. The axis swap, the texture coordinate flip and the winding are
+// set together by the presets, since converting one without the others
+// mirrors the mesh, turns it inside out or scrambles its textures.
+type CoordinateConversion struct {
+	// Axes holds, for every axis of the target, the axis of the buffer it
+	// is taken from, negated when Negate is set for it.
+	Axes   [3]Axis
+	Negate [3]bool
+	// FlipV replaces the V texture coordinate by 1-V, for systems whose
+	// texture origin is the top left.
+	FlipV bool
+	// ReverseWinding reverses the winding of the faces, for systems whose
+	// front faces are clockwise.
+	ReverseWinding bool
+}
+
+var (
+	// ForUnity converts to Unity: left-handed with Y up, so X is negated,
+	// and clockwise front faces.
+	ForUnity = CoordinateConversion{
+		Axes:           [3]Axis{AxisX, AxisY, AxisZ},
+		Negate:         [3]bool{true, false, false},
+		ReverseWinding: true,
+	}
+	// ForUnreal converts to Unreal Engine: left-handed with X forward, Y
+	// right and Z up, clockwise front faces and texture coordinates with
+	// their origin at the top left. Units are not converted.
+	ForUnreal = CoordinateConversion{
+		Axes:           [3]Axis{AxisZ, AxisX, AxisY},
+		Negate:         [3]bool{true, false, false},
+		FlipV:          true,
+		ReverseWinding: true,
+	}
+	// ForThreeJS converts to three.js, which shares the conventions of OBJ
+	// and leaves the buffer unchanged.
+	ForThreeJS = CoordinateConversion{
+		Axes: [3]Axis{AxisX, AxisY, AxisZ},
+	}
+)
+
+// ConvertCoordinates converts the positions, offset included, the normals,
+// the texture coordinates and the winding of the faces as set by c.
+func (b *ObjBuffer) ConvertCoordinates(c CoordinateConversion) {
+	for i, v := range b.V {
+		b.V[i] = c.vector(v)
+	}
+	for i, v := range b.VD {
+		b.VD[i] = c.vectorD(v)
+	}
+	b.Offset = c.vectorD(b.Offset)
+	// The axis swap is orthogonal, so it transforms the normals as it
+	// does the positions.
+	for i, vn := range b.VN {
+		b.VN[i] = c.vector(vn)
+	}
+	if c.FlipV {
+		for i := range b.VT {
+			b.VT[i][1] = 1 - b.VT[i][1]
+		}
+	}
+	if c.ReverseWinding {
+		for i := range b.F {
+			b.F[i].reverse()
+		}
+	}
+}
+
+func (c *CoordinateConversion) vector(v vec3.T) vec3.T {
+	var converted vec3.T
+	for k, axis := range c.Axes {
+		converted[k] = v[axis]
+		if c.Negate[k] {
+			converted[k] = -converted[k]
+		}
+	}
+	return converted
+}
+
+func (c *CoordinateConversion) vectorD(v dvec3.T) dvec3.T {
+	var converted dvec3.T
+	for k, axis := range c.Axes {
+		converted[k] = v[axis]
+		if c.Negate[k] {
+			converted[k] = -converted[k]
+		}
+	}
+	return converted
+}
@@ -0,0 +1,67 @@
+package obj
+
+import (
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func createConversionTestBuffer() *ObjBuffer {
+	return &ObjBuffer{
+		V:      []vec3.T{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}},
+		VN:     []vec3.T{{0, 0, 1}},
+		VT:     []vec2.T{{0.25, 0.75}},
+		Offset: dvec3.T{10, 20, 30},
+		F: []Face{{Corners: []FaceCorner{
+			{VertexIndex: 0, NormalIndex: 0, TexcoordIndex: 0},
+			{VertexIndex: 1, NormalIndex: 0, TexcoordIndex: 0},
+			{VertexIndex: 2, NormalIndex: 0, TexcoordIndex: 0},
+		}}},
+	}
+}
+
+func TestObjBuffer_ConvertCoordinates_ForUnity_MirrorsXAndReversesWinding(t *testing.T) {
+	// Arrange
+	buffer := createConversionTestBuffer()
+
+	// Act
+	buffer.ConvertCoordinates(ForUnity)
+
+	// Assert
+	assert.Equal(t, []vec3.T{{-1, 2, 3}, {-4, 5, 6}, {-7, 8, 9}}, buffer.V)
+	assert.Equal(t, dvec3.T{-10, 20, 30}, buffer.Offset)
+	assert.Equal(t, []vec3.T{{0, 0, 1}}, buffer.VN)
+	assert.Equal(t, []vec2.T{{0.25, 0.75}}, buffer.VT)
+	assert.Equal(t, []int{2, 1, 0}, vertexIndices(buffer.F[0].Corners))
+}
+
+func TestObjBuffer_ConvertCoordinates_ForUnreal_SwapsToZUpAndFlipsV(t *testing.T) {
+	// Arrange
+	buffer := createConversionTestBuffer()
+	buffer.VD = []dvec3.T{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}
+
+	// Act
+	buffer.ConvertCoordinates(ForUnreal)
+
+	// Assert
+	assert.Equal(t, vec3.T{-3, 1, 2}, buffer.V[0])
+	assert.Equal(t, dvec3.T{-3, 1, 2}, buffer.VD[0])
+	assert.Equal(t, dvec3.T{-30, 10, 20}, buffer.Offset)
+	assert.Equal(t, []vec3.T{{-1, 0, 0}}, buffer.VN)
+	assert.Equal(t, []vec2.T{{0.25, 0.25}}, buffer.VT)
+	assert.Equal(t, []int{2, 1, 0}, vertexIndices(buffer.F[0].Corners))
+}
+
+func TestObjBuffer_ConvertCoordinates_ForThreeJS_LeavesBuffer(t *testing.T) {
+	// Arrange
+	buffer := createConversionTestBuffer()
+
+	// Act
+	buffer.ConvertCoordinates(ForThreeJS)
+
+	// Assert
+	assert.Equal(t, createConversionTestBuffer(), buffer)
+}
@@ -0,0 +1,378 @@
+package obj
+
+import (
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+)
+
+// csgEpsilon is the tolerance used to decide whether a point lies on a plane.
+const csgEpsilon = 1e-5
+
+// Union returns a buffer holding the volume covered by a or b. Both buffers
+// are triangulated and are expected to be closed; the result is expressed in
+// the coordinate frame of a, including its Offset.
+func Union(a, b *ObjBuffer) *ObjBuffer {
+	na, nb := csgNodes(a, b)
+	na.clipTo(nb)
+	nb.clipTo(na)
+	nb.invert()
+	nb.clipTo(na)
+	nb.invert()
+	na.build(nb.allPolygons())
+	return csgBuffer(a, na.allPolygons())
+}
+
+// Subtract returns a buffer holding the volume of a that is not covered by
+// b. See Union for the requirements on the buffers.
+func Subtract(a, b *ObjBuffer) *ObjBuffer {
+	na, nb := csgNodes(a, b)
+	na.invert()
+	na.clipTo(nb)
+	nb.clipTo(na)
+	nb.invert()
+	nb.clipTo(na)
+	nb.invert()
+	na.build(nb.allPolygons())
+	na.invert()
+	return csgBuffer(a, na.allPolygons())
+}
+
+// Intersect returns a buffer holding the volume covered by both a and b.
+// See Union for the requirements on the buffers.
+func Intersect(a, b *ObjBuffer) *ObjBuffer {
+	na, nb := csgNodes(a, b)
+	na.invert()
+	nb.clipTo(na)
+	nb.invert()
+	na.clipTo(nb)
+	nb.clipTo(na)
+	na.build(nb.allPolygons())
+	na.invert()
+	return csgBuffer(a, na.allPolygons())
+}
+
+type csgVertex struct {
+	pos    dvec3.T
+	normal dvec3.T
+	uv     [2]float64
+}
+
+func (v csgVertex) interpolate(other csgVertex, t float64) csgVertex {
+	return csgVertex{
+		pos:    dvec3.Interpolate(&v.pos, &other.pos, t),
+		normal: dvec3.Interpolate(&v.normal, &other.normal, t),
+		uv: [2]float64{
+			v.uv[0] + (other.uv[0]-v.uv[0])*t,
+			v.uv[1] + (other.uv[1]-v.uv[1])*t,
+		},
+	}
+}
+
+type csgPlane struct {
+	normal dvec3.T
+	w      float64
+}
+
+func csgPlaneFromPoints(a, b, c dvec3.T) (csgPlane, bool) {
+	e1 := dvec3.Sub(&b, &a)
+	e2 := dvec3.Sub(&c, &a)
+	n := dvec3.Cross(&e1, &e2)
+	if n.Length() == 0 {
+		return csgPlane{}, false
+	}
+	n.Normalize()
+	return csgPlane{n, dvec3.Dot(&n, &a)}, true
+}
+
+func (p *csgPlane) flip() {
+	p.normal.Invert()
+	p.w = -p.w
+}
+
+type csgPolygon struct {
+	vertices []csgVertex
+	plane    csgPlane
+	material string
+	// hasNormals and hasUVs record whether the source face carried vertex
+	// normals and texture coordinates.
+	hasNormals bool
+	hasUVs     bool
+}
+
+func (p *csgPolygon) flip() {
+	for i, j := 0, len(p.vertices)-1; i < j; i, j = i+1, j-1 {
+		p.vertices[i], p.vertices[j] = p.vertices[j], p.vertices[i]
+	}
+	for i := range p.vertices {
+		p.vertices[i].normal.Invert()
+	}
+	p.plane.flip()
+}
+
+const (
+	csgCoplanar = 0
+	csgFront    = 1
+	csgBack     = 2
+	csgSpanning = 3
+)
+
+// splitPolygon sorts polygon into the lists by its position relative to the
+// plane, splitting it when it spans the plane.
+func (p *csgPlane) splitPolygon(polygon *csgPolygon, coplanarFront, coplanarBack, front, back *[]*csgPolygon) {
+	polygonType := 0
+	types := make([]int, len(polygon.vertices))
+	for i := range polygon.vertices {
+		t := dvec3.Dot(&p.normal, &polygon.vertices[i].pos) - p.w
+		vertexType := csgCoplanar
+		if t < -csgEpsilon {
+			vertexType = csgBack
+		} else if t > csgEpsilon {
+			vertexType = csgFront
+		}
+		polygonType |= vertexType
+		types[i] = vertexType
+	}
+
+	switch polygonType {
+	case csgCoplanar:
+		if dvec3.Dot(&p.normal, &polygon.plane.normal) > 0 {
+			*coplanarFront = append(*coplanarFront, polygon)
+		} else {
+			*coplanarBack = append(*coplanarBack, polygon)
+		}
+	case csgFront:
+		*front = append(*front, polygon)
+	case csgBack:
+		*back = append(*back, polygon)
+	case csgSpanning:
+		var f, b []csgVertex
+		n := len(polygon.vertices)
+		for i := 0; i < n; i++ {
+			j := (i + 1) % n
+			ti, tj := types[i], types[j]
+			vi, vj := polygon.vertices[i], polygon.vertices[j]
+			if ti != csgBack {
+				f = append(f, vi)
+			}
+			if ti != csgFront {
+				b = append(b, vi)
+			}
+			if (ti | tj) == csgSpanning {
+				d := dvec3.Sub(&vj.pos, &vi.pos)
+				t := (p.w - dvec3.Dot(&p.normal, &vi.pos)) / dvec3.Dot(&p.normal, &d)
+				v := vi.interpolate(vj, t)
+				f = append(f, v)
+				b = append(b, v)
+			}
+		}
+		if len(f) >= 3 {
+			*front = append(*front, polygon.withVertices(f))
+		}
+		if len(b) >= 3 {
+			*back = append(*back, polygon.withVertices(b))
+		}
+	}
+}
+
+func (p *csgPolygon) withVertices(vertices []csgVertex) *csgPolygon {
+	q := *p
+	q.vertices = vertices
+	return &q
+}
+
+// csgNode is a node of a BSP tree of polygons.
+type csgNode struct {
+	plane       *csgPlane
+	front, back *csgNode
+	polygons    []*csgPolygon
+}
+
+func (n *csgNode) invert() {
+	for _, p := range n.polygons {
+		p.flip()
+	}
+	if n.plane != nil {
+		n.plane.flip()
+	}
+	if n.front != nil {
+		n.front.invert()
+	}
+	if n.back != nil {
+		n.back.invert()
+	}
+	n.front, n.back = n.back, n.front
+}
+
+// clipPolygons removes the parts of polygons that are inside the tree.
+func (n *csgNode) clipPolygons(polygons []*csgPolygon) []*csgPolygon {
+	if n.plane == nil {
+		return append([]*csgPolygon(nil), polygons...)
+	}
+	var front, back []*csgPolygon
+	for _, p := range polygons {
+		n.plane.splitPolygon(p, &front, &back, &front, &back)
+	}
+	if n.front != nil {
+		front = n.front.clipPolygons(front)
+	}
+	if n.back != nil {
+		back = n.back.clipPolygons(back)
+	} else {
+		back = nil
+	}
+	return append(front, back...)
+}
+
+// clipTo removes the parts of the polygons of this tree that are inside
+// the tree other.
+func (n *csgNode) clipTo(other *csgNode) {
+	n.polygons = other.clipPolygons(n.polygons)
+	if n.front != nil {
+		n.front.clipTo(other)
+	}
+	if n.back != nil {
+		n.back.clipTo(other)
+	}
+}
+
+func (n *csgNode) allPolygons() []*csgPolygon {
+	polygons := append([]*csgPolygon(nil), n.polygons...)
+	if n.front != nil {
+		polygons = append(polygons, n.front.allPolygons()...)
+	}
+	if n.back != nil {
+		polygons = append(polygons, n.back.allPolygons()...)
+	}
+	return polygons
+}
+
+func (n *csgNode) build(polygons []*csgPolygon) {
+	if len(polygons) == 0 {
+		return
+	}
+	if n.plane == nil {
+		plane := polygons[0].plane
+		n.plane = &plane
+	}
+	var front, back []*csgPolygon
+	for _, p := range polygons {
+		n.plane.splitPolygon(p, &n.polygons, &n.polygons, &front, &back)
+	}
+	if len(front) > 0 {
+		if n.front == nil {
+			n.front = &csgNode{}
+		}
+		n.front.build(front)
+	}
+	if len(back) > 0 {
+		if n.back == nil {
+			n.back = &csgNode{}
+		}
+		n.back.build(back)
+	}
+}
+
+// csgNodes builds the BSP trees of a and b, with b moved into the frame of a.
+func csgNodes(a, b *ObjBuffer) (*csgNode, *csgNode) {
+	shift := dvec3.Sub(&b.Offset, &a.Offset)
+	na, nb := &csgNode{}, &csgNode{}
+	na.build(csgPolygons(a, dvec3.Zero))
+	nb.build(csgPolygons(b, shift))
+	return na, nb
+}
+
+// csgPolygons returns the triangles of b as polygons, moved by shift.
+// Degenerate triangles are dropped.
+func csgPolygons(b *ObjBuffer, shift dvec3.T) []*csgPolygon {
+	var polygons []*csgPolygon
+	b.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		var vertices [3]csgVertex
+		hasNormals, hasUVs := true, true
+		for k, c := range corners {
+			vertices[k].pos = b.positionD(c.VertexIndex)
+			vertices[k].pos.Add(&shift)
+			if c.NormalIndex >= 0 && c.NormalIndex < len(b.VN) {
+				n := b.VN[c.NormalIndex]
+				vertices[k].normal = dvec3.T{float64(n[0]), float64(n[1]), float64(n[2])}
+			} else {
+				hasNormals = false
+			}
+			if c.TexcoordIndex >= 0 && c.TexcoordIndex < len(b.VT) {
+				uv := b.VT[c.TexcoordIndex]
+				vertices[k].uv = [2]float64{float64(uv[0]), float64(uv[1])}
+			} else {
+				hasUVs = false
+			}
+		}
+		plane, ok := csgPlaneFromPoints(vertices[0].pos, vertices[1].pos, vertices[2].pos)
+		if !ok {
+			return true
+		}
+		if !hasNormals {
+			for k := range vertices {
+				vertices[k].normal = plane.normal
+			}
+		}
+		polygons = append(polygons, &csgPolygon{
+			vertices:   vertices[:],
+			plane:      plane,
+			material:   b.F[faceIdx].Material,
+			hasNormals: hasNormals,
+			hasUVs:     hasUVs,
+		})
+		return true
+	})
+	return polygons
+}
+
+// csgBuffer converts polygons back into a buffer in the frame of a. Equal
+// positions, normals and texture coordinates are shared between faces.
+func csgBuffer(a *ObjBuffer, polygons []*csgPolygon) *ObjBuffer {
+	buffer := &ObjBuffer{MTL: a.MTL, Offset: a.Offset}
+	positions := make(map[vec3.T]int)
+	normals := make(map[vec3.T]int)
+	texcoords := make(map[vec2.T]int)
+	for _, p := range polygons {
+		f := Face{Corners: make([]FaceCorner, len(p.vertices)), Material: p.material}
+		for k, v := range p.vertices {
+			pos := vec3.T{float32(v.pos[0]), float32(v.pos[1]), float32(v.pos[2])}
+			idx, ok := positions[pos]
+			if !ok {
+				idx = len(buffer.V)
+				buffer.V = append(buffer.V, pos)
+				positions[pos] = idx
+			}
+			f.Corners[k] = FaceCorner{VertexIndex: idx, NormalIndex: -1, TexcoordIndex: -1}
+
+			n := v.normal
+			if !p.hasNormals || n.Length() == 0 {
+				n = p.plane.normal
+			}
+			n.Normalize()
+			normal := vec3.T{float32(n[0]), float32(n[1]), float32(n[2])}
+			if idx, ok = normals[normal]; !ok {
+				idx = len(buffer.VN)
+				buffer.VN = append(buffer.VN, normal)
+				normals[normal] = idx
+			}
+			f.Corners[k].NormalIndex = idx
+
+			if p.hasUVs {
+				uv := vec2.T{float32(v.uv[0]), float32(v.uv[1])}
+				if idx, ok = texcoords[uv]; !ok {
+					idx = len(buffer.VT)
+					buffer.VT = append(buffer.VT, uv)
+					texcoords[uv] = idx
+				}
+				f.Corners[k].TexcoordIndex = idx
+			}
+		}
+		buffer.F = append(buffer.F, f)
+	}
+	if len(buffer.F) > 0 {
+		buffer.G = []Group{{Name: defaultGroupName, FirstFaceIndex: 0, FaceCount: len(buffer.F)}}
+	}
+	buffer.FaceGroup = faceGroupsOf(buffer.F)
+	return buffer
+}
@@ -0,0 +1,123 @@
+package obj
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+// createCube returns a closed, outward facing cube spanning min to max.
+func createCube(min, max vec3.T, material string) *ObjBuffer {
+	b := &ObjBuffer{}
+	for i := 0; i < 8; i++ {
+		v := min
+		if i&1 != 0 {
+			v[0] = max[0]
+		}
+		if i&2 != 0 {
+			v[1] = max[1]
+		}
+		if i&4 != 0 {
+			v[2] = max[2]
+		}
+		b.V = append(b.V, v)
+	}
+	quads := [][4]int{
+		{0, 4, 6, 2}, {1, 3, 7, 5}, // -x, +x
+		{0, 1, 5, 4}, {2, 6, 7, 3}, // -y, +y
+		{0, 2, 3, 1}, {4, 5, 7, 6}, // -z, +z
+	}
+	for _, q := range quads {
+		f := Face{Material: material}
+		for _, idx := range q {
+			f.Corners = append(f.Corners, FaceCorner{VertexIndex: idx, NormalIndex: -1, TexcoordIndex: -1})
+		}
+		b.F = append(b.F, f)
+	}
+	b.G = []Group{{Name: "cube", FirstFaceIndex: 0, FaceCount: len(b.F)}}
+	b.FaceGroup = faceGroupsOf(b.F)
+	return b
+}
+
+// meshVolume returns the volume enclosed by the triangles of b.
+func meshVolume(b *ObjBuffer) float64 {
+	volume := 0.0
+	b.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		cross := vec3.Cross(&tri[1], &tri[2])
+		volume += float64(vec3.Dot(&tri[0], &cross)) / 6
+		return true
+	})
+	return math.Abs(volume)
+}
+
+func TestCSG_OverlappingCubes_ReturnsExpectedVolumes(t *testing.T) {
+	// Arrange
+	a := createCube(vec3.T{0, 0, 0}, vec3.T{2, 2, 2}, "rock")
+	b := createCube(vec3.T{1, 1, 1}, vec3.T{3, 3, 3}, "tunnel")
+
+	// Act
+	union := Union(a, b)
+	intersection := Intersect(a, b)
+	difference := Subtract(a, b)
+
+	// Assert
+	assert.InDelta(t, 15, meshVolume(union), 1e-4)
+	assert.InDelta(t, 1, meshVolume(intersection), 1e-4)
+	assert.InDelta(t, 7, meshVolume(difference), 1e-4)
+	assert.Equal(t, 1, len(difference.G))
+	assert.Equal(t, len(difference.F), difference.G[0].FaceCount)
+}
+
+func TestCSG_Subtract_KeepsMaterialsOfBothOperands(t *testing.T) {
+	// Arrange
+	a := createCube(vec3.T{0, 0, 0}, vec3.T{2, 2, 2}, "rock")
+	b := createCube(vec3.T{1, 1, 1}, vec3.T{3, 3, 3}, "tunnel")
+
+	// Act
+	difference := Subtract(a, b)
+
+	// Assert
+	materials := map[string]bool{}
+	for _, f := range difference.F {
+		materials[f.Material] = true
+	}
+	assert.Equal(t, map[string]bool{"rock": true, "tunnel": true}, materials)
+	for _, n := range difference.VN {
+		assert.InDelta(t, 1, n.Length(), 1e-5)
+	}
+}
+
+func TestCSG_Subtract_DisjointOperand_ReturnsOriginalVolume(t *testing.T) {
+	// Arrange
+	a := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "rock")
+	b := createCube(vec3.T{5, 5, 5}, vec3.T{6, 6, 6}, "tunnel")
+
+	// Act
+	difference := Subtract(a, b)
+	intersection := Intersect(a, b)
+
+	// Assert
+	assert.InDelta(t, 1, meshVolume(difference), 1e-5)
+	assert.Equal(t, 0, len(intersection.F))
+	assert.Equal(t, 0, len(intersection.G))
+}
+
+func TestCSG_Union_DifferentOffsets_UsesFrameOfFirstOperand(t *testing.T) {
+	// Arrange
+	a := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "rock")
+	a.Offset[0] = 1000
+	b := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "rock")
+	b.Offset[0] = 1002
+
+	// Act
+	union := Union(a, b)
+
+	// Assert
+	box := union.BoundingBox()
+	assert.Equal(t, a.Offset, union.Offset)
+	assert.Equal(t, vec3.T{0, 0, 0}, box.Min)
+	assert.Equal(t, vec3.T{3, 1, 1}, box.Max)
+	assert.InDelta(t, 2, meshVolume(union), 1e-5)
+}
@@ -0,0 +1,277 @@
+package obj
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Diff is the structural difference between two buffers, a and b, as
+// returned by DiffBuffers. Indices of removed elements refer to a, indices
+// of added and changed elements to b.
+type Diff struct {
+	AddedVertices   []int
+	RemovedVertices []int
+	// ChangedVertices lists the vertices present in both buffers that moved
+	// by more than the tolerance.
+	ChangedVertices []int
+
+	AddedFaces   []int
+	RemovedFaces []int
+	// ChangedFaces lists the faces whose index exists in both buffers but
+	// whose content, found in neither buffer, differs.
+	ChangedFaces []int
+
+	AddedGroups   []string
+	RemovedGroups []string
+	// ChangedGroups lists the groups present in both buffers with different
+	// faces.
+	ChangedGroups []string
+
+	AddedMaterials   []string
+	RemovedMaterials []string
+	// ChangedMaterials lists the materials present in both buffers assigned
+	// to a different number of faces.
+	ChangedMaterials []string
+}
+
+// DiffBuffers compares a and b. Vertices are compared index by index.
+// Faces are compared by content, the positions of their corners and their
+// material, so that faces that were only reordered or reference renumbered
+// vertices are not reported. Positions closer than tolerance are equal.
+// Groups and materials are compared by name.
+func DiffBuffers(a, b *ObjBuffer, tolerance float64) *Diff {
+	d := &Diff{}
+
+	for i := 0; i < len(a.V) || i < len(b.V); i++ {
+		switch {
+		case i >= len(b.V):
+			d.RemovedVertices = append(d.RemovedVertices, i)
+		case i >= len(a.V):
+			d.AddedVertices = append(d.AddedVertices, i)
+		default:
+			pa, pb := a.positionD(i), b.positionD(i)
+			pa.Add(&a.Offset)
+			pb.Add(&b.Offset)
+			if vectorDistance(pa[:], pb[:]) > tolerance {
+				d.ChangedVertices = append(d.ChangedVertices, i)
+			}
+		}
+	}
+
+	keysA, keysB := a.faceKeys(tolerance), b.faceKeys(tolerance)
+	removed := unmatchedKeys(keysA, keysB)
+	added := unmatchedKeys(keysB, keysA)
+	isAdded := map[int]bool{}
+	for _, i := range added {
+		isAdded[i] = true
+	}
+	for _, i := range removed {
+		if isAdded[i] {
+			d.ChangedFaces = append(d.ChangedFaces, i)
+			delete(isAdded, i)
+		} else {
+			d.RemovedFaces = append(d.RemovedFaces, i)
+		}
+	}
+	for _, i := range added {
+		if isAdded[i] {
+			d.AddedFaces = append(d.AddedFaces, i)
+		}
+	}
+
+	groupsA, groupsB := a.groupFaceKeys(keysA), b.groupFaceKeys(keysB)
+	d.AddedGroups, d.RemovedGroups, d.ChangedGroups = diffNamed(sortedListKeys(groupsA), sortedListKeys(groupsB), func(name string) bool {
+		return strings.Join(groupsA[name], "\n") != strings.Join(groupsB[name], "\n")
+	})
+	usesA, usesB := a.materialUses(), b.materialUses()
+	d.AddedMaterials, d.RemovedMaterials, d.ChangedMaterials = diffNamed(sortedCountKeys(usesA), sortedCountKeys(usesB), func(name string) bool {
+		return usesA[name] != usesB[name]
+	})
+	return d
+}
+
+// Empty reports whether the buffers compared equal.
+func (d *Diff) Empty() bool {
+	return len(d.AddedVertices)+len(d.RemovedVertices)+len(d.ChangedVertices)+
+		len(d.AddedFaces)+len(d.RemovedFaces)+len(d.ChangedFaces)+
+		len(d.AddedGroups)+len(d.RemovedGroups)+len(d.ChangedGroups)+
+		len(d.AddedMaterials)+len(d.RemovedMaterials)+len(d.ChangedMaterials) == 0
+}
+
+// String formats the difference one element per line, prefixed with "+"
+// for added, "-" for removed and "~" for changed elements. Vertices and
+// faces are numbered from 1, as in OBJ files.
+func (d *Diff) String() string {
+	var b strings.Builder
+	indices := func(prefix, what string, list []int) {
+		for _, i := range list {
+			fmt.Fprintf(&b, "%s %s %d\n", prefix, what, i+1)
+		}
+	}
+	names := func(prefix, what string, list []string) {
+		for _, name := range list {
+			fmt.Fprintf(&b, "%s %s %s\n", prefix, what, name)
+		}
+	}
+	indices("-", "vertex", d.RemovedVertices)
+	indices("+", "vertex", d.AddedVertices)
+	indices("~", "vertex", d.ChangedVertices)
+	indices("-", "face", d.RemovedFaces)
+	indices("+", "face", d.AddedFaces)
+	indices("~", "face", d.ChangedFaces)
+	names("-", "group", d.RemovedGroups)
+	names("+", "group", d.AddedGroups)
+	names("~", "group", d.ChangedGroups)
+	names("-", "material", d.RemovedMaterials)
+	names("+", "material", d.AddedMaterials)
+	names("~", "material", d.ChangedMaterials)
+	return b.String()
+}
+
+// faceKeys returns a key per face identifying it by material and by the
+// positions of its corners and those of its holes, snapped to a grid of the
+// tolerance and starting at the smallest of each loop.
+func (b *ObjBuffer) faceKeys(tolerance float64) []string {
+	keys := make([]string, len(b.F))
+	for i, f := range b.F {
+		key := f.Material
+		for _, loop := range f.loops() {
+			key += "|" + strings.Join(rotateToSmallest(b.cornerKeys(loop, tolerance)), ",")
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+// cornerKeys returns the positions of the corners of a loop, snapped to a
+// grid of the tolerance, as strings.
+func (b *ObjBuffer) cornerKeys(loop []FaceCorner, tolerance float64) []string {
+	corners := make([]string, len(loop))
+	for j, c := range loop {
+		if c.VertexIndex < 0 || c.VertexIndex >= len(b.V) {
+			corners[j] = "?"
+			continue
+		}
+		p := b.positionD(c.VertexIndex)
+		p.Add(&b.Offset)
+		corners[j] = fmt.Sprintf("%g %g %g", snap(p[0], tolerance), snap(p[1], tolerance), snap(p[2], tolerance))
+	}
+	return corners
+}
+
+// rotateToSmallest returns keys rotated to start at the smallest.
+func rotateToSmallest(keys []string) []string {
+	first := 0
+	for j := range keys {
+		if keys[j] < keys[first] {
+			first = j
+		}
+	}
+	return append(keys[first:len(keys):len(keys)], keys[:first]...)
+}
+
+// groupFaceKeys returns the sorted keys of the faces of each group, by
+// group name.
+func (b *ObjBuffer) groupFaceKeys(keys []string) map[string][]string {
+	groups := map[string][]string{}
+	for _, g := range b.G {
+		list := groups[g.Name]
+		for i := g.FirstFaceIndex; i < g.FirstFaceIndex+g.FaceCount && i < len(keys); i++ {
+			list = append(list, keys[i])
+		}
+		sort.Strings(list)
+		groups[g.Name] = list
+	}
+	return groups
+}
+
+// materialUses returns the number of faces using each material.
+func (b *ObjBuffer) materialUses() map[string]int {
+	uses := map[string]int{}
+	for _, f := range b.F {
+		if f.Material != "" {
+			uses[f.Material]++
+		}
+	}
+	return uses
+}
+
+// unmatchedKeys returns the indices of the keys of a that have no
+// counterpart in b, pairing equal keys one to one.
+func unmatchedKeys(a, b []string) []int {
+	available := map[string]int{}
+	for _, k := range b {
+		available[k]++
+	}
+	var unmatched []int
+	for i, k := range a {
+		if available[k] > 0 {
+			available[k]--
+			continue
+		}
+		unmatched = append(unmatched, i)
+	}
+	return unmatched
+}
+
+// diffNamed returns the names only in namesB, only in namesA, and in both
+// for which changed returns true. The names must be sorted.
+func diffNamed(namesA, namesB []string, changed func(name string) bool) (added, removed, both []string) {
+	inA := map[string]bool{}
+	for _, name := range namesA {
+		inA[name] = true
+	}
+	for _, name := range namesB {
+		if !inA[name] {
+			added = append(added, name)
+		} else if changed(name) {
+			both = append(both, name)
+		}
+		delete(inA, name)
+	}
+	for _, name := range namesA {
+		if inA[name] {
+			removed = append(removed, name)
+		}
+	}
+	return added, removed, both
+}
+
+func sortedListKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedCountKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func snap(v, tolerance float64) float64 {
+	if tolerance <= 0 {
+		return v
+	}
+	if v = math.Round(v/tolerance) * tolerance; v == 0 {
+		// Drop the sign of negative zero.
+		return 0
+	}
+	return v
+}
+
+func vectorDistance(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,81 @@
+package obj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffBuffers_Equal_ReturnsEmptyDiff(t *testing.T) {
+	// Arrange
+	a := &readTestObj(t, "v 0 0 0\nv 1 0 0\nv 0 1 0\ng a\nusemtl red\nf 1 2 3\n", ReadOptions{}).ObjBuffer
+
+	// Act
+	diff := DiffBuffers(a, a, 0)
+
+	// Assert
+	assert.True(t, diff.Empty())
+	assert.Equal(t, "", diff.String())
+}
+
+func TestDiffBuffers_ReorderedFacesAndRotatedCorners_AreEqual(t *testing.T) {
+	// Arrange
+	a := &readTestObj(t, "v 0 0 0\nv 1 0 0\nv 0 1 0\nv 1 1 0\ng a\nf 1 2 3\nf 2 4 3\n", ReadOptions{}).ObjBuffer
+	b := &readTestObj(t, "v 0 0 0\nv 1 0 0\nv 0 1 0\nv 1 1 0\ng a\nf 4 3 2\nf 3 1 2\n", ReadOptions{}).ObjBuffer
+
+	// Act
+	diff := DiffBuffers(a, b, 1e-6)
+
+	// Assert
+	assert.True(t, diff.Empty(), diff.String())
+}
+
+func TestDiffBuffers_Changes_ReportsAddedRemovedAndChanged(t *testing.T) {
+	// Arrange
+	a := &readTestObj(t, "v 0 0 0\nv 1 0 0\nv 0 1 0\n"+
+		"g roof\nusemtl tiles\nf 1 2 3\ng walls\nusemtl brick\nf 1 3 2\n", ReadOptions{}).ObjBuffer
+	b := &readTestObj(t, "v 0 0 0\nv 1 0 0\nv 0 1.5 0\nv 1 1 0\n"+
+		"g roof\nusemtl tiles\nf 1 2 4\ng terrain\nusemtl grass\nf 1 2 3\nf 2 4 3\n", ReadOptions{}).ObjBuffer
+
+	// Act
+	diff := DiffBuffers(a, b, 1e-6)
+
+	// Assert
+	assert.Equal(t, []int{3}, diff.AddedVertices)
+	assert.Equal(t, []int{2}, diff.ChangedVertices)
+	assert.Equal(t, []int{0, 1}, diff.ChangedFaces)
+	assert.Equal(t, []int{2}, diff.AddedFaces)
+	assert.Empty(t, diff.RemovedFaces)
+	assert.Equal(t, []string{"terrain"}, diff.AddedGroups)
+	assert.Equal(t, []string{"walls"}, diff.RemovedGroups)
+	assert.Equal(t, []string{"roof"}, diff.ChangedGroups)
+	assert.Equal(t, []string{"grass"}, diff.AddedMaterials)
+	assert.Equal(t, []string{"brick"}, diff.RemovedMaterials)
+	assert.Empty(t, diff.ChangedMaterials)
+	assert.Contains(t, diff.String(), "+ vertex 4\n~ vertex 3\n+ face 3\n~ face 1\n~ face 2\n- group walls\n")
+}
+
+func TestDiffBuffers_WithinTolerance_IgnoresMovement(t *testing.T) {
+	// Arrange
+	a := &readTestObj(t, "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n", ReadOptions{}).ObjBuffer
+	b := &readTestObj(t, "v 0 0 0\nv 1.0000001 0 0\nv 0 1 -0.0000001\nf 1 2 3\n", ReadOptions{}).ObjBuffer
+
+	// Act
+	diff := DiffBuffers(a, b, 1e-4)
+
+	// Assert
+	assert.True(t, diff.Empty(), diff.String())
+}
+
+func TestDiffBuffers_HoleMoved_ReportsChangedFace(t *testing.T) {
+	// Arrange
+	a := createSquareWithHole()
+	b := createSquareWithHole()
+	b.V[4][0], b.V[5][0] = 0.5, 0.5
+
+	// Act
+	diff := DiffBuffers(a, b, 1e-6)
+
+	// Assert
+	assert.Equal(t, []int{0}, diff.ChangedFaces)
+}
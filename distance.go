@@ -0,0 +1,217 @@
+package obj
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+)
+
+// MeshDistance measures how far apart the surfaces of two buffers are.
+type MeshDistance struct {
+	// Mean is the mean distance from the points of either surface to the
+	// other surface.
+	Mean float64
+	// Max is the largest distance from a point of either surface to the
+	// other surface, an estimate of the Hausdorff distance.
+	Max float64
+}
+
+// Distance measures the deviation between the surfaces of a and b, offsets
+// included. It places about samples points at random on each surface and
+// measures their distance to the other surface; the vertices are measured
+// too, for the maximum only. The mean is thus an average over the area of
+// the surfaces. Distance fails if samples is not positive or a buffer has
+// no faces with an area.
+func Distance(a, b *ObjBuffer, samples int) (MeshDistance, error) {
+	if samples <= 0 {
+		return MeshDistance{}, fmt.Errorf("Invalid number of samples %d", samples)
+	}
+	treeA, treeB := newTriangleTree(a), newTriangleTree(b)
+	if treeA == nil || treeB == nil {
+		return MeshDistance{}, fmt.Errorf("Cannot measure the distance to a buffer without faces")
+	}
+	var d MeshDistance
+	sum, count := 0.0, 0
+	for _, pair := range []struct {
+		from *ObjBuffer
+		to   *triangleTree
+		area float64
+	}{{a, treeB, treeA.area}, {b, treeA, treeB.area}} {
+		cloud, err := pair.from.SamplePoints(float64(samples) / pair.area)
+		if err != nil {
+			return MeshDistance{}, err
+		}
+		for _, p := range cloud.Positions {
+			p.Add(&pair.from.Offset)
+			distance := pair.to.distance(p)
+			sum += distance
+			count++
+			d.Max = math.Max(d.Max, distance)
+		}
+		for i := range pair.from.V {
+			p := pair.from.positionD(i)
+			p.Add(&pair.from.Offset)
+			d.Max = math.Max(d.Max, pair.to.distance(p))
+		}
+	}
+	if count > 0 {
+		d.Mean = sum / float64(count)
+	}
+	return d, nil
+}
+
+// triangleTree is a bounding volume hierarchy over the triangles of a
+// buffer, answering closest point queries.
+type triangleTree struct {
+	triangles [][3]dvec3.T
+	nodes     []triangleNode
+	area      float64
+}
+
+// triangleNode bounds triangles first to first+count of the tree, or its
+// children left and right if count is 0.
+type triangleNode struct {
+	box          dvec3.Box
+	left, right  int
+	first, count int
+}
+
+// triangleLeafSize is the largest number of triangles of a leaf.
+const triangleLeafSize = 4
+
+// newTriangleTree returns the tree over the triangles of b with an area,
+// offset included, or nil if there are none.
+func newTriangleTree(b *ObjBuffer) *triangleTree {
+	t := &triangleTree{}
+	b.EachTriangle(func(_ [3]vec3.T, corners [3]FaceCorner, _ int) bool {
+		var tri [3]dvec3.T
+		for k, c := range corners {
+			tri[k] = b.positionD(c.VertexIndex)
+			tri[k].Add(&b.Offset)
+		}
+		e1, e2 := dvec3.Sub(&tri[1], &tri[0]), dvec3.Sub(&tri[2], &tri[0])
+		cross := dvec3.Cross(&e1, &e2)
+		if area := cross.Length() / 2; area > 0 {
+			t.triangles = append(t.triangles, tri)
+			t.area += area
+		}
+		return true
+	})
+	if len(t.triangles) == 0 {
+		return nil
+	}
+	t.build(0, len(t.triangles))
+	return t
+}
+
+// build adds the node over triangles first to last and returns its index.
+func (t *triangleTree) build(first, last int) int {
+	node := triangleNode{box: dvec3.Box{Min: t.triangles[first][0], Max: t.triangles[first][0]}}
+	for _, tri := range t.triangles[first:last] {
+		for k := range tri {
+			node.box.Extend(&tri[k])
+		}
+	}
+	index := len(t.nodes)
+	t.nodes = append(t.nodes, node)
+	if last-first <= triangleLeafSize {
+		t.nodes[index].first, t.nodes[index].count = first, last-first
+		return index
+	}
+
+	// Split at the median of the centers along the longest axis.
+	size := dvec3.Sub(&node.box.Max, &node.box.Min)
+	axis := 0
+	for k := 1; k < 3; k++ {
+		if size[k] > size[axis] {
+			axis = k
+		}
+	}
+	triangles := t.triangles[first:last]
+	center := func(tri [3]dvec3.T) float64 { return tri[0][axis] + tri[1][axis] + tri[2][axis] }
+	sort.Slice(triangles, func(i, j int) bool { return center(triangles[i]) < center(triangles[j]) })
+	mid := (first + last) / 2
+	left := t.build(first, mid)
+	right := t.build(mid, last)
+	t.nodes[index].left, t.nodes[index].right = left, right
+	return index
+}
+
+// distance returns the distance from p to the closest triangle.
+func (t *triangleTree) distance(p dvec3.T) float64 {
+	best := math.Inf(1)
+	var search func(i int)
+	search = func(i int) {
+		node := &t.nodes[i]
+		if boxDistance(&node.box, &p) >= best {
+			return
+		}
+		if node.count > 0 {
+			for _, tri := range t.triangles[node.first : node.first+node.count] {
+				q := closestPointOnTriangle(p, tri)
+				best = math.Min(best, dvec3.Distance(&p, &q))
+			}
+			return
+		}
+		left, right := node.left, node.right
+		if boxDistance(&t.nodes[right].box, &p) < boxDistance(&t.nodes[left].box, &p) {
+			left, right = right, left
+		}
+		search(left)
+		search(right)
+	}
+	search(0)
+	return best
+}
+
+// boxDistance returns the distance from p to box, 0 inside it.
+func boxDistance(box *dvec3.Box, p *dvec3.T) float64 {
+	var d dvec3.T
+	for k := 0; k < 3; k++ {
+		d[k] = math.Max(0, math.Max(box.Min[k]-p[k], p[k]-box.Max[k]))
+	}
+	return d.Length()
+}
+
+// closestPointOnTriangle returns the point of the triangle closest to p,
+// following Ericson, "Real-Time Collision Detection", 5.1.5.
+func closestPointOnTriangle(p dvec3.T, tri [3]dvec3.T) dvec3.T {
+	a, b, c := tri[0], tri[1], tri[2]
+	ab, ac, ap := dvec3.Sub(&b, &a), dvec3.Sub(&c, &a), dvec3.Sub(&p, &a)
+	d1, d2 := dvec3.Dot(&ab, &ap), dvec3.Dot(&ac, &ap)
+	if d1 <= 0 && d2 <= 0 {
+		return a
+	}
+	bp := dvec3.Sub(&p, &b)
+	d3, d4 := dvec3.Dot(&ab, &bp), dvec3.Dot(&ac, &bp)
+	if d3 >= 0 && d4 <= d3 {
+		return b
+	}
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		return dvec3.Interpolate(&a, &b, d1/(d1-d3))
+	}
+	cp := dvec3.Sub(&p, &c)
+	d5, d6 := dvec3.Dot(&ab, &cp), dvec3.Dot(&ac, &cp)
+	if d6 >= 0 && d5 <= d6 {
+		return c
+	}
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		return dvec3.Interpolate(&a, &c, d2/(d2-d6))
+	}
+	va := d3*d6 - d5*d4
+	if va <= 0 && d4-d3 >= 0 && d5-d6 >= 0 {
+		return dvec3.Interpolate(&b, &c, (d4-d3)/((d4-d3)+(d5-d6)))
+	}
+	denom := 1 / (va + vb + vc)
+	v, w := vb*denom, vc*denom
+	q := a
+	abv, acw := ab.Scaled(v), ac.Scaled(w)
+	q.Add(&abv)
+	q.Add(&acw)
+	return q
+}
@@ -0,0 +1,67 @@
+package obj
+
+import (
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance_SameSurface_IsZero(t *testing.T) {
+	// Arrange
+	a := createTerrain(10)
+	b := createTerrain(10)
+	b.Triangulate()
+
+	// Act
+	d, err := Distance(a, b, 1000)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, d.Max, 1e-5)
+	assert.InDelta(t, 0, d.Mean, 1e-5)
+}
+
+func TestDistance_RaisedPlane_MeasuresOffset(t *testing.T) {
+	// Arrange
+	a := createGrid(4, 4)
+	b := createGrid(4, 1)
+	b.Offset = dvec3.T{0, 0, 0.5}
+
+	// Act
+	d, err := Distance(a, b, 1000)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.5, d.Max, 1e-6)
+	assert.InDelta(t, 0.5, d.Mean, 1e-6)
+}
+
+func TestDistance_Spike_FindsMaximumAtVertex(t *testing.T) {
+	// Arrange
+	a := createGrid(4, 4)
+	a.V[12][2] = 1
+	b := createGrid(4, 1)
+
+	// Act
+	d, err := Distance(a, b, 2000)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, d.Max, 1e-6)
+	// The spike is a pyramid of height 1 over a quarter of the plane: the
+	// mean distance is about a third of its height over a quarter of the area.
+	assert.InDelta(t, 1.0/12, d.Mean, 0.02)
+}
+
+func TestDistance_NoFaces_Fails(t *testing.T) {
+	// Arrange
+	a := createGrid(4, 4)
+	b := &ObjBuffer{V: a.V}
+
+	// Act
+	_, err := Distance(a, b, 100)
+
+	// Assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,45 @@
+package obj
+
+import "strings"
+
+// DuplicateFace is a face removed by RemoveDuplicateFaces. Indices are
+// those before the removal.
+type DuplicateFace struct {
+	Face int
+	// DuplicateOf is the index of the first face at the same place, which
+	// was kept.
+	DuplicateOf int
+}
+
+// RemoveDuplicateFaces removes the faces whose corners are at the same
+// positions as those of an earlier face, whatever their vertex indices and
+// materials, and returns the faces removed. The corners must follow each
+// other in the same order, starting anywhere; with ignoreWinding, faces
+// with the reverse order are duplicates too.
+func (b *ObjBuffer) RemoveDuplicateFaces(ignoreWinding bool) []DuplicateFace {
+	first := map[string]int{}
+	var duplicates []DuplicateFace
+	remove := make([]bool, len(b.F))
+	for i, f := range b.F {
+		corners := b.cornerKeys(f.Corners, 0)
+		key := strings.Join(rotateToSmallest(corners), ",")
+		if ignoreWinding {
+			for j, k := 0, len(corners)-1; j < k; j, k = j+1, k-1 {
+				corners[j], corners[k] = corners[k], corners[j]
+			}
+			if reversed := strings.Join(rotateToSmallest(corners), ","); reversed < key {
+				key = reversed
+			}
+		}
+		if j, ok := first[key]; ok {
+			remove[i] = true
+			duplicates = append(duplicates, DuplicateFace{Face: i, DuplicateOf: j})
+			continue
+		}
+		first[key] = i
+	}
+	b.RemoveFaces(func(i int, f *Face) bool {
+		return remove[i]
+	})
+	return duplicates
+}
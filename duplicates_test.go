@@ -0,0 +1,41 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// duplicatesTestObj has two tiles sharing a border face, with vertices
+// duplicated along the border.
+const duplicatesTestObj = "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 1 0 0\nv 1 1 0\nv 0 0 0\n" +
+	"g tile1\nusemtl a\nf 1 2 3\ng tile2\nusemtl b\nf 5 6 4\nf 3 2 1\n"
+
+func TestObjBuffer_RemoveDuplicateFaces_SameWinding_RemovesCoincidentFace(t *testing.T) {
+	// Arrange
+	loader := &ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader(duplicatesTestObj)))
+
+	// Act
+	duplicates := loader.RemoveDuplicateFaces(false)
+
+	// Assert
+	assert.Equal(t, []DuplicateFace{{Face: 1, DuplicateOf: 0}}, duplicates)
+	assert.Equal(t, 2, len(loader.F))
+	assert.Equal(t, 1, loader.G[1].FaceCount)
+}
+
+func TestObjBuffer_RemoveDuplicateFaces_IgnoreWinding_RemovesReversedFace(t *testing.T) {
+	// Arrange
+	loader := &ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader(duplicatesTestObj)))
+
+	// Act
+	duplicates := loader.RemoveDuplicateFaces(true)
+
+	// Assert
+	assert.Equal(t, []DuplicateFace{{Face: 1, DuplicateOf: 0}, {Face: 2, DuplicateOf: 0}}, duplicates)
+	assert.Equal(t, 1, len(loader.F))
+	assert.Equal(t, 1, len(loader.G))
+}
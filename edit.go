@@ -0,0 +1,466 @@
+package obj
+
+import (
+	"sort"
+
+	"github.com/flywave/go3d/vec3"
+)
+
+// RemoveFaces removes the faces for which remove returns true and returns
+// the number of faces removed. Groups and face groups are shrunk
+// accordingly and dropped once empty. Vertices, normals and texture
+// coordinates are left untouched.
+func (b *ObjBuffer) RemoveFaces(remove func(i int, f *Face) bool) int {
+	newIndex := make([]int, len(b.F))
+	kept := 0
+	for i := range b.F {
+		if remove(i, &b.F[i]) {
+			newIndex[i] = -1
+			continue
+		}
+		newIndex[i] = kept
+		b.F[kept] = b.F[i]
+		kept++
+	}
+	removed := len(b.F) - kept
+	if removed == 0 {
+		return 0
+	}
+	for i := kept; i < len(b.F); i++ {
+		b.F[i] = Face{}
+	}
+	b.F = b.F[:kept]
+
+	groups := b.G[:0]
+	for _, g := range b.G {
+		first, count := remapFaceRange(newIndex, g.FirstFaceIndex, g.FaceCount)
+		if count > 0 {
+			g.FirstFaceIndex, g.FaceCount = first, count
+			groups = append(groups, g)
+		}
+	}
+	b.G = groups
+
+	faceGroups := b.FaceGroup[:0]
+	for _, fg := range b.FaceGroup {
+		first, count := remapFaceRange(newIndex, fg.Offset, fg.Size)
+		if count > 0 {
+			fg.Offset, fg.Size = first, count
+			faceGroups = append(faceGroups, fg)
+		}
+	}
+	b.FaceGroup = mergeFaceGroups(faceGroups)
+	return removed
+}
+
+// RemoveGroup removes the faces of every group named name, together with
+// the groups, and returns the number of faces removed.
+func (b *ObjBuffer) RemoveGroup(name string) int {
+	remove := make([]bool, len(b.F))
+	for i := range b.G {
+		g := &b.G[i]
+		if g.Name != name && !g.HasName(name) {
+			continue
+		}
+		for j := g.FirstFaceIndex; j < g.FirstFaceIndex+g.FaceCount; j++ {
+			remove[j] = true
+		}
+	}
+	return b.RemoveFaces(func(i int, f *Face) bool {
+		return remove[i]
+	})
+}
+
+// RenameMaterial renames the material oldName to newName on all faces and
+// face groups and returns the number of faces renamed. Face groups that end
+// up next to each other with the same material are merged.
+func (b *ObjBuffer) RenameMaterial(oldName, newName string) int {
+	renamed := 0
+	for i := range b.F {
+		if b.F[i].Material == oldName {
+			b.F[i].Material = newName
+			renamed++
+		}
+	}
+	for _, fg := range b.FaceGroup {
+		if fg.Material == oldName {
+			fg.Material = newName
+		}
+	}
+	b.FaceGroup = mergeFaceGroups(b.FaceGroup)
+	if b.activeMaterial == oldName {
+		b.activeMaterial = newName
+	}
+	return renamed
+}
+
+// SortFacesByMaterial reorders the faces of every group so that faces
+// sharing a material, and within a material a smoothing group, follow each
+// other, and the writer switches to each material once per group. With
+// stable, materials come in the order they first appear in the group and
+// faces keep their relative order; otherwise materials are sorted by name
+// and faces may move within their material. The face groups are rebuilt.
+func (b *ObjBuffer) SortFacesByMaterial(stable bool) {
+	for _, g := range b.G {
+		first, count := g.FirstFaceIndex, g.FaceCount
+		if first < 0 || count <= 0 || first+count > len(b.F) {
+			continue
+		}
+		faces := b.F[first : first+count]
+		if !stable {
+			sort.Slice(faces, func(i, j int) bool {
+				if faces[i].Material != faces[j].Material {
+					return faces[i].Material < faces[j].Material
+				}
+				return faces[i].SmoothingGroup < faces[j].SmoothingGroup
+			})
+			continue
+		}
+		materials := make(map[string]int)
+		smoothing := make(map[string]map[uint32]int)
+		for _, f := range faces {
+			if _, ok := materials[f.Material]; !ok {
+				materials[f.Material] = len(materials)
+				smoothing[f.Material] = make(map[uint32]int)
+			}
+			if _, ok := smoothing[f.Material][f.SmoothingGroup]; !ok {
+				smoothing[f.Material][f.SmoothingGroup] = len(smoothing[f.Material])
+			}
+		}
+		sort.SliceStable(faces, func(i, j int) bool {
+			mi, mj := materials[faces[i].Material], materials[faces[j].Material]
+			if mi != mj {
+				return mi < mj
+			}
+			return smoothing[faces[i].Material][faces[i].SmoothingGroup] < smoothing[faces[j].Material][faces[j].SmoothingGroup]
+		})
+	}
+	b.FaceGroup = faceGroupsOf(b.F)
+}
+
+// Selection selects faces of a buffer. A face is selected when it matches
+// every criterion set, so the zero value selects all faces.
+type Selection struct {
+	// Group selects the faces of the groups with this name, either as full
+	// name or as one of their names.
+	Group string
+	// Material selects the faces with this material.
+	Material string
+	// Faces selects the faces for which it returns true.
+	Faces func(i int, f *Face) bool
+}
+
+// selected returns whether each face of b is selected by s.
+func (b *ObjBuffer) selected(s Selection) []bool {
+	selected := make([]bool, len(b.F))
+	inGroup := selected
+	if s.Group != "" {
+		inGroup = make([]bool, len(b.F))
+		for _, i := range b.FacesInGroup(s.Group) {
+			inGroup[i] = true
+		}
+	}
+	for i := range b.F {
+		f := &b.F[i]
+		selected[i] = (s.Group == "" || inGroup[i]) &&
+			(s.Material == "" || f.Material == s.Material) &&
+			(s.Faces == nil || s.Faces(i, f))
+	}
+	return selected
+}
+
+// FlipNormals reverses the winding of the selected faces and negates their
+// normals, and returns the number of faces flipped. Normals shared with
+// faces that are not selected are duplicated before being negated.
+func (b *ObjBuffer) FlipNormals(selection Selection) int {
+	selected := b.selected(selection)
+
+	// Normals referenced by selected faces only are negated in place, the
+	// others get a negated copy.
+	const (
+		byUnselected = 1 << iota
+		bySelected
+	)
+	uses := make([]int, len(b.VN))
+	for i := range b.F {
+		use := byUnselected
+		if selected[i] {
+			use = bySelected
+		}
+		for _, loop := range b.F[i].loops() {
+			for _, c := range loop {
+				if c.NormalIndex >= 0 && c.NormalIndex < len(b.VN) {
+					uses[c.NormalIndex] |= use
+				}
+			}
+		}
+	}
+	flipped := make([]int, len(b.VN))
+	for i, use := range uses {
+		flipped[i] = i
+		if use&bySelected == 0 {
+			continue
+		}
+		n := b.VN[i]
+		n.Scale(-1)
+		if use&byUnselected == 0 {
+			b.VN[i] = n
+			continue
+		}
+		flipped[i] = len(b.VN)
+		b.VN = append(b.VN, n)
+	}
+
+	count := 0
+	for i := range b.F {
+		if !selected[i] {
+			continue
+		}
+		b.F[i].reverse()
+		for _, corners := range b.F[i].loops() {
+			for j := range corners {
+				if n := corners[j].NormalIndex; n >= 0 && n < len(flipped) {
+					corners[j].NormalIndex = flipped[n]
+				}
+			}
+		}
+		count++
+	}
+	return count
+}
+
+// Axis is a coordinate axis.
+type Axis int
+
+const (
+	AxisX Axis = iota
+	AxisY
+	AxisZ
+)
+
+// Mirror mirrors the buffer across the plane through the origin
+// perpendicular to axis, offset included. Face windings are reversed and
+// normals mirrored too, so that faces keep facing outwards.
+func (b *ObjBuffer) Mirror(axis Axis) {
+	for i := range b.V {
+		b.V[i][axis] = -b.V[i][axis]
+	}
+	for i := range b.VD {
+		b.VD[i][axis] = -b.VD[i][axis]
+	}
+	b.Offset[axis] = -b.Offset[axis]
+	for i := range b.VN {
+		b.VN[i][axis] = -b.VN[i][axis]
+	}
+	for i := range b.F {
+		b.F[i].reverse()
+	}
+}
+
+// loops returns the outer loop of f followed by its holes.
+func (f *Face) loops() [][]FaceCorner {
+	if len(f.Holes) == 0 {
+		return [][]FaceCorner{f.Corners}
+	}
+	return append([][]FaceCorner{f.Corners}, f.Holes...)
+}
+
+// reverse reverses the winding of the face and of its holes.
+func (f *Face) reverse() {
+	for _, loop := range f.loops() {
+		reverseCorners(loop)
+	}
+}
+
+// reverseCorners reverses the winding of a loop.
+func reverseCorners(corners []FaceCorner) {
+	for i, j := 0, len(corners)-1; i < j; i, j = i+1, j-1 {
+		corners[i], corners[j] = corners[j], corners[i]
+	}
+}
+
+// remapFaceRange returns the range of new face indices covering the faces
+// of the old range that were kept.
+func remapFaceRange(newIndex []int, first, count int) (int, int) {
+	newFirst, newCount := -1, 0
+	for i := first; i < first+count && i < len(newIndex); i++ {
+		if newIndex[i] == -1 {
+			continue
+		}
+		if newFirst == -1 {
+			newFirst = newIndex[i]
+		}
+		newCount++
+	}
+	return newFirst, newCount
+}
+
+// mergeFaceGroups merges adjacent face groups sharing a material.
+func mergeFaceGroups(groups []*FaceGroup) []*FaceGroup {
+	if len(groups) == 0 {
+		return groups
+	}
+	merged := groups[:1]
+	for _, fg := range groups[1:] {
+		last := merged[len(merged)-1]
+		if last.Material == fg.Material && last.Offset+last.Size == fg.Offset {
+			last.Size += fg.Size
+			continue
+		}
+		merged = append(merged, fg)
+	}
+	return merged
+}
+
+// Triangulate replaces every face with more than three corners by its
+// triangles, which keep the material of the face. Groups and face groups
+// are adjusted to cover the triangles of their faces.
+func (b *ObjBuffer) Triangulate() {
+	b.TriangulateWith(TriangulateOptions{})
+}
+
+// TriangulateWith triangulates the faces like Triangulate, using the given
+// options.
+func (b *ObjBuffer) TriangulateWith(options TriangulateOptions) {
+	newFirst := make([]int, len(b.F)+1)
+	faces := make([]Face, 0, len(b.F))
+	for i := range b.F {
+		newFirst[i] = len(faces)
+		f := b.F[i]
+		if len(f.Corners) <= 3 && len(f.Holes) == 0 {
+			faces = append(faces, f)
+			continue
+		}
+		for _, t := range f.TriangulateWith(b.V, options) {
+			faces = append(faces, Face{Corners: t, Material: f.Material, SmoothingGroup: f.SmoothingGroup, Metadata: f.Metadata})
+		}
+	}
+	newFirst[len(b.F)] = len(faces)
+	if len(faces) == len(b.F) {
+		return
+	}
+
+	remap := func(first, count int) (int, int) {
+		end := first + count
+		if first < 0 || count < 0 || end > len(b.F) {
+			return first, count
+		}
+		return newFirst[first], newFirst[end] - newFirst[first]
+	}
+	for i := range b.G {
+		b.G[i].FirstFaceIndex, b.G[i].FaceCount = remap(b.G[i].FirstFaceIndex, b.G[i].FaceCount)
+	}
+	for _, fg := range b.FaceGroup {
+		fg.Offset, fg.Size = remap(fg.Offset, fg.Size)
+	}
+	b.F = faces
+}
+
+// Merge returns a new buffer holding the elements of all buffers, in order.
+// Positions are moved into the offset of the first buffer, the material
+// library is the first one set, and buffers without groups get a group of
+// their own. Attributes missing from a buffer, or declared there with
+// another type or size, are zero for its vertices.
+func Merge(buffers ...*ObjBuffer) *ObjBuffer {
+	merged := new(ObjBuffer)
+	if len(buffers) == 0 {
+		return merged
+	}
+	merged.Offset = buffers[0].Offset
+	double, colors := false, false
+	for _, b := range buffers {
+		double = double || b.hasDoublePrecision()
+		colors = colors || b.hasVertexColors()
+		if merged.MTL == "" {
+			merged.MTL = b.MTL
+		}
+		for name, a := range b.Attributes {
+			if merged.Attributes == nil {
+				merged.Attributes = map[string]AttributeBuffer{}
+			}
+			if _, ok := merged.Attributes[name]; !ok {
+				merged.Attributes[name] = a.emptyCopy()
+			}
+		}
+	}
+
+	shift := func(idx, base int) int {
+		if idx < 0 {
+			return idx
+		}
+		return idx + base
+	}
+	for _, b := range buffers {
+		base := merged.counts()
+		firstFace := len(merged.F)
+
+		delta := b.Offset
+		delta.Sub(&merged.Offset)
+		for i := range b.V {
+			p := b.positionD(i)
+			p.Add(&delta)
+			merged.V = append(merged.V, vec3.T{float32(p[0]), float32(p[1]), float32(p[2])})
+			if double {
+				merged.VD = append(merged.VD, p)
+			}
+			if colors {
+				c := vec3.T{1, 1, 1}
+				if b.hasVertexColors() {
+					c = b.VC[i]
+				}
+				merged.VC = append(merged.VC, c)
+			}
+			for name, a := range merged.Attributes {
+				merged.Attributes[name] = a.appendVertex(b.Attributes[name], i)
+			}
+		}
+		merged.VN = append(merged.VN, b.VN...)
+		merged.VT = append(merged.VT, b.VT...)
+
+		for _, f := range b.F {
+			corners := make([]FaceCorner, len(f.Corners))
+			for j, c := range f.Corners {
+				corners[j] = FaceCorner{
+					VertexIndex:   shift(c.VertexIndex, base.v),
+					NormalIndex:   shift(c.NormalIndex, base.vn),
+					TexcoordIndex: shift(c.TexcoordIndex, base.vt),
+				}
+			}
+			var holes [][]FaceCorner
+			for _, hole := range f.Holes {
+				shifted := make([]FaceCorner, len(hole))
+				for j, c := range hole {
+					shifted[j] = FaceCorner{
+						VertexIndex:   shift(c.VertexIndex, base.v),
+						NormalIndex:   shift(c.NormalIndex, base.vn),
+						TexcoordIndex: shift(c.TexcoordIndex, base.vt),
+					}
+				}
+				holes = append(holes, shifted)
+			}
+			merged.F = append(merged.F, Face{
+				Corners:        corners,
+				Holes:          holes,
+				Material:       f.Material,
+				SmoothingGroup: f.SmoothingGroup,
+				Metadata:       f.Metadata,
+			})
+		}
+		for _, l := range b.L {
+			corners := make([]int, len(l.Corners))
+			for j, c := range l.Corners {
+				corners[j] = shift(c, base.v)
+			}
+			merged.L = append(merged.L, line{Corners: corners, Material: l.Material})
+		}
+		for _, g := range b.G {
+			g.FirstFaceIndex += firstFace
+			merged.G = append(merged.G, g)
+		}
+		if len(b.G) == 0 && len(b.F) > 0 {
+			merged.G = append(merged.G, Group{Name: defaultGroupName, FirstFaceIndex: firstFace, FaceCount: len(b.F)})
+		}
+	}
+	merged.FaceGroup = faceGroupsOf(merged.F)
+	return merged
+}
@@ -0,0 +1,242 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+const editTestObj = "v 0 0 0\nv 1 0 0\nv 0 1 0\n" +
+	"g roof\nusemtl tiles\nf 1 2 3\nf 1 2 3\n" +
+	"g walls\nusemtl brick\nf 1 2 3\nusemtl tiles\nf 1 2 3\n" +
+	"g body wheel\nusemtl rubber\nf 1 2 3\n"
+
+func faceGroupValues(groups []*FaceGroup) []FaceGroup {
+	values := make([]FaceGroup, len(groups))
+	for i, fg := range groups {
+		values[i] = *fg
+	}
+	return values
+}
+
+func TestObjBuffer_RemoveFaces_UpdatesGroupsAndFaceGroups(t *testing.T) {
+	// Arrange
+	loader := readTestObj(t, editTestObj, ReadOptions{})
+
+	// Act
+	removed := loader.RemoveFaces(func(i int, f *Face) bool {
+		return i == 1 || f.Material == "brick"
+	})
+
+	// Assert
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, 3, len(loader.F))
+	assert.Equal(t, []Group{
+		{Name: "roof", FirstFaceIndex: 0, FaceCount: 1},
+		{Name: "walls", FirstFaceIndex: 1, FaceCount: 1},
+		{Name: "body wheel", FirstFaceIndex: 2, FaceCount: 1},
+	}, loader.G)
+	assert.Equal(t, []FaceGroup{
+		{Offset: 0, Size: 2, Material: "tiles"},
+		{Offset: 2, Size: 1, Material: "rubber"},
+	}, faceGroupValues(loader.FaceGroup))
+}
+
+func TestObjBuffer_RemoveGroup_RemovesFacesAndGroup(t *testing.T) {
+	// Arrange
+	loader := readTestObj(t, editTestObj, ReadOptions{})
+
+	// Act
+	removed := loader.RemoveGroup("walls")
+
+	// Assert
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, []Group{
+		{Name: "roof", FirstFaceIndex: 0, FaceCount: 2},
+		{Name: "body wheel", FirstFaceIndex: 2, FaceCount: 1},
+	}, loader.G)
+	assert.Equal(t, []FaceGroup{
+		{Offset: 0, Size: 2, Material: "tiles"},
+		{Offset: 2, Size: 1, Material: "rubber"},
+	}, faceGroupValues(loader.FaceGroup))
+}
+
+func TestObjBuffer_RemoveGroup_OneOfSeveralNames_RemovesFaces(t *testing.T) {
+	loader := readTestObj(t, editTestObj, ReadOptions{})
+
+	removed := loader.RemoveGroup("wheel")
+
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, 2, len(loader.G))
+	assert.Equal(t, 4, len(loader.F))
+}
+
+func TestObjBuffer_RenameMaterial_RenamesFacesAndMergesFaceGroups(t *testing.T) {
+	// Arrange
+	loader := readTestObj(t, editTestObj, ReadOptions{})
+
+	// Act
+	renamed := loader.RenameMaterial("brick", "tiles")
+
+	// Assert
+	assert.Equal(t, 1, renamed)
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, "tiles", loader.F[i].Material)
+	}
+	assert.Equal(t, []FaceGroup{
+		{Offset: 0, Size: 4, Material: "tiles"},
+		{Offset: 4, Size: 1, Material: "rubber"},
+	}, faceGroupValues(loader.FaceGroup))
+}
+
+func TestObjBuffer_SortFacesByMaterial_Stable_WritesEachMaterialOnce(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\ng walls\n" +
+		"usemtl brick\nf 1 2 3\nusemtl glass\nf 2 3 1\nusemtl brick\nf 3 1 2\nusemtl glass\nf 1 3 2\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+
+	// Act
+	loader.SortFacesByMaterial(true)
+
+	// Assert
+	assert.Equal(t, []FaceGroup{
+		{Offset: 0, Size: 2, Material: "brick"},
+		{Offset: 2, Size: 2, Material: "glass"},
+	}, faceGroupValues(loader.FaceGroup))
+	assert.Equal(t, []int{0, 2, 1, 0}, []int{
+		loader.F[0].Corners[0].VertexIndex, loader.F[1].Corners[0].VertexIndex,
+		loader.F[2].Corners[0].VertexIndex, loader.F[3].Corners[0].VertexIndex})
+	var out strings.Builder
+	assert.NoError(t, loader.WriteWith(&out, WriteOptions{OmitBanner: true}))
+	assert.Equal(t, 2, strings.Count(out.String(), "usemtl"))
+}
+
+func TestObjBuffer_SortFacesByMaterial_Unstable_SortsByNameWithinGroups(t *testing.T) {
+	// Arrange
+	loader := readTestObj(t, editTestObj, ReadOptions{})
+
+	// Act
+	loader.SortFacesByMaterial(false)
+
+	// Assert
+	assert.Equal(t, []FaceGroup{
+		{Offset: 0, Size: 2, Material: "tiles"},
+		{Offset: 2, Size: 1, Material: "brick"},
+		{Offset: 3, Size: 1, Material: "tiles"},
+		{Offset: 4, Size: 1, Material: "rubber"},
+	}, faceGroupValues(loader.FaceGroup))
+	assert.Equal(t, 2, loader.G[1].FaceCount)
+}
+
+func TestObjBuffer_Triangulate_Quads_RemapsGroups(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nv 2 0 0\n" +
+		"g a\nusemtl red\nf 1 2 3 4\nf 2 5 3\ng b\nusemtl blue\nf 1 2 3 4\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+
+	// Act
+	loader.Triangulate()
+
+	// Assert
+	assert.Equal(t, 5, len(loader.F))
+	for _, f := range loader.F {
+		assert.Equal(t, 3, len(f.Corners))
+	}
+	assert.Equal(t, []Group{{Name: "a", FirstFaceIndex: 0, FaceCount: 3}, {Name: "b", FirstFaceIndex: 3, FaceCount: 2}}, loader.G)
+	assert.Equal(t, []*FaceGroup{{Offset: 0, Size: 3, Material: "red"}, {Offset: 3, Size: 2, Material: "blue"}}, loader.FaceGroup)
+	assert.Equal(t, "blue", loader.F[4].Material)
+}
+
+func TestMerge_DifferentOffsets_ShiftsIndicesAndPositions(t *testing.T) {
+	// Arrange
+	a := &ObjBuffer{MTL: "a.mtl", V: []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}, VN: []vec3.T{{0, 0, 1}}}
+	a.F = []Face{{Corners: []FaceCorner{{0, 0, -1}, {1, 0, -1}, {2, 0, -1}}, Material: "red"}}
+	b := &ObjBuffer{V: []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}, VC: []vec3.T{{1, 0, 0}, {1, 0, 0}, {1, 0, 0}}}
+	b.Offset = dvec3.T{10, 0, 0}
+	b.F = []Face{{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {2, -1, -1}}, Material: "blue"}}
+	b.L = []line{{Corners: []int{0, 2}}}
+	b.G = []Group{{Name: "b", FirstFaceIndex: 0, FaceCount: 1}}
+
+	// Act
+	merged := Merge(a, b)
+
+	// Assert
+	assert.Equal(t, "a.mtl", merged.MTL)
+	assert.Equal(t, vec3.T{11, 0, 0}, merged.V[4])
+	assert.Equal(t, vec3.T{1, 1, 1}, merged.VC[0])
+	assert.Equal(t, vec3.T{1, 0, 0}, merged.VC[3])
+	assert.Equal(t, []FaceCorner{{3, -1, -1}, {4, -1, -1}, {5, -1, -1}}, merged.F[1].Corners)
+	assert.Equal(t, []int{3, 5}, merged.L[0].Corners)
+	assert.Equal(t, []Group{{Name: "default group", FirstFaceIndex: 0, FaceCount: 1}, {Name: "b", FirstFaceIndex: 1, FaceCount: 1}}, merged.G)
+	assert.Equal(t, 2, len(merged.FaceGroup))
+}
+
+func TestObjBuffer_FlipNormals_Group_DuplicatesSharedNormals(t *testing.T) {
+	// Arrange
+	loader := &ObjReader{}
+	err := loader.Read(strings.NewReader("v 0 0 0\nv 1 0 0\nv 0 1 0\nvn 0 0 1\nvn 1 0 0\n" +
+		"g roof\nf 1//1 2//1 3//1\nf 1//2 2//2 3//2\ng walls\nf 1//1 2//1 3//1\n"))
+	assert.NoError(t, err)
+
+	// Act
+	flipped := loader.FlipNormals(Selection{Group: "roof"})
+
+	// Assert
+	assert.Equal(t, 2, flipped)
+	assert.Equal(t, []vec3.T{{0, 0, 1}, {-1, 0, 0}, {0, 0, -1}}, loader.VN)
+	assert.Equal(t, []FaceCorner{{2, 2, -1}, {1, 2, -1}, {0, 2, -1}}, loader.F[0].Corners)
+	assert.Equal(t, 1, loader.F[1].Corners[0].NormalIndex)
+	assert.Equal(t, []FaceCorner{{0, 0, -1}, {1, 0, -1}, {2, 0, -1}}, loader.F[2].Corners)
+}
+
+func TestObjBuffer_FlipNormals_MaterialAndPredicate_FlipsMatchingFaces(t *testing.T) {
+	// Arrange
+	loader := readTestObj(t, editTestObj, ReadOptions{})
+
+	// Act
+	flipped := loader.FlipNormals(Selection{Material: "tiles", Faces: func(i int, f *Face) bool {
+		return i > 0
+	}})
+
+	// Assert
+	assert.Equal(t, 2, flipped)
+	assert.Equal(t, 0, loader.F[0].Corners[0].VertexIndex)
+	assert.Equal(t, 2, loader.F[1].Corners[0].VertexIndex)
+	assert.Equal(t, 0, loader.F[2].Corners[0].VertexIndex)
+	assert.Equal(t, 2, loader.F[3].Corners[0].VertexIndex)
+}
+
+func TestObjBuffer_FlipNormals_ZeroSelection_FlipsAll(t *testing.T) {
+	// Arrange
+	loader := readTestObj(t, editTestObj, ReadOptions{})
+
+	// Act
+	flipped := loader.FlipNormals(Selection{})
+
+	// Assert
+	assert.Equal(t, len(loader.F), flipped)
+}
+
+func TestObjBuffer_Mirror_X_NegatesPositionsAndReversesFaces(t *testing.T) {
+	// Arrange
+	loader := &ObjReader{}
+	loader.SetOptions(ReadOptions{DoublePrecision: true})
+	err := loader.Read(strings.NewReader("v 1 2 3\nv 2 0 0\nv 0 1 0\nvn 0.6 0 0.8\nf 1//1 2//1 3//1\n"))
+	assert.NoError(t, err)
+	loader.Offset = dvec3.T{10, 20, 30}
+
+	// Act
+	loader.Mirror(AxisX)
+
+	// Assert
+	assert.Equal(t, vec3.T{-1, 2, 3}, loader.V[0])
+	assert.Equal(t, dvec3.T{-1, 2, 3}, loader.VD[0])
+	assert.Equal(t, dvec3.T{-10, 20, 30}, loader.Offset)
+	assert.Equal(t, vec3.T{-0.6, 0, 0.8}, loader.VN[0])
+	assert.Equal(t, []FaceCorner{{2, 0, -1}, {1, 0, -1}, {0, 0, -1}}, loader.F[0].Corners)
+}
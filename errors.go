@@ -0,0 +1,182 @@
+package obj
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors matched by the errors returned when parsing fails, classifying the
+// statement at fault. Use errors.Is to test for them and errors.As with a
+// *LineError for the position.
+var (
+	// ErrBadVertex is matched by malformed v, vn and vt statements.
+	ErrBadVertex = errors.New("bad vertex statement")
+	// ErrBadFace is matched by malformed f statements.
+	ErrBadFace = errors.New("bad face statement")
+	// ErrBadPolyline is matched by malformed l statements.
+	ErrBadPolyline = errors.New("bad line statement")
+	// ErrBadIndex is matched by element references that are not numbers or
+	// do not reference an element.
+	ErrBadIndex = errors.New("bad index")
+	// ErrBadStatement is matched by malformed g, s, mtllib and usemtl
+	// statements.
+	ErrBadStatement = errors.New("bad statement")
+	// ErrBadMaterialStatement is matched by malformed statements of a
+	// material library.
+	ErrBadMaterialStatement = errors.New("bad material statement")
+)
+
+// LineError is the error returned when a line of a file cannot be parsed.
+// It wraps the error describing the problem.
+type LineError struct {
+	// File names the file, if known.
+	File string
+	// Line is the 1-based number of the line.
+	Line int
+	// Column is the 1-based byte offset of the field at fault in the line,
+	// or 0 if the line as a whole is at fault.
+	Column int
+	// Text is the content of the line, without comment.
+	Text string
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	var b strings.Builder
+	if e.File != "" {
+		b.WriteString(e.File + ": ")
+	}
+	fmt.Fprintf(&b, "Line #%d", e.Line)
+	if e.Column > 0 {
+		fmt.Fprintf(&b, ", column %d", e.Column)
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+	if e.Text != "" {
+		fmt.Fprintf(&b, " ('%s')", e.Text)
+	}
+	return b.String()
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// newLineError wraps err, returned for the statement text of line raw, into
+// a LineError locating the field at fault.
+func newLineError(lineNumber int, raw, text string, err error) *LineError {
+	e := &LineError{Line: lineNumber, Text: text, Err: err}
+	var se *statementError
+	if errors.As(err, &se) && se.field >= 0 {
+		// Arguments follow the keyword.
+		e.Column = fieldColumn(raw, se.field+1)
+	}
+	return e
+}
+
+// fieldColumn returns the 1-based byte offset of field n of line, or 0 if
+// the line has fewer fields.
+func fieldColumn(line string, n int) int {
+	inField := false
+	for i, r := range line {
+		space := r == ' ' || r == '\t' || r == '\v' || r == '\f' || r == '\r'
+		if !space && !inField {
+			if n == 0 {
+				return i + 1
+			}
+			n--
+		}
+		inField = !space
+	}
+	return 0
+}
+
+// statementError describes what is wrong with a statement. field is the
+// index of the argument at fault, or -1.
+type statementError struct {
+	kind  error
+	field int
+	msg   string
+}
+
+func (e *statementError) Error() string {
+	return e.msg
+}
+
+func (e *statementError) Unwrap() error {
+	return e.kind
+}
+
+// badStatement returns a statementError of the given kind that is not tied
+// to an argument.
+func badStatement(kind error, format string, args ...interface{}) error {
+	return &statementError{kind: kind, field: -1, msg: fmt.Sprintf(format, args...)}
+}
+
+// atField ties err to argument field if it is a statementError without one.
+func atField(err error, field int) error {
+	if se, ok := err.(*statementError); ok && se.field < 0 {
+		se.field = field
+	}
+	return err
+}
+
+// ErrLimitExceeded is matched by the errors returned when the input exceeds
+// one of the configured Limits. Use errors.As with a *LimitError for the
+// details.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// LimitError reports that the input exceeds one of the configured Limits.
+type LimitError struct {
+	// Limit is the name of the exceeded field of Limits.
+	Limit string
+	// Max is the configured value of the limit.
+	Max int
+	// What describes what was counted, such as "vertices".
+	What string
+	// Line is the number of the offending line, or 0 if the error is
+	// reported for the input as a whole.
+	Line int
+}
+
+func (e *LimitError) Error() string {
+	msg := fmt.Sprintf("More than %d %s (%s)", e.Max, e.What, e.Limit)
+	if e.Line > 0 {
+		return fmt.Sprintf("Line #%d: %s", e.Line, msg)
+	}
+	return msg
+}
+
+// Is reports whether target is ErrLimitExceeded.
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// Warning reports a statement that was read but ignored, so its data is
+// not part of the result.
+type Warning struct {
+	// File names the file, if known.
+	File string
+	// Line is the 1-based number of the line.
+	Line int
+	// Keyword is the keyword of the ignored statement.
+	Keyword string
+	// Text is the statement, without comment.
+	Text string
+}
+
+func (w Warning) String() string {
+	if w.File != "" {
+		return fmt.Sprintf("%s: Line #%d: ignored '%s' statement ('%s')", w.File, w.Line, w.Keyword, w.Text)
+	}
+	return fmt.Sprintf("Line #%d: ignored '%s' statement ('%s')", w.Line, w.Keyword, w.Text)
+}
+
+// checkLimit returns a LimitError if count elements already reached max.
+// A max of zero is unlimited.
+func checkLimit(count, max int, limit, what string) error {
+	if max > 0 && count >= max {
+		return &LimitError{Limit: limit, Max: max, What: what}
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package obj
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjReader_Read_MaxVertices_ReturnsLimitError(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{Limits: Limits{MaxVertices: 2}})
+
+	// Act
+	err := loader.Read(strings.NewReader("v 0 0 0\nv 1 0 0\nv 0 1 0\n"))
+
+	// Assert
+	var limitErr *LimitError
+	assert.True(t, errors.Is(err, ErrLimitExceeded))
+	if assert.True(t, errors.As(err, &limitErr)) {
+		assert.Equal(t, LimitError{Limit: "MaxVertices", Max: 2, What: "vertices"}, *limitErr)
+	}
+	assert.Contains(t, err.Error(), "Line #3")
+}
+
+func TestObjReader_Read_MaxMaterials_CountsDistinctMaterials(t *testing.T) {
+	// Arrange
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\n" +
+		"usemtl a\nf 1 2 3\nusemtl b\nf 1 2 3\nusemtl a\nf 1 2 3\n"
+
+	// Act
+	withinLimit := ObjReader{}
+	withinLimit.SetOptions(ReadOptions{Limits: Limits{MaxMaterials: 2}})
+	errWithin := withinLimit.Read(strings.NewReader(input))
+	overLimit := ObjReader{}
+	overLimit.SetOptions(ReadOptions{Limits: Limits{MaxMaterials: 1}})
+	errOver := overLimit.Read(strings.NewReader(input))
+
+	// Assert
+	assert.NoError(t, errWithin)
+	assert.True(t, errors.Is(errOver, ErrLimitExceeded))
+}
+
+func TestReadMaterialsWithLimits_TooManyMaterials_ReturnsLimitError(t *testing.T) {
+	input := "newmtl a\nKd 1 0 0\nnewmtl b\nKd 0 1 0\nnewmtl c\n"
+
+	_, err := ReadMaterialsWithLimits(strings.NewReader(input), "scene.mtl", Limits{MaxMaterials: 2})
+
+	assert.EqualError(t, err, "Line #5: More than 2 materials (MaxMaterials)")
+	assert.True(t, errors.Is(err, ErrLimitExceeded))
+}
+
+func TestReadMaterialsWithLimits_TooManyTextures_ReturnsLimitError(t *testing.T) {
+	// Arrange
+	input := "newmtl a\nmap_Kd a.png\nbump a.png\nnewmtl b\nmap_Kd -clamp on b.png\nmap_Ks c.png\n"
+
+	// Act
+	_, errWithin := ReadMaterialsWithLimits(strings.NewReader(input), "scene.mtl", Limits{MaxTextures: 3})
+	_, errOver := ReadMaterialsWithLimits(strings.NewReader(input), "scene.mtl", Limits{MaxTextures: 2})
+
+	// Assert
+	assert.NoError(t, errWithin)
+	var limitErr *LimitError
+	if assert.True(t, errors.As(errOver, &limitErr)) {
+		assert.Equal(t, "MaxTextures", limitErr.Limit)
+		assert.Equal(t, 6, limitErr.Line)
+	}
+}
+
+func TestObjReader_Read_MalformedStatements_ClassifiesErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		kind  error
+	}{
+		{"v 1 2\n", ErrBadVertex},
+		{"vn 0 x 1\n", ErrBadVertex},
+		{"vt nan 0\n", ErrBadVertex},
+		{"f 1 2\n", ErrBadFace},
+		{"f 1 2 3/\n", ErrBadFace},
+		{"f 1 0 2\n", ErrBadIndex},
+		{"f 1 2/99999999999999999999 3\n", ErrBadIndex},
+		{"l 1\n", ErrBadPolyline},
+		{"l 1 x\n", ErrBadIndex},
+		{"mtllib a.mtl\nmtllib b.mtl\n", ErrBadStatement},
+	}
+	for _, test := range tests {
+		loader := ObjReader{}
+
+		err := loader.Read(strings.NewReader(test.input))
+
+		var lineErr *LineError
+		assert.True(t, errors.Is(err, test.kind), "%q: %v", test.input, err)
+		assert.True(t, errors.As(err, &lineErr), test.input)
+	}
+}
+
+func TestObjReader_Read_BadField_ReportsColumn(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\n  f 1  2/x 3 # comment\n"
+
+	// Act
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	var lineErr *LineError
+	if assert.True(t, errors.As(err, &lineErr)) {
+		assert.Equal(t, 2, lineErr.Line)
+		assert.Equal(t, 8, lineErr.Column)
+		assert.Equal(t, "f 1  2/x 3", lineErr.Text)
+	}
+	assert.EqualError(t, err, "Line #2, column 8: Face field '2/x' is not on a supported format ('f 1  2/x 3')")
+}
+
+func TestObjReader_Read_WrongFieldCount_ReportsNoColumn(t *testing.T) {
+	loader := ObjReader{}
+
+	err := loader.Read(strings.NewReader("vn 0 0\n"))
+
+	assert.EqualError(t, err, "Line #1: Expected 3 fields, but got 2 ('vn 0 0')")
+}
+
+func TestReadMaterialsFrom_BadStatement_ReturnsLineError(t *testing.T) {
+	// Arrange
+	input := "newmtl a\nKd 1 0\n"
+
+	// Act
+	_, err := ReadMaterialsFrom(strings.NewReader(input), "scene.mtl")
+
+	// Assert
+	var lineErr *LineError
+	assert.True(t, errors.Is(err, ErrBadMaterialStatement))
+	if assert.True(t, errors.As(err, &lineErr)) {
+		assert.Equal(t, "scene.mtl", lineErr.File)
+		assert.Equal(t, 2, lineErr.Line)
+	}
+	assert.EqualError(t, err, "scene.mtl: Line #2: unsupported diffuse color line ('Kd 1 0')")
+}
+
+func TestFieldColumn_ReturnsOffsetOfField(t *testing.T) {
+	assert.Equal(t, 1, fieldColumn("f 1 2", 0))
+	assert.Equal(t, 5, fieldColumn("f 1\t2", 2))
+	assert.Equal(t, 0, fieldColumn("f 1 2", 3))
+}
+
+func TestObjReader_Read_IgnoredStatements_RecordsWarnings(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "o cube\nv 0 0 0\nmg 1\nvp 0.5\ncstype bezier # curve\n"
+
+	// Act
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []Warning{
+		{Line: 3, Keyword: "mg", Text: "mg 1"},
+		{Line: 4, Keyword: "vp", Text: "vp 0.5"},
+		{Line: 5, Keyword: "cstype", Text: "cstype bezier"},
+	}, loader.Warnings)
+	assert.Equal(t, "Line #3: ignored 'mg' statement ('mg 1')", loader.Warnings[0].String())
+}
+
+func TestObjReader_Read_OnWarning_CallsCallback(t *testing.T) {
+	// Arrange
+	var warnings []Warning
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{OnWarning: func(w Warning) { warnings = append(warnings, w) }})
+
+	// Act
+	err := loader.Read(strings.NewReader("v 0 0 0\nvp 0.5\n"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []Warning{{Line: 2, Keyword: "vp", Text: "vp 0.5"}}, warnings)
+	assert.Empty(t, loader.Warnings)
+}
+
+func TestReadMaterialsWith_IgnoredStatements_CallsOnWarning(t *testing.T) {
+	// Arrange
+	var warnings []Warning
+	input := "newmtl a\nKd 1 0 0\nsharpness 60\nmap_Ns shiny.png\nmap_refl sky.png\n"
+
+	// Act
+	materials, err := ReadMaterialsWith(strings.NewReader(input), "scene.mtl",
+		MaterialReadOptions{OnWarning: func(w Warning) { warnings = append(warnings, w) }})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(materials))
+	assert.Equal(t, []Warning{
+		{File: "scene.mtl", Line: 3, Keyword: "sharpness", Text: "sharpness 60"},
+		{File: "scene.mtl", Line: 4, Keyword: "map_Ns", Text: "map_Ns shiny.png"},
+		{File: "scene.mtl", Line: 5, Keyword: "map_refl", Text: "map_refl sky.png"},
+	}, warnings)
+	assert.Equal(t, "scene.mtl: Line #3: ignored 'sharpness' statement ('sharpness 60')", warnings[0].String())
+}
@@ -0,0 +1,101 @@
+package obj
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/flywave/go3d/vec3"
+)
+
+// FlatPositions returns the vertex positions as a flat array of x, y, z
+// coordinates. The array shares the memory of V rather than copying it, so
+// it can be processed with vectorized code or handed to cgo and GPU APIs
+// as is: writing to it moves the vertices, and it stays valid until V is
+// reallocated, for example by appending vertices.
+func (b *ObjBuffer) FlatPositions() []float32 {
+	return vec3sToFloats(b.V)
+}
+
+// FlatNormals returns the normals as a flat array like FlatPositions,
+// sharing the memory of VN.
+func (b *ObjBuffer) FlatNormals() []float32 {
+	return vec3sToFloats(b.VN)
+}
+
+// FlatTexCoords returns the texture coordinates as a flat array of u, v
+// coordinates, sharing the memory of VT.
+func (b *ObjBuffer) FlatTexCoords() []float32 {
+	if len(b.VT) == 0 {
+		return nil
+	}
+	return floatsAt(unsafe.Pointer(&b.VT[0]), 2*len(b.VT), 2*cap(b.VT))
+}
+
+// SetFlatPositions sets V to the positions of a flat array of x, y, z
+// coordinates, such as one filled by cgo, without copying it: V shares the
+// memory of positions. VD, which would take precedence over the new
+// positions, is cleared.
+func (b *ObjBuffer) SetFlatPositions(positions []float32) error {
+	v, err := floatsToVec3s(positions)
+	if err != nil {
+		return err
+	}
+	b.V, b.VD = v, nil
+	return nil
+}
+
+// SetFlatNormals sets VN to the normals of a flat array like
+// SetFlatPositions.
+func (b *ObjBuffer) SetFlatNormals(normals []float32) error {
+	vn, err := floatsToVec3s(normals)
+	if err != nil {
+		return err
+	}
+	b.VN = vn
+	return nil
+}
+
+// SetFlatTexCoords sets VT to the texture coordinates of a flat array of u,
+// v coordinates, without copying it.
+func (b *ObjBuffer) SetFlatTexCoords(texcoords []float32) error {
+	if len(texcoords)%2 != 0 {
+		return fmt.Errorf("%d floats is not a multiple of 2", len(texcoords))
+	}
+	b.VT = nil
+	if len(texcoords) > 0 {
+		h := (*reflect.SliceHeader)(unsafe.Pointer(&b.VT))
+		h.Data, h.Len, h.Cap = uintptr(unsafe.Pointer(&texcoords[0])), len(texcoords)/2, cap(texcoords)/2
+	}
+	return nil
+}
+
+// vec3sToFloats returns the flat array sharing the memory of v.
+func vec3sToFloats(v []vec3.T) []float32 {
+	if len(v) == 0 {
+		return nil
+	}
+	return floatsAt(unsafe.Pointer(&v[0]), 3*len(v), 3*cap(v))
+}
+
+// floatsToVec3s returns the vectors sharing the memory of a flat array.
+func floatsToVec3s(f []float32) ([]vec3.T, error) {
+	if len(f)%3 != 0 {
+		return nil, fmt.Errorf("%d floats is not a multiple of 3", len(f))
+	}
+	var v []vec3.T
+	if len(f) > 0 {
+		h := (*reflect.SliceHeader)(unsafe.Pointer(&v))
+		h.Data, h.Len, h.Cap = uintptr(unsafe.Pointer(&f[0])), len(f)/3, cap(f)/3
+	}
+	return v, nil
+}
+
+// floatsAt returns the float slice of the given length and capacity
+// starting at data.
+func floatsAt(data unsafe.Pointer, length, capacity int) []float32 {
+	var f []float32
+	h := (*reflect.SliceHeader)(unsafe.Pointer(&f))
+	h.Data, h.Len, h.Cap = uintptr(data), length, capacity
+	return f
+}
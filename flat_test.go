@@ -0,0 +1,57 @@
+package obj
+
+import (
+	"testing"
+
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_FlatPositions_SharesMemoryOfV(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{
+		V:  []vec3.T{{1, 2, 3}, {4, 5, 6}},
+		VN: []vec3.T{{0, 0, 1}},
+		VT: []vec2.T{{0.25, 0.5}, {1, 0}},
+	}
+
+	// Act
+	positions := buffer.FlatPositions()
+	positions[4] = 50
+
+	// Assert
+	assert.Equal(t, []float32{1, 2, 3, 4, 50, 6}, positions)
+	assert.Equal(t, vec3.T{4, 50, 6}, buffer.V[1])
+	assert.Equal(t, []float32{0, 0, 1}, buffer.FlatNormals())
+	assert.Equal(t, []float32{0.25, 0.5, 1, 0}, buffer.FlatTexCoords())
+	assert.Nil(t, (&ObjBuffer{}).FlatPositions())
+}
+
+func TestObjBuffer_SetFlatPositions_SharesMemoryOfArray(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{}
+	positions := []float32{1, 2, 3, 4, 5, 6}
+	texcoords := []float32{0, 1}
+
+	// Act
+	err := FirstError(buffer.SetFlatPositions(positions), buffer.SetFlatTexCoords(texcoords))
+	positions[0] = 10
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []vec3.T{{10, 2, 3}, {4, 5, 6}}, buffer.V)
+	assert.Equal(t, []vec2.T{{0, 1}}, buffer.VT)
+}
+
+func TestObjBuffer_SetFlatNormals_LengthNotMultipleOf3_ReturnsError(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{VN: []vec3.T{{0, 0, 1}}}
+
+	// Act
+	err := buffer.SetFlatNormals([]float32{0, 1})
+
+	// Assert
+	assert.EqualError(t, err, "2 floats is not a multiple of 3")
+	assert.Equal(t, []vec3.T{{0, 0, 1}}, buffer.VN)
+}
@@ -0,0 +1,135 @@
+package obj
+
+import (
+	"io"
+	"math"
+
+	"github.com/flywave/go3d/vec3"
+)
+
+// FootprintOptions controls ExportFootprints.
+type FootprintOptions struct {
+	// Selection selects the faces to export, such as the roofs or the
+	// ground polygons. The zero value selects all faces.
+	Selection Selection
+	// MaxTilt, in degrees, skips the faces whose normal is tilted by more
+	// than MaxTilt from Up, such as walls. Zero keeps all faces.
+	MaxTilt float64
+	// Up is the up direction of the model, +Z if zero.
+	Up vec3.T
+	// CRSTransform maps the vertex positions to the coordinates of the
+	// reference system, like the crsTransform of ExportLinesGeoJSON. When
+	// it is nil, the X and Y of the positions are used.
+	CRSTransform func(vec3.T) [2]float64
+	// ByGroup writes a MultiPolygon feature per group, with the name of the
+	// group as property, instead of a Polygon feature per face.
+	ByGroup bool
+}
+
+// ExportFootprints projects the selected faces onto the plane of the
+// reference system and writes them as a GeoJSON feature collection of 2D
+// polygons, with their holes, to derive footprints such as those of
+// buildings. Each Polygon feature has the index of its face and its
+// material, if any, as properties. The rings are closed and wound as RFC
+// 7946 requires: counterclockwise, and clockwise for holes. Faces that
+// project to no area, or reference missing vertices, are skipped.
+func (b *ObjBuffer) ExportFootprints(w io.Writer, options FootprintOptions) error {
+	up := options.Up
+	if up == (vec3.T{}) {
+		up = vec3.UnitZ
+	}
+	up.Normalize()
+	minCos := -2.0
+	if options.MaxTilt > 0 {
+		minCos = math.Cos(options.MaxTilt * math.Pi / 180)
+	}
+
+	selected := b.selected(options.Selection)
+	var features []geoJSONFeature
+	groups := make(map[int]int)
+	groupOf := b.groupOfFaces()
+	for i := range b.F {
+		f := &b.F[i]
+		if !selected[i] {
+			continue
+		}
+		if normal := f.Normal(b.V); float64(vec3.Dot(&normal, &up)) < minCos {
+			continue
+		}
+		polygon, ok := b.footprint(f, options.CRSTransform)
+		if !ok {
+			continue
+		}
+		if !options.ByGroup {
+			properties := map[string]interface{}{"index": i}
+			if f.Material != "" {
+				properties["material"] = f.Material
+			}
+			features = append(features, geoJSONFeature{
+				Type:       "Feature",
+				Geometry:   geoJSONGeometry{Type: "Polygon", Coordinates: polygon},
+				Properties: properties,
+			})
+			continue
+		}
+		g := groupOf[i]
+		feature, ok := groups[g]
+		if !ok {
+			name := defaultGroupName
+			if g >= 0 {
+				name = b.G[g].Name
+			}
+			feature = len(features)
+			groups[g] = feature
+			features = append(features, geoJSONFeature{
+				Type:       "Feature",
+				Geometry:   geoJSONGeometry{Type: "MultiPolygon", Coordinates: [][][][2]float64{}},
+				Properties: map[string]interface{}{"group": name},
+			})
+		}
+		geometry := &features[feature].Geometry
+		geometry.Coordinates = append(geometry.Coordinates.([][][][2]float64), polygon)
+	}
+	return writeGeoJSON(w, features)
+}
+
+// footprint returns the rings of face f projected by crsTransform, closed
+// and wound as GeoJSON requires, or false if its outer ring has no area or
+// a corner references a missing vertex. Holes without area are dropped.
+func (b *ObjBuffer) footprint(f *Face, crsTransform func(vec3.T) [2]float64) ([][][2]float64, bool) {
+	var polygon [][][2]float64
+	for k, loop := range f.loops() {
+		ring := make([][2]float64, 0, len(loop)+1)
+		for _, c := range loop {
+			if c.VertexIndex < 0 || c.VertexIndex >= len(b.V) {
+				return nil, false
+			}
+			ring = append(ring, b.projectVertex(c.VertexIndex, crsTransform))
+		}
+		area := ringArea(ring)
+		if area == 0 {
+			if k == 0 {
+				return nil, false
+			}
+			continue
+		}
+		if hole := k > 0; hole == (area > 0) {
+			for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+				ring[i], ring[j] = ring[j], ring[i]
+			}
+		}
+		polygon = append(polygon, append(ring, ring[0]))
+	}
+	return polygon, true
+}
+
+// ringArea returns the signed area of a ring, positive when it is
+// counterclockwise.
+func ringArea(ring [][2]float64) float64 {
+	area := 0.0
+	for i := range ring {
+		p, q := ring[i], ring[(i+1)%len(ring)]
+		area += p[0]*q[1] - q[0]*p[1]
+	}
+	return area / 2
+}
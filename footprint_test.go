@@ -0,0 +1,64 @@
+package obj
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_ExportFootprints_Hole_WritesClosedRings(t *testing.T) {
+	// Arrange
+	buffer := createSquareWithHole()
+	buffer.F[0].Material = "roof"
+
+	// Act
+	var buf bytes.Buffer
+	err := buffer.ExportFootprints(&buf, FootprintOptions{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "properties": {"index": 0, "material": "roof"}, "geometry": {"type": "Polygon", "coordinates": [
+			[[0, 0], [4, 0], [4, 4], [0, 4], [0, 0]],
+			[[1, 1], [1, 3], [3, 3], [3, 1], [1, 1]]
+		]}}
+	]}`, buf.String())
+}
+
+func TestObjBuffer_ExportFootprints_MaxTilt_KeepsRoofOnly(t *testing.T) {
+	// Arrange
+	buffer := createCube(vec3.T{0, 0, 0}, vec3.T{2, 1, 3}, "")
+	transform := func(v vec3.T) [2]float64 { return [2]float64{float64(v[0]) * 10, float64(v[1]) * 10} }
+
+	// Act
+	var buf bytes.Buffer
+	err := buffer.ExportFootprints(&buf, FootprintOptions{MaxTilt: 10, CRSTransform: transform})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "properties": {"index": 5}, "geometry": {"type": "Polygon", "coordinates": [
+			[[0, 0], [20, 0], [20, 10], [0, 10], [0, 0]]
+		]}}
+	]}`, buf.String())
+}
+
+func TestObjBuffer_ExportFootprints_ByGroup_WritesMultiPolygons(t *testing.T) {
+	// Arrange
+	buffer := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "")
+	buffer.G = []Group{{Name: "bottom", FirstFaceIndex: 0, FaceCount: 5}, {Name: "top", FirstFaceIndex: 5, FaceCount: 1}}
+
+	// Act
+	var buf bytes.Buffer
+	err := buffer.ExportFootprints(&buf, FootprintOptions{ByGroup: true, Up: vec3.T{0, 0, -1}, MaxTilt: 45})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "properties": {"group": "bottom"}, "geometry": {"type": "MultiPolygon", "coordinates": [
+			[[[1, 0], [1, 1], [0, 1], [0, 0], [1, 0]]]
+		]}}
+	]}`, buf.String())
+}
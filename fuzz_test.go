@@ -0,0 +1,55 @@
+//go:build go1.18
+
+package obj
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+)
+
+var fuzzLimits = Limits{MaxVertices: 1 << 12, MaxFaces: 1 << 12, MaxLineLen: 1 << 12}
+
+func FuzzRead(f *testing.F) {
+	f.Add("v 0 0 0\nv 1 0 0\nv 0 1 0\nvt 0 0\nvn 0 0 1\ng a b\nusemtl m\nf 1/1/1 2/1/1 3/1/1\nl 1 2\n")
+	f.Add("mtllib a.mtl\nv 1e38 -1e38 0 1 0 0\nf 1 1 1 1\no x\ns 1\n# offset 1 2 3\n")
+	f.Add("f 99999999999 1 -1\nusemtl\nusemtl a\nusemtl b\nv nan 0 0\n")
+	f.Add("# attribute a float 20000000\nv 0 0 0\n#va 1\nv 0 0 0\nv 0 0 0\n")
+	f.Fuzz(func(t *testing.T, input string) {
+		for _, options := range []ReadOptions{
+			{Limits: fuzzLimits},
+			{Limits: fuzzLimits, TwoPass: true, DoublePrecision: true, KeepComments: true},
+			{Limits: fuzzLimits, Lossless: true, AutoRecenter: RecenterBoundingBox},
+		} {
+			loader := ObjReader{}
+			loader.SetOptions(options)
+			if err := loader.Read(strings.NewReader(input)); err != nil {
+				continue
+			}
+			if len(loader.V) > fuzzLimits.MaxVertices || len(loader.F) > fuzzLimits.MaxFaces {
+				t.Fatalf("limits exceeded: %d vertices, %d faces", len(loader.V), len(loader.F))
+			}
+			if err := loader.WriteWith(ioutil.Discard, WriteOptions{Lossless: options.Lossless}); err != nil {
+				t.Fatalf("writing a buffer that was read failed: %v", err)
+			}
+			loader.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool { return true })
+			loader.BuildTopology()
+		}
+	})
+}
+
+func FuzzReadMaterials(f *testing.F) {
+	f.Add("newmtl a\nKa 0 0 0\nKd 1 1 1\nNs 10\nd 0.5\nillum 2\nmap_Kd a.png\n")
+	f.Add("Kd 1 1 1\n")
+	f.Add("newmtl a\nKd nan 1 1\nnewmtl\n")
+	f.Fuzz(func(t *testing.T, input string) {
+		materials, err := ReadMaterialsFrom(bytes.NewReader([]byte(input)), "fuzz.mtl")
+		if err != nil {
+			return
+		}
+		HashMaterials(materials)
+	})
+}
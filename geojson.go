@@ -0,0 +1,121 @@
+package obj
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/flywave/go3d/vec3"
+)
+
+// geoJSONFeatureCollection is a GeoJSON document, as specified by RFC 7946.
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// writeGeoJSON writes the features as a feature collection.
+func writeGeoJSON(w io.Writer, features []geoJSONFeature) error {
+	if features == nil {
+		features = []geoJSONFeature{}
+	}
+	return json.NewEncoder(w).Encode(geoJSONFeatureCollection{Type: "FeatureCollection", Features: features})
+}
+
+// ExportLinesGeoJSON writes the lines as a GeoJSON feature collection, a
+// LineString feature per line with the index of the line and its material,
+// if any, as properties. crsTransform maps the vertex positions, without
+// Offset, to the coordinates of the reference system, longitude and
+// latitude for GeoJSON; it must apply Offset itself for buffers that have
+// one. When it is nil, the X and Y of the positions are written, offset
+// included, in double precision if the buffer has it. Lines of less than 2 corners are skipped.
+func (b *ObjBuffer) ExportLinesGeoJSON(w io.Writer, crsTransform func(vec3.T) [2]float64) error {
+	lines, err := b.projectLines(crsTransform)
+	if err != nil {
+		return err
+	}
+	var features []geoJSONFeature
+	for i, l := range lines {
+		if l == nil {
+			continue
+		}
+		properties := map[string]interface{}{"index": i}
+		if b.L[i].Material != "" {
+			properties["material"] = b.L[i].Material
+		}
+		features = append(features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "LineString", Coordinates: l},
+			Properties: properties,
+		})
+	}
+	return writeGeoJSON(w, features)
+}
+
+// ExportLinesWKT writes the lines as a well-known text MULTILINESTRING,
+// mapping the positions like ExportLinesGeoJSON. Lines of less than 2
+// corners are skipped.
+func (b *ObjBuffer) ExportLinesWKT(w io.Writer, crsTransform func(vec3.T) [2]float64) error {
+	lines, err := b.projectLines(crsTransform)
+	if err != nil {
+		return err
+	}
+	var parts []string
+	for _, l := range lines {
+		if l == nil {
+			continue
+		}
+		points := make([]string, len(l))
+		for j, p := range l {
+			points[j] = fmt.Sprintf("%g %g", p[0], p[1])
+		}
+		parts = append(parts, "("+strings.Join(points, ", ")+")")
+	}
+	text := "MULTILINESTRING EMPTY\n"
+	if len(parts) > 0 {
+		text = "MULTILINESTRING (" + strings.Join(parts, ", ") + ")\n"
+	}
+	_, err = io.WriteString(w, text)
+	return err
+}
+
+// projectLines returns the corners of every line mapped by crsTransform,
+// or nil for the lines of less than 2 corners.
+func (b *ObjBuffer) projectLines(crsTransform func(vec3.T) [2]float64) ([][][2]float64, error) {
+	lines := make([][][2]float64, len(b.L))
+	for i, l := range b.L {
+		if len(l.Corners) < 2 {
+			continue
+		}
+		for _, c := range l.Corners {
+			if c < 0 || c >= len(b.V) {
+				return nil, badStatement(ErrBadIndex, "Line %d references vertex %d of %d", i+1, c+1, len(b.V))
+			}
+			lines[i] = append(lines[i], b.projectVertex(c, crsTransform))
+		}
+	}
+	return lines, nil
+}
+
+// projectVertex returns vertex i mapped by crsTransform, or the X and Y of
+// its world position if crsTransform is nil.
+func (b *ObjBuffer) projectVertex(i int, crsTransform func(vec3.T) [2]float64) [2]float64 {
+	if crsTransform != nil {
+		return crsTransform(b.V[i])
+	}
+	p := b.positionD(i)
+	p.Add(&b.Offset)
+	return [2]float64{p[0], p[1]}
+}
@@ -0,0 +1,80 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_ExportLinesGeoJSON_Lines_WritesLineStrings(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{V: []vec3.T{{0, 0, 0}, {1, 2, 3}, {4, 5, 6}}}
+	buffer.L = []line{{Corners: []int{0, 1, 2}, Material: "road"}, {Corners: []int{1}}, {Corners: []int{2, 0}}}
+	transform := func(v vec3.T) [2]float64 { return [2]float64{float64(v[0]) + 100, float64(v[2])} }
+
+	// Act
+	var buf bytes.Buffer
+	err := buffer.ExportLinesGeoJSON(&buf, transform)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "geometry": {"type": "LineString", "coordinates": [[100, 0], [101, 3], [104, 6]]},
+		 "properties": {"index": 0, "material": "road"}},
+		{"type": "Feature", "geometry": {"type": "LineString", "coordinates": [[104, 6], [100, 0]]},
+		 "properties": {"index": 2}}
+	]}`, buf.String())
+}
+
+func TestObjBuffer_ExportLinesGeoJSON_NoTransform_AppliesOffset(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{V: []vec3.T{{0, 0, 0}, {1, 2, 3}}, Offset: dvec3.T{500000, 4000000, 0}}
+	buffer.L = []line{{Corners: []int{0, 1}}}
+
+	// Act
+	var buf bytes.Buffer
+	err := buffer.ExportLinesGeoJSON(&buf, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	var doc geoJSONFeatureCollection
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, []interface{}{[]interface{}{500000.0, 4000000.0}, []interface{}{500001.0, 4000002.0}},
+		doc.Features[0].Geometry.Coordinates)
+}
+
+func TestObjBuffer_ExportLinesWKT_Lines_WritesMultiLineString(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{V: []vec3.T{{0, 0, 0}, {1.5, 2, 3}, {4, 5, 6}}}
+	buffer.L = []line{{Corners: []int{0, 1}}, {Corners: []int{1, 2, 0}}}
+
+	// Act
+	var buf bytes.Buffer
+	err := buffer.ExportLinesWKT(&buf, nil)
+	var empty bytes.Buffer
+	emptyErr := (&ObjBuffer{}).ExportLinesWKT(&empty, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "MULTILINESTRING ((0 0, 1.5 2), (1.5 2, 4 5, 0 0))\n", buf.String())
+	assert.NoError(t, emptyErr)
+	assert.Equal(t, "MULTILINESTRING EMPTY\n", empty.String())
+}
+
+func TestObjBuffer_ExportLinesGeoJSON_MissingVertex_ReturnsError(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{V: []vec3.T{{0, 0, 0}}}
+	buffer.L = []line{{Corners: []int{0, 3}}}
+
+	// Act
+	err := buffer.ExportLinesGeoJSON(&bytes.Buffer{}, nil)
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrBadIndex))
+	assert.EqualError(t, err, "Line 1 references vertex 4 of 1")
+}
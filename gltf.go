@@ -0,0 +1,759 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+
+	dvec4 "github.com/flywave/go3d/float64/vec4"
+	"github.com/flywave/go3d/vec3"
+)
+
+// glTF constants used by the exporter.
+const (
+	gltfFloat        = 5126
+	gltfUnsignedInt  = 5125
+	gltfArrayBuffer  = 34962
+	gltfElementArray = 34963
+
+	glbMagic     = 0x46546C67
+	glbChunkJSON = 0x4E4F534A
+	glbChunkBIN  = 0x004E4942
+)
+
+// gltfLabelRegex matches the labels glTF accepts for feature IDs.
+var gltfLabelRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// gltfVectorTypes are the accessor types by number of components.
+var gltfVectorTypes = [...]string{1: "SCALAR", 2: "VEC2", 3: "VEC3", 4: "VEC4"}
+
+// WriteGLTF writes the scene as a glTF 2.0 document, with the geometry
+// embedded as a base64 data URI. Faces are triangulated and get one
+// primitive per material. Normals and texture coordinates are exported when
+// every corner has them. The buffer offset becomes the translation of the
+// node, and textures are referenced by the paths of the material library.
+// Every scene node becomes a glTF node, sharing the mesh of its buffer.
+// Materials are converted with ConvertMaterialToPBR; their displacement and
+// decal textures are listed in the extras of the material. Custom vertex
+// attributes of up to four components are exported as float attributes
+// named after them, in upper case and prefixed with an underscore, and
+// listed in the extras of the mesh. Face metadata is exported with
+// EXT_mesh_features, as a feature ID attribute per metadata name.
+func (s *Scene) WriteGLTF(w io.Writer) error {
+	return s.WriteGLTFWith(w, GLTFOptions{})
+}
+
+// GLTFOptions controls the export of a scene as glTF.
+type GLTFOptions struct {
+	// TextureFormat, TexturePNG or TextureJPEG, converts the TGA, BMP and
+	// TIFF textures, which glTF viewers do not load, to this format. The
+	// converted files are named after the textures with the extension of
+	// the format, and the document references them instead. JPEG has no
+	// alpha channel, so textures with transparent pixels are converted to
+	// PNG. The zero value keeps the textures as they are.
+	TextureFormat TextureFormat
+	// TextureDir is the directory the converted textures are written to,
+	// at the relative paths of the textures. It defaults to the Dir of the
+	// scene. Textures with an absolute path are converted next to the
+	// original.
+	TextureDir string
+	// JPEGQuality is the quality of the JPEG encoder, from 1 to 100. It
+	// defaults to 90.
+	JPEGQuality int
+	// Transcoder, when set, transcodes every texture after the conversion
+	// to TextureFormat, for example to KTX2 with Basis Universal. The
+	// results are written to TextureDir like the converted textures. KTX2
+	// textures are referenced with the KHR_texture_basisu extension, with
+	// the untranscoded image as the fallback of viewers without it.
+	Transcoder TextureTranscoder
+	// MorphTargets maps buffers of the scene to the shapes exported as the
+	// morph targets of their mesh. The targets must have the vertices of
+	// the buffer; their positions and, when they have as many normals as
+	// the buffer, their normals are written as displacements from it.
+	MorphTargets map[*ObjBuffer][]MorphTarget
+}
+
+// WriteGLTFWith writes the scene like WriteGLTF, converting and
+// transcoding the textures as set by options.
+func (s *Scene) WriteGLTFWith(w io.Writer, options GLTFOptions) error {
+	doc, bin, err := s.gltfDocumentWith(options)
+	if err != nil {
+		return err
+	}
+	if len(bin) > 0 {
+		doc.Buffers = []gltfBuffer{{
+			ByteLength: len(bin),
+			URI:        "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(bin),
+		}}
+	}
+	return json.NewEncoder(w).Encode(doc)
+}
+
+// WriteGLB writes the scene like WriteGLTF, as a binary glTF container.
+func (s *Scene) WriteGLB(w io.Writer) error {
+	return s.WriteGLBWith(w, GLTFOptions{})
+}
+
+// WriteGLBWith writes the scene like WriteGLB, converting and transcoding
+// the textures as set by options.
+func (s *Scene) WriteGLBWith(w io.Writer, options GLTFOptions) error {
+	doc, bin, err := s.gltfDocumentWith(options)
+	if err != nil {
+		return err
+	}
+	glb, err := encodeGLB(doc, bin)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(glb)
+	return err
+}
+
+// encodeGLB returns the binary glTF container of the document and the
+// content of its buffer.
+func encodeGLB(doc *gltfDocument, bin []byte) ([]byte, error) {
+	if len(bin) > 0 {
+		doc.Buffers = []gltfBuffer{{ByteLength: len(bin)}}
+	}
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	for len(js)%4 != 0 {
+		js = append(js, ' ')
+	}
+	for len(bin)%4 != 0 {
+		bin = append(bin, 0)
+	}
+
+	length := 12 + 8 + len(js)
+	if len(bin) > 0 {
+		length += 8 + len(bin)
+	}
+	var out bytes.Buffer
+	binary.Write(&out, binary.LittleEndian, []uint32{glbMagic, 2, uint32(length)})
+	binary.Write(&out, binary.LittleEndian, []uint32{uint32(len(js)), glbChunkJSON})
+	out.Write(js)
+	if len(bin) > 0 {
+		binary.Write(&out, binary.LittleEndian, []uint32{uint32(len(bin)), glbChunkBIN})
+		out.Write(bin)
+	}
+	return out.Bytes(), nil
+}
+
+type gltfDocument struct {
+	Asset       gltfAsset        `json:"asset"`
+	Scene       int              `json:"scene"`
+	Scenes      []gltfScene      `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes,omitempty"`
+	Meshes      []gltfMesh       `json:"meshes,omitempty"`
+	Materials   []gltfMaterial   `json:"materials,omitempty"`
+	Textures    []gltfTexture    `json:"textures,omitempty"`
+	Images      []gltfImage      `json:"images,omitempty"`
+	Accessors   []gltfAccessor   `json:"accessors,omitempty"`
+	BufferViews []gltfBufferView `json:"bufferViews,omitempty"`
+	Buffers     []gltfBuffer     `json:"buffers,omitempty"`
+
+	ExtensionsUsed []string `json:"extensionsUsed,omitempty"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator,omitempty"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Name        string    `json:"name,omitempty"`
+	Mesh        *int      `json:"mesh,omitempty"`
+	Matrix      []float64 `json:"matrix,omitempty"`
+	Translation []float64 `json:"translation,omitempty"`
+}
+
+type gltfMesh struct {
+	Primitives []gltfPrimitive `json:"primitives"`
+	Weights    []float64       `json:"weights,omitempty"`
+	Extras     *gltfMeshExtras `json:"extras,omitempty"`
+}
+
+type gltfPrimitiveExtensions struct {
+	MeshFeatures *gltfMeshFeatures `json:"EXT_mesh_features,omitempty"`
+}
+
+type gltfMeshFeatures struct {
+	FeatureIDs []gltfFeatureID `json:"featureIds"`
+}
+
+type gltfFeatureID struct {
+	FeatureCount  int     `json:"featureCount"`
+	Attribute     int     `json:"attribute"`
+	Label         string  `json:"label,omitempty"`
+	NullFeatureID *uint32 `json:"nullFeatureId,omitempty"`
+}
+
+// gltfMeshExtras maps the names of the custom vertex attributes to their
+// glTF attribute semantics, and names the morph targets.
+type gltfMeshExtras struct {
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	TargetNames []string          `json:"targetNames,omitempty"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int   `json:"attributes"`
+	Indices    int              `json:"indices"`
+	Material   *int             `json:"material,omitempty"`
+	Targets    []map[string]int `json:"targets,omitempty"`
+
+	Extensions *gltfPrimitiveExtensions `json:"extensions,omitempty"`
+}
+
+type gltfMaterial struct {
+	Name                 string                   `json:"name,omitempty"`
+	PbrMetallicRoughness gltfPBRMetallicRoughness `json:"pbrMetallicRoughness"`
+	NormalTexture        *gltfTextureInfo         `json:"normalTexture,omitempty"`
+	EmissiveTexture      *gltfTextureInfo         `json:"emissiveTexture,omitempty"`
+	EmissiveFactor       []float32                `json:"emissiveFactor,omitempty"`
+	AlphaMode            string                   `json:"alphaMode,omitempty"`
+	Extras               *gltfMaterialExtras      `json:"extras,omitempty"`
+}
+
+// gltfMaterialExtras holds the textures glTF has no property for.
+type gltfMaterialExtras struct {
+	DisplacementTexture *gltfTextureInfo `json:"displacementTexture,omitempty"`
+	DecalTexture        *gltfTextureInfo `json:"decalTexture,omitempty"`
+}
+
+type gltfPBRMetallicRoughness struct {
+	BaseColorFactor  []float32        `json:"baseColorFactor"`
+	BaseColorTexture *gltfTextureInfo `json:"baseColorTexture,omitempty"`
+	MetallicFactor   float32          `json:"metallicFactor"`
+	RoughnessFactor  float32          `json:"roughnessFactor"`
+}
+
+type gltfTextureInfo struct {
+	Index int `json:"index"`
+}
+
+type gltfTexture struct {
+	Source     int                    `json:"source"`
+	Extensions *gltfTextureExtensions `json:"extensions,omitempty"`
+}
+
+type gltfTextureExtensions struct {
+	Basisu *gltfTextureSource `json:"KHR_texture_basisu,omitempty"`
+}
+
+type gltfTextureSource struct {
+	Source int `json:"source"`
+}
+
+type gltfImage struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ByteOffset    int       `json:"byteOffset,omitempty"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target,omitempty"`
+}
+
+type gltfBuffer struct {
+	ByteLength int    `json:"byteLength"`
+	URI        string `json:"uri,omitempty"`
+}
+
+// gltfDocument returns the glTF document of the scene without buffers, and
+// the content of its single buffer. Buffers shared by several nodes are
+// written once, as a mesh instanced by the nodes.
+func (s *Scene) gltfDocument() (*gltfDocument, []byte) {
+	return s.newGLTFBuilder().build()
+}
+
+// gltfDocumentWith returns the document like gltfDocument, after
+// converting and transcoding the textures as set by options.
+func (s *Scene) gltfDocumentWith(options GLTFOptions) (*gltfDocument, []byte, error) {
+	g := s.newGLTFBuilder()
+	if err := validateMorphTargets(options.MorphTargets); err != nil {
+		return nil, nil, err
+	}
+	g.morphTargets = options.MorphTargets
+	if options.TextureFormat != "" {
+		uris, err := s.convertTextures(options)
+		if err != nil {
+			return nil, nil, err
+		}
+		g.textureURIs = uris
+	}
+	if options.Transcoder != nil {
+		transcoded, err := s.transcodeTextures(options, g.textureURIs)
+		if err != nil {
+			return nil, nil, err
+		}
+		g.transcoded = transcoded
+	}
+	doc, bin := g.build()
+	return doc, bin, nil
+}
+
+func (s *Scene) newGLTFBuilder() *gltfBuilder {
+	return &gltfBuilder{
+		scene: s,
+		doc: &gltfDocument{
+			Asset:  gltfAsset{Version: "2.0", Generator: DefaultGenerator},
+			Scenes: []gltfScene{{Nodes: []int{}}},
+		},
+		meshes:    map[*ObjBuffer]int{},
+		materials: map[string]int{},
+		textures:  map[string]int{},
+	}
+}
+
+// build adds the scene buffer and nodes to the document, and returns it
+// with the content of its buffer.
+func (g *gltfBuilder) build() (*gltfDocument, []byte) {
+	s := g.scene
+	if s.Buffer != nil {
+		if mesh := g.mesh(s.Buffer); mesh >= 0 {
+			node := gltfNode{Mesh: &mesh}
+			if !s.Buffer.Offset.IsZero() {
+				node.Translation = []float64{s.Buffer.Offset[0], s.Buffer.Offset[1], s.Buffer.Offset[2]}
+			}
+			g.addNode(node)
+		}
+	}
+	for i := range s.Nodes {
+		n := &s.Nodes[i]
+		mesh := g.mesh(n.Buffer)
+		if mesh < 0 {
+			continue
+		}
+		// Move the offset of the buffer into the transform.
+		matrix := n.Transform
+		offset := dvec4.T{n.Buffer.Offset[0], n.Buffer.Offset[1], n.Buffer.Offset[2], 1}
+		matrix[3] = n.Transform.MulVec4(&offset)
+		g.addNode(gltfNode{Name: n.Name, Mesh: &mesh, Matrix: matrix.Slice()})
+	}
+	return g.doc, g.bin.Bytes()
+}
+
+// gltfBuilder assembles a glTF document and its buffer.
+type gltfBuilder struct {
+	scene *Scene
+	doc   *gltfDocument
+	bin   bytes.Buffer
+	// meshes, materials and textures map what was already added to its
+	// index in the document, textures by path.
+	meshes    map[*ObjBuffer]int
+	materials map[string]int
+	textures  map[string]int
+	// textureURIs maps the paths of the converted textures to the paths of
+	// their conversion.
+	textureURIs map[string]string
+	// transcoded maps the paths of the transcoded textures to the result.
+	transcoded map[string]transcodedTexture
+	// morphTargets maps buffers to the morph targets of their mesh.
+	morphTargets map[*ObjBuffer][]MorphTarget
+
+	// batchKey, when set, names the face metadata exported as the
+	// _BATCHID attribute of 3D Tiles instead of with EXT_mesh_features.
+	// batches maps its values to batch IDs, faces without it get
+	// nullBatch.
+	batchKey  string
+	batches   map[uint32]int
+	nullBatch int
+}
+
+// useExtension lists the extension name in the extensions used by the
+// document.
+func (g *gltfBuilder) useExtension(name string) {
+	for _, used := range g.doc.ExtensionsUsed {
+		if used == name {
+			return
+		}
+	}
+	g.doc.ExtensionsUsed = append(g.doc.ExtensionsUsed, name)
+}
+
+func (g *gltfBuilder) addNode(node gltfNode) {
+	g.doc.Nodes = append(g.doc.Nodes, node)
+	g.doc.Scenes[0].Nodes = append(g.doc.Scenes[0].Nodes, len(g.doc.Nodes)-1)
+}
+
+func (g *gltfBuilder) addView(data interface{}, target int) int {
+	view := gltfBufferView{ByteOffset: g.bin.Len(), Target: target}
+	binary.Write(&g.bin, binary.LittleEndian, data)
+	view.ByteLength = g.bin.Len() - view.ByteOffset
+	g.doc.BufferViews = append(g.doc.BufferViews, view)
+	return len(g.doc.BufferViews) - 1
+}
+
+func (g *gltfBuilder) addAccessor(a gltfAccessor) int {
+	g.doc.Accessors = append(g.doc.Accessors, a)
+	return len(g.doc.Accessors) - 1
+}
+
+// material returns the index of the named material, adding it to the
+// document if needed, or -1 if the scene does not define it.
+func (g *gltfBuilder) material(name string) int {
+	if index, ok := g.materials[name]; ok {
+		return index
+	}
+	m, ok := g.scene.Materials[name]
+	if !ok {
+		return -1
+	}
+	index := len(g.doc.Materials)
+	g.doc.Materials = append(g.doc.Materials, g.gltfMaterial(m))
+	g.materials[name] = index
+	return index
+}
+
+// mesh returns the index of the mesh of b, adding it to the document if
+// needed, or -1 if b has no triangles.
+func (g *gltfBuilder) mesh(b *ObjBuffer) int {
+	if index, ok := g.meshes[b]; ok {
+		return index
+	}
+	g.meshes[b] = -1
+
+	withNormals, withUVs := true, true
+	type triangle struct {
+		corners [3]FaceCorner
+		face    int
+	}
+	var materials []string
+	triangles := map[string][]triangle{}
+	b.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		for _, c := range corners {
+			withNormals = withNormals && c.NormalIndex >= 0 && c.NormalIndex < len(b.VN)
+			withUVs = withUVs && c.TexcoordIndex >= 0 && c.TexcoordIndex < len(b.VT)
+		}
+		material := b.F[faceIdx].Material
+		if _, ok := triangles[material]; !ok {
+			materials = append(materials, material)
+		}
+		triangles[material] = append(triangles[material], triangle{corners, faceIdx})
+		return true
+	})
+	if len(materials) == 0 {
+		return -1
+	}
+
+	// Weld the corners into glTF vertices, shared by all primitives.
+	// Faces with different metadata get vertices of their own, f being the
+	// index of the metadata in faceFeatures.
+	type vertexKey struct{ v, n, t, f int }
+	vertices := map[vertexKey]uint32{}
+	var positions, normals, uvs []float32
+	var indices [][]uint32
+	// The OBJ vertex and normal of every glTF vertex, for the morph targets.
+	var vertexSources, normalSources []int
+	var attributeNames []string
+	for _, name := range b.attributeNames() {
+		if a := b.Attributes[name]; a.Size <= 4 && a.Len() == len(b.V) {
+			attributeNames = append(attributeNames, name)
+		}
+	}
+	attributeValues := make([][]float32, len(attributeNames))
+	featureNames := b.faceMetadataNames()
+	if g.batchKey != "" {
+		featureNames = []string{g.batchKey}
+	}
+	featureValues := make([][]float32, len(featureNames))
+	features := map[string]int{}
+	var faceFeatures [][]*uint32
+	featureOf := func(f *Face) int {
+		if len(featureNames) == 0 {
+			return -1
+		}
+		ids := make([]*uint32, len(featureNames))
+		var key strings.Builder
+		for j, name := range featureNames {
+			if id, ok := f.Metadata[name]; ok {
+				ids[j] = &id
+				fmt.Fprintf(&key, "%d,", id)
+			} else {
+				key.WriteString("-,")
+			}
+		}
+		index, ok := features[key.String()]
+		if !ok {
+			index = len(faceFeatures)
+			features[key.String()] = index
+			faceFeatures = append(faceFeatures, ids)
+		}
+		return index
+	}
+	for _, material := range materials {
+		var primitive []uint32
+		for _, tri := range triangles[material] {
+			feature := featureOf(&b.F[tri.face])
+			for _, c := range tri.corners {
+				key := vertexKey{c.VertexIndex, -1, -1, feature}
+				if withNormals {
+					key.n = c.NormalIndex
+				}
+				if withUVs {
+					key.t = c.TexcoordIndex
+				}
+				index, ok := vertices[key]
+				if !ok {
+					index = uint32(len(vertices))
+					vertices[key] = index
+					v := b.V[key.v]
+					positions = append(positions, v[0], v[1], v[2])
+					vertexSources = append(vertexSources, key.v)
+					if withNormals {
+						n := b.VN[key.n]
+						normals = append(normals, n[0], n[1], n[2])
+						normalSources = append(normalSources, key.n)
+					}
+					if withUVs {
+						t := b.VT[key.t]
+						uvs = append(uvs, t[0], 1-t[1])
+					}
+					for j, name := range attributeNames {
+						a := b.Attributes[name]
+						for k := 0; k < a.Size; k++ {
+							attributeValues[j] = append(attributeValues[j], float32(a.Float(key.v, k)))
+						}
+					}
+					for j := range featureNames {
+						// Missing identifiers are resolved once all are known.
+						value := float32(-1)
+						if id := faceFeatures[key.f][j]; id != nil && g.batchKey != "" {
+							value = float32(g.batches[*id])
+						} else if id != nil {
+							value = float32(*id)
+						}
+						featureValues[j] = append(featureValues[j], value)
+					}
+				}
+				primitive = append(primitive, index)
+			}
+		}
+		indices = append(indices, primitive)
+	}
+
+	count := len(vertices)
+	attributes := map[string]int{}
+	min, max := floatBounds(positions)
+	attributes["POSITION"] = g.addAccessor(gltfAccessor{
+		BufferView: g.addView(positions, gltfArrayBuffer), ComponentType: gltfFloat,
+		Count: count, Type: "VEC3", Min: min, Max: max,
+	})
+	if withNormals {
+		attributes["NORMAL"] = g.addAccessor(gltfAccessor{
+			BufferView: g.addView(normals, gltfArrayBuffer), ComponentType: gltfFloat,
+			Count: count, Type: "VEC3",
+		})
+	}
+	if withUVs {
+		attributes["TEXCOORD_0"] = g.addAccessor(gltfAccessor{
+			BufferView: g.addView(uvs, gltfArrayBuffer), ComponentType: gltfFloat,
+			Count: count, Type: "VEC2",
+		})
+	}
+
+	var extras *gltfMeshExtras
+	for j, name := range attributeNames {
+		semantic := "_" + strings.ToUpper(name)
+		attributes[semantic] = g.addAccessor(gltfAccessor{
+			BufferView: g.addView(attributeValues[j], gltfArrayBuffer), ComponentType: gltfFloat,
+			Count: count, Type: gltfVectorTypes[b.Attributes[name].Size],
+		})
+		if extras == nil {
+			extras = &gltfMeshExtras{Attributes: map[string]string{}}
+		}
+		extras.Attributes[name] = semantic
+	}
+
+	if g.batchKey != "" {
+		batchIDs := featureValues[0]
+		for k, value := range batchIDs {
+			if value < 0 {
+				batchIDs[k] = float32(g.nullBatch)
+			}
+		}
+		attributes["_BATCHID"] = g.addAccessor(gltfAccessor{
+			BufferView: g.addView(batchIDs, gltfArrayBuffer), ComponentType: gltfFloat,
+			Count: count, Type: "SCALAR",
+		})
+		featureNames = nil
+	}
+	var meshFeatures *gltfMeshFeatures
+	for j, name := range featureNames {
+		feature := gltfFeatureID{Attribute: j}
+		if gltfLabelRegex.MatchString(name) {
+			feature.Label = name
+		}
+		distinct := map[float32]bool{}
+		max := float32(-1)
+		for _, value := range featureValues[j] {
+			if value >= 0 {
+				distinct[value] = true
+				max = float32(math.Max(float64(max), float64(value)))
+			}
+		}
+		feature.FeatureCount = len(distinct)
+		if len(distinct) < len(featureValues[j]) {
+			for k, value := range featureValues[j] {
+				if value < 0 {
+					featureValues[j][k] = max + 1
+					null := uint32(max + 1)
+					feature.NullFeatureID = &null
+				}
+			}
+		}
+		attributes[fmt.Sprintf("_FEATURE_ID_%d", j)] = g.addAccessor(gltfAccessor{
+			BufferView: g.addView(featureValues[j], gltfArrayBuffer), ComponentType: gltfFloat,
+			Count: count, Type: "SCALAR",
+		})
+		if meshFeatures == nil {
+			meshFeatures = &gltfMeshFeatures{}
+			g.useExtension("EXT_mesh_features")
+		}
+		meshFeatures.FeatureIDs = append(meshFeatures.FeatureIDs, feature)
+	}
+
+	var all []uint32
+	for _, primitive := range indices {
+		all = append(all, primitive...)
+	}
+	indexView := g.addView(all, gltfElementArray)
+	targets := g.addMorphTargets(b, vertexSources, normalSources)
+	mesh := gltfMesh{Extras: extras}
+	var names []string
+	named := false
+	for _, m := range g.morphTargets[b] {
+		mesh.Weights = append(mesh.Weights, m.Weight)
+		names = append(names, m.Name)
+		named = named || m.Name != ""
+	}
+	if named {
+		if mesh.Extras == nil {
+			mesh.Extras = &gltfMeshExtras{}
+		}
+		mesh.Extras.TargetNames = names
+	}
+	offset := 0
+	for i, material := range materials {
+		primitive := gltfPrimitive{
+			Attributes: attributes,
+			Targets:    targets,
+			Indices: g.addAccessor(gltfAccessor{
+				BufferView: indexView, ByteOffset: offset * 4, ComponentType: gltfUnsignedInt,
+				Count: len(indices[i]), Type: "SCALAR",
+			}),
+		}
+		offset += len(indices[i])
+		if meshFeatures != nil {
+			primitive.Extensions = &gltfPrimitiveExtensions{MeshFeatures: meshFeatures}
+		}
+		if index := g.material(material); index >= 0 {
+			primitive.Material = &index
+		}
+		mesh.Primitives = append(mesh.Primitives, primitive)
+	}
+	g.doc.Meshes = append(g.doc.Meshes, mesh)
+	g.meshes[b] = len(g.doc.Meshes) - 1
+	return g.meshes[b]
+}
+
+// gltfMaterial converts m to a glTF material with ConvertMaterialToPBR,
+// adding its textures to the document.
+func (g *gltfBuilder) gltfMaterial(m *Material) gltfMaterial {
+	doc := g.doc
+	texture := func(path string) *gltfTextureInfo {
+		if path == "" {
+			return nil
+		}
+		index, ok := g.textures[path]
+		if !ok {
+			index = len(doc.Textures)
+			g.textures[path] = index
+			uri, ok := g.textureURIs[path]
+			if !ok {
+				uri = path
+			}
+			image := gltfImage{URI: uri}
+			t := gltfTexture{Source: len(doc.Images)}
+			if transcoded, ok := g.transcoded[path]; ok {
+				if transcoded.mimeType == MIMETypeKTX2 {
+					// Viewers without KTX2 support fall back to the source.
+					g.useExtension("KHR_texture_basisu")
+					t.Extensions = &gltfTextureExtensions{Basisu: &gltfTextureSource{Source: len(doc.Images) + 1}}
+					doc.Images = append(doc.Images, image)
+				}
+				image = gltfImage{URI: transcoded.uri, MimeType: transcoded.mimeType}
+			}
+			doc.Images = append(doc.Images, image)
+			doc.Textures = append(doc.Textures, t)
+		}
+		return &gltfTextureInfo{Index: index}
+	}
+
+	p := ConvertMaterialToPBR(m)
+	material := gltfMaterial{
+		Name: p.Name,
+		PbrMetallicRoughness: gltfPBRMetallicRoughness{
+			BaseColorFactor:  p.BaseColor[:],
+			BaseColorTexture: texture(p.BaseColorTexture),
+			MetallicFactor:   p.Metallic,
+			RoughnessFactor:  p.Roughness,
+		},
+		NormalTexture:   texture(p.NormalTexture),
+		EmissiveTexture: texture(p.EmissiveTexture),
+	}
+	if p.Emissive != [3]float32{} {
+		material.EmissiveFactor = p.Emissive[:]
+	}
+	if p.Blend {
+		material.AlphaMode = "BLEND"
+	}
+	if p.DisplacementTexture != "" || p.DecalTexture != "" {
+		material.Extras = &gltfMaterialExtras{
+			DisplacementTexture: texture(p.DisplacementTexture),
+			DecalTexture:        texture(p.DecalTexture),
+		}
+	}
+	return material
+}
+
+// floatBounds returns the componentwise bounds of a list of 3D vectors.
+func floatBounds(values []float32) ([]float32, []float32) {
+	min := []float32{math.MaxFloat32, math.MaxFloat32, math.MaxFloat32}
+	max := []float32{-math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+	for i := 0; i < len(values); i += 3 {
+		for j := 0; j < 3; j++ {
+			if values[i+j] < min[j] {
+				min[j] = values[i+j]
+			}
+			if values[i+j] > max[j] {
+				max[j] = values[i+j]
+			}
+		}
+	}
+	return min, max
+}
@@ -0,0 +1,143 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	dmat4 "github.com/flywave/go3d/float64/mat4"
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScene_WriteGLTF_Cube_WritesPrimitivePerMaterial(t *testing.T) {
+	// Arrange
+	buffer := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "red")
+	buffer.F[0].Material = "blue"
+	buffer.Offset = dvec3.T{100, 200, 0}
+	scene := &Scene{Buffer: buffer, Materials: map[string]*Material{
+		"red": {Name: "red", Diffuse: []float32{1, 0, 0}, Opacity: 1, DiffuseTexture: "red.png"},
+	}}
+
+	// Act
+	var out bytes.Buffer
+	err := scene.WriteGLTF(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	var doc gltfDocument
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+	assert.Equal(t, "2.0", doc.Asset.Version)
+	assert.Equal(t, []float64{100, 200, 0}, doc.Nodes[0].Translation)
+	primitives := doc.Meshes[0].Primitives
+	if assert.Equal(t, 2, len(primitives)) {
+		assert.Equal(t, 6, doc.Accessors[primitives[0].Indices].Count)
+		assert.Equal(t, 30, doc.Accessors[primitives[1].Indices].Count)
+		assert.Nil(t, primitives[0].Material)
+		assert.Equal(t, 0, *primitives[1].Material)
+	}
+	position := doc.Accessors[primitives[0].Attributes["POSITION"]]
+	assert.Equal(t, 8, position.Count)
+	assert.Equal(t, []float32{0, 0, 0}, position.Min)
+	assert.Equal(t, []float32{1, 1, 1}, position.Max)
+	assert.NotContains(t, primitives[0].Attributes, "NORMAL")
+	assert.Equal(t, []float32{1, 0, 0, 1}, doc.Materials[0].PbrMetallicRoughness.BaseColorFactor)
+	assert.Equal(t, []gltfImage{{URI: "red.png"}}, doc.Images)
+	assert.True(t, strings.HasPrefix(doc.Buffers[0].URI, "data:application/octet-stream;base64,"))
+}
+
+func TestScene_WriteGLTF_NormDispDecal_MapsTextures(t *testing.T) {
+	// Arrange
+	buffer := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "brick")
+	mtls, err := ReadMaterialsFrom(strings.NewReader("newmtl brick\nbump brick_h.png\nnorm brick_n.png\ndisp brick_d.png\ndecal brick_s.png\n"), "brick.mtl")
+	assert.NoError(t, err)
+	scene := &Scene{Buffer: buffer, Materials: mtls}
+
+	// Act
+	var out bytes.Buffer
+	err = scene.WriteGLTF(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	var doc gltfDocument
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+	uri := func(info *gltfTextureInfo) string {
+		return doc.Images[doc.Textures[info.Index].Source].URI
+	}
+	material := doc.Materials[0]
+	assert.Equal(t, "brick_n.png", uri(material.NormalTexture))
+	if assert.NotNil(t, material.Extras) {
+		assert.Equal(t, "brick_d.png", uri(material.Extras.DisplacementTexture))
+		assert.Equal(t, "brick_s.png", uri(material.Extras.DecalTexture))
+	}
+}
+
+func TestScene_WriteGLB_WritesContainer(t *testing.T) {
+	// Arrange
+	scene := &Scene{Buffer: createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "red")}
+
+	// Act
+	var out bytes.Buffer
+	err := scene.WriteGLB(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	data := out.Bytes()
+	assert.Equal(t, "glTF", string(data[0:4]))
+	assert.Equal(t, uint32(2), binary.LittleEndian.Uint32(data[4:8]))
+	assert.Equal(t, uint32(len(data)), binary.LittleEndian.Uint32(data[8:12]))
+	jsonLength := binary.LittleEndian.Uint32(data[12:16])
+	assert.Equal(t, "JSON", string(data[16:20]))
+	var doc gltfDocument
+	assert.NoError(t, json.Unmarshal(data[20:20+jsonLength], &doc))
+	assert.Equal(t, "", doc.Buffers[0].URI)
+	bin := data[20+jsonLength:]
+	assert.Equal(t, "BIN\x00", string(bin[4:8]))
+	assert.Equal(t, doc.Buffers[0].ByteLength, int(binary.LittleEndian.Uint32(bin[0:4])))
+}
+
+func TestScene_WriteGLTF_NoFaces_WritesEmptyScene(t *testing.T) {
+	// Arrange
+	scene := &Scene{Buffer: &ObjBuffer{V: []vec3.T{{0, 0, 0}}}}
+
+	// Act
+	var out bytes.Buffer
+	err := scene.WriteGLTF(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	var doc gltfDocument
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+	assert.Empty(t, doc.Meshes)
+	assert.Empty(t, doc.Buffers)
+}
+
+func TestScene_WriteGLTF_Instances_ShareMesh(t *testing.T) {
+	// Arrange
+	bench := createTriangle()
+	bench.Offset = dvec3.T{1, 0, 0}
+	scene := &Scene{}
+	transform := dmat4.Ident
+	transform.SetTranslation(&dvec3.T{10, 0, 0})
+	scene.AddInstance("a", bench, dmat4.Ident)
+	scene.AddInstance("b", bench, transform)
+
+	// Act
+	var out bytes.Buffer
+	err := scene.WriteGLTF(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	var doc gltfDocument
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+	assert.Equal(t, 1, len(doc.Meshes))
+	if assert.Equal(t, 2, len(doc.Nodes)) {
+		assert.Equal(t, 0, *doc.Nodes[0].Mesh)
+		assert.Equal(t, 0, *doc.Nodes[1].Mesh)
+		assert.Equal(t, "b", doc.Nodes[1].Name)
+		assert.Equal(t, []float64{11, 0, 0, 1}, doc.Nodes[1].Matrix[12:])
+	}
+}
@@ -1,66 +1,254 @@
 package obj
 
+import (
+	"sort"
+	"strings"
+)
+
 func FillIntSlice(slice []int, val int) {
 	for i := 0; i < len(slice); i++ {
 		slice[i] = val
 	}
 }
 
-type faceGroup struct {
-	Offset int
-	Size   int
+// FaceGroup is a range of consecutive faces sharing a material.
+type FaceGroup struct {
+	Offset   int
+	Size     int
+	Material string
 }
 
-type group struct {
+// defaultGroupName names the group of the faces read before any "g"
+// statement. It is a single name, despite the space.
+const defaultGroupName = "default group"
+
+// Group is a range of consecutive faces declared by a "g" statement. Name
+// holds the statement as written; a face range may belong to several groups
+// at once, separated by whitespace.
+type Group struct {
 	Name           string
 	FirstFaceIndex int
 	FaceCount      int
 }
 
-func (g *group) buildBuffers(parentBuffer *ObjBuffer) *ObjBuffer {
-	buffer := new(ObjBuffer)
-	buffer.MTL = parentBuffer.MTL
-	buffer.G = []group{
-		group{
+// Names returns the names of the groups the face range belongs to. The
+// default group of the faces outside of any "g" statement has the single
+// name "default group".
+func (g *Group) Names() []string {
+	if g.Name == defaultGroupName {
+		return []string{defaultGroupName}
+	}
+	return strings.Fields(g.Name)
+}
+
+// HasName reports whether the face range belongs to the group name.
+func (g *Group) HasName(name string) bool {
+	for _, n := range g.Names() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GroupNames returns the distinct group names of the buffer in order of
+// first appearance.
+func (b *ObjBuffer) GroupNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for i := range b.G {
+		for _, name := range b.G[i].Names() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// FacesInGroup returns the indices of the faces belonging to the group name,
+// in ascending order.
+func (b *ObjBuffer) FacesInGroup(name string) []int {
+	var faces []int
+	for i := range b.G {
+		g := &b.G[i]
+		if !g.HasName(name) {
+			continue
+		}
+		for j := g.FirstFaceIndex; j < g.FirstFaceIndex+g.FaceCount; j++ {
+			faces = append(faces, j)
+		}
+	}
+	sort.Ints(faces)
+	return faces
+}
+
+// Group returns the first group declared with the given name, either as
+// its full name or as one of its names.
+func (b *ObjBuffer) Group(name string) (*Group, bool) {
+	for i := range b.G {
+		if g := &b.G[i]; g.Name == name || g.HasName(name) {
+			return g, true
+		}
+	}
+	return nil, false
+}
+
+// ExtractGroups copies the faces of the groups with any of the given names
+// into a new buffer holding only the elements they reference. The groups keep
+// their names and order.
+func (b *ObjBuffer) ExtractGroups(names ...string) *ObjBuffer {
+	var faces []int
+	var groups []Group
+	for i := range b.G {
+		g := &b.G[i]
+		if !g.hasAnyName(names) {
+			continue
+		}
+		groups = append(groups, Group{
+			Name:           g.Name,
+			FirstFaceIndex: len(faces),
+			FaceCount:      g.FaceCount,
+		})
+		for j := g.FirstFaceIndex; j < g.FirstFaceIndex+g.FaceCount; j++ {
+			faces = append(faces, j)
+		}
+	}
+	buffer := b.subset(faces)
+	buffer.G = groups
+	return buffer
+}
+
+func (g *Group) hasAnyName(names []string) bool {
+	for _, name := range names {
+		if g.Name == name || g.HasName(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Group) buildBuffers(parentBuffer *ObjBuffer) *ObjBuffer {
+	faces := make([]int, g.FaceCount)
+	for i := range faces {
+		faces[i] = g.FirstFaceIndex + i
+	}
+	buffer := parentBuffer.subset(faces)
+	buffer.G = []Group{
+		Group{
 			Name:      g.Name,
 			FaceCount: g.FaceCount,
 		},
 	}
+	return buffer
+}
 
-	vertexMapping := make([]int, len(parentBuffer.V))
+// subset copies the given faces, in order, into a new buffer. Only the
+// vertices, normals and texture coordinates referenced by the faces are
+// copied and the corners are remapped accordingly; indices that do not
+// reference an element are kept as they are.
+func (b *ObjBuffer) subset(faces []int) *ObjBuffer {
+	buffer := new(ObjBuffer)
+	buffer.MTL = b.MTL
+	buffer.Offset = b.Offset
+
+	double := b.hasDoublePrecision()
+	colors := b.hasVertexColors()
+	for name, a := range b.Attributes {
+		if buffer.Attributes == nil {
+			buffer.Attributes = map[string]AttributeBuffer{}
+		}
+		buffer.Attributes[name] = a.emptyCopy()
+	}
+	vertexMapping := make([]int, len(b.V))
 	FillIntSlice(vertexMapping, -1)
-	normalMapping := make([]int, len(parentBuffer.VN))
+	normalMapping := make([]int, len(b.VN))
 	FillIntSlice(normalMapping, -1)
+	texcoordMapping := make([]int, len(b.VT))
+	FillIntSlice(texcoordMapping, -1)
 
-	for i := g.FirstFaceIndex; i < g.FirstFaceIndex+g.FaceCount; i++ {
+	remap := func(corners []FaceCorner) []FaceCorner {
+		remapped := make([]FaceCorner, len(corners))
+		for j, c := range corners {
+			remapped[j].VertexIndex = remapIndex(vertexMapping, c.VertexIndex, func(idx int) int {
+				buffer.V = append(buffer.V, b.V[idx])
+				if double {
+					buffer.VD = append(buffer.VD, b.VD[idx])
+				}
+				if colors {
+					buffer.VC = append(buffer.VC, b.VC[idx])
+				}
+				for name, a := range b.Attributes {
+					buffer.Attributes[name] = buffer.Attributes[name].appendVertex(a, idx)
+				}
+				return len(buffer.V) - 1
+			})
+			remapped[j].NormalIndex = remapIndex(normalMapping, c.NormalIndex, func(idx int) int {
+				buffer.VN = append(buffer.VN, b.VN[idx])
+				return len(buffer.VN) - 1
+			})
+			remapped[j].TexcoordIndex = remapIndex(texcoordMapping, c.TexcoordIndex, func(idx int) int {
+				buffer.VT = append(buffer.VT, b.VT[idx])
+				return len(buffer.VT) - 1
+			})
+		}
+		return remapped
+	}
 
-		originalFace := parentBuffer.F[i]
+	for _, i := range faces {
+		originalFace := b.F[i]
 
-		f := face{Material: originalFace.Material}
-		f.Corners = make([]faceCorner, len(originalFace.Corners))
+		f := Face{Material: originalFace.Material, SmoothingGroup: originalFace.SmoothingGroup, Metadata: cloneMetadata(originalFace.Metadata)}
+		f.Corners = remap(originalFace.Corners)
+		for _, hole := range originalFace.Holes {
+			f.Holes = append(f.Holes, remap(hole))
+		}
 
-		for j, origCorner := range originalFace.Corners {
-			origVertIdx := origCorner.VertexIndex
-			origNormIdx := origCorner.NormalIndex
+		buffer.F = append(buffer.F, f)
+	}
+	buffer.FaceGroup = faceGroupsOf(buffer.F)
+	return buffer
+}
 
-			var newVertIdx int
-			if newVertIdx = vertexMapping[origVertIdx]; newVertIdx == -1 {
-				newVertIdx = len(buffer.V)
-				buffer.V = append(buffer.V, parentBuffer.V[origVertIdx])
-				vertexMapping[origVertIdx] = newVertIdx
-			}
+// remapIndex returns the new index of idx, calling add to copy the element
+// the first time it is referenced. Indices outside of mapping are returned
+// unchanged.
+func remapIndex(mapping []int, idx int, add func(idx int) int) int {
+	if idx < 0 || idx >= len(mapping) {
+		return idx
+	}
+	if mapping[idx] == -1 {
+		mapping[idx] = add(idx)
+	}
+	return mapping[idx]
+}
 
-			var newNormIdx int
-			if newNormIdx = normalMapping[origNormIdx]; newNormIdx == -1 {
-				newNormIdx = len(buffer.VN)
-				buffer.VN = append(buffer.VN, parentBuffer.VN[origNormIdx])
-				normalMapping[origNormIdx] = newNormIdx
+// groupOfFaces returns the index of the first group covering every face,
+// or -1 for faces outside all groups.
+func (b *ObjBuffer) groupOfFaces() []int {
+	groupOf := make([]int, len(b.F))
+	FillIntSlice(groupOf, -1)
+	for g := range b.G {
+		for i := b.G[g].FirstFaceIndex; i < b.G[g].FirstFaceIndex+b.G[g].FaceCount && i < len(b.F); i++ {
+			if i >= 0 && groupOf[i] == -1 {
+				groupOf[i] = g
 			}
-
-			f.Corners[j].VertexIndex, f.Corners[j].NormalIndex = newVertIdx, newNormIdx
 		}
+	}
+	return groupOf
+}
 
-		buffer.F = append(buffer.F, f)
+// faceGroupsOf returns the face groups of runs of faces sharing a material.
+func faceGroupsOf(faces []Face) []*FaceGroup {
+	var groups []*FaceGroup
+	for i := range faces {
+		n := len(groups)
+		if n > 0 && groups[n-1].Material == faces[i].Material {
+			groups[n-1].Size++
+			continue
+		}
+		groups = append(groups, &FaceGroup{Offset: i, Size: 1, Material: faces[i].Material})
 	}
-	return buffer
+	return groups
 }
@@ -1,15 +1,16 @@
 package obj
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/flywave/go3d/vec3"
 )
 
-func createFace(material string, cornerIdx ...int) face {
-	f := face{}
-	f.Corners = make([]faceCorner, len(cornerIdx))
+func createFace(material string, cornerIdx ...int) Face {
+	f := Face{}
+	f.Corners = make([]FaceCorner, len(cornerIdx))
 	for i := 0; i < len(cornerIdx); i++ {
 		f.Corners[i].VertexIndex = cornerIdx[i]
 		f.Corners[i].NormalIndex = cornerIdx[i]
@@ -20,7 +21,7 @@ func createFace(material string, cornerIdx ...int) face {
 
 func TestGroup_BuildFormats_EmptyGroup_ReturnsEmptyBuffer(t *testing.T) {
 	// Arrange
-	g := group{}
+	g := Group{}
 	origBuffer := ObjBuffer{}
 	origBuffer.MTL = "materials.mtl"
 
@@ -36,13 +37,13 @@ func TestGroup_BuildFormats_EmptyGroup_ReturnsEmptyBuffer(t *testing.T) {
 
 func TestGroup_BuildFormats_SingleGroupWithSingleFace_ReturnsCorrect(t *testing.T) {
 	// Arrange
-	g := group{}
+	g := Group{}
 	g.FirstFaceIndex = 0
 	g.FaceCount = 1
 
 	origBuffer := ObjBuffer{}
-	origBuffer.G = []group{g}
-	origBuffer.F = []face{
+	origBuffer.G = []Group{g}
+	origBuffer.F = []Face{
 		createFace("mat", 0, 1, 2),
 	}
 	origBuffer.V = []vec3.T{
@@ -69,7 +70,7 @@ func TestGroup_BuildFormats_SingleGroupWithSingleFace_ReturnsCorrect(t *testing.
 func TestGroup_BuildFormats_TwoGroupsWithTwoFaces_ReturnsCorrectGroups(t *testing.T) {
 	// Arrange
 	origBuffer := ObjBuffer{}
-	origBuffer.F = []face{
+	origBuffer.F = []Face{
 		// Group 1
 		createFace("mat1", 0, 2, 4),
 		createFace("mat2", 4, 2, 6),
@@ -98,9 +99,9 @@ func TestGroup_BuildFormats_TwoGroupsWithTwoFaces_ReturnsCorrectGroups(t *testin
 		vec3.T{-7, -7, -7},
 	}
 
-	g1 := group{Name: "Group 1", FirstFaceIndex: 0, FaceCount: 2}
-	g2 := group{Name: "Group 2", FirstFaceIndex: 2, FaceCount: 2}
-	origBuffer.G = []group{g1, g2}
+	g1 := Group{Name: "Group 1", FirstFaceIndex: 0, FaceCount: 2}
+	g2 := Group{Name: "Group 2", FirstFaceIndex: 2, FaceCount: 2}
+	origBuffer.G = []Group{g1, g2}
 
 	// Act
 	buffer := g1.buildBuffers(&origBuffer)
@@ -118,7 +119,7 @@ func TestGroup_BuildFormats_TwoGroupsWithTwoFaces_ReturnsCorrectGroups(t *testin
 		buffer.VN)
 	assert.Equal(t, 1, len(buffer.G))
 	assert.Equal(t,
-		group{Name: "Group 1", FirstFaceIndex: 0, FaceCount: 2},
+		Group{Name: "Group 1", FirstFaceIndex: 0, FaceCount: 2},
 		buffer.G[0])
 	assert.Equal(t, 2, len(buffer.F))
 	assert.Equal(t, "mat1", buffer.F[0].Material)
@@ -128,7 +129,7 @@ func TestGroup_BuildFormats_TwoGroupsWithTwoFaces_ReturnsCorrectGroups(t *testin
 func TestGroup_BuildFormats_GroupWithTwoFacesets_ReturnsCorrectSubset(t *testing.T) {
 	// Arrange
 	origBuffer := ObjBuffer{}
-	origBuffer.F = []face{
+	origBuffer.F = []Face{
 		// Group 1
 		createFace("Material 1", 0, 2, 4),
 		createFace("Material 1", 4, 2, 6),
@@ -159,9 +160,9 @@ func TestGroup_BuildFormats_GroupWithTwoFacesets_ReturnsCorrectSubset(t *testing
 		vec3.T{-7, -7, -7},
 	}
 
-	g1 := group{Name: "Group 1", FirstFaceIndex: 0, FaceCount: 4}
-	g2 := group{Name: "Group 2", FirstFaceIndex: 4, FaceCount: 2}
-	origBuffer.G = []group{g1, g2}
+	g1 := Group{Name: "Group 1", FirstFaceIndex: 0, FaceCount: 4}
+	g2 := Group{Name: "Group 2", FirstFaceIndex: 4, FaceCount: 2}
+	origBuffer.G = []Group{g1, g2}
 
 	// Act
 	buffer := g2.buildBuffers(&origBuffer)
@@ -177,9 +178,110 @@ func TestGroup_BuildFormats_GroupWithTwoFacesets_ReturnsCorrectSubset(t *testing
 			vec3.T{-5, -5, -5}, vec3.T{-7, -7, -7}, vec3.T{-2, -2, -2}, vec3.T{-4, -4, -4},
 		},
 		buffer.VN)
-	assert.EqualValues(t, []face{
+	assert.EqualValues(t, []Face{
 		createFace("Material 3", 0, 1, 2), // Remapped indices
 		createFace("Material 3", 1, 0, 3), // Remapped indices
 	}, buffer.F)
-	assert.EqualValues(t, []group{group{"Group 2", 0, 2}}, buffer.G)
+	assert.EqualValues(t, []Group{Group{"Group 2", 0, 2}}, buffer.G)
+}
+
+func TestGroup_Names_MultipleNames_ReturnsAll(t *testing.T) {
+	g := Group{Name: "body  wheel front"}
+
+	assert.Equal(t, []string{"body", "wheel", "front"}, g.Names())
+	assert.True(t, g.HasName("wheel"))
+	assert.False(t, g.HasName("wheel front"))
+	assert.False(t, g.HasName("rear"))
+}
+
+func TestObjBuffer_FacesInGroup_SharedMembership_ReturnsFaces(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\n" +
+		"g body\nf 1 2 3\n" +
+		"g body wheel front\nf 1 2 3\nf 1 2 3\n" +
+		"g wheel rear\nf 1 2 3\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+
+	// Act & Assert
+	assert.Equal(t, []int{0, 1, 2}, loader.FacesInGroup("body"))
+	assert.Equal(t, []int{1, 2, 3}, loader.FacesInGroup("wheel"))
+	assert.Equal(t, []int{1, 2}, loader.FacesInGroup("front"))
+	assert.Nil(t, loader.FacesInGroup("roof"))
+	assert.Equal(t, []string{"body", "wheel", "front", "rear"}, loader.GroupNames())
+}
+
+func TestObjBuffer_Group_ExistingName_ReturnsGroup(t *testing.T) {
+	// Arrange
+	buffer := ObjBuffer{}
+	buffer.G = []Group{
+		{Name: "roof", FirstFaceIndex: 0, FaceCount: 1},
+		{Name: "body wheel", FirstFaceIndex: 1, FaceCount: 2},
+	}
+
+	// Act
+	byName, okByName := buffer.Group("wheel")
+	byFullName, okByFullName := buffer.Group("body wheel")
+	_, okMissing := buffer.Group("door")
+
+	// Assert
+	assert.True(t, okByName)
+	assert.Equal(t, &buffer.G[1], byName)
+	assert.True(t, okByFullName)
+	assert.Equal(t, &buffer.G[1], byFullName)
+	assert.False(t, okMissing)
+}
+
+func TestObjBuffer_ExtractGroups_MergesSelectedGroups(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\nv 1 1 0\nv 2 2 0\n" +
+		"vt 0 0\nvt 1 0\nvt 0 1\nvt 1 1\n" +
+		"g roof\nusemtl tiles\nf 1/1 2/2 3/3\n" +
+		"g walls\nusemtl brick\nf 2 4 3\n" +
+		"g terrain\nusemtl grass\nf 3/3 4/4 5/1\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+
+	// Act
+	buffer := loader.ExtractGroups("roof", "terrain")
+
+	// Assert
+	assert.Equal(t, []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}, {2, 2, 0}}, buffer.V)
+	assert.Equal(t, 4, len(buffer.VT))
+	assert.Equal(t, []Group{
+		{Name: "roof", FirstFaceIndex: 0, FaceCount: 1},
+		{Name: "terrain", FirstFaceIndex: 1, FaceCount: 1},
+	}, buffer.G)
+	assert.Equal(t, 2, len(buffer.F))
+	assert.Equal(t, FaceCorner{VertexIndex: 2, NormalIndex: -1, TexcoordIndex: 2}, buffer.F[1].Corners[0])
+	assert.Equal(t, FaceCorner{VertexIndex: 4, NormalIndex: -1, TexcoordIndex: 0}, buffer.F[1].Corners[2])
+	assert.Equal(t, 2, len(buffer.FaceGroup))
+	assert.Equal(t, "grass", buffer.FaceGroup[1].Material)
+}
+
+func TestObjBuffer_ExtractGroups_UnknownName_ReturnsEmptyBuffer(t *testing.T) {
+	buffer := ObjBuffer{}
+	buffer.G = []Group{{Name: "roof", FirstFaceIndex: 0, FaceCount: 0}}
+
+	extracted := buffer.ExtractGroups("door")
+
+	assert.Equal(t, 0, len(extracted.F))
+	assert.Equal(t, 0, len(extracted.G))
+}
+
+func TestObjBuffer_FacesInGroup_DefaultGroup_IsSingleName(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\ng group\nf 1 3 2\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+
+	// Act
+	defaultFaces := loader.FacesInGroup("default group")
+	groupFaces := loader.FacesInGroup("group")
+
+	// Assert
+	assert.Equal(t, []int{0}, defaultFaces)
+	assert.Equal(t, []int{1}, groupFaces)
+	assert.Equal(t, []string{"default group", "group"}, loader.GroupNames())
+	assert.Empty(t, loader.FacesInGroup("default"))
 }
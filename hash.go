@@ -0,0 +1,187 @@
+package obj
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"math"
+	"sort"
+)
+
+// Hash returns a hex encoded SHA-256 digest of the content of the buffer:
+// the material library, the offset, the vertices with their colors and
+// attributes, the normals, texture coordinates, faces, lines and groups. Values are hashed
+// in binary, so the digest does not depend on how numbers were formatted in
+// the file the buffer was read from. Comments and recorded statements are
+// not part of the digest.
+func (b *ObjBuffer) Hash() string {
+	h := newContentHasher()
+	h.string(b.MTL)
+	h.floats64(b.Offset[:]...)
+
+	h.int(len(b.V))
+	for i := range b.V {
+		p := b.positionD(i)
+		h.floats64(p[:]...)
+	}
+	h.int(len(b.VC))
+	for _, c := range b.VC {
+		h.floats32(c[:]...)
+	}
+	h.int(len(b.Attributes))
+	for _, name := range b.attributeNames() {
+		a := b.Attributes[name]
+		h.string(name)
+		h.int(int(a.Type))
+		h.int(a.Size)
+		h.int(len(a.Floats))
+		h.floats64(a.Floats...)
+		h.int(len(a.Ints))
+		for _, v := range a.Ints {
+			h.uint64(uint64(v))
+		}
+	}
+	h.int(len(b.VN))
+	for _, n := range b.VN {
+		h.floats32(n[:]...)
+	}
+	h.int(len(b.VT))
+	for _, t := range b.VT {
+		h.floats32(t[:]...)
+	}
+	h.int(len(b.F))
+	for _, f := range b.F {
+		h.string(f.Material)
+		h.int(len(f.Corners))
+		for _, c := range f.Corners {
+			h.int(c.VertexIndex)
+			h.int(c.NormalIndex)
+			h.int(c.TexcoordIndex)
+		}
+		h.int(int(f.SmoothingGroup))
+		h.int(len(f.Holes))
+		for _, hole := range f.Holes {
+			h.int(len(hole))
+			for _, c := range hole {
+				h.int(c.VertexIndex)
+				h.int(c.NormalIndex)
+				h.int(c.TexcoordIndex)
+			}
+		}
+		h.int(len(f.Metadata))
+		for _, name := range f.metadataNames() {
+			h.string(name)
+			h.int(int(f.Metadata[name]))
+		}
+	}
+	h.int(len(b.L))
+	for _, l := range b.L {
+		h.string(l.Material)
+		h.int(len(l.Corners))
+		for _, c := range l.Corners {
+			h.int(c)
+		}
+	}
+	h.int(len(b.G))
+	for _, g := range b.G {
+		h.string(g.Name)
+		h.int(g.FirstFaceIndex)
+		h.int(g.FaceCount)
+	}
+	return h.sum()
+}
+
+// Hash returns a hex encoded SHA-256 digest of the properties of the
+// material.
+func (m *Material) Hash() string {
+	h := newContentHasher()
+	m.hashInto(h)
+	return h.sum()
+}
+
+// HashMaterials returns a hex encoded SHA-256 digest of a set of materials,
+// independent of the iteration order of the map.
+func HashMaterials(mtls map[string]*Material) string {
+	keys := make([]string, 0, len(mtls))
+	for k := range mtls {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := newContentHasher()
+	h.int(len(keys))
+	for _, k := range keys {
+		h.string(k)
+		mtls[k].hashInto(h)
+	}
+	return h.sum()
+}
+
+func (m *Material) hashInto(h *contentHasher) {
+	h.string(m.Name)
+	for _, color := range [][]float32{m.Ambient, m.Diffuse, m.Specular, m.Emissive, m.TransmissionFilter} {
+		h.int(len(color))
+		h.floats32(color...)
+	}
+	h.floats64(m.Shininess, m.Opacity)
+	for _, texture := range []string{m.AmbientTexture, m.DiffuseTexture, m.SpecularTexture, m.EmissiveTexture, m.AlphaTexture, m.BumpTexture, m.DisplacementTexture, m.DecalTexture, m.NormalTexture, m.RoughnessTexture, m.MetallicTexture, m.SheenTexture} {
+		h.string(texture)
+	}
+	for _, t := range m.ReflectionMap.textures() {
+		h.string(t[0])
+		h.string(t[1])
+	}
+	h.int(int(m.Illumination))
+	h.floats32(m.Roughness, m.Metallic, m.Sheen, m.ClearcoatThickness, m.ClearcoatRoughness, m.Anisotropy, m.AnisotropyRotation, m.OpticalDensity)
+}
+
+// contentHasher feeds values to a hash in a fixed binary layout. Strings and
+// lists are prefixed with their length so that adjacent values cannot be
+// confused.
+type contentHasher struct {
+	h   hash.Hash
+	buf [8]byte
+}
+
+func newContentHasher() *contentHasher {
+	return &contentHasher{h: sha256.New()}
+}
+
+func (h *contentHasher) uint64(v uint64) {
+	binary.LittleEndian.PutUint64(h.buf[:], v)
+	h.h.Write(h.buf[:])
+}
+
+func (h *contentHasher) int(v int) {
+	h.uint64(uint64(int64(v)))
+}
+
+func (h *contentHasher) string(s string) {
+	h.int(len(s))
+	h.h.Write([]byte(s))
+}
+
+// floats64 hashes the values, treating negative zero as zero and every NaN
+// alike.
+func (h *contentHasher) floats64(values ...float64) {
+	for _, v := range values {
+		switch {
+		case v == 0:
+			v = 0
+		case math.IsNaN(v):
+			v = math.NaN()
+		}
+		h.uint64(math.Float64bits(v))
+	}
+}
+
+func (h *contentHasher) floats32(values ...float32) {
+	for _, v := range values {
+		h.floats64(float64(v))
+	}
+}
+
+func (h *contentHasher) sum() string {
+	return hex.EncodeToString(h.h.Sum(nil))
+}
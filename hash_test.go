@@ -0,0 +1,57 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_Hash_DifferentFormatting_ReturnsSameHash(t *testing.T) {
+	// Arrange
+	a, b := ObjReader{}, ObjReader{}
+	assert.NoError(t, a.Read(strings.NewReader("v 1 0 0\nv 0 1 0\nv 0 0 1\nf 1 2 3\n")))
+	assert.NoError(t, b.Read(strings.NewReader("# exported again\nv 1.000 0.0 -0\nv 0e0 1 0\nv 0 0 1.0\n\nf 1 2 3 # face\n")))
+
+	// Act & Assert
+	assert.Equal(t, a.Hash(), b.Hash())
+	assert.Len(t, a.Hash(), 64)
+}
+
+func TestObjBuffer_Hash_ChangedContent_ReturnsDifferentHash(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader("v 1 0 0\nv 0 1 0\nv 0 0 1\nusemtl a\nf 1 2 3\n")))
+	original := loader.Hash()
+
+	// Act
+	loader.RenameMaterial("a", "b")
+
+	// Assert
+	assert.NotEqual(t, original, loader.Hash())
+}
+
+func TestHashMaterials_IndependentOfInsertionOrder(t *testing.T) {
+	// Arrange
+	a := map[string]*Material{}
+	b := map[string]*Material{}
+	for i, name := range []string{"brick", "glass", "roof", "steel", "wood"} {
+		a[name] = &Material{Name: name, Diffuse: []float32{float32(i), 0, 0, 1}}
+	}
+	for _, name := range []string{"wood", "steel", "roof", "glass", "brick"} {
+		b[name] = a[name]
+	}
+
+	// Act & Assert
+	assert.Equal(t, HashMaterials(a), HashMaterials(b))
+	assert.NotEqual(t, a["brick"].Hash(), a["glass"].Hash())
+}
+
+func TestMaterial_Hash_ChangedTexture_ReturnsDifferentHash(t *testing.T) {
+	m := Material{Name: "roof", DiffuseTexture: "roof.png"}
+	original := m.Hash()
+
+	m.DiffuseTexture = "roof2.png"
+
+	assert.NotEqual(t, original, m.Hash())
+}
@@ -0,0 +1,98 @@
+package obj
+
+import "fmt"
+
+// IlluminationModel is the illumination model of a material, set by the
+// illum statement of a material library. The models are those of the MTL
+// specification.
+type IlluminationModel uint32
+
+const (
+	// IllumColor shows the diffuse color only, without ambient light.
+	IllumColor IlluminationModel = iota
+	// IllumColorAmbient adds ambient light to the diffuse color.
+	IllumColorAmbient
+	// IllumHighlight adds specular highlights.
+	IllumHighlight
+	// IllumReflection adds ray traced reflections.
+	IllumReflection
+	// IllumGlass is transparent glass with ray traced reflections.
+	IllumGlass
+	// IllumFresnel adds ray traced Fresnel reflections.
+	IllumFresnel
+	// IllumRefraction is transparent with refraction and ray traced
+	// reflections, without Fresnel.
+	IllumRefraction
+	// IllumRefractionFresnel is transparent with refraction and ray traced
+	// Fresnel reflections.
+	IllumRefractionFresnel
+	// IllumReflectionNoRayTrace adds reflections from the reflection map
+	// only.
+	IllumReflectionNoRayTrace
+	// IllumGlassNoRayTrace is transparent glass with reflections from the
+	// reflection map only.
+	IllumGlassNoRayTrace
+	// IllumShadowMatte is invisible but receives shadows.
+	IllumShadowMatte
+
+	maxIlluminationModel = IllumShadowMatte
+)
+
+var illuminationModelNames = [...]string{
+	"color",
+	"color and ambient",
+	"highlight",
+	"reflection",
+	"glass",
+	"fresnel",
+	"refraction",
+	"refraction and fresnel",
+	"reflection without ray tracing",
+	"glass without ray tracing",
+	"shadow matte",
+}
+
+// Valid reports whether the model is one of the specification.
+func (m IlluminationModel) Valid() bool {
+	return m <= maxIlluminationModel
+}
+
+// String returns the description of the model.
+func (m IlluminationModel) String() string {
+	if !m.Valid() {
+		return fmt.Sprintf("illum %d", uint32(m))
+	}
+	return illuminationModelNames[m]
+}
+
+// Transparent reports whether the model renders the material transparent,
+// using its opacity and transmission filter.
+func (m IlluminationModel) Transparent() bool {
+	switch m {
+	case IllumGlass, IllumRefraction, IllumRefractionFresnel, IllumGlassNoRayTrace:
+		return true
+	}
+	return false
+}
+
+// Reflective reports whether the model renders reflections.
+func (m IlluminationModel) Reflective() bool {
+	return m >= IllumReflection && m <= IllumGlassNoRayTrace
+}
+
+// RayTraced reports whether the model ray traces reflections.
+func (m IlluminationModel) RayTraced() bool {
+	return m >= IllumReflection && m <= IllumRefractionFresnel
+}
+
+// IsTransparent reports whether the material lets light through: it is
+// not fully opaque, or its illumination model is transparent.
+func (m *Material) IsTransparent() bool {
+	return m.Opacity < 1 || m.Illumination.Transparent()
+}
+
+// UsesReflection reports whether the illumination model of the material
+// renders reflections.
+func (m *Material) UsesReflection() bool {
+	return m.Illumination.Reflective()
+}
@@ -0,0 +1,76 @@
+package obj
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadMaterialsFrom_Illum_SetsIlluminationModel(t *testing.T) {
+	// Arrange
+	input := "newmtl glass\nKd 1 1 1\nillum 4\nd 0.5\n" +
+		"newmtl matte\nillum 1\n" +
+		"newmtl mirror\nillum 3\nrefl -type sphere sky.png\n"
+
+	// Act
+	mtls, err := ReadMaterialsFrom(strings.NewReader(input), "test.mtl")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, IllumGlass, mtls["glass"].Illumination)
+	assert.True(t, mtls["glass"].IsTransparent())
+	assert.True(t, mtls["glass"].UsesReflection())
+	assert.Equal(t, IllumColorAmbient, mtls["matte"].Illumination)
+	assert.False(t, mtls["matte"].IsTransparent())
+	assert.False(t, mtls["matte"].UsesReflection())
+	assert.Equal(t, IllumReflection, mtls["mirror"].Illumination)
+	assert.True(t, mtls["mirror"].UsesReflection())
+}
+
+func TestReadMaterialsFrom_InvalidIllum_Fails(t *testing.T) {
+	for _, input := range []string{"newmtl a\nillum 11\n", "newmtl a\nillum two\n", "newmtl a\nillum\n"} {
+		// Act
+		_, err := ReadMaterialsFrom(strings.NewReader(input), "test.mtl")
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrBadMaterialStatement), input)
+	}
+}
+
+func TestWriteMaterialsTo_Illumination_RoundTrips(t *testing.T) {
+	// Arrange
+	mtls := map[string]*Material{"a": {Name: "a", Opacity: 1, Illumination: IllumRefractionFresnel}}
+
+	// Act
+	var out bytes.Buffer
+	err := WriteMaterialsTo(&out, mtls)
+
+	// Assert
+	assert.NoError(t, err)
+	read, err := ReadMaterialsFrom(strings.NewReader(out.String()), "out.mtl")
+	assert.NoError(t, err)
+	assert.Equal(t, IllumRefractionFresnel, read["a"].Illumination)
+}
+
+func TestWriteMaterialsTo_InvalidIllumination_Fails(t *testing.T) {
+	// Arrange
+	mtls := map[string]*Material{"a": {Name: "a", Opacity: 1, Illumination: 12}}
+
+	// Act
+	err := WriteMaterialsTo(&bytes.Buffer{}, mtls)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestIlluminationModel_String_DescribesModel(t *testing.T) {
+	assert.Equal(t, "glass without ray tracing", IllumGlassNoRayTrace.String())
+	assert.Equal(t, "illum 42", IlluminationModel(42).String())
+	assert.True(t, IllumShadowMatte.Valid())
+	assert.False(t, IllumShadowMatte.Reflective())
+	assert.True(t, IllumFresnel.RayTraced())
+	assert.False(t, IllumReflectionNoRayTrace.RayTraced())
+}
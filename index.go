@@ -0,0 +1,241 @@
+package obj
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+)
+
+// Index describes the content of an OBJ file without its geometry: how many
+// elements it declares, the materials it uses and where the faces of every
+// group are located.
+type Index struct {
+	// Counts holds the number of elements of each kind the file declares.
+	// It can be passed as ReadOptions.PreallocHint.
+	Counts PreallocHint
+	// Offset is the offset recorded in the "# offset" comment written by
+	// ObjBuffer.Write, if any.
+	Offset dvec3.T
+	// MTL is the material library of the file.
+	MTL string
+	// Materials lists the distinct materials used by "usemtl" statements,
+	// in order of first use.
+	Materials []string
+	// Groups lists the groups with faces, in order of first appearance.
+	// Like ObjBuffer.GroupNames, a "g" statement with several names adds
+	// its faces to every one of them.
+	Groups []IndexGroup
+
+	// marks holds the byte offsets of the lines declaring every
+	// indexStride-th vertex, normal and texture coordinate, so that
+	// LoadGroupAt does not have to read the file from its start to find
+	// them.
+	marks [3][]int64
+}
+
+// Kinds of elements in Index.marks.
+const (
+	markVertex = iota
+	markNormal
+	markTexCoord
+)
+
+// indexStride is the number of elements between two marks of an Index.
+const indexStride = 1024
+
+// IndexGroup locates the faces of a group name in an indexed file.
+type IndexGroup struct {
+	Name string
+	// Faces is the number of faces of the group.
+	Faces int
+	// Materials lists the distinct materials of the faces of the group.
+	Materials []string
+	// Ranges holds the parts of the file declaring faces of the group.
+	Ranges []ByteRange
+}
+
+// ByteRange is a part of an indexed file running from a "g" statement, or
+// from the start of the file, to the next "g" statement or the end of the
+// file.
+type ByteRange struct {
+	Offset int64
+	Size   int64
+	// Group is the name of the group as written in its "g" statement.
+	Group string
+	// Material is the material in use at the start of the range.
+	Material string
+	// SmoothingGroup is the smoothing group in use at the start of the
+	// range.
+	SmoothingGroup uint32
+	// Vertices, Normals and TexCoords are the number of elements of each
+	// kind declared before the range.
+	Vertices  int
+	Normals   int
+	TexCoords int
+}
+
+// Group returns the indexed group with the given name.
+func (idx *Index) Group(name string) (*IndexGroup, bool) {
+	for i := range idx.Groups {
+		if idx.Groups[i].Name == name {
+			return &idx.Groups[i], true
+		}
+	}
+	return nil, false
+}
+
+// indexRange is the range being scanned by ScanIndex.
+type indexRange struct {
+	ByteRange
+	faces     int
+	materials []string
+	// implicit is set for the range before the first "g" statement.
+	implicit bool
+}
+
+// ScanIndex reads an OBJ file and indexes it, without parsing or storing
+// its geometry. The ranges of the index can be parsed later on, to load
+// selected groups only.
+func ScanIndex(r io.Reader) (*Index, error) {
+	idx := &Index{}
+	var offset, next int64
+	scanner := bufio.NewScanner(r)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if advance > 0 {
+			offset, next = next, next+int64(advance)
+		}
+		return advance, token, err
+	})
+
+	groups := make(map[string]int)
+	usedMaterials := make(map[string]bool)
+	material := ""
+	smoothing := uint32(0)
+	current := &indexRange{ByteRange: ByteRange{Group: defaultGroupName}, implicit: true}
+	closeRange := func(end int64) {
+		current.Size = end - current.Offset
+		if current.faces == 0 {
+			return
+		}
+		names := strings.Fields(current.Group)
+		if current.implicit || len(names) == 0 {
+			// Faces outside of any "g" statement.
+			names = []string{current.Group}
+		}
+		for _, name := range names {
+			i, ok := groups[name]
+			if !ok {
+				i = len(idx.Groups)
+				groups[name] = i
+				idx.Groups = append(idx.Groups, IndexGroup{Name: name})
+			}
+			g := &idx.Groups[i]
+			g.Faces += current.faces
+			g.Ranges = append(g.Ranges, current.ByteRange)
+			for _, m := range current.materials {
+				g.Materials = appendMissing(g.Materials, m)
+			}
+		}
+	}
+
+	for scanner.Scan() {
+		line, keyword := splitStatement(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			if offset, ok := parseOffsetComment(line); ok {
+				idx.Offset = offset
+			}
+			continue
+		}
+		switch keyword {
+		case "v":
+			idx.mark(markVertex, idx.Counts.Vertices, offset)
+			idx.Counts.Vertices++
+		case "vn":
+			idx.mark(markNormal, idx.Counts.Normals, offset)
+			idx.Counts.Normals++
+		case "vt":
+			idx.mark(markTexCoord, idx.Counts.TexCoords, offset)
+			idx.Counts.TexCoords++
+		case "f":
+			idx.Counts.Faces++
+			current.faces++
+			current.materials = appendMissing(current.materials, material)
+		case "l":
+			idx.Counts.Lines++
+		case "g":
+			closeRange(offset)
+			current = &indexRange{ByteRange: ByteRange{
+				Offset:         offset,
+				Group:          strings.TrimSpace(line[1:]),
+				Material:       material,
+				SmoothingGroup: smoothing,
+				Vertices:       idx.Counts.Vertices,
+				Normals:        idx.Counts.Normals,
+				TexCoords:      idx.Counts.TexCoords,
+			}}
+		case "usemtl":
+			material = strings.TrimSpace(line[len(keyword):])
+			if material != "" && !usedMaterials[material] {
+				usedMaterials[material] = true
+				idx.Materials = append(idx.Materials, material)
+			}
+		case "s":
+			if fields := strings.Fields(line); len(fields) == 2 {
+				if group, err := strconv.ParseUint(fields[1], 10, 32); err == nil {
+					smoothing = uint32(group)
+				} else if strings.EqualFold(fields[1], "off") {
+					smoothing = 0
+				}
+			}
+		case "mtllib":
+			if idx.MTL == "" {
+				idx.MTL = strings.TrimSpace(line[len(keyword):])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	closeRange(next)
+	return idx, nil
+}
+
+// mark records the offset of the line declaring element i of the given kind
+// if it starts a stride.
+func (idx *Index) mark(kind, i int, offset int64) {
+	if i%indexStride == 0 {
+		idx.marks[kind] = append(idx.marks[kind], offset)
+	}
+}
+
+// splitStatement returns the statement of the line raw without its trailing
+// comment, and its keyword in lower case. Comment lines are returned whole
+// with an empty keyword.
+func splitStatement(raw string) (line, keyword string) {
+	line = strings.TrimSpace(raw)
+	if strings.HasPrefix(line, "#") {
+		return line, ""
+	}
+	if hashPos := strings.IndexRune(line, '#'); hashPos != -1 {
+		line = strings.TrimSpace(line[:hashPos])
+	}
+	keyword = line
+	if n := strings.IndexAny(line, " \t"); n != -1 {
+		keyword = line[:n]
+	}
+	return line, strings.ToLower(keyword)
+}
+
+// appendMissing appends s to list unless it already holds it.
+func appendMissing(list []string, s string) []string {
+	for _, l := range list {
+		if l == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,65 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const indexInput = "g first second\nf 1 2 3\n# scene\nmtllib scene.mtl\nv 0 0 0\nv 1 0 0\nv 0 1 0\nvn 0 0 1\n" +
+	"g roads\nusemtl asphalt\nf 1//1 2//1 3//1\n" +
+	"g terrain ground\r\nvt 0 0\nusemtl grass\nf 3 2 1\nf 1 3 2\nl 1 2\n" +
+	"g roads\nusemtl paint\nf 2 1 3\n" +
+	"g empty\n"
+
+func TestScanIndex_CountsElementsAndLocatesGroups(t *testing.T) {
+	// Act
+	idx, err := ScanIndex(strings.NewReader(indexInput))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, PreallocHint{Vertices: 3, Normals: 1, TexCoords: 1, Faces: 5, Lines: 1}, idx.Counts)
+	assert.Equal(t, "scene.mtl", idx.MTL)
+	assert.Equal(t, []string{"asphalt", "grass", "paint"}, idx.Materials)
+	var names []string
+	for _, g := range idx.Groups {
+		names = append(names, g.Name)
+	}
+	assert.Equal(t, []string{"first", "second", "roads", "terrain", "ground"}, names)
+
+	roads, ok := idx.Group("roads")
+	assert.True(t, ok)
+	assert.Equal(t, 2, roads.Faces)
+	assert.Equal(t, []string{"asphalt", "paint"}, roads.Materials)
+	if assert.Len(t, roads.Ranges, 2) {
+		first := roads.Ranges[0]
+		assert.Equal(t, "g roads\nusemtl asphalt\nf 1//1 2//1 3//1\n",
+			indexInput[first.Offset:first.Offset+first.Size])
+		second := roads.Ranges[1]
+		assert.Equal(t, ByteRange{Offset: second.Offset, Size: second.Size, Group: "roads",
+			Material: "grass", Vertices: 3, Normals: 1, TexCoords: 1}, second)
+		assert.Equal(t, "g roads\nusemtl paint\nf 2 1 3\n", indexInput[second.Offset:second.Offset+second.Size])
+	}
+
+	ground, ok := idx.Group("ground")
+	assert.True(t, ok)
+	assert.Equal(t, "terrain ground", ground.Ranges[0].Group)
+	assert.Equal(t, 2, ground.Faces)
+	_, ok = idx.Group("empty")
+	assert.False(t, ok)
+}
+
+func TestScanIndex_FacesBeforeGroups_IndexesDefaultGroup(t *testing.T) {
+	// Act
+	idx, err := ScanIndex(strings.NewReader("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3"))
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, idx.Groups, 1) {
+		assert.Equal(t, "default group", idx.Groups[0].Name)
+		assert.Equal(t, []string{""}, idx.Groups[0].Materials)
+		assert.Equal(t, int64(0), idx.Groups[0].Ranges[0].Offset)
+		assert.Equal(t, int64(31), idx.Groups[0].Ranges[0].Size)
+	}
+}
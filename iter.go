@@ -0,0 +1,93 @@
+//go:build go1.23
+
+package obj
+
+import (
+	"iter"
+
+	"github.com/flywave/go3d/vec3"
+)
+
+// Vertices returns a sequence of the vertex indices and positions.
+func (b *ObjBuffer) Vertices() iter.Seq2[int, vec3.T] {
+	return func(yield func(int, vec3.T) bool) {
+		for i, v := range b.V {
+			if !yield(i, v) {
+				return
+			}
+		}
+	}
+}
+
+// Faces returns a sequence of the face indices and faces.
+func (b *ObjBuffer) Faces() iter.Seq2[int, *Face] {
+	return func(yield func(int, *Face) bool) {
+		for i := range b.F {
+			if !yield(i, &b.F[i]) {
+				return
+			}
+		}
+	}
+}
+
+// GroupFaces returns a sequence of the faces belonging to the group name.
+func (b *ObjBuffer) GroupFaces(name string) iter.Seq2[int, *Face] {
+	return func(yield func(int, *Face) bool) {
+		for i := range b.G {
+			g := &b.G[i]
+			if !g.HasName(name) {
+				continue
+			}
+			for j := g.FirstFaceIndex; j < g.FirstFaceIndex+g.FaceCount; j++ {
+				if !yield(j, &b.F[j]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// MaterialFaces returns a sequence of the faces using material.
+func (b *ObjBuffer) MaterialFaces(material string) iter.Seq2[int, *Face] {
+	return func(yield func(int, *Face) bool) {
+		for i := range b.F {
+			if b.F[i].Material == material && !yield(i, &b.F[i]) {
+				return
+			}
+		}
+	}
+}
+
+// TrianglesSeq returns a sequence of the triangles of all faces, which are
+// triangulated one at a time as the sequence is consumed.
+func (b *ObjBuffer) TrianglesSeq() iter.Seq[Triangle] {
+	return b.TrianglesOf(b.Faces())
+}
+
+// TrianglesOf returns a sequence of the triangles of the given faces, such
+// as those returned by GroupFaces or MaterialFaces.
+func (b *ObjBuffer) TrianglesOf(faces iter.Seq2[int, *Face]) iter.Seq[Triangle] {
+	return func(yield func(Triangle) bool) {
+		stopped := false
+		emit := func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+			if !yield(Triangle{tri, corners, faceIdx}) {
+				stopped = true
+			}
+			return !stopped
+		}
+		for i, f := range faces {
+			if len(f.Corners) == 3 && len(f.Holes) == 0 {
+				b.emitTriangle(emit, f.Corners, i)
+			} else if len(f.Corners) > 3 {
+				for _, t := range f.Triangulate(b.V) {
+					if !b.emitTriangle(emit, t, i) {
+						break
+					}
+				}
+			}
+			if stopped {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+//go:build go1.23
+
+package obj
+
+import (
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+const iterTestObj = "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\n" +
+	"g roof\nusemtl tiles\nf 1 2 3 4\n" +
+	"g walls\nusemtl brick\nf 1 2 3\nusemtl tiles\nf 1 3 4\n"
+
+func TestObjBuffer_Vertices_YieldsAllVertices(t *testing.T) {
+	loader := readTestObj(t, iterTestObj, ReadOptions{})
+
+	var vertices []vec3.T
+	for i, v := range loader.Vertices() {
+		assert.Equal(t, len(vertices), i)
+		vertices = append(vertices, v)
+	}
+
+	assert.Equal(t, loader.V, vertices)
+}
+
+func TestObjBuffer_GroupFaces_YieldsGroupMembers(t *testing.T) {
+	loader := readTestObj(t, iterTestObj, ReadOptions{})
+
+	var faces []int
+	for i := range loader.GroupFaces("walls") {
+		faces = append(faces, i)
+	}
+
+	assert.Equal(t, []int{1, 2}, faces)
+}
+
+func TestObjBuffer_TrianglesOf_ComposesWithMaterialFilter(t *testing.T) {
+	loader := readTestObj(t, iterTestObj, ReadOptions{})
+
+	var faces []int
+	for tri := range loader.TrianglesOf(loader.MaterialFaces("tiles")) {
+		faces = append(faces, tri.Face)
+	}
+
+	assert.Equal(t, []int{0, 0, 2}, faces)
+}
+
+func TestObjBuffer_TrianglesSeq_Break_StopsIteration(t *testing.T) {
+	loader := readTestObj(t, iterTestObj, ReadOptions{})
+
+	count := 0
+	for range loader.TrianglesSeq() {
+		count++
+		if count == 1 {
+			break
+		}
+	}
+
+	assert.Equal(t, 1, count)
+}
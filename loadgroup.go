@@ -0,0 +1,192 @@
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// LoadGroupAt loads the faces of the group groupName of the file indexed by
+// ScanIndex, reading only the ranges of the file declaring them and the
+// vertices, normals and texture coordinates they reference. Like
+// ExtractGroups, the faces keep the names of their groups and reference
+// the elements loaded.
+func LoadGroupAt(ra io.ReaderAt, idx *Index, groupName string) (*ObjBuffer, error) {
+	group, ok := idx.Group(groupName)
+	if !ok {
+		return nil, fmt.Errorf("Group '%s' is not indexed", groupName)
+	}
+	loader := &ObjReader{}
+	loader.MTL = idx.MTL
+	loader.Offset = idx.Offset
+	for _, r := range group.Ranges {
+		if err := loader.loadRange(ra, r); err != nil {
+			return nil, err
+		}
+	}
+	if err := loader.loadReferenced(ra, idx); err != nil {
+		return nil, err
+	}
+	loader.FaceGroup = faceGroupsOf(loader.F)
+	return &loader.ObjBuffer, nil
+}
+
+// loadRange reads the faces declared in the range r of the file, with
+// references to the elements of the whole file, and adds a group for them.
+func (l *ObjReader) loadRange(ra io.ReaderAt, r ByteRange) error {
+	counts := elementCounts{r.Vertices, r.TexCoords, r.Normals}
+	material := r.Material
+	l.smoothingGroup = r.SmoothingGroup
+	first := len(l.F)
+	l.metadataFace = 0
+	scanner := bufio.NewScanner(io.NewSectionReader(ra, r.Offset, r.Size))
+	for scanner.Scan() {
+		line, keyword := splitStatement(scanner.Text())
+		if keyword == "" {
+			if strings.HasPrefix(line, "#") {
+				l.processMetadataComment(0, line)
+			}
+			continue
+		}
+		l.metadataFace = 0
+		switch keyword {
+		case "v":
+			counts.v++
+		case "vn":
+			counts.vn++
+		case "vt":
+			counts.vt++
+		case "usemtl":
+			material = strings.TrimSpace(line[len(keyword):])
+		case "s":
+			if err := l.processSmoothingGroup(strings.Fields(line)[1:]); err != nil {
+				return err
+			}
+		case "f":
+			fields := strings.Fields(line)[1:]
+			if len(fields) < 3 {
+				return badStatement(ErrBadFace, "Expected %d fields, but got %d", 3, len(fields))
+			}
+			f := Face{Corners: make([]FaceCorner, len(fields)), Material: material, SmoothingGroup: l.smoothingGroup}
+			for i, field := range fields {
+				corner, err := parseFaceField(field, counts)
+				if err != nil {
+					return atField(err, i)
+				}
+				f.Corners[i] = corner
+			}
+			l.F = append(l.F, f)
+			l.metadataFace = len(l.F)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(l.F) > first {
+		l.G = append(l.G, Group{Name: r.Group, FirstFaceIndex: first, FaceCount: len(l.F) - first})
+	}
+	l.metadataFace = 0
+	return nil
+}
+
+// loadReferenced reads the vertices, normals and texture coordinates the
+// faces reference, and remaps the faces to them.
+func (l *ObjReader) loadReferenced(ra io.ReaderAt, idx *Index) error {
+	vertices := make(map[int]int)
+	normals := make(map[int]int)
+	texcoords := make(map[int]int)
+	for _, f := range l.F {
+		for _, c := range f.Corners {
+			vertices[c.VertexIndex] = -1
+			if c.NormalIndex >= 0 {
+				normals[c.NormalIndex] = -1
+			}
+			if c.TexcoordIndex >= 0 {
+				texcoords[c.TexcoordIndex] = -1
+			}
+		}
+	}
+	err := FirstError(
+		l.loadElements(ra, idx.marks[markVertex], "v", "Vertex", vertices, func(fields []string) (int, error) {
+			err := l.processVertex(fields)
+			return len(l.V) - 1, err
+		}),
+		l.loadElements(ra, idx.marks[markNormal], "vn", "Normal", normals, func(fields []string) (int, error) {
+			err := l.processVertexNormal(fields)
+			return len(l.VN) - 1, err
+		}),
+		l.loadElements(ra, idx.marks[markTexCoord], "vt", "Texture coordinate", texcoords, func(fields []string) (int, error) {
+			err := l.processVertexTexCoord(fields)
+			return len(l.VT) - 1, err
+		}))
+	if err != nil {
+		return err
+	}
+	for _, f := range l.F {
+		for j := range f.Corners {
+			c := &f.Corners[j]
+			c.VertexIndex = vertices[c.VertexIndex]
+			if c.NormalIndex >= 0 {
+				c.NormalIndex = normals[c.NormalIndex]
+			}
+			if c.TexcoordIndex >= 0 {
+				c.TexcoordIndex = texcoords[c.TexcoordIndex]
+			}
+		}
+	}
+	return nil
+}
+
+// loadElements reads the elements declared by keyword statements whose
+// indices are the keys of mapping, in order, and maps them to the indices
+// returned by parse. marks holds the offsets of every indexStride-th
+// statement, where reading starts instead of at the start of the file.
+func (l *ObjReader) loadElements(ra io.ReaderAt, marks []int64, keyword, what string, mapping map[int]int, parse func(fields []string) (int, error)) error {
+	needed := make([]int, 0, len(mapping))
+	for i := range mapping {
+		needed = append(needed, i)
+	}
+	sort.Ints(needed)
+
+	var scanner *bufio.Scanner
+	next := 0
+	for _, i := range needed {
+		if i < 0 {
+			return badStatement(ErrBadIndex, "%s %d is not defined", what, i+1)
+		}
+		if block := i / indexStride; scanner == nil || block < len(marks) && block*indexStride > next {
+			start := int64(0)
+			next = 0
+			if block < len(marks) {
+				start, next = marks[block], block*indexStride
+			}
+			scanner = bufio.NewScanner(io.NewSectionReader(ra, start, math.MaxInt64-start))
+		}
+		found := false
+		for !found && scanner.Scan() {
+			line, k := splitStatement(scanner.Text())
+			if k != keyword {
+				continue
+			}
+			if next == i {
+				j, err := parse(strings.Fields(line)[1:])
+				if err != nil {
+					return err
+				}
+				mapping[i] = j
+				found = true
+			}
+			next++
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		if !found {
+			return badStatement(ErrBadIndex, "%s %d is not defined", what, i+1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package obj
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// createStripFile returns an OBJ file with a strip of n quads, split into
+// the groups "even" and "odd" by alternating quads.
+func createStripFile(n int) string {
+	var s strings.Builder
+	s.WriteString("mtllib strip.mtl\n")
+	for i := 0; i <= n; i++ {
+		fmt.Fprintf(&s, "v %d 0 0\nv %d 1 0\n", i, i)
+	}
+	s.WriteString("vn 0 0 1\n")
+	for i := 0; i < n; i++ {
+		group := "even"
+		if i%2 == 1 {
+			group = "odd"
+		}
+		fmt.Fprintf(&s, "g %s\nusemtl %s\nf %d//1 %d//1 %d//1 %d//1\n#fm quad=%d\n",
+			group, group, 2*i+1, 2*i+3, 2*i+4, 2*i+2, i)
+	}
+	return s.String()
+}
+
+func TestLoadGroupAt_MatchesExtractGroups(t *testing.T) {
+	// Arrange
+	input := createStripFile(1500)
+	idx, err := ScanIndex(strings.NewReader(input))
+	assert.NoError(t, err)
+	loader := ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+	expected := loader.ExtractGroups("odd")
+
+	// Act
+	b, err := LoadGroupAt(strings.NewReader(input), idx, "odd")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "strip.mtl", b.MTL)
+	assert.Equal(t, []string{"odd"}, b.GroupNames())
+	assert.Len(t, b.F, 750)
+	assert.Len(t, b.V, len(expected.V))
+	assert.Len(t, b.VN, 1)
+	for i := range b.F {
+		assert.Equal(t, "odd", b.F[i].Material)
+		assert.Equal(t, map[string]uint32{"quad": uint32(2*i + 1)}, b.F[i].Metadata)
+		for j, c := range b.F[i].Corners {
+			e := expected.F[i].Corners[j]
+			assert.Equal(t, expected.V[e.VertexIndex], b.V[c.VertexIndex])
+			assert.Equal(t, 0, c.NormalIndex)
+		}
+	}
+}
+
+func TestLoadGroupAt_RelativeIndices_ResolvesAgainstRange(t *testing.T) {
+	// Arrange
+	input := "g a\nv 0 0 0\nv 1 0 0\nv 0 1 0\nf -3 -2 -1\n" +
+		"g b\nv 5 5 5\nvt 0.5 0.5\nf -4/-1 -1/-1 -2/-1\n"
+	idx, err := ScanIndex(strings.NewReader(input))
+	assert.NoError(t, err)
+
+	// Act
+	b, err := LoadGroupAt(strings.NewReader(input), idx, "b")
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, b.F, 1) {
+		var positions []float32
+		for _, c := range b.F[0].Corners {
+			positions = append(positions, b.V[c.VertexIndex][0])
+			assert.Equal(t, 0, c.TexcoordIndex)
+		}
+		assert.Equal(t, []float32{0, 5, 0}, positions)
+	}
+	assert.Len(t, b.V, 3)
+}
+
+func TestLoadGroupAt_UndefinedVertex_ReturnsError(t *testing.T) {
+	// Arrange
+	input := "v 0 0 0\ng a\nf 1 2 3\n"
+	idx, err := ScanIndex(strings.NewReader(input))
+	assert.NoError(t, err)
+
+	// Act
+	_, errMissing := LoadGroupAt(strings.NewReader(input), idx, "a")
+	_, errUnknown := LoadGroupAt(strings.NewReader(input), idx, "b")
+
+	// Assert
+	assert.True(t, errors.Is(errMissing, ErrBadIndex))
+	assert.EqualError(t, errUnknown, "Group 'b' is not indexed")
+}
@@ -0,0 +1,189 @@
+package obj
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/flywave/go3d/vec3"
+)
+
+// StatementKind identifies the element a recorded statement declares.
+type StatementKind int
+
+const (
+	// StatementRaw is a line that is replayed verbatim, such as a comment,
+	// a group, a material or an unknown keyword.
+	StatementRaw StatementKind = iota
+	StatementVertex
+	StatementNormal
+	StatementTexCoord
+	StatementFace
+	StatementLine
+)
+
+// Statement is a line of an OBJ file recorded in lossless mode.
+type Statement struct {
+	Kind StatementKind
+	// Index is the index of the declared element in V, VN, VT, F or L.
+	Index int
+	// Raw is the line as read, without the line terminator.
+	Raw string
+}
+
+var errStatementsOutOfDate = errors.New("recorded statements do not match the buffer")
+
+// writeStatements replays the recorded statements. An element statement is
+// written as read unless its element was changed since, in which case the
+// current value is written instead. Statements other than elements are
+// always written as read.
+func (b *ObjBuffer) writeStatements(w io.Writer) error {
+	if err := b.checkStatements(); err != nil {
+		return err
+	}
+	var counts elementCounts
+	for _, s := range b.Statements {
+		text := s.Raw
+		switch s.Kind {
+		case StatementVertex:
+			counts.v++
+			if !b.vertexMatches(s.Index, s.Raw) {
+				text = b.formatVertex(s.Index, nil)
+			}
+		case StatementNormal:
+			counts.vn++
+			if !vectorMatches(b.VN[s.Index][:], s.Raw) {
+				vn := b.VN[s.Index]
+				text = fmt.Sprintf("vn %g %g %g", vn[0], vn[1], vn[2])
+			}
+		case StatementTexCoord:
+			counts.vt++
+			if !vectorMatches(b.VT[s.Index][:], s.Raw) {
+				vt := b.VT[s.Index]
+				text = fmt.Sprintf("vt %g %g", vt[0], vt[1])
+			}
+		case StatementFace:
+			if !faceMatches(&b.F[s.Index], s.Raw, counts) {
+				var sb strings.Builder
+				writeFace(&sb, b.F[s.Index], nil)
+				text = strings.TrimSuffix(sb.String(), "\n")
+			}
+		case StatementLine:
+			if !lineMatches(&b.L[s.Index], s.Raw, counts.v) {
+				var sb strings.Builder
+				writeLine(&sb, b.L[s.Index], nil)
+				text = strings.TrimSuffix(sb.String(), "\n")
+			}
+		}
+		if _, err := io.WriteString(w, text+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStatements verifies that the recorded statements declare exactly the
+// elements of the buffer, which no longer holds once elements were added or
+// removed after reading.
+func (b *ObjBuffer) checkStatements() error {
+	var counts [StatementLine + 1]int
+	for _, s := range b.Statements {
+		if s.Kind != StatementRaw && s.Index != counts[s.Kind] {
+			return errStatementsOutOfDate
+		}
+		counts[s.Kind]++
+	}
+	if counts[StatementVertex] != len(b.V) || counts[StatementNormal] != len(b.VN) ||
+		counts[StatementTexCoord] != len(b.VT) || counts[StatementFace] != len(b.F) ||
+		counts[StatementLine] != len(b.L) {
+		return errStatementsOutOfDate
+	}
+	return nil
+}
+
+// statementFields returns the arguments of a statement, without keyword and
+// trailing comment.
+func statementFields(raw string) []string {
+	if hashPos := strings.IndexRune(raw, '#'); hashPos != -1 {
+		raw = raw[0:hashPos]
+	}
+	fields := strings.Fields(raw)
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields[1:]
+}
+
+func (b *ObjBuffer) vertexMatches(i int, raw string) bool {
+	if b.hasVertexColors() && !colorMatches(b.VC[i], raw) {
+		return false
+	}
+	if !b.hasDoublePrecision() {
+		return vectorMatches(b.V[i][:], raw)
+	}
+	fields := statementFields(raw)
+	if len(fields) < 3 {
+		return false
+	}
+	for j := 0; j < 3; j++ {
+		f, err := strconv.ParseFloat(fields[j], 64)
+		if err != nil || f != b.VD[i][j] {
+			return false
+		}
+	}
+	return true
+}
+
+// colorMatches reports whether a vertex statement declares color c, an
+// omitted color being white.
+func colorMatches(c vec3.T, raw string) bool {
+	fields := statementFields(raw)
+	if len(fields) != 6 {
+		return c == vec3.T{1, 1, 1}
+	}
+	return vectorMatches(c[:], strings.Join(append([]string{"v"}, fields[3:]...), " "))
+}
+
+func vectorMatches(v []float32, raw string) bool {
+	fields := statementFields(raw)
+	if len(fields) < len(v) {
+		return false
+	}
+	for j := range v {
+		f, err := strconv.ParseFloat(fields[j], 32)
+		if err != nil || float32(f) != v[j] {
+			return false
+		}
+	}
+	return true
+}
+
+func faceMatches(f *Face, raw string, counts elementCounts) bool {
+	fields := statementFields(raw)
+	if len(fields) != len(f.Corners) {
+		return false
+	}
+	for j, field := range fields {
+		corner, err := parseFaceField(field, counts)
+		if err != nil || corner != f.Corners[j] {
+			return false
+		}
+	}
+	return true
+}
+
+func lineMatches(l *line, raw string, vertices int) bool {
+	fields := statementFields(raw)
+	if len(fields) != len(l.Corners) {
+		return false
+	}
+	for j, field := range fields {
+		corner, err := parseIndex(field, vertices)
+		if err != nil || corner != l.Corners[j] {
+			return false
+		}
+	}
+	return true
+}
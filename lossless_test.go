@@ -0,0 +1,82 @@
+package obj
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+const losslessTestObj = `# building.obj
+mtllib building.mtl
+o Building_01
+v 1.000000 0.000000 0.000000
+v 0.000000 1.000000 0.000000
+v 0.000000 0.000000 1.000000  # apex
+vn 0 0 1
+
+g walls
+usemtl brick
+s 1
+f 1//1 2//1 3//1
+x_custom 1 2 3
+l 1 2
+`
+
+func TestObjBuffer_WriteWith_Lossless_ReproducesInput(t *testing.T) {
+	// Arrange
+	loader := readTestObj(t, losslessTestObj, ReadOptions{Lossless: true})
+
+	// Act
+	var out bytes.Buffer
+	err := loader.WriteWith(&out, WriteOptions{Lossless: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, losslessTestObj, out.String())
+}
+
+func TestObjBuffer_WriteWith_Lossless_PatchesChangedElementsOnly(t *testing.T) {
+	// Arrange
+	loader := readTestObj(t, losslessTestObj, ReadOptions{Lossless: true})
+	loader.V[1] = vec3.T{0, 2, 0}
+	loader.F[0].Corners[0].NormalIndex = -1
+
+	// Act
+	var out bytes.Buffer
+	err := loader.WriteWith(&out, WriteOptions{Lossless: true})
+
+	// Assert
+	assert.NoError(t, err)
+	expected := strings.Replace(losslessTestObj, "v 0.000000 1.000000 0.000000", "v 0 2 0", 1)
+	expected = strings.Replace(expected, "f 1//1 2//1 3//1", "f 1 2//1 3//1", 1)
+	assert.Equal(t, expected, out.String())
+}
+
+func TestObjBuffer_WriteWith_Lossless_StructuralChange_ReturnsError(t *testing.T) {
+	// Arrange
+	loader := readTestObj(t, losslessTestObj, ReadOptions{Lossless: true})
+	loader.V = append(loader.V, vec3.T{5, 5, 5})
+
+	// Act
+	err := loader.WriteWith(&bytes.Buffer{}, WriteOptions{Lossless: true})
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestObjBuffer_WriteWith_Lossless_KeepsRelativeIndices(t *testing.T) {
+	// Arrange
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\nf -3 -2 -1\nv 1 1 0\nf -3 -1 -2\nl -1 -4\n"
+	loader := readTestObj(t, input, ReadOptions{Lossless: true})
+
+	// Act
+	var out bytes.Buffer
+	err := loader.WriteWith(&out, WriteOptions{Lossless: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, input, out.String())
+}
@@ -4,8 +4,10 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"math"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -24,8 +26,22 @@ type Material struct {
 	EmissiveTexture    string
 	AlphaTexture       string
 	BumpTexture        string
-	Opacity            float64
-	Illumination       uint32
+	// DisplacementTexture, DecalTexture and NormalTexture are set by the
+	// disp, decal and norm statements. NormalTexture is a tangent space
+	// normal map, unlike the height map of BumpTexture.
+	DisplacementTexture string
+	DecalTexture        string
+	NormalTexture       string
+	// RoughnessTexture, MetallicTexture and SheenTexture are set by the
+	// map_Pr, map_Pm and map_Ps statements, the texture maps of the PBR
+	// extension.
+	RoughnessTexture string
+	MetallicTexture  string
+	SheenTexture     string
+	Opacity          float64
+	// OpticalDensity is the index of refraction, 0 if not specified.
+	OpticalDensity     float32
+	Illumination       IlluminationModel
 	Roughness          float32
 	Metallic           float32
 	Sheen              float32
@@ -33,6 +49,68 @@ type Material struct {
 	ClearcoatRoughness float32
 	Anisotropy         float32
 	AnisotropyRotation float32
+	// ReflectionMap holds the textures of the refl statements.
+	ReflectionMap ReflectionMap
+}
+
+// ReflectionMap is the environment a material reflects, either a sphere
+// map or a cube map given as six textures, one per face of the cube.
+type ReflectionMap struct {
+	Sphere     string
+	CubeTop    string
+	CubeBottom string
+	CubeFront  string
+	CubeBack   string
+	CubeLeft   string
+	CubeRight  string
+}
+
+// IsZero reports whether the map has no texture.
+func (r *ReflectionMap) IsZero() bool {
+	return *r == ReflectionMap{}
+}
+
+// textures returns the -type option and the texture of every refl
+// statement of the map, in the order they are written.
+func (r *ReflectionMap) textures() [][2]string {
+	var textures [][2]string
+	for _, t := range [][2]string{
+		{"sphere", r.Sphere},
+		{"cube_top", r.CubeTop},
+		{"cube_bottom", r.CubeBottom},
+		{"cube_front", r.CubeFront},
+		{"cube_back", r.CubeBack},
+		{"cube_left", r.CubeLeft},
+		{"cube_right", r.CubeRight},
+	} {
+		if t[1] != "" {
+			textures = append(textures, t)
+		}
+	}
+	return textures
+}
+
+// set sets the texture of the map type, as given by the -type option.
+func (r *ReflectionMap) set(mapType, texture string) bool {
+	switch mapType {
+	case "sphere":
+		r.Sphere = texture
+	case "cube_top":
+		r.CubeTop = texture
+	case "cube_bottom":
+		r.CubeBottom = texture
+	case "cube_front":
+		r.CubeFront = texture
+	case "cube_back":
+		r.CubeBack = texture
+	case "cube_left":
+		r.CubeLeft = texture
+	case "cube_right":
+		r.CubeRight = texture
+	default:
+		return false
+	}
+	return true
 }
 
 func ReadMaterials(filename string) (map[string]*Material, error) {
@@ -41,7 +119,37 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 		return nil, fmt.Errorf("cannot read referenced material library: %v", err)
 	}
 	defer file.Close()
+	return ReadMaterialsFrom(file, filename)
+}
+
+// ReadMaterialsFrom reads a material library from reader. name identifies
+// the library in error messages.
+func ReadMaterialsFrom(reader io.Reader, name string) (map[string]*Material, error) {
+	return ReadMaterialsWith(reader, name, MaterialReadOptions{})
+}
+
+// ReadMaterialsWithLimits reads a material library from reader, failing with
+// an error matching ErrLimitExceeded when the library defines more
+// materials or references more textures than limits allow. Only the
+// MaxMaterials, MaxTextures and MaxLineLen limits apply.
+func ReadMaterialsWithLimits(reader io.Reader, name string, limits Limits) (map[string]*Material, error) {
+	return ReadMaterialsWith(reader, name, MaterialReadOptions{Limits: limits})
+}
+
+// MaterialReadOptions controls how a material library is read.
+type MaterialReadOptions struct {
+	// Limits fails reading with an error matching ErrLimitExceeded when the
+	// library defines more materials or references more textures than
+	// allowed. Only MaxMaterials, MaxTextures and MaxLineLen apply.
+	Limits Limits
+	// OnWarning is called for every statement that is read but ignored.
+	OnWarning func(Warning)
+}
 
+// ReadMaterialsWith reads a material library from reader. name identifies
+// the library in errors and warnings.
+func ReadMaterialsWith(reader io.Reader, name string, options MaterialReadOptions) (map[string]*Material, error) {
+	limits := options.Limits
 	var (
 		materials = make(map[string]*Material)
 		material  *Material
@@ -49,15 +157,27 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 
 	lno := 0
 	line := ""
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(reader)
+	if limits.MaxLineLen > 0 {
+		scanner.Buffer(make([]byte, 0, minInt(limits.MaxLineLen+2, bufio.MaxScanTokenSize)), limits.MaxLineLen+2)
+	}
+	textures := make(map[string]bool)
 
 	fail := func(msg string) error {
-		return fmt.Errorf(msg+" at %s:%d: %s", filename, lno, line)
+		return &LineError{File: name, Line: lno, Text: line, Err: badStatement(ErrBadMaterialStatement, "%s", msg)}
+	}
+	warn := func(keyword string) {
+		if options.OnWarning != nil {
+			options.OnWarning(Warning{File: name, Line: lno, Keyword: keyword, Text: line})
+		}
 	}
 
 	for scanner.Scan() {
 		lno++
 		line = scanner.Text()
+		if limits.MaxLineLen > 0 && len(line) > limits.MaxLineLen {
+			return nil, &LimitError{Limit: "MaxLineLen", Max: limits.MaxLineLen, What: "bytes in a line", Line: lno}
+		}
 		if strings.HasPrefix(line, "#") {
 			continue
 		}
@@ -71,6 +191,11 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 			if len(fields) != 2 {
 				return nil, fail("unsupported material definition")
 			}
+			if _, ok := materials[fields[1]]; !ok {
+				if limits.MaxMaterials > 0 && len(materials) >= limits.MaxMaterials {
+					return nil, &LimitError{Limit: "MaxMaterials", Max: limits.MaxMaterials, What: "materials", Line: lno}
+				}
+			}
 
 			material = &Material{Name: fields[1]}
 			material.Ambient = []float32{0.0, 0.0, 0.0, 1.0}
@@ -89,13 +214,23 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 			return nil, fail("found data before material")
 		}
 
+		if isTextureStatement(fields[0]) && len(fields) > 1 {
+			texture := fields[len(fields)-1]
+			if !textures[texture] {
+				if limits.MaxTextures > 0 && len(textures) >= limits.MaxTextures {
+					return nil, &LimitError{Limit: "MaxTextures", Max: limits.MaxTextures, What: "textures", Line: lno}
+				}
+				textures[texture] = true
+			}
+		}
+
 		switch fields[0] {
 		case "Ka":
 			if len(fields) != 4 {
 				return nil, fail("unsupported ambient color line")
 			}
 			for i := 0; i < 3; i++ {
-				f, err := strconv.ParseFloat(fields[i+1], 32)
+				f, err := parseFloat(fields[i+1], 32)
 				if err != nil {
 					return nil, fail("cannot parse float")
 				}
@@ -106,7 +241,7 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 				return nil, fail("unsupported diffuse color line")
 			}
 			for i := 0; i < 3; i++ {
-				f, err := strconv.ParseFloat(fields[i+1], 32)
+				f, err := parseFloat(fields[i+1], 32)
 				if err != nil {
 					return nil, fail("cannot parse float")
 				}
@@ -117,7 +252,7 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 				return nil, fail("unsupported specular color line")
 			}
 			for i := 0; i < 3; i++ {
-				f, err := strconv.ParseFloat(fields[i+1], 32)
+				f, err := parseFloat(fields[i+1], 32)
 				if err != nil {
 					return nil, fail("cannot parse float")
 				}
@@ -128,7 +263,7 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 				return nil, fail("unsupported specular color line")
 			}
 			for i := 0; i < 3; i++ {
-				f, err := strconv.ParseFloat(fields[i+1], 32)
+				f, err := parseFloat(fields[i+1], 32)
 				if err != nil {
 					return nil, fail("cannot parse float")
 				}
@@ -140,26 +275,35 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 			if len(fields) != 2 {
 				return nil, fail("unsupported shininess line")
 			}
-			f, err := strconv.ParseFloat(fields[1], 32)
+			f, err := parseFloat(fields[1], 32)
 			if err != nil {
 				return nil, fail("cannot parse float")
 			}
-			material.Shininess = float64(f / 1000)
+			material.Shininess = float64(f / shininessScale)
 		case "d":
 			if len(fields) != 2 {
 				return nil, fail("unsupported transparency line")
 			}
-			f, err := strconv.ParseFloat(fields[1], 32)
+			f, err := parseFloat(fields[1], 32)
 			if err != nil {
 				return nil, fail("cannot parse float")
 			}
 			material.Opacity = f
+		case "Ni":
+			if len(fields) != 2 {
+				return nil, fail("unsupported optical density line")
+			}
+			f, err := parseFloat(fields[1], 32)
+			if err != nil {
+				return nil, fail("cannot parse float")
+			}
+			material.OpticalDensity = float32(f)
 		case "Tf":
 			if len(fields) != 4 {
 				return nil, fail("unsupported transmission filter line")
 			}
 			for i := 0; i < 3; i++ {
-				f, err := strconv.ParseFloat(fields[i+1], 32)
+				f, err := parseFloat(fields[i+1], 32)
 				if err != nil {
 					return nil, fail("cannot parse float")
 				}
@@ -174,6 +318,7 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 				material.DiffuseTexture = fields[1]
 			}
 		case "map_Ns":
+			warn(fields[0])
 		case "map_Ks":
 			if len(fields) == 2 {
 				material.SpecularTexture = fields[1]
@@ -182,28 +327,67 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 			if len(fields) == 2 {
 				material.EmissiveTexture = fields[1]
 			}
-		case "map_d":
-		case "map_opacity":
+		case "map_Pr":
+			if len(fields) == 2 {
+				material.RoughnessTexture = fields[1]
+			}
+		case "map_Pm":
+			if len(fields) == 2 {
+				material.MetallicTexture = fields[1]
+			}
+		case "map_Ps":
+			if len(fields) == 2 {
+				material.SheenTexture = fields[1]
+			}
+		case "map_d", "map_opacity":
 			if len(fields) == 2 {
 				material.AlphaTexture = fields[1]
 			}
-		case "map_bump":
-		case "bump":
+		case "map_bump", "bump":
 			if len(fields) == 2 {
 				material.BumpTexture = fields[1]
 			}
+		case "disp":
+			if len(fields) == 2 {
+				material.DisplacementTexture = fields[1]
+			}
+		case "decal":
+			if len(fields) == 2 {
+				material.DecalTexture = fields[1]
+			}
+		case "norm":
+			if len(fields) == 2 {
+				material.NormalTexture = fields[1]
+			}
 		case "illum":
+			if len(fields) != 2 {
+				return nil, fail("unsupported illumination line")
+			}
+			n, err := strconv.ParseUint(fields[1], 10, 32)
+			if err != nil {
+				return nil, fail("cannot parse illumination model")
+			}
+			if !IlluminationModel(n).Valid() {
+				return nil, fail("unsupported illumination model")
+			}
+			material.Illumination = IlluminationModel(n)
 		case "refl":
-			if len(fields) == 2 {
-				f, err := strconv.ParseUint(fields[1], 0, 10)
-				if err != nil {
-					return nil, fail("cannot parse float")
+			// The texture comes last, after options such as -type.
+			if len(fields) < 2 {
+				return nil, fail("unsupported reflection map line")
+			}
+			mapType := "sphere"
+			for i := 1; i < len(fields)-2; i++ {
+				if fields[i] == "-type" {
+					mapType = fields[i+1]
 				}
-				material.Illumination = uint32(f)
+			}
+			if !material.ReflectionMap.set(mapType, fields[len(fields)-1]) {
+				return nil, fail("unsupported reflection map type")
 			}
 		case "Pr":
 			if len(fields) == 2 {
-				f, err := strconv.ParseFloat(fields[1], 32)
+				f, err := parseFloat(fields[1], 32)
 				if err != nil {
 					return nil, fail("cannot parse float")
 				}
@@ -211,7 +395,7 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 			}
 		case "Pm":
 			if len(fields) == 2 {
-				f, err := strconv.ParseFloat(fields[1], 32)
+				f, err := parseFloat(fields[1], 32)
 				if err != nil {
 					return nil, fail("cannot parse float")
 				}
@@ -219,7 +403,7 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 			}
 		case "Ps":
 			if len(fields) == 2 {
-				f, err := strconv.ParseFloat(fields[1], 32)
+				f, err := parseFloat(fields[1], 32)
 				if err != nil {
 					return nil, fail("cannot parse float")
 				}
@@ -227,7 +411,7 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 			}
 		case "Pc":
 			if len(fields) == 2 {
-				f, err := strconv.ParseFloat(fields[1], 32)
+				f, err := parseFloat(fields[1], 32)
 				if err != nil {
 					return nil, fail("cannot parse float")
 				}
@@ -235,7 +419,7 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 			}
 		case "Pcr":
 			if len(fields) == 2 {
-				f, err := strconv.ParseFloat(fields[1], 32)
+				f, err := parseFloat(fields[1], 32)
 				if err != nil {
 					return nil, fail("cannot parse float")
 				}
@@ -243,7 +427,7 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 			}
 		case "aniso":
 			if len(fields) == 2 {
-				f, err := strconv.ParseFloat(fields[1], 32)
+				f, err := parseFloat(fields[1], 32)
 				if err != nil {
 					return nil, fail("cannot parse float")
 				}
@@ -251,17 +435,22 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 			}
 		case "anisor":
 			if len(fields) == 2 {
-				f, err := strconv.ParseFloat(fields[1], 32)
+				f, err := parseFloat(fields[1], 32)
 				if err != nil {
 					return nil, fail("cannot parse float")
 				}
 				material.AnisotropyRotation = float32(f)
 			}
+		default:
+			warn(fields[0])
 		}
 
 	}
 
 	if err := scanner.Err(); err != nil {
+		if err == bufio.ErrTooLong && limits.MaxLineLen > 0 {
+			return nil, &LimitError{Limit: "MaxLineLen", Max: limits.MaxLineLen, What: "bytes in a line", Line: lno + 1}
+		}
 		return nil, err
 	}
 
@@ -278,9 +467,38 @@ func ReadMaterials(filename string) (map[string]*Material, error) {
 	return materials, nil
 }
 
+// isTextureStatement reports whether keyword declares a texture map.
+func isTextureStatement(keyword string) bool {
+	switch keyword {
+	case "bump", "disp", "decal", "refl", "norm":
+		return true
+	}
+	return strings.HasPrefix(keyword, "map_")
+}
+
 func WriteMaterials(filename string, mtls map[string]*Material) error {
-	var ret []byte
-	buff := bytes.NewBuffer(ret)
+	var buff bytes.Buffer
+	if err := WriteMaterialsTo(&buff, mtls); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(buff.Bytes())
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// WriteMaterialsTo writes the material library to w, with the materials
+// sorted by name.
+func WriteMaterialsTo(w io.Writer, mtls map[string]*Material) error {
+	buff := bufio.NewWriter(w)
 	_, err := buff.WriteString("#\n")
 	if err != nil {
 		return err
@@ -298,7 +516,13 @@ func WriteMaterials(filename string, mtls map[string]*Material) error {
 		return err
 	}
 
-	for i, k := range mtls {
+	names := make([]string, 0, len(mtls))
+	for name := range mtls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, i := range names {
+		k := mtls[i]
 		_, err = buff.WriteString("\n")
 		if err != nil {
 			return err
@@ -346,6 +570,12 @@ func WriteMaterials(filename string, mtls map[string]*Material) error {
 				return err
 			}
 		}
+		if k.OpticalDensity != 0 {
+			_, err = buff.WriteString(fmt.Sprintf("Ni %g\n", k.OpticalDensity))
+			if err != nil {
+				return err
+			}
+		}
 		if k.AmbientTexture != "" {
 			_, err = buff.WriteString(fmt.Sprintf("map_Ka %s\n", k.AmbientTexture))
 			if err != nil {
@@ -370,6 +600,24 @@ func WriteMaterials(filename string, mtls map[string]*Material) error {
 				return err
 			}
 		}
+		if k.RoughnessTexture != "" {
+			_, err = buff.WriteString(fmt.Sprintf("map_Pr %s\n", k.RoughnessTexture))
+			if err != nil {
+				return err
+			}
+		}
+		if k.MetallicTexture != "" {
+			_, err = buff.WriteString(fmt.Sprintf("map_Pm %s\n", k.MetallicTexture))
+			if err != nil {
+				return err
+			}
+		}
+		if k.SheenTexture != "" {
+			_, err = buff.WriteString(fmt.Sprintf("map_Ps %s\n", k.SheenTexture))
+			if err != nil {
+				return err
+			}
+		}
 		if k.AlphaTexture != "" {
 			_, err = buff.WriteString(fmt.Sprintf("map_d %s\n", k.AlphaTexture))
 			if err != nil {
@@ -382,6 +630,33 @@ func WriteMaterials(filename string, mtls map[string]*Material) error {
 				return err
 			}
 		}
+		if k.DisplacementTexture != "" {
+			_, err = buff.WriteString(fmt.Sprintf("disp %s\n", k.DisplacementTexture))
+			if err != nil {
+				return err
+			}
+		}
+		if k.DecalTexture != "" {
+			_, err = buff.WriteString(fmt.Sprintf("decal %s\n", k.DecalTexture))
+			if err != nil {
+				return err
+			}
+		}
+		if k.NormalTexture != "" {
+			_, err = buff.WriteString(fmt.Sprintf("norm %s\n", k.NormalTexture))
+			if err != nil {
+				return err
+			}
+		}
+		for _, t := range k.ReflectionMap.textures() {
+			_, err = buff.WriteString(fmt.Sprintf("refl -type %s %s\n", t[0], t[1]))
+			if err != nil {
+				return err
+			}
+		}
+		if !k.Illumination.Valid() {
+			return fmt.Errorf("Material %s has invalid illumination model %d", i, k.Illumination)
+		}
 		if k.Illumination != 0 {
 			_, err = buff.WriteString(fmt.Sprintf("illum %d\n", k.Illumination))
 			if err != nil {
@@ -432,15 +707,5 @@ func WriteMaterials(filename string, mtls map[string]*Material) error {
 		}
 	}
 
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	_, err = file.Write(buff.Bytes())
-	if err != nil {
-		return err
-	}
-	return nil
+	return buff.Flush()
 }
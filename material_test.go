@@ -1,6 +1,13 @@
 package obj
 
-import "testing"
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestMaterial(t *testing.T) {
 	mtls, err := ReadMaterials("../data/test.mtl")
@@ -13,3 +20,114 @@ func TestMaterial(t *testing.T) {
 		t.Error("error")
 	}
 }
+
+func TestWriteMaterialsTo_RoundTrips(t *testing.T) {
+	// Arrange
+	mtls := map[string]*Material{
+		"b": {Name: "b", Diffuse: []float32{0.5, 0.5, 0.5}, Opacity: 1, DiffuseTexture: "b.png"},
+		"a": {Name: "a", Diffuse: []float32{0.1, 0.2, 0.3}, Opacity: 0.5},
+	}
+
+	// Act
+	var out bytes.Buffer
+	err := WriteMaterialsTo(&out, mtls)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, strings.Index(out.String(), "newmtl a") < strings.Index(out.String(), "newmtl b"))
+	read, err := ReadMaterialsFrom(strings.NewReader(out.String()), "out.mtl")
+	assert.NoError(t, err)
+	assert.Equal(t, "b.png", read["b"].DiffuseTexture)
+	assert.Equal(t, 0.5, read["a"].Opacity)
+}
+
+func TestReadMaterialsFrom_Refl_SetsReflectionMap(t *testing.T) {
+	// Arrange
+	input := "newmtl chrome\nillum 3\nrefl -type sphere -mm 0 1 chrome.png\n" +
+		"newmtl room\nrefl -type cube_top top.png\nrefl -type cube_bottom bottom.png\n" +
+		"refl -type cube_front front.png\nrefl -type cube_back back.png\n" +
+		"refl -type cube_left left.png\nrefl -type cube_right right.png\n"
+
+	// Act
+	mtls, err := ReadMaterialsFrom(strings.NewReader(input), "test.mtl")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, IllumReflection, mtls["chrome"].Illumination)
+	assert.Equal(t, ReflectionMap{Sphere: "chrome.png"}, mtls["chrome"].ReflectionMap)
+	assert.Equal(t, ReflectionMap{
+		CubeTop: "top.png", CubeBottom: "bottom.png", CubeFront: "front.png",
+		CubeBack: "back.png", CubeLeft: "left.png", CubeRight: "right.png",
+	}, mtls["room"].ReflectionMap)
+}
+
+func TestReadMaterialsFrom_ReflUnknownType_Fails(t *testing.T) {
+	// Act
+	_, err := ReadMaterialsFrom(strings.NewReader("newmtl a\nrefl -type cylinder a.png\n"), "test.mtl")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrBadMaterialStatement))
+}
+
+func TestWriteMaterialsTo_ReflectionMap_RoundTrips(t *testing.T) {
+	// Arrange
+	room := ReflectionMap{CubeTop: "top.png", CubeLeft: "left.png"}
+	mtls := map[string]*Material{
+		"chrome": {Name: "chrome", Opacity: 1, ReflectionMap: ReflectionMap{Sphere: "chrome.png"}},
+		"room":   {Name: "room", Opacity: 1, ReflectionMap: room},
+	}
+
+	// Act
+	var out bytes.Buffer
+	err := WriteMaterialsTo(&out, mtls)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "refl -type sphere chrome.png\n")
+	read, err := ReadMaterialsFrom(strings.NewReader(out.String()), "out.mtl")
+	assert.NoError(t, err)
+	assert.Equal(t, "chrome.png", read["chrome"].ReflectionMap.Sphere)
+	assert.Equal(t, room, read["room"].ReflectionMap)
+	before := HashMaterials(mtls)
+	mtls["room"].ReflectionMap.CubeTop = "sky.png"
+	assert.NotEqual(t, before, HashMaterials(mtls))
+}
+
+func TestWriteMaterialsTo_DispDecalNorm_RoundTrips(t *testing.T) {
+	// Arrange
+	mtls := map[string]*Material{"a": {Name: "a", Opacity: 1, DisplacementTexture: "d.png", DecalTexture: "s.png", NormalTexture: "n.png"}}
+
+	// Act
+	var out bytes.Buffer
+	err := WriteMaterialsTo(&out, mtls)
+
+	// Assert
+	assert.NoError(t, err)
+	read, err := ReadMaterialsFrom(strings.NewReader(out.String()), "out.mtl")
+	assert.NoError(t, err)
+	assert.Equal(t, "d.png", read["a"].DisplacementTexture)
+	assert.Equal(t, "s.png", read["a"].DecalTexture)
+	assert.Equal(t, "n.png", read["a"].NormalTexture)
+}
+
+func TestReadMaterialsFrom_PBRTextureMaps_RoundTrip(t *testing.T) {
+	// Arrange
+	input := "newmtl metal\nPr 0.5\nmap_Pr rough.png\nPm 1\nmap_Pm metal.png\nPs 0.2\nmap_Ps sheen.png\nmap_Ke glow.png\n"
+
+	// Act
+	mtls, err := ReadMaterialsFrom(strings.NewReader(input), "test.mtl")
+	var out bytes.Buffer
+	writeErr := WriteMaterialsTo(&out, mtls)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, writeErr)
+	m := mtls["metal"]
+	assert.Equal(t, "rough.png", m.RoughnessTexture)
+	assert.Equal(t, "metal.png", m.MetallicTexture)
+	assert.Equal(t, "sheen.png", m.SheenTexture)
+	assert.Equal(t, "glow.png", m.EmissiveTexture)
+	read, err := ReadMaterialsFrom(strings.NewReader(out.String()), "out.mtl")
+	assert.NoError(t, err)
+	assert.Equal(t, m, read["metal"])
+}
@@ -0,0 +1,87 @@
+// Package materials provides material presets for generated meshes, so that
+// buffers built in code can be written with a usable material library.
+package materials
+
+import (
+	"fmt"
+	"sort"
+
+	obj "github.com/flywave/go-obj"
+)
+
+// Preset returns a new material with the given name.
+type Preset func(name string) *obj.Material
+
+// Presets are the presets available to New, by preset name.
+var Presets = map[string]Preset{
+	"metal":         DefaultPBRMetal,
+	"matte-plastic": MattePlastic,
+	"glass":         Glass,
+}
+
+// New returns a new material with the given name from the preset named
+// preset.
+func New(preset, name string) (*obj.Material, error) {
+	p, ok := Presets[preset]
+	if !ok {
+		return nil, fmt.Errorf("Unknown material preset '%s'", preset)
+	}
+	return p(name), nil
+}
+
+// Names returns the sorted names of the presets.
+func Names() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// base returns a material with the defaults of the material reader.
+func base(name string) *obj.Material {
+	return &obj.Material{
+		Name:               name,
+		Ambient:            []float32{0, 0, 0, 1},
+		Diffuse:            []float32{0.8, 0.8, 0.8, 1},
+		Specular:           []float32{0, 0, 0, 1},
+		Emissive:           []float32{0, 0, 0, 1},
+		TransmissionFilter: []float32{1, 1, 1},
+		Opacity:            1,
+	}
+}
+
+// DefaultPBRMetal is a polished grey metal.
+func DefaultPBRMetal(name string) *obj.Material {
+	m := base(name)
+	m.Diffuse = []float32{0.56, 0.57, 0.58, 1}
+	m.Specular = []float32{0.9, 0.9, 0.9, 1}
+	m.Illumination = 3
+	m.Metallic = 1
+	m.Roughness = 0.3
+	return m
+}
+
+// MattePlastic is a rough, light grey dielectric.
+func MattePlastic(name string) *obj.Material {
+	m := base(name)
+	m.Specular = []float32{0.04, 0.04, 0.04, 1}
+	m.Illumination = 2
+	m.Roughness = 0.9
+	return m
+}
+
+// Glass is a clear, mostly transparent glass with an index of refraction
+// of 1.5.
+func Glass(name string) *obj.Material {
+	m := base(name)
+	m.Diffuse = []float32{0.9, 0.9, 0.9, 1}
+	m.Specular = []float32{1, 1, 1, 1}
+	m.TransmissionFilter = []float32{0.9, 0.9, 0.9}
+	m.Opacity = 0.2
+	m.OpticalDensity = 1.5
+	m.Illumination = 4
+	m.Roughness = 0.05
+	return m
+}
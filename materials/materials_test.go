@@ -0,0 +1,59 @@
+package materials
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	obj "github.com/flywave/go-obj"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_KnownPreset_ReturnsNamedMaterial(t *testing.T) {
+	// Act
+	m, err := New("glass", "window")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "window", m.Name)
+	assert.Equal(t, float32(1.5), m.OpticalDensity)
+	assert.True(t, m.Opacity < 1)
+}
+
+func TestNew_UnknownPreset_ReturnsError(t *testing.T) {
+	// Act
+	_, err := New("wood", "floor")
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestNames_ReturnsSortedPresets(t *testing.T) {
+	// Act
+	names := Names()
+
+	// Assert
+	assert.Equal(t, []string{"glass", "matte-plastic", "metal"}, names)
+}
+
+func TestPresets_WriteMaterials_RoundTrips(t *testing.T) {
+	// Arrange
+	mtls := map[string]*obj.Material{}
+	for _, name := range Names() {
+		m, _ := New(name, name)
+		mtls[name] = m
+	}
+
+	// Act
+	var out bytes.Buffer
+	err := obj.WriteMaterialsTo(&out, mtls)
+
+	// Assert
+	assert.NoError(t, err)
+	read, err := obj.ReadMaterialsFrom(strings.NewReader(out.String()), "presets.mtl")
+	assert.NoError(t, err)
+	assert.Equal(t, float32(1.5), read["glass"].OpticalDensity)
+	assert.InDelta(t, 0.2, read["glass"].Opacity, 1e-6)
+	assert.Equal(t, float32(1), read["metal"].Metallic)
+	assert.Equal(t, float32(0.9), read["matte-plastic"].Roughness)
+}
@@ -0,0 +1,53 @@
+package obj
+
+import "sort"
+
+// UnusedMaterials returns the names of the materials of the scene that no
+// face or line of the buffer or of the nodes uses, sorted.
+func (s *Scene) UnusedMaterials() []string {
+	used := s.usedMaterials()
+	var unused []string
+	for name := range s.Materials {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// MissingMaterials returns the names of the materials that faces or lines
+// of the buffer or of the nodes use but the scene does not define, sorted.
+// Elements without a material are not reported.
+func (s *Scene) MissingMaterials() []string {
+	var missing []string
+	for name := range s.usedMaterials() {
+		if _, ok := s.Materials[name]; !ok && name != "" {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// usedMaterials returns the set of the materials of the faces and lines of
+// the buffer and of the nodes.
+func (s *Scene) usedMaterials() map[string]bool {
+	used := make(map[string]bool)
+	buffers := []*ObjBuffer{s.Buffer}
+	for _, n := range s.Nodes {
+		buffers = append(buffers, n.Buffer)
+	}
+	for _, b := range buffers {
+		if b == nil {
+			continue
+		}
+		for _, f := range b.F {
+			used[f.Material] = true
+		}
+		for _, l := range b.L {
+			used[l.Material] = true
+		}
+	}
+	return used
+}
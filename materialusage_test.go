@@ -0,0 +1,47 @@
+package obj
+
+import (
+	"testing"
+
+	dmat4 "github.com/flywave/go3d/float64/mat4"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScene_MaterialUsage_CrossChecksFacesAndLibrary(t *testing.T) {
+	// Arrange
+	buffer := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "red")
+	buffer.F[0].Material = "ghost"
+	buffer.F[1].Material = ""
+	buffer.L = []line{{Corners: []int{0, 1}, Material: "wire"}}
+	node := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "blue")
+	scene := &Scene{Buffer: buffer, Materials: map[string]*Material{
+		"red":  {Name: "red"},
+		"blue": {Name: "blue"},
+		"wire": {Name: "wire"},
+		"dead": {Name: "dead"},
+		"old":  {Name: "old"},
+	}}
+	scene.AddInstance("copy", node, dmat4.Ident)
+
+	// Act
+	unused := scene.UnusedMaterials()
+	missing := scene.MissingMaterials()
+
+	// Assert
+	assert.Equal(t, []string{"dead", "old"}, unused)
+	assert.Equal(t, []string{"ghost"}, missing)
+}
+
+func TestScene_MaterialUsage_NoBuffer_ReportsAllUnused(t *testing.T) {
+	// Arrange
+	scene := &Scene{Materials: map[string]*Material{"b": {Name: "b"}, "a": {Name: "a"}}}
+
+	// Act
+	unused := scene.UnusedMaterials()
+	missing := scene.MissingMaterials()
+
+	// Assert
+	assert.Equal(t, []string{"a", "b"}, unused)
+	assert.Empty(t, missing)
+}
@@ -0,0 +1,174 @@
+package obj
+
+import (
+	"unsafe"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+)
+
+// mapEntryOverhead estimates the bytes a map uses per entry beyond its key
+// and value: the bucket slots, hash bits and free space.
+const mapEntryOverhead = 16
+
+// MemoryFootprint estimates the bytes of memory held by the buffer: the
+// capacity of its slices, including the corners of the faces and lines and
+// the custom attributes, the metadata of the faces, and the text of the
+// comments and statements. Material and group names are counted by their
+// string headers only, since faces share them. The estimate excludes the
+// overhead of the allocator and, for buffers read by the reader, the unused
+// part of the blocks it allocates face corners from, which ShrinkToFit
+// releases.
+func (b *ObjBuffer) MemoryFootprint() int64 {
+	size := int64(unsafe.Sizeof(*b))
+	size += int64(cap(b.V)+cap(b.VN)+cap(b.VC)) * int64(unsafe.Sizeof(vec3.T{}))
+	size += int64(cap(b.VT)) * int64(unsafe.Sizeof(vec2.T{}))
+	size += int64(cap(b.VD)) * int64(unsafe.Sizeof(dvec3.T{}))
+
+	cornerSize := int64(unsafe.Sizeof(FaceCorner{}))
+	size += int64(cap(b.F)) * int64(unsafe.Sizeof(Face{}))
+	for i := range b.F {
+		f := &b.F[i]
+		size += int64(cap(f.Corners)) * cornerSize
+		size += int64(cap(f.Holes)) * int64(unsafe.Sizeof([]FaceCorner{}))
+		for _, hole := range f.Holes {
+			size += int64(cap(hole)) * cornerSize
+		}
+		for name := range f.Metadata {
+			size += int64(unsafe.Sizeof(name)+unsafe.Sizeof(uint32(0))+mapEntryOverhead) + int64(len(name))
+		}
+	}
+	size += int64(cap(b.L)) * int64(unsafe.Sizeof(line{}))
+	for _, l := range b.L {
+		size += int64(cap(l.Corners)) * int64(unsafe.Sizeof(int(0)))
+	}
+
+	size += int64(cap(b.G)) * int64(unsafe.Sizeof(Group{}))
+	size += int64(cap(b.FaceGroup)) * int64(unsafe.Sizeof(&FaceGroup{}))
+	size += int64(len(b.FaceGroup)) * int64(unsafe.Sizeof(FaceGroup{}))
+	size += int64(cap(b.Comments)) * int64(unsafe.Sizeof(Comment{}))
+	for _, c := range b.Comments {
+		size += int64(len(c.Text))
+	}
+	size += int64(cap(b.Statements)) * int64(unsafe.Sizeof(Statement{}))
+	for _, s := range b.Statements {
+		size += int64(len(s.Raw))
+	}
+	for name, a := range b.Attributes {
+		size += int64(unsafe.Sizeof(name)+unsafe.Sizeof(a)+mapEntryOverhead) + int64(len(name))
+		size += int64(cap(a.Floats))*int64(unsafe.Sizeof(float64(0))) + int64(cap(a.Ints))*int64(unsafe.Sizeof(int64(0)))
+	}
+	return size
+}
+
+// ShrinkToFit copies every slice of the buffer whose capacity exceeds its
+// length to a slice of the exact length, so that a buffer kept in memory
+// after reading or editing does not hold the room its slices grew. The
+// corners of the faces are packed into a single allocation, which also
+// releases the blocks the reader allocates them from. Faces and lines keep
+// their order and values.
+func (b *ObjBuffer) ShrinkToFit() {
+	b.V = shrinkVec3s(b.V)
+	b.VN = shrinkVec3s(b.VN)
+	b.VC = shrinkVec3s(b.VC)
+	if cap(b.VT) > len(b.VT) {
+		vt := make([]vec2.T, len(b.VT))
+		copy(vt, b.VT)
+		b.VT = vt
+	}
+	if cap(b.VD) > len(b.VD) {
+		vd := make([]dvec3.T, len(b.VD))
+		copy(vd, b.VD)
+		b.VD = vd
+	}
+
+	if cap(b.F) > len(b.F) {
+		f := make([]Face, len(b.F))
+		copy(f, b.F)
+		b.F = f
+	}
+	count := 0
+	for _, f := range b.F {
+		count += len(f.Corners)
+		for _, hole := range f.Holes {
+			count += len(hole)
+		}
+	}
+	corners := make([]FaceCorner, count)
+	pack := func(c []FaceCorner) []FaceCorner {
+		if c == nil {
+			return nil
+		}
+		n := copy(corners, c)
+		packed := corners[:n:n]
+		corners = corners[n:]
+		return packed
+	}
+	for i := range b.F {
+		f := &b.F[i]
+		f.Corners = pack(f.Corners)
+		if len(f.Holes) > 0 {
+			holes := make([][]FaceCorner, len(f.Holes))
+			for j, hole := range f.Holes {
+				holes[j] = pack(hole)
+			}
+			f.Holes = holes
+		}
+	}
+
+	if cap(b.L) > len(b.L) {
+		l := make([]line, len(b.L))
+		copy(l, b.L)
+		b.L = l
+	}
+	for i := range b.L {
+		if corners := b.L[i].Corners; cap(corners) > len(corners) {
+			b.L[i].Corners = make([]int, len(corners))
+			copy(b.L[i].Corners, corners)
+		}
+	}
+	if cap(b.G) > len(b.G) {
+		g := make([]Group, len(b.G))
+		copy(g, b.G)
+		b.G = g
+	}
+	if cap(b.FaceGroup) > len(b.FaceGroup) {
+		fg := make([]*FaceGroup, len(b.FaceGroup))
+		copy(fg, b.FaceGroup)
+		b.FaceGroup = fg
+	}
+	if cap(b.Comments) > len(b.Comments) {
+		comments := make([]Comment, len(b.Comments))
+		copy(comments, b.Comments)
+		b.Comments = comments
+	}
+	if cap(b.Statements) > len(b.Statements) {
+		statements := make([]Statement, len(b.Statements))
+		copy(statements, b.Statements)
+		b.Statements = statements
+	}
+	for name, a := range b.Attributes {
+		if cap(a.Floats) > len(a.Floats) {
+			floats := make([]float64, len(a.Floats))
+			copy(floats, a.Floats)
+			a.Floats = floats
+		}
+		if cap(a.Ints) > len(a.Ints) {
+			ints := make([]int64, len(a.Ints))
+			copy(ints, a.Ints)
+			a.Ints = ints
+		}
+		b.Attributes[name] = a
+	}
+}
+
+// shrinkVec3s returns v, or a copy of v without spare capacity.
+func shrinkVec3s(v []vec3.T) []vec3.T {
+	if cap(v) == len(v) {
+		return v
+	}
+	shrunk := make([]vec3.T, len(v))
+	copy(shrunk, v)
+	return shrunk
+}
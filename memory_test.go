@@ -0,0 +1,45 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_MemoryFootprint_SpareCapacity_IsCounted(t *testing.T) {
+	// Arrange
+	buffer := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "")
+	grown := *buffer
+	grown.V = append(make([]vec3.T, 0, 1000), buffer.V...)
+
+	// Act
+	size, grownSize := buffer.MemoryFootprint(), grown.MemoryFootprint()
+
+	// Assert
+	assert.True(t, size > 8*12+6*4*24)
+	assert.Equal(t, int64((1000-cap(buffer.V))*12), grownSize-size)
+}
+
+func TestObjBuffer_ShrinkToFit_ReadBuffer_KeepsContentWithoutSpareCapacity(t *testing.T) {
+	// Arrange
+	reader := &ObjReader{}
+	reader.SetOptions(ReadOptions{PreallocHint: PreallocHint{Vertices: 100, Faces: 100}})
+	assert.NoError(t, reader.Read(strings.NewReader("v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nf 1 2 3 4\nf 1 3 4\n")))
+	buffer := &reader.ObjBuffer
+	expected := buffer.Clone()
+	before := buffer.MemoryFootprint()
+
+	// Act
+	buffer.ShrinkToFit()
+
+	// Assert
+	assert.Equal(t, expected.V, buffer.V)
+	assert.Equal(t, expected.F, buffer.F)
+	assert.Equal(t, expected.G, buffer.G)
+	assert.Equal(t, len(buffer.V), cap(buffer.V))
+	assert.Equal(t, len(buffer.F), cap(buffer.F))
+	assert.Equal(t, 4, cap(buffer.F[0].Corners))
+	assert.True(t, buffer.MemoryFootprint() < before)
+}
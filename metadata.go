@@ -0,0 +1,85 @@
+package obj
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// metadataNames returns the sorted names of the metadata of f.
+func (f *Face) metadataNames() []string {
+	names := make([]string, 0, len(f.Metadata))
+	for name := range f.Metadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeFaceMetadata writes the "#fm" comment holding the metadata of f, if
+// it has any.
+func writeFaceMetadata(w io.Writer, f Face) error {
+	if len(f.Metadata) == 0 {
+		return nil
+	}
+	var s strings.Builder
+	s.WriteString("#fm")
+	for _, name := range f.metadataNames() {
+		fmt.Fprintf(&s, " %s=%d", name, f.Metadata[name])
+	}
+	s.WriteString("\n")
+	_, err := io.WriteString(w, s.String())
+	return err
+}
+
+// processMetadataComment handles the face metadata comments written by
+// ObjBuffer.Write, and reports whether line is one.
+func (l *ObjReader) processMetadataComment(lineNumber int, line string) bool {
+	if !strings.HasPrefix(line, "#fm ") {
+		return false
+	}
+	if l.metadataFace < 0 {
+		return true
+	}
+	if l.metadataFace == 0 {
+		l.warn(Warning{Line: lineNumber, Keyword: "#fm", Text: line})
+		return true
+	}
+	f := &l.F[l.metadataFace-1]
+	for _, field := range strings.Fields(line[len("#fm"):]) {
+		eq := strings.IndexByte(field, '=')
+		if eq <= 0 {
+			l.warn(Warning{Line: lineNumber, Keyword: "#fm", Text: line})
+			continue
+		}
+		id, err := strconv.ParseUint(field[eq+1:], 10, 32)
+		if err != nil {
+			l.warn(Warning{Line: lineNumber, Keyword: "#fm", Text: line})
+			continue
+		}
+		if f.Metadata == nil {
+			f.Metadata = map[string]uint32{}
+		}
+		f.Metadata[l.keep(field[:eq])] = uint32(id)
+	}
+	return true
+}
+
+// faceMetadataNames returns the sorted names of the metadata of the faces
+// of b.
+func (b *ObjBuffer) faceMetadataNames() []string {
+	seen := map[string]bool{}
+	var names []string
+	for i := range b.F {
+		for name := range b.F[i].Metadata {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,94 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const metadataTestObj = "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\n" +
+	"g city\nf 1 2 3\n#fm building=7 feature=12\nf 1 3 4\n"
+
+func TestObjReader_Read_FaceMetadata_AppliesToPreviousFace(t *testing.T) {
+	// Act
+	loader := &ObjReader{}
+	err := loader.Read(strings.NewReader(metadataTestObj))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]uint32{"building": 7, "feature": 12}, loader.F[0].Metadata)
+	assert.Nil(t, loader.F[1].Metadata)
+	assert.Empty(t, loader.Warnings)
+}
+
+func TestObjReader_Read_FaceMetadataWithoutFace_Warns(t *testing.T) {
+	// Act
+	loader := &ObjReader{}
+	err := loader.Read(strings.NewReader("v 0 0 0\n#fm building=7\n"))
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(loader.Warnings)) {
+		assert.Equal(t, "#fm", loader.Warnings[0].Keyword)
+	}
+}
+
+func TestObjBuffer_Write_FaceMetadata_RoundTrips(t *testing.T) {
+	// Arrange
+	loader := &ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader(metadataTestObj)))
+
+	// Act
+	var out bytes.Buffer
+	err := loader.Write(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "f 1 2 3\n#fm building=7 feature=12\nf 1 3 4\n")
+	read := &ObjReader{}
+	assert.NoError(t, read.Read(strings.NewReader(out.String())))
+	assert.Equal(t, loader.Hash(), read.Hash())
+}
+
+func TestScene_WriteGLTF_FaceMetadata_ExportsFeatureIDs(t *testing.T) {
+	// Arrange
+	loader := &ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader(metadataTestObj)))
+	scene := &Scene{Buffer: &loader.ObjBuffer}
+
+	// Act
+	var out bytes.Buffer
+	err := scene.WriteGLTF(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	var doc gltfDocument
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+	assert.Equal(t, []string{"EXT_mesh_features"}, doc.ExtensionsUsed)
+	primitive := doc.Meshes[0].Primitives[0]
+	features := primitive.Extensions.MeshFeatures.FeatureIDs
+	if assert.Equal(t, 2, len(features)) {
+		assert.Equal(t, "building", features[0].Label)
+		assert.Equal(t, 1, features[0].FeatureCount)
+		assert.Equal(t, uint32(8), *features[0].NullFeatureID)
+	}
+	// The corners shared by both faces are split by their features.
+	assert.Equal(t, 6, doc.Accessors[primitive.Attributes["POSITION"]].Count)
+	assert.Contains(t, primitive.Attributes, "_FEATURE_ID_1")
+}
+
+func TestObjBuffer_Triangulate_KeepsFaceMetadata(t *testing.T) {
+	// Arrange
+	loader := &ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader("v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nf 1 2 3 4\n#fm building=3\n")))
+
+	// Act
+	loader.Triangulate()
+
+	// Assert
+	assert.Equal(t, 2, len(loader.F))
+	assert.Equal(t, uint32(3), loader.F[1].Metadata["building"])
+}
@@ -0,0 +1,172 @@
+package obj
+
+import (
+	"expvar"
+	"io"
+	"runtime"
+	"time"
+)
+
+// Metrics receives measurements of the reads and writes it is set for, with
+// ReadOptions.Metrics or WriteOptions.Metrics, for monitoring. Observe is
+// called once per read or write, including failed ones, from the goroutine
+// doing it.
+type Metrics interface {
+	Observe(stats Stats)
+}
+
+// MetricsFunc adapts a function to the Metrics interface.
+type MetricsFunc func(stats Stats)
+
+// Observe calls f.
+func (f MetricsFunc) Observe(stats Stats) {
+	f(stats)
+}
+
+// Stats measures a read or a write.
+type Stats struct {
+	// Operation is "read" or "write".
+	Operation string
+	// Bytes is the number of bytes parsed or written.
+	Bytes int64
+	// Duration is the time the operation took.
+	Duration time.Duration
+	// Stages holds the time spent in each stage of the operation, in order.
+	// Reads go through "count" with ReadOptions.TwoPass, "parse", "finish"
+	// and "validate" when the indices are validated; writes through
+	// "header", "elements" for the vertices, normals and texture
+	// coordinates, and "faces" for the groups and lines, or "statements" in
+	// lossless mode.
+	Stages []StageTiming
+	// Allocs and AllocBytes are the number and the total size of the heap
+	// allocations made during the operation. They are counted for the whole
+	// process, so allocations of other goroutines are included.
+	Allocs     uint64
+	AllocBytes uint64
+	// Err is the error the operation failed with, or nil.
+	Err error
+}
+
+// StageTiming is the time spent in a stage of a read or a write.
+type StageTiming struct {
+	Stage    string
+	Duration time.Duration
+}
+
+// BytesPerSecond returns the throughput of the operation.
+func (s *Stats) BytesPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / s.Duration.Seconds()
+}
+
+// metricsRecorder measures an operation for Metrics. All its methods do
+// nothing on a nil recorder, which is what startMetrics returns without
+// Metrics, so that measuring costs nothing when it is not wanted.
+type metricsRecorder struct {
+	metrics    Metrics
+	stats      Stats
+	start      time.Time
+	stageStart time.Time
+	mallocs    uint64
+	allocBytes uint64
+}
+
+func startMetrics(metrics Metrics, operation string) *metricsRecorder {
+	if metrics == nil {
+		return nil
+	}
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	now := time.Now()
+	return &metricsRecorder{
+		metrics:    metrics,
+		stats:      Stats{Operation: operation},
+		start:      now,
+		stageStart: now,
+		mallocs:    mem.Mallocs,
+		allocBytes: mem.TotalAlloc,
+	}
+}
+
+// endStage records the time since the previous stage ended as the time of
+// stage.
+func (r *metricsRecorder) endStage(stage string) {
+	if r == nil {
+		return
+	}
+	now := time.Now()
+	r.stats.Stages = append(r.stats.Stages, StageTiming{Stage: stage, Duration: now.Sub(r.stageStart)})
+	r.stageStart = now
+}
+
+// finish reports the operation to the Metrics.
+func (r *metricsRecorder) finish(bytes int64, err error) {
+	if r == nil {
+		return
+	}
+	r.stats.Duration = time.Since(r.start)
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	r.stats.Allocs = mem.Mallocs - r.mallocs
+	r.stats.AllocBytes = mem.TotalAlloc - r.allocBytes
+	r.stats.Bytes = bytes
+	r.stats.Err = err
+	r.metrics.Observe(r.stats)
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// ExpvarMetrics publishes the measurements as counters of an expvar.Map,
+// served with the other expvar variables on /debug/vars. For every
+// operation, the map holds OPERATION_count, OPERATION_errors,
+// OPERATION_bytes, OPERATION_nanoseconds, OPERATION_allocs,
+// OPERATION_alloc_bytes and OPERATION_STAGE_nanoseconds for every stage.
+type ExpvarMetrics struct {
+	Map *expvar.Map
+}
+
+// NewExpvarMetrics returns the metrics publishing the expvar map name. Like
+// expvar.Publish, it panics if the name is already used.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	return &ExpvarMetrics{Map: expvar.NewMap(name)}
+}
+
+// Observe adds the measurements to the counters.
+func (m *ExpvarMetrics) Observe(stats Stats) {
+	prefix := stats.Operation + "_"
+	m.Map.Add(prefix+"count", 1)
+	if stats.Err != nil {
+		m.Map.Add(prefix+"errors", 1)
+	}
+	m.Map.Add(prefix+"bytes", stats.Bytes)
+	m.Map.Add(prefix+"nanoseconds", int64(stats.Duration))
+	m.Map.Add(prefix+"allocs", int64(stats.Allocs))
+	m.Map.Add(prefix+"alloc_bytes", int64(stats.AllocBytes))
+	for _, s := range stats.Stages {
+		m.Map.Add(prefix+s.Stage+"_nanoseconds", int64(s.Duration))
+	}
+}
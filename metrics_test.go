@@ -0,0 +1,110 @@
+package obj
+
+import (
+	"bytes"
+	"expvar"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stageNames returns the names of the stages of stats.
+func stageNames(stats Stats) []string {
+	var names []string
+	for _, s := range stats.Stages {
+		names = append(names, s.Stage)
+	}
+	return names
+}
+
+func TestObjReader_Read_Metrics_ObservesRead(t *testing.T) {
+	// Arrange
+	var observed []Stats
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{
+		TwoPass:         true,
+		ValidateIndices: true,
+		Metrics:         MetricsFunc(func(stats Stats) { observed = append(observed, stats) }),
+	})
+
+	// Act
+	err := loader.Read(strings.NewReader(readFileTestObj))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, observed, 1)
+	stats := observed[0]
+	assert.Equal(t, "read", stats.Operation)
+	assert.Equal(t, int64(len(readFileTestObj)), stats.Bytes)
+	assert.Equal(t, []string{"count", "parse", "finish", "validate"}, stageNames(stats))
+	assert.True(t, stats.Duration > 0)
+	assert.True(t, stats.Allocs > 0)
+	assert.True(t, stats.BytesPerSecond() > 0)
+	assert.NoError(t, stats.Err)
+}
+
+func TestObjReader_Read_MetricsOnError_ReportsError(t *testing.T) {
+	// Arrange
+	var observed Stats
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{Metrics: MetricsFunc(func(stats Stats) { observed = stats })})
+
+	// Act
+	err := loader.Read(strings.NewReader("v 0 0 0\nf 1 x 1\n"))
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, err, observed.Err)
+	assert.Empty(t, observed.Stages)
+}
+
+func TestReadFile_Metrics_ObservesRead(t *testing.T) {
+	// Arrange
+	path := writeTempObj(t, readFileTestObj)
+	var observed Stats
+
+	// Act
+	_, err := ReadFile(path, ReadOptions{Metrics: MetricsFunc(func(stats Stats) { observed = stats })})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(readFileTestObj)), observed.Bytes)
+	assert.Equal(t, []string{"parse", "finish"}, stageNames(observed))
+}
+
+func TestObjBuffer_WriteWith_Metrics_ObservesWrite(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader(readFileTestObj)))
+	var observed Stats
+	var out bytes.Buffer
+
+	// Act
+	err := loader.WriteWith(&out, WriteOptions{Metrics: MetricsFunc(func(stats Stats) { observed = stats })})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "write", observed.Operation)
+	assert.Equal(t, int64(out.Len()), observed.Bytes)
+	assert.Equal(t, []string{"header", "elements", "faces"}, stageNames(observed))
+}
+
+func TestExpvarMetrics_Observe_AddsCounters(t *testing.T) {
+	// Arrange
+	metrics := NewExpvarMetrics("obj_test_metrics")
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{Metrics: metrics})
+
+	// Act
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, loader.Read(strings.NewReader("v 0 0 0\n")))
+	}
+
+	// Assert
+	assert.Equal(t, "2", metrics.Map.Get("read_count").String())
+	assert.Equal(t, "16", metrics.Map.Get("read_bytes").String())
+	assert.NotNil(t, metrics.Map.Get("read_parse_nanoseconds"))
+	assert.Nil(t, metrics.Map.Get("read_errors"))
+	assert.Equal(t, metrics.Map, expvar.Get("obj_test_metrics"))
+}
@@ -0,0 +1,16 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package obj
+
+import "io/ioutil"
+
+// mapFile reads the whole file at path into memory on platforms without
+// memory-mapping support.
+func mapFile(path string) ([]byte, func(), error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() {}, nil
+}
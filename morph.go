@@ -0,0 +1,73 @@
+package obj
+
+import (
+	"fmt"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+)
+
+// MorphTarget is a shape of a buffer exported as a glTF morph target: a
+// buffer with the vertices of the base buffer at other positions, such as a
+// frame read by LoadSequence.
+type MorphTarget struct {
+	// Name is listed in the targetNames of the extras of the mesh, which
+	// most viewers show.
+	Name   string
+	Buffer *ObjBuffer
+	// Weight is the default weight of the target in the mesh.
+	Weight float64
+}
+
+// validateMorphTargets checks that the targets of every buffer have the
+// vertices of the buffer.
+func validateMorphTargets(targets map[*ObjBuffer][]MorphTarget) error {
+	for b, morphs := range targets {
+		for i, m := range morphs {
+			if m.Buffer == nil {
+				return fmt.Errorf("Morph target %d has no buffer", i+1)
+			}
+			if len(m.Buffer.V) != len(b.V) {
+				return fmt.Errorf("Morph target %d: %d vertices, expected %d", i+1, len(m.Buffer.V), len(b.V))
+			}
+		}
+	}
+	return nil
+}
+
+// addMorphTargets adds the targets of b as the displacements of the glTF
+// vertices, which are the OBJ vertices and normals of b at the given
+// indices, and returns the attributes of the targets. The positions are
+// compared in double precision with their offset. Normals are displaced
+// only by the targets with the normals of b.
+func (g *gltfBuilder) addMorphTargets(b *ObjBuffer, vertices, normals []int) []map[string]int {
+	var targets []map[string]int
+	for _, m := range g.morphTargets[b] {
+		t := m.Buffer
+		positions := make([]float32, 0, 3*len(vertices))
+		for _, v := range vertices {
+			base, moved := b.positionD(v), t.positionD(v)
+			base, moved = dvec3.Add(&base, &b.Offset), dvec3.Add(&moved, &t.Offset)
+			delta := dvec3.Sub(&moved, &base)
+			positions = append(positions, float32(delta[0]), float32(delta[1]), float32(delta[2]))
+		}
+		min, max := floatBounds(positions)
+		attributes := map[string]int{
+			"POSITION": g.addAccessor(gltfAccessor{
+				BufferView: g.addView(positions, gltfArrayBuffer), ComponentType: gltfFloat,
+				Count: len(vertices), Type: "VEC3", Min: min, Max: max,
+			}),
+		}
+		if normals != nil && len(t.VN) == len(b.VN) {
+			deltas := make([]float32, 0, 3*len(normals))
+			for _, n := range normals {
+				deltas = append(deltas, t.VN[n][0]-b.VN[n][0], t.VN[n][1]-b.VN[n][1], t.VN[n][2]-b.VN[n][2])
+			}
+			attributes["NORMAL"] = g.addAccessor(gltfAccessor{
+				BufferView: g.addView(deltas, gltfArrayBuffer), ComponentType: gltfFloat,
+				Count: len(normals), Type: "VEC3",
+			})
+		}
+		targets = append(targets, attributes)
+	}
+	return targets
+}
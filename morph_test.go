@@ -0,0 +1,65 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScene_WriteGLTFWith_MorphTargets_WritesDisplacements(t *testing.T) {
+	// Arrange
+	base := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "")
+	base.Offset = dvec3.T{10, 0, 0}
+	stretched := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 3}, "")
+	stretched.Offset = dvec3.T{10, 0, 0}
+	moved := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "")
+	moved.Offset = dvec3.T{11, 0, 0}
+	scene := &Scene{Buffer: base}
+	options := GLTFOptions{MorphTargets: map[*ObjBuffer][]MorphTarget{base: {
+		{Name: "stretch", Buffer: stretched},
+		{Name: "move", Buffer: moved, Weight: 0.5},
+	}}}
+
+	// Act
+	var out bytes.Buffer
+	err := scene.WriteGLTFWith(&out, options)
+
+	// Assert
+	assert.NoError(t, err)
+	var doc gltfDocument
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+	mesh := doc.Meshes[0]
+	assert.Equal(t, []float64{0, 0.5}, mesh.Weights)
+	assert.Equal(t, []string{"stretch", "move"}, mesh.Extras.TargetNames)
+	targets := mesh.Primitives[0].Targets
+	if assert.Equal(t, 2, len(targets)) {
+		stretch := doc.Accessors[targets[0]["POSITION"]]
+		assert.Equal(t, 8, stretch.Count)
+		assert.Equal(t, []float32{0, 0, 0}, stretch.Min)
+		assert.Equal(t, []float32{0, 0, 2}, stretch.Max)
+		move := doc.Accessors[targets[1]["POSITION"]]
+		assert.Equal(t, []float32{1, 0, 0}, move.Min)
+		assert.Equal(t, []float32{1, 0, 0}, move.Max)
+		assert.NotContains(t, targets[0], "NORMAL")
+	}
+}
+
+func TestScene_WriteGLTFWith_MorphTargetVertexCountDiffers_ReturnsError(t *testing.T) {
+	// Arrange
+	base := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "")
+	target := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "")
+	target.V = target.V[:7]
+	scene := &Scene{Buffer: base}
+
+	// Act
+	var out bytes.Buffer
+	err := scene.WriteGLTFWith(&out, GLTFOptions{MorphTargets: map[*ObjBuffer][]MorphTarget{base: {{Buffer: target}}}})
+
+	// Assert
+	assert.EqualError(t, err, "Morph target 1: 7 vertices, expected 8")
+	assert.Equal(t, 0, out.Len())
+}
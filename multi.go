@@ -0,0 +1,90 @@
+package obj
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// objectStart is an "o" statement: the name of an object and the index of
+// its first face.
+type objectStart struct {
+	Name           string
+	FirstFaceIndex int
+}
+
+// ReadMulti reads an OBJ file packing several independent objects and
+// returns a buffer per "o" statement, in the order of the file, so that the
+// objects need not be split by group afterwards. The faces before the first
+// "o" statement, if any, form a buffer of their own, and objects without
+// faces are skipped. Like CloneSubset, every buffer holds only the
+// vertices, normals and texture coordinates its faces reference, with the
+// groups clipped to the object, and lines are not kept. The faces of an
+// object outside of any group are in a group named after the object.
+func ReadMulti(r io.Reader) ([]*ObjBuffer, error) {
+	l := ObjReader{}
+	if err := l.Read(r); err != nil {
+		return nil, err
+	}
+
+	objects := l.objects
+	if len(objects) == 0 || objects[0].FirstFaceIndex > 0 {
+		objects = append([]objectStart{{}}, objects...)
+	}
+	var buffers []*ObjBuffer
+	for i, o := range objects {
+		end := len(l.F)
+		if i+1 < len(objects) {
+			end = objects[i+1].FirstFaceIndex
+		}
+		if end == o.FirstFaceIndex {
+			continue
+		}
+		buffer := l.CloneSubset(o.FirstFaceIndex, end-o.FirstFaceIndex)
+		if o.Name != "" {
+			for j := range buffer.G {
+				if buffer.G[j].Name == defaultGroupName {
+					buffer.G[j].Name = o.Name
+				}
+			}
+		}
+		buffers = append(buffers, buffer)
+	}
+	return buffers, nil
+}
+
+// WriteAll writes the buffers to a single OBJ file, each as an object of
+// its own, the inverse of ReadMulti. The buffers are merged like Merge, so
+// the indices of every object are offset by the elements of the objects
+// before it, and the mtllib statement lists the distinct material libraries
+// of the buffers. Objects are named after their first group, or "objectN"
+// for the Nth buffer without groups; buffers without faces get no object.
+// Lines are written after the faces of all objects, and Lossless does not
+// apply.
+func WriteAll(w io.Writer, buffers []*ObjBuffer, options WriteOptions) error {
+	merged := Merge(buffers...)
+	var libraries []string
+	seen := make(map[string]bool)
+	var objects []objectStart
+	first := 0
+	for i, b := range buffers {
+		for _, library := range strings.Fields(b.MTL) {
+			if !seen[library] {
+				seen[library] = true
+				libraries = append(libraries, library)
+			}
+		}
+		if len(b.F) == 0 {
+			continue
+		}
+		name := fmt.Sprintf("object%d", i+1)
+		if len(b.G) > 0 {
+			name = b.G[0].Name
+		}
+		objects = append(objects, objectStart{Name: name, FirstFaceIndex: first})
+		first += len(b.F)
+	}
+	merged.MTL = strings.Join(libraries, " ")
+	options.Lossless = false
+	return merged.writeWith(w, options, objects)
+}
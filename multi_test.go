@@ -0,0 +1,122 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+const readMultiTestObj = `mtllib models.mtl
+v 0 0 0
+v 1 0 0
+v 0 1 0
+v 5 5 5
+v 6 5 5
+v 5 6 5
+v 5 5 6
+o first
+usemtl red
+f 1 2 3
+o empty
+o second
+g body
+usemtl blue
+f 4 5 6
+f 4 6 7
+`
+
+func TestReadMulti_Objects_SplitIntoBuffers(t *testing.T) {
+	// Act
+	buffers, err := ReadMulti(strings.NewReader(readMultiTestObj))
+
+	// Assert
+	assert.NoError(t, err)
+	if !assert.Len(t, buffers, 2) {
+		return
+	}
+	first, second := buffers[0], buffers[1]
+	assert.Equal(t, "models.mtl", first.MTL)
+	assert.Equal(t, []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}, first.V)
+	assert.Equal(t, []Group{{Name: "first", FirstFaceIndex: 0, FaceCount: 1}}, first.G)
+	assert.Equal(t, "red", first.F[0].Material)
+	assert.Equal(t, []vec3.T{{5, 5, 5}, {6, 5, 5}, {5, 6, 5}, {5, 5, 6}}, second.V)
+	assert.Len(t, second.F, 2)
+	assert.Equal(t, []Group{{Name: "body", FirstFaceIndex: 0, FaceCount: 2}}, second.G)
+	assert.Equal(t, []FaceCorner{{0, -1, -1}, {2, -1, -1}, {3, -1, -1}}, second.F[1].Corners)
+}
+
+func TestReadMulti_FacesBeforeFirstObject_FormOwnBuffer(t *testing.T) {
+	// Arrange
+	data := "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\no later\nf 3 2 1\n"
+
+	// Act
+	buffers, err := ReadMulti(strings.NewReader(data))
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, buffers, 2) {
+		assert.Equal(t, "default group", buffers[0].G[0].Name)
+		assert.Equal(t, "later", buffers[1].G[0].Name)
+	}
+}
+
+func TestReadMulti_NoObjects_ReturnsSingleBuffer(t *testing.T) {
+	// Act
+	buffers, err := ReadMulti(strings.NewReader("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, buffers, 1)
+}
+
+func TestReadMulti_InvalidStatement_ReturnsError(t *testing.T) {
+	// Act
+	_, err := ReadMulti(strings.NewReader("v 0 0\n"))
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestWriteAll_Buffers_WritesObjectsWithOffsetIndices(t *testing.T) {
+	// Arrange
+	a := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "red")
+	a.MTL = "a.mtl"
+	b := &ObjBuffer{MTL: "a.mtl b.mtl", V: []vec3.T{{5, 5, 5}, {6, 5, 5}, {5, 6, 5}},
+		F: []Face{{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {2, -1, -1}}, Material: "blue"}}}
+
+	// Act
+	var buf strings.Builder
+	err := WriteAll(&buf, []*ObjBuffer{a, &ObjBuffer{}, b}, WriteOptions{OmitBanner: true})
+
+	// Assert
+	assert.NoError(t, err)
+	out := buf.String()
+	assert.Contains(t, out, "mtllib a.mtl b.mtl\n")
+	assert.Contains(t, out, "o object3\ng default group\nusemtl blue\nf 9 10 11\n")
+	assert.Equal(t, 2, strings.Count(out, "\no "))
+}
+
+func TestWriteAll_ReadMulti_RoundTrips(t *testing.T) {
+	// Arrange
+	buffers, err := ReadMulti(strings.NewReader(readMultiTestObj))
+	assert.NoError(t, err)
+
+	// Act
+	var buf strings.Builder
+	err = WriteAll(&buf, buffers, WriteOptions{RelativeIndices: true})
+	read, readErr := ReadMulti(strings.NewReader(buf.String()))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, readErr)
+	if assert.Len(t, read, 2) {
+		for i := range buffers {
+			assert.Equal(t, buffers[i].V, read[i].V)
+			assert.Equal(t, buffers[i].F, read[i].F)
+			assert.Equal(t, buffers[i].G, read[i].G)
+			assert.Equal(t, "models.mtl", read[i].MTL)
+		}
+	}
+}
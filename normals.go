@@ -0,0 +1,148 @@
+package obj
+
+import (
+	"math"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+)
+
+// normalTolerance is how far from 1 the length of a normal may be before
+// NormalizeNormals rescales it.
+const normalTolerance = 1e-4
+
+// NormalizeNormals rescales the normals to unit length and replaces the
+// ones that cannot be, with a zero length or components that are not
+// finite numbers. With fromFaces, a replaced normal is the area-weighted
+// average of the normals of the faces referencing it; otherwise, or when
+// these faces have no area, it is +Z. It returns the number of normals
+// changed.
+func (b *ObjBuffer) NormalizeNormals(fromFaces bool) int {
+	var replacements []dvec3.T
+	if fromFaces {
+		replacements = make([]dvec3.T, len(b.VN))
+		for i := range b.F {
+			n := b.newellNormal(i)
+			for _, c := range b.F[i].Corners {
+				if c.NormalIndex >= 0 && c.NormalIndex < len(b.VN) {
+					replacements[c.NormalIndex].Add(&n)
+				}
+			}
+		}
+	}
+
+	fixed := 0
+	for i, n := range b.VN {
+		d := dvec3.T{float64(n[0]), float64(n[1]), float64(n[2])}
+		length := d.Length()
+		if length > 0 && !math.IsInf(length, 0) && !math.IsNaN(length) {
+			if math.Abs(length-1) > normalTolerance {
+				d.Scale(1 / length)
+				b.VN[i] = vec3.T{float32(d[0]), float32(d[1]), float32(d[2])}
+				fixed++
+			}
+			continue
+		}
+		b.VN[i] = vec3.UnitZ
+		if replacements != nil {
+			if r := replacements[i]; r.Length() > 0 {
+				r.Normalize()
+				b.VN[i] = vec3.T{float32(r[0]), float32(r[1]), float32(r[2])}
+			}
+		}
+		fixed++
+	}
+	return fixed
+}
+
+// NormalOptions controls how ComputeNormals smooths the normals.
+type NormalOptions struct {
+	// CreaseAngle is the largest angle, in degrees, between two faces
+	// sharing a vertex for their normals to be smoothed there. Sharper
+	// edges stay hard. 0 smooths faces at any angle.
+	CreaseAngle float64
+	// IgnoreSmoothingGroups smooths faces regardless of their smoothing
+	// groups.
+	IgnoreSmoothingGroups bool
+}
+
+// ComputeNormals replaces the normals of the buffer by normals computed from
+// the faces. The normal of a face at one of its vertices averages, weighted
+// by area, the normals of the faces around the vertex it is smoothed with:
+// faces sharing its smoothing group, and within the crease angle. Faces
+// with smoothing off keep their own normal. When no face has a smoothing
+// group, as in files without "s" statements, all faces are smoothed
+// together.
+func (b *ObjBuffer) ComputeNormals(options NormalOptions) {
+	faceNormals := make([]dvec3.T, len(b.F))
+	units := make([]dvec3.T, len(b.F))
+	grouped := false
+	for i := range b.F {
+		faceNormals[i] = b.newellNormal(i)
+		units[i] = faceNormals[i]
+		if units[i].Length() > 0 {
+			units[i].Normalize()
+		}
+		grouped = grouped || b.F[i].SmoothingGroup != 0
+	}
+	smoothAll := options.IgnoreSmoothingGroups || !grouped
+	minCos := -1.0
+	if options.CreaseAngle > 0 {
+		minCos = math.Cos(options.CreaseAngle * math.Pi / 180)
+	}
+	smoothed := func(f, g int) bool {
+		if f == g {
+			return true
+		}
+		if !smoothAll {
+			sf := b.F[f].SmoothingGroup
+			if sf == 0 || sf != b.F[g].SmoothingGroup {
+				return false
+			}
+		}
+		return dvec3.Dot(&units[f], &units[g]) >= minCos-1e-12
+	}
+
+	// facesAt lists the faces around every vertex.
+	facesAt := make(map[int][]int)
+	for i := range b.F {
+		for _, loop := range b.F[i].loops() {
+			for _, c := range loop {
+				faces := facesAt[c.VertexIndex]
+				if n := len(faces); n == 0 || faces[n-1] != i {
+					facesAt[c.VertexIndex] = append(faces, i)
+				}
+			}
+		}
+	}
+
+	b.VN = nil
+	indices := make(map[vec3.T]int)
+	for i := range b.F {
+		for _, loop := range b.F[i].loops() {
+			for j := range loop {
+				var n dvec3.T
+				for _, g := range facesAt[loop[j].VertexIndex] {
+					if smoothed(i, g) {
+						n.Add(&faceNormals[g])
+					}
+				}
+				if n.Length() == 0 {
+					n = units[i]
+				}
+				normal := vec3.UnitZ
+				if n.Length() > 0 {
+					n.Normalize()
+					normal = vec3.T{float32(n[0]), float32(n[1]), float32(n[2])}
+				}
+				index, ok := indices[normal]
+				if !ok {
+					index = len(b.VN)
+					indices[normal] = index
+					b.VN = append(b.VN, normal)
+				}
+				loop[j].NormalIndex = index
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package obj
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_NormalizeNormals_RescalesAndReplaces(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{
+		V:  []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 0, 1}},
+		VN: []vec3.T{{0, 0, 2}, {0, 0, 0}, {float32(math.NaN()), 0, 0}, {0, 1, 0}},
+		F: []Face{{Corners: []FaceCorner{
+			{VertexIndex: 0, NormalIndex: 1, TexcoordIndex: -1},
+			{VertexIndex: 1, NormalIndex: 1, TexcoordIndex: -1},
+			{VertexIndex: 2, NormalIndex: 3, TexcoordIndex: -1},
+		}}},
+	}
+
+	// Act
+	fixed := buffer.NormalizeNormals(false)
+
+	// Assert
+	assert.Equal(t, 3, fixed)
+	assert.Equal(t, []vec3.T{{0, 0, 1}, {0, 0, 1}, {0, 0, 1}, {0, 1, 0}}, buffer.VN)
+}
+
+func TestObjBuffer_NormalizeNormals_FromFaces_UsesFaceNormals(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{
+		V:  []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 0, 1}},
+		VN: []vec3.T{{0, 0, 0}, {0, 0, 0}},
+		F: []Face{{Corners: []FaceCorner{
+			{VertexIndex: 0, NormalIndex: 0, TexcoordIndex: -1},
+			{VertexIndex: 1, NormalIndex: 0, TexcoordIndex: -1},
+			{VertexIndex: 2, NormalIndex: 0, TexcoordIndex: -1},
+		}}},
+	}
+
+	// Act
+	fixed := buffer.NormalizeNormals(true)
+
+	// Assert
+	assert.Equal(t, 2, fixed)
+	assert.Equal(t, vec3.T{0, -1, 0}, buffer.VN[0])
+	assert.Equal(t, vec3.T{0, 0, 1}, buffer.VN[1])
+}
+
+// readSmoothingCube reads a unit cube whose faces are preceded by the given
+// smoothing statements, one per face, or none if empty.
+func readSmoothingCube(t *testing.T, smoothing ...string) *ObjReader {
+	faces := []string{"f 1 4 3 2", "f 5 6 7 8", "f 1 2 6 5", "f 2 3 7 6", "f 3 4 8 7", "f 4 1 5 8"}
+	input := "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nv 0 0 1\nv 1 0 1\nv 1 1 1\nv 0 1 1\n"
+	for i, f := range faces {
+		if len(smoothing) > 0 {
+			input += smoothing[i] + "\n"
+		}
+		input += f + "\n"
+	}
+	loader := &ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+	return loader
+}
+
+func TestObjBuffer_ComputeNormals_NoSmoothingGroups_SmoothsAll(t *testing.T) {
+	// Arrange
+	cube := readSmoothingCube(t)
+
+	// Act
+	cube.ComputeNormals(NormalOptions{})
+
+	// Assert
+	assert.Len(t, cube.VN, 8)
+	n := cube.VN[cube.F[0].Corners[0].NormalIndex]
+	assert.InDelta(t, -1/math.Sqrt(3), n[0], 1e-6)
+	assert.InDelta(t, -1/math.Sqrt(3), n[2], 1e-6)
+}
+
+func TestObjBuffer_ComputeNormals_CreaseAngle_KeepsEdgesHard(t *testing.T) {
+	// Arrange
+	cube := readSmoothingCube(t)
+
+	// Act
+	cube.ComputeNormals(NormalOptions{CreaseAngle: 60})
+
+	// Assert
+	assert.Len(t, cube.VN, 6)
+	assert.Equal(t, vec3.T{0, 0, -1}, cube.VN[cube.F[0].Corners[0].NormalIndex])
+	assert.Equal(t, vec3.T{0, 0, 1}, cube.VN[cube.F[1].Corners[2].NormalIndex])
+}
+
+func TestObjBuffer_ComputeNormals_SmoothingGroups_SmoothsWithinGroups(t *testing.T) {
+	// Arrange
+	cube := readSmoothingCube(t, "s off", "s off", "s 1", "s 1", "s 1", "s 1")
+
+	// Act
+	cube.ComputeNormals(NormalOptions{})
+
+	// Assert
+	assert.Equal(t, vec3.T{0, 0, -1}, cube.VN[cube.F[0].Corners[0].NormalIndex])
+	assert.Equal(t, vec3.T{0, 0, 1}, cube.VN[cube.F[1].Corners[0].NormalIndex])
+	side := cube.VN[cube.F[2].Corners[0].NormalIndex]
+	assert.InDelta(t, -1/math.Sqrt(2), side[0], 1e-6)
+	assert.InDelta(t, -1/math.Sqrt(2), side[1], 1e-6)
+	assert.InDelta(t, 0, side[2], 1e-6)
+	assert.Len(t, cube.VN, 2+4)
+}
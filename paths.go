@@ -0,0 +1,79 @@
+package obj
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// PathStrategy selects how RewritePaths rewrites the paths of a scene.
+type PathStrategy int
+
+const (
+	// PathRelative makes the paths relative to the directory of the scene.
+	// Absolute paths that do not name a file, such as Windows paths of the
+	// machine the asset was authored on, are replaced by their longest
+	// trailing part naming a file in the directory, or by their base name.
+	PathRelative PathStrategy = iota
+	// PathBaseName keeps only the file name of the paths, for assets whose
+	// files are all in the directory of the scene.
+	PathBaseName
+)
+
+// RewritePaths rewrites the material library of the buffers and the texture
+// paths of the materials into portable paths with forward slashes, as set
+// by strategy, so that the scene is written with paths that resolve on any
+// system. Paths are resolved against Dir.
+func (s *Scene) RewritePaths(strategy PathStrategy) {
+	buffers := []*ObjBuffer{s.Buffer}
+	for _, n := range s.Nodes {
+		buffers = append(buffers, n.Buffer)
+	}
+	for _, b := range buffers {
+		if b != nil && b.MTL != "" {
+			b.MTL = s.portablePath(b.MTL, strategy)
+		}
+	}
+	for _, m := range s.Materials {
+		for _, t := range m.texturePaths() {
+			if *t != "" {
+				*t = s.portablePath(*t, strategy)
+			}
+		}
+	}
+}
+
+// portablePath rewrites p as set by strategy.
+func (s *Scene) portablePath(p string, strategy PathStrategy) string {
+	p = strings.Replace(p, "\\", "/", -1)
+	// A drive letter makes the path absolute on Windows only.
+	drive := len(p) >= 2 && p[1] == ':' && (p[0]|0x20 >= 'a' && p[0]|0x20 <= 'z')
+	if drive {
+		p = p[2:]
+	}
+	if strategy == PathBaseName {
+		return path.Base(p)
+	}
+	if !drive && !strings.HasPrefix(p, "/") {
+		return path.Clean(p)
+	}
+
+	if !drive {
+		if _, err := os.Stat(p); err == nil {
+			if dir, err := filepath.Abs(s.Dir); err == nil {
+				if rel, err := filepath.Rel(dir, p); err == nil {
+					return filepath.ToSlash(rel)
+				}
+			}
+		}
+	}
+	parts := strings.Split(strings.Trim(path.Clean(p), "/"), "/")
+	for i := range parts {
+		candidate := strings.Join(parts[i:], "/")
+		if _, err := os.Stat(filepath.Join(s.Dir, filepath.FromSlash(candidate))); err == nil {
+			return candidate
+		}
+	}
+	return parts[len(parts)-1]
+}
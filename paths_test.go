@@ -0,0 +1,55 @@
+package obj
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScene_RewritePaths_Relative_MakesPathsPortable(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "textures"), 0755))
+	png := encodeTestPNG(image.NewGray(image.Rect(0, 0, 1, 1)))
+	writeTestTextures(t, dir, map[string][]byte{"textures/wood.png": png, "stone.png": png})
+	material := &Material{
+		Name:           "wood",
+		DiffuseTexture: `C:\Users\artist\project\textures\wood.png`,
+		BumpTexture:    `maps\bump.png`,
+		AlphaTexture:   `D:\elsewhere\missing.png`,
+		NormalTexture:  filepath.Join(dir, "stone.png"),
+	}
+	material.ReflectionMap.CubeTop = `.\textures\..\sky.png`
+	scene := &Scene{Buffer: &ObjBuffer{MTL: `C:\Users\artist\project\scene.mtl`}, Dir: dir,
+		Materials: map[string]*Material{"wood": material}}
+
+	// Act
+	scene.RewritePaths(PathRelative)
+
+	// Assert
+	assert.Equal(t, "scene.mtl", scene.Buffer.MTL)
+	assert.Equal(t, "textures/wood.png", material.DiffuseTexture)
+	assert.Equal(t, "maps/bump.png", material.BumpTexture)
+	assert.Equal(t, "missing.png", material.AlphaTexture)
+	assert.Equal(t, "stone.png", material.NormalTexture)
+	assert.Equal(t, "sky.png", material.ReflectionMap.CubeTop)
+}
+
+func TestScene_RewritePaths_BaseName_KeepsFileNames(t *testing.T) {
+	// Arrange
+	material := &Material{Name: "a", DiffuseTexture: `C:\art\maps\a.png`, SpecularTexture: "maps/b.png"}
+	node := &ObjBuffer{MTL: "/home/artist/lib/node.mtl"}
+	scene := &Scene{Buffer: &ObjBuffer{}, Nodes: []Node{{Buffer: node}}, Materials: map[string]*Material{"a": material}}
+
+	// Act
+	scene.RewritePaths(PathBaseName)
+
+	// Assert
+	assert.Equal(t, "", scene.Buffer.MTL)
+	assert.Equal(t, "node.mtl", node.MTL)
+	assert.Equal(t, "a.png", material.DiffuseTexture)
+	assert.Equal(t, "b.png", material.SpecularTexture)
+}
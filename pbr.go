@@ -0,0 +1,177 @@
+package obj
+
+import "math"
+
+// PBRMaterial is a material in the metallic-roughness model of glTF.
+// Textures are referenced by the paths of the material library.
+type PBRMaterial struct {
+	Name string
+	// BaseColor is the linear RGBA base color, alpha being the opacity.
+	BaseColor        [4]float32
+	BaseColorTexture string
+	Metallic         float32
+	Roughness        float32
+	NormalTexture    string
+	Emissive         [3]float32
+	EmissiveTexture  string
+	// DisplacementTexture and DecalTexture have no counterpart in the
+	// metallic-roughness model and are kept as they are.
+	DisplacementTexture string
+	DecalTexture        string
+	// Blend reports whether the material is transparent and must be alpha
+	// blended.
+	Blend bool
+}
+
+// ConvertMaterialToPBR converts m to the metallic-roughness model:
+//
+//   - Kd and d give the base color and its alpha, map_Kd the base color
+//     texture. Blend is set when d is below 1.
+//   - Pm and Pr give the metallic factor and the roughness. Without them,
+//     both and the base color are derived from Ks and Ns, see
+//     ToMetallicRoughness.
+//   - norm gives the normal texture, or map_bump and bump without it.
+//   - disp and decal give the displacement and decal textures.
+//   - map_Ke gives the emissive texture, with a white emissive factor. Ke
+//     alone is ignored, since the material reader defaults it to grey.
+func ConvertMaterialToPBR(m *Material) PBRMaterial {
+	color, metallic, roughness := m.ToMetallicRoughness()
+	p := PBRMaterial{
+		Name:                m.Name,
+		BaseColor:           [4]float32{color[0], color[1], color[2], float32(m.Opacity)},
+		BaseColorTexture:    m.DiffuseTexture,
+		Metallic:            metallic,
+		Roughness:           roughness,
+		NormalTexture:       m.NormalTexture,
+		EmissiveTexture:     m.EmissiveTexture,
+		Blend:               m.Opacity < 1,
+		DisplacementTexture: m.DisplacementTexture,
+		DecalTexture:        m.DecalTexture,
+	}
+	if p.NormalTexture == "" {
+		p.NormalTexture = m.BumpTexture
+	}
+	if m.EmissiveTexture != "" {
+		p.Emissive = [3]float32{1, 1, 1}
+	}
+	return p
+}
+
+// dielectricSpecular is the specular reflectance of dielectrics in the
+// metallic-roughness model.
+const dielectricSpecular = 0.04
+
+// ToMetallicRoughness returns the base color, metallic factor and roughness
+// of m in the metallic-roughness model.
+//
+// When m has Pm or Pr, the base color is Kd and the factors are taken as
+// is, a missing Pr being derived from Ns. Otherwise the material is a
+// specular one: the metallic factor is solved from the perceived
+// brightness of Kd and Ks with the usual specular-glossiness conversion,
+// and for metals the base color is blended from Kd towards Ks.
+//
+// The roughness is derived from the specular exponent as sqrt(2/(Ns+2)),
+// or is 1 without Ns. illum 1 has no specular highlight, Ns is then
+// ignored. illum 0 cannot be told apart from a missing illum statement and,
+// like the other illumination models, does not change the conversion.
+func (m *Material) ToMetallicRoughness() (baseColor [3]float32, metallic, roughness float32) {
+	baseColor = [3]float32{1, 1, 1}
+	if len(m.Diffuse) >= 3 {
+		copy(baseColor[:], m.Diffuse[:3])
+	}
+	roughness = m.Roughness
+	if roughness == 0 {
+		roughness = 1
+		if ns := specularExponent(m); ns > 0 && m.Illumination != 1 {
+			roughness = float32(math.Sqrt(2 / (ns + 2)))
+		}
+	}
+	if m.Metallic != 0 || m.Roughness != 0 || len(m.Specular) < 3 {
+		return baseColor, m.Metallic, roughness
+	}
+
+	var diffuse, specular [3]float64
+	for i := 0; i < 3; i++ {
+		diffuse[i], specular[i] = float64(baseColor[i]), float64(m.Specular[i])
+	}
+	oneMinusSpecularStrength := 1 - math.Max(specular[0], math.Max(specular[1], specular[2]))
+	solved := solveMetallic(perceivedBrightness(diffuse), perceivedBrightness(specular), oneMinusSpecularStrength)
+	if solved == 0 {
+		return baseColor, 0, roughness
+	}
+	const epsilon = 1e-6
+	for i := 0; i < 3; i++ {
+		fromDiffuse := diffuse[i] * oneMinusSpecularStrength / (1 - dielectricSpecular) / math.Max(1-solved, epsilon)
+		fromSpecular := (specular[i] - dielectricSpecular*(1-solved)) / math.Max(solved, epsilon)
+		c := fromDiffuse + (fromSpecular-fromDiffuse)*solved*solved
+		baseColor[i] = float32(math.Max(0, math.Min(1, c)))
+	}
+	return baseColor, float32(solved), roughness
+}
+
+// perceivedBrightness returns the brightness of the color c as perceived by
+// the eye.
+func perceivedBrightness(c [3]float64) float64 {
+	return math.Sqrt(0.299*c[0]*c[0] + 0.587*c[1]*c[1] + 0.114*c[2]*c[2])
+}
+
+// solveMetallic returns the metallic factor for which the diffuse and
+// specular brightness match those of a metallic-roughness material.
+func solveMetallic(diffuse, specular, oneMinusSpecularStrength float64) float64 {
+	if specular < dielectricSpecular {
+		return 0
+	}
+	a := dielectricSpecular
+	b := diffuse*oneMinusSpecularStrength/(1-dielectricSpecular) + specular - 2*dielectricSpecular
+	c := dielectricSpecular - specular
+	d := b*b - 4*a*c
+	return math.Max(0, math.Min(1, (-b+math.Sqrt(d))/(2*a)))
+}
+
+// ConvertPBRToMaterial converts p to an MTL material, reversing
+// ConvertMaterialToPBR:
+//
+//   - The base color gives Kd and d, its texture map_Kd.
+//   - The metallic factor gives Pm, and Ks is interpolated between the
+//     reflectance of dielectrics, 0.04, and the base color.
+//   - The roughness gives Pr and the specular exponent Ns = 2/r²-2.
+//   - The normal texture gives norm, the emissive factor and texture give
+//     Ke and map_Ke, the displacement and decal textures disp and decal.
+//   - illum is 2, highlights on.
+func ConvertPBRToMaterial(p PBRMaterial) *Material {
+	m := &Material{
+		Name:                p.Name,
+		Ambient:             []float32{0, 0, 0, 1},
+		Diffuse:             []float32{p.BaseColor[0], p.BaseColor[1], p.BaseColor[2], 1},
+		Specular:            make([]float32, 4),
+		Emissive:            []float32{p.Emissive[0], p.Emissive[1], p.Emissive[2], 1},
+		TransmissionFilter:  []float32{1, 1, 1},
+		DiffuseTexture:      p.BaseColorTexture,
+		EmissiveTexture:     p.EmissiveTexture,
+		NormalTexture:       p.NormalTexture,
+		DisplacementTexture: p.DisplacementTexture,
+		DecalTexture:        p.DecalTexture,
+		Opacity:             float64(p.BaseColor[3]),
+		Illumination:        2,
+		Roughness:           p.Roughness,
+		Metallic:            p.Metallic,
+	}
+	for i := 0; i < 3; i++ {
+		m.Specular[i] = dielectricSpecular + (p.BaseColor[i]-dielectricSpecular)*p.Metallic
+	}
+	m.Specular[3] = 1
+	if p.Roughness > 0 {
+		r := float64(p.Roughness)
+		m.Shininess = (2/(r*r) - 2) / shininessScale
+	}
+	return m
+}
+
+// shininessScale is the factor the material reader divides Ns by to store
+// it as Shininess.
+const shininessScale = 1000
+
+// specularExponent returns the Ns value of m.
+func specularExponent(m *Material) float64 {
+	return m.Shininess * shininessScale
+}
@@ -0,0 +1,134 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertMaterialToPBR_PhongMaterial_DerivesRoughness(t *testing.T) {
+	// Arrange
+	mtls, err := ReadMaterialsFrom(strings.NewReader("newmtl wall\nKd 0.5 0.5 0.5\nNs 98\nd 0.5\nbump wall_n.png\n"), "wall.mtl")
+	assert.NoError(t, err)
+
+	// Act
+	p := ConvertMaterialToPBR(mtls["wall"])
+
+	// Assert
+	assert.InDelta(t, 0.65, p.BaseColor[0], 1e-6)
+	assert.InDelta(t, 0.5, p.BaseColor[3], 1e-6)
+	assert.InDelta(t, 0.1414, p.Roughness, 1e-4)
+	assert.Equal(t, float32(0), p.Metallic)
+	assert.Equal(t, "wall_n.png", p.NormalTexture)
+	assert.True(t, p.Blend)
+}
+
+func TestConvertMaterialToPBR_NormAndBump_PrefersNorm(t *testing.T) {
+	// Arrange
+	m := &Material{Name: "wall", Opacity: 1, BumpTexture: "wall_h.png", NormalTexture: "wall_n.png", DisplacementTexture: "wall_d.png"}
+
+	// Act
+	p := ConvertMaterialToPBR(m)
+
+	// Assert
+	assert.Equal(t, "wall_n.png", p.NormalTexture)
+	assert.Equal(t, "wall_d.png", p.DisplacementTexture)
+	assert.Equal(t, "wall_n.png", ConvertPBRToMaterial(p).NormalTexture)
+}
+
+func TestConvertMaterialToPBR_IllumOne_IgnoresShininess(t *testing.T) {
+	// Arrange
+	m := &Material{Name: "flat", Diffuse: []float32{1, 0, 0}, Opacity: 1, Shininess: 0.098, Illumination: 1}
+
+	// Act
+	p := ConvertMaterialToPBR(m)
+
+	// Assert
+	assert.Equal(t, float32(1), p.Roughness)
+	assert.False(t, p.Blend)
+}
+
+func TestConvertMaterialToPBR_RoughnessGiven_KeepsIt(t *testing.T) {
+	// Arrange
+	m := &Material{Name: "metal", Diffuse: []float32{1, 1, 1}, Opacity: 1, Shininess: 0.5, Roughness: 0.25, Metallic: 1}
+
+	// Act
+	p := ConvertMaterialToPBR(m)
+
+	// Assert
+	assert.Equal(t, float32(0.25), p.Roughness)
+	assert.Equal(t, float32(1), p.Metallic)
+}
+
+func TestConvertPBRToMaterial_RoundTrips(t *testing.T) {
+	// Arrange
+	p := PBRMaterial{
+		Name:             "gold",
+		BaseColor:        [4]float32{1, 0.8, 0.3, 1},
+		BaseColorTexture: "gold.png",
+		Metallic:         1,
+		Roughness:        0.5,
+		NormalTexture:    "gold_n.png",
+	}
+
+	// Act
+	m := ConvertPBRToMaterial(p)
+
+	// Assert
+	assert.Equal(t, float32(0.8), m.Specular[1])
+	assert.InDelta(t, 6, specularExponent(m), 1e-9)
+	assert.Equal(t, p, ConvertMaterialToPBR(m))
+}
+
+func TestMaterial_ToMetallicRoughness_MetallicSpecular_DerivesMetal(t *testing.T) {
+	// Arrange
+	m := &Material{Diffuse: []float32{0, 0, 0}, Specular: []float32{1, 0.8, 0.3}, Shininess: 0.2, Opacity: 1}
+
+	// Act
+	color, metallic, roughness := m.ToMetallicRoughness()
+
+	// Assert
+	assert.Equal(t, float32(1), metallic)
+	assert.InDelta(t, 0.8, color[1], 1e-6)
+	assert.InDelta(t, 0.0995, roughness, 1e-4)
+}
+
+func TestMaterial_ToMetallicRoughness_LowSpecular_IsDielectric(t *testing.T) {
+	// Arrange
+	m := &Material{Diffuse: []float32{0.5, 0.2, 0.1}, Specular: []float32{0.02, 0.02, 0.02}, Opacity: 1}
+
+	// Act
+	color, metallic, roughness := m.ToMetallicRoughness()
+
+	// Assert
+	assert.Equal(t, float32(0), metallic)
+	assert.Equal(t, [3]float32{0.5, 0.2, 0.1}, color)
+	assert.Equal(t, float32(1), roughness)
+}
+
+func TestMaterial_ToMetallicRoughness_PBRFields_KeepsThem(t *testing.T) {
+	// Arrange
+	m := &Material{Diffuse: []float32{0.5, 0.5, 0.5}, Specular: []float32{1, 1, 1}, Roughness: 0.4, Opacity: 1}
+
+	// Act
+	_, metallic, roughness := m.ToMetallicRoughness()
+
+	// Assert
+	assert.Equal(t, float32(0), metallic)
+	assert.Equal(t, float32(0.4), roughness)
+}
+
+func TestConvertMaterialToPBR_MapBumpFromMTL_BecomesNormalTexture(t *testing.T) {
+	// Arrange
+	mtls, err := ReadMaterialsFrom(strings.NewReader("newmtl wall\nKd 1 1 1\nmap_bump wall_h.png\nmap_d wall_a.png\n"), "wall.mtl")
+	assert.NoError(t, err)
+
+	// Act
+	p := ConvertMaterialToPBR(mtls["wall"])
+
+	// Assert
+	assert.Equal(t, "wall_h.png", mtls["wall"].BumpTexture)
+	assert.Equal(t, "wall_a.png", mtls["wall"].AlphaTexture)
+	assert.Equal(t, "wall_h.png", p.NormalTexture)
+}
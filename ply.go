@@ -0,0 +1,52 @@
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// WritePLY writes the vertices and faces of the buffer as ASCII PLY,
+// including the vertex colors if the buffer has them. Faces keep their
+// corners, normals and texture coordinates are not written. The buffer
+// offset is recorded in a comment but not applied.
+func (b *ObjBuffer) WritePLY(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	colors := b.hasVertexColors()
+
+	fmt.Fprintf(bw, "ply\nformat ascii 1.0\ncomment Exported using %s\n", DefaultGenerator)
+	if !b.Offset.IsZero() {
+		fmt.Fprintf(bw, "comment offset %g %g %g\n", b.Offset[0], b.Offset[1], b.Offset[2])
+	}
+	fmt.Fprintf(bw, "element vertex %d\nproperty float x\nproperty float y\nproperty float z\n", len(b.V))
+	if colors {
+		io.WriteString(bw, "property uchar red\nproperty uchar green\nproperty uchar blue\n")
+	}
+	countType := "uchar"
+	for _, f := range b.F {
+		if len(f.Corners) > 255 {
+			countType = "int"
+			break
+		}
+	}
+	fmt.Fprintf(bw, "element face %d\nproperty list %s int vertex_indices\nend_header\n", len(b.F), countType)
+
+	for i, v := range b.V {
+		fmt.Fprintf(bw, "%g %g %g", v[0], v[1], v[2])
+		if colors {
+			c := b.VC[i]
+			fmt.Fprintf(bw, " %d %d %d", colorByte(c[0]), colorByte(c[1]), colorByte(c[2]))
+		}
+		io.WriteString(bw, "\n")
+	}
+	for _, f := range b.F {
+		fmt.Fprintf(bw, "%d", len(f.Corners))
+		for _, c := range f.Corners {
+			fmt.Fprintf(bw, " %d", c.VertexIndex)
+		}
+		if _, err := io.WriteString(bw, "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,26 @@
+package obj
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_WritePLY_VertexColors_WritesColorProperties(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader("v 0 0 0 1 0 0\nv 1 0 0\nv 0 1 0\nv 1 1 0\nf 1 2 4 3\n")))
+
+	// Act
+	var out bytes.Buffer
+	err := loader.WritePLY(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "element vertex 4\n")
+	assert.Contains(t, out.String(), "property uchar red\n")
+	assert.Contains(t, out.String(), "element face 1\nproperty list uchar int vertex_indices\nend_header\n")
+	assert.True(t, strings.HasSuffix(out.String(), "end_header\n0 0 0 255 0 0\n1 0 0 255 255 255\n0 1 0 255 255 255\n1 1 0 255 255 255\n4 0 1 3 2\n"))
+}
@@ -0,0 +1,205 @@
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+)
+
+// PointCloud is a set of points sampled on a surface, in the frame of the
+// buffer it was sampled from. The points are sorted in octree order: points
+// in the same octree cell, at any depth, are consecutive.
+type PointCloud struct {
+	Offset    dvec3.T
+	Positions []dvec3.T
+	// Normals holds the unit normal of the surface at every point.
+	Normals []vec3.T
+	// Colors holds the color of every point, interpolated from the vertex
+	// colors. It is empty if the buffer has no vertex colors.
+	Colors []vec3.T
+	// Alphas holds the opacity of every point, interpolated from the
+	// AlphaAttribute attribute. It is empty if the buffer has no such
+	// attribute.
+	Alphas []float32
+}
+
+// samplingSeed seeds the random sampling, so that sampling a buffer twice
+// gives the same points.
+const samplingSeed = 1
+
+// SamplePoints places points at random on the faces, density points per
+// unit of area on average. The normal of a point is interpolated from the
+// normals of the corners of its face if they all have one, and is the
+// normal of the face otherwise.
+func (b *ObjBuffer) SamplePoints(density float64) (*PointCloud, error) {
+	if density <= 0 || math.IsNaN(density) || math.IsInf(density, 0) {
+		return nil, fmt.Errorf("Invalid point density %g", density)
+	}
+	cloud := &PointCloud{Offset: b.Offset}
+	colors := b.hasVertexColors()
+	alphas, hasAlphas := b.Attributes[AlphaAttribute]
+	hasAlphas = hasAlphas && alphas.Size == 1 && alphas.Len() == len(b.V)
+	rng := rand.New(rand.NewSource(samplingSeed))
+	b.EachTriangle(func(_ [3]vec3.T, corners [3]FaceCorner, _ int) bool {
+		var p [3]dvec3.T
+		for k, c := range corners {
+			p[k] = b.positionD(c.VertexIndex)
+		}
+		e1, e2 := dvec3.Sub(&p[1], &p[0]), dvec3.Sub(&p[2], &p[0])
+		faceNormal := dvec3.Cross(&e1, &e2)
+		area := faceNormal.Length() / 2
+		if area == 0 {
+			return true
+		}
+		faceNormal.Normalize()
+		smooth := true
+		for _, c := range corners {
+			smooth = smooth && c.NormalIndex >= 0 && c.NormalIndex < len(b.VN)
+		}
+
+		// Round the expected number of points up or down at random, so that
+		// small triangles get their share on average.
+		n := int(area*density + rng.Float64())
+		for i := 0; i < n; i++ {
+			// Uniform barycentric coordinates.
+			r1, r2 := math.Sqrt(rng.Float64()), rng.Float64()
+			w := [3]float64{1 - r1, r1 * (1 - r2), r1 * r2}
+			var point, normal, color dvec3.T
+			alpha := 0.0
+			for k := range corners {
+				q := p[k].Scaled(w[k])
+				point.Add(&q)
+				if smooth {
+					vn := b.VN[corners[k].NormalIndex]
+					normal.Add(&dvec3.T{float64(vn[0]) * w[k], float64(vn[1]) * w[k], float64(vn[2]) * w[k]})
+				}
+				if colors {
+					vc := b.VC[corners[k].VertexIndex]
+					color.Add(&dvec3.T{float64(vc[0]) * w[k], float64(vc[1]) * w[k], float64(vc[2]) * w[k]})
+				}
+				if hasAlphas {
+					alpha += alphas.Float(corners[k].VertexIndex, 0) * w[k]
+				}
+			}
+			if !smooth || normal.Length() == 0 {
+				normal = faceNormal
+			}
+			normal.Normalize()
+			cloud.Positions = append(cloud.Positions, point)
+			cloud.Normals = append(cloud.Normals, vec3.T{float32(normal[0]), float32(normal[1]), float32(normal[2])})
+			if colors {
+				cloud.Colors = append(cloud.Colors, vec3.T{float32(color[0]), float32(color[1]), float32(color[2])})
+			}
+			if hasAlphas {
+				cloud.Alphas = append(cloud.Alphas, float32(alpha))
+			}
+		}
+		return true
+	})
+	cloud.sortOctree()
+	return cloud, nil
+}
+
+// Len returns the number of points.
+func (c *PointCloud) Len() int {
+	return len(c.Positions)
+}
+
+// sortOctree sorts the points by the Morton code of their position in the
+// bounding cube of the cloud, which is the order of a depth-first walk of
+// its octree.
+func (c *PointCloud) sortOctree() {
+	if len(c.Positions) == 0 {
+		return
+	}
+	box := dvec3.Box{Min: c.Positions[0], Max: c.Positions[0]}
+	for i := range c.Positions {
+		box.Extend(&c.Positions[i])
+	}
+	size := math.Max(box.Max[0]-box.Min[0], math.Max(box.Max[1]-box.Min[1], box.Max[2]-box.Min[2]))
+	if size == 0 {
+		return
+	}
+	const levels = 21
+	codes := make([]uint64, len(c.Positions))
+	for i, p := range c.Positions {
+		for axis := 0; axis < 3; axis++ {
+			cell := uint64((p[axis] - box.Min[axis]) / size * (1<<levels - 1))
+			for bit := uint(0); bit < levels; bit++ {
+				codes[i] |= (cell >> bit & 1) << (3*bit + uint(axis))
+			}
+		}
+	}
+	order := make([]int, len(c.Positions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return codes[order[i]] < codes[order[j]] })
+
+	positions := make([]dvec3.T, len(order))
+	normals := make([]vec3.T, len(order))
+	for j, i := range order {
+		positions[j] = c.Positions[i]
+		normals[j] = c.Normals[i]
+	}
+	c.Positions, c.Normals = positions, normals
+	if len(c.Colors) > 0 {
+		colors := make([]vec3.T, len(order))
+		for j, i := range order {
+			colors[j] = c.Colors[i]
+		}
+		c.Colors = colors
+	}
+	if len(c.Alphas) > 0 {
+		alphas := make([]float32, len(order))
+		for j, i := range order {
+			alphas[j] = c.Alphas[i]
+		}
+		c.Alphas = alphas
+	}
+}
+
+// WritePLY writes the points as ASCII PLY with their normals, and their
+// colors and opacities if the cloud has them. The offset is recorded in a comment but not
+// applied.
+func (c *PointCloud) WritePLY(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	colors := len(c.Colors) == len(c.Positions) && len(c.Colors) > 0
+	alphas := len(c.Alphas) == len(c.Positions) && len(c.Alphas) > 0
+
+	fmt.Fprintf(bw, "ply\nformat ascii 1.0\ncomment Exported using %s\n", DefaultGenerator)
+	if !c.Offset.IsZero() {
+		fmt.Fprintf(bw, "comment offset %g %g %g\n", c.Offset[0], c.Offset[1], c.Offset[2])
+	}
+	fmt.Fprintf(bw, "element vertex %d\nproperty double x\nproperty double y\nproperty double z\n", len(c.Positions))
+	io.WriteString(bw, "property float nx\nproperty float ny\nproperty float nz\n")
+	if colors {
+		io.WriteString(bw, "property uchar red\nproperty uchar green\nproperty uchar blue\n")
+	}
+	if alphas {
+		io.WriteString(bw, "property uchar alpha\n")
+	}
+	io.WriteString(bw, "end_header\n")
+
+	for i, p := range c.Positions {
+		n := c.Normals[i]
+		fmt.Fprintf(bw, "%g %g %g %g %g %g", p[0], p[1], p[2], n[0], n[1], n[2])
+		if colors {
+			col := c.Colors[i]
+			fmt.Fprintf(bw, " %d %d %d", colorByte(col[0]), colorByte(col[1]), colorByte(col[2]))
+		}
+		if alphas {
+			fmt.Fprintf(bw, " %d", colorByte(c.Alphas[i]))
+		}
+		if _, err := io.WriteString(bw, "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,124 @@
+package obj
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_SamplePoints_Plane_CoversSurface(t *testing.T) {
+	// Arrange
+	buffer := createGrid(4, 2)
+
+	// Act
+	cloud, err := buffer.SamplePoints(100)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.InDelta(t, 1600, cloud.Len(), 120)
+	assert.Len(t, cloud.Normals, cloud.Len())
+	assert.Empty(t, cloud.Colors)
+	var quadrants [4]int
+	for i, p := range cloud.Positions {
+		assert.True(t, p[0] >= 0 && p[0] <= 4 && p[1] >= 0 && p[1] <= 4 && p[2] == 0)
+		assert.InDelta(t, 1, cloud.Normals[i][2], 1e-6)
+		quadrants[int(p[0]/2)%2+2*(int(p[1]/2)%2)]++
+	}
+	for _, n := range quadrants {
+		assert.InDelta(t, 400, n, 80)
+	}
+}
+
+func TestObjBuffer_SamplePoints_Plane_SortsInOctreeOrder(t *testing.T) {
+	// Arrange
+	buffer := createGrid(4, 2)
+
+	// Act
+	cloud, _ := buffer.SamplePoints(50)
+
+	// Assert
+	// The first octant of the bounding cube comes first, then the one along
+	// X, along Y and along both.
+	octant := func(i int) int {
+		p := cloud.Positions[i]
+		o := 0
+		if p[0] >= 2 {
+			o |= 1
+		}
+		if p[1] >= 2 {
+			o |= 2
+		}
+		return o
+	}
+	for i := 1; i < cloud.Len(); i++ {
+		assert.True(t, octant(i-1) <= octant(i))
+	}
+}
+
+func TestObjBuffer_SamplePoints_Colors_AreInterpolated(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{
+		V:  []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+		VC: []vec3.T{{1, 0, 0}, {1, 0, 0}, {0, 0, 1}},
+		VN: []vec3.T{{0, 0, 1}, {0, 0, 1}, {0, 1, 0}},
+		F:  []Face{{Corners: []FaceCorner{{0, -1, 0}, {1, -1, 0}, {2, -1, 1}}}},
+	}
+
+	// Act
+	cloud, _ := buffer.SamplePoints(200)
+	var out bytes.Buffer
+	err := cloud.WritePLY(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, cloud.Colors, cloud.Len())
+	for i, p := range cloud.Positions {
+		c := cloud.Colors[i]
+		assert.InDelta(t, p[1], c[2], 1e-5)
+		assert.InDelta(t, 1-p[1], c[0], 1e-5)
+		assert.InDelta(t, 1, cloud.Normals[i].Length(), 1e-5)
+		assert.True(t, cloud.Normals[i][1] >= 0)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Contains(t, lines, "property float nx")
+	assert.Contains(t, lines, "property uchar red")
+	assert.Contains(t, lines, fmt.Sprintf("element vertex %d", cloud.Len()))
+	assert.Len(t, strings.Fields(lines[len(lines)-1]), 9)
+}
+
+func TestObjBuffer_SamplePoints_BakedOpacity_WritesAlpha(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{
+		V: []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+		F: []Face{{Material: "glass", Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {2, -1, -1}}}},
+	}
+	buffer.BakeMaterialColors(map[string]*Material{"glass": {Diffuse: []float32{0, 0, 1}, Opacity: 0.5}})
+
+	// Act
+	cloud, _ := buffer.SamplePoints(100)
+	var out bytes.Buffer
+	err := cloud.WritePLY(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, cloud.Alphas, cloud.Len())
+	assert.InDelta(t, 0.5, cloud.Alphas[0], 1e-6)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Contains(t, lines, "property uchar alpha")
+	assert.Equal(t, "128", strings.Fields(lines[len(lines)-1])[9])
+}
+
+func TestObjBuffer_SamplePoints_InvalidDensity_Fails(t *testing.T) {
+	// Arrange
+	buffer := createGrid(4, 2)
+
+	// Act
+	_, err := buffer.SamplePoints(0)
+
+	// Assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package obj
+
+import (
+	"math"
+	"sort"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+)
+
+// pointTree is a k-d tree over points, answering nearest neighbor queries.
+// The tree is implicit: the median of every range of order splits it along
+// the axis given by the depth.
+type pointTree struct {
+	points []dvec3.T
+	order  []int
+}
+
+func newPointTree(points []dvec3.T) *pointTree {
+	t := &pointTree{points: points, order: allIndices(len(points))}
+	t.build(t.order, 0)
+	return t
+}
+
+func (t *pointTree) build(order []int, depth int) {
+	if len(order) <= 1 {
+		return
+	}
+	axis := depth % 3
+	sort.Slice(order, func(i, j int) bool { return t.points[order[i]][axis] < t.points[order[j]][axis] })
+	mid := len(order) / 2
+	t.build(order[:mid], depth+1)
+	t.build(order[mid+1:], depth+1)
+}
+
+// nearest returns the index of the point closest to p and its distance, or
+// -1 if the tree is empty.
+func (t *pointTree) nearest(p dvec3.T) (int, float64) {
+	best, distance := -1, math.Inf(1)
+	var search func(order []int, depth int)
+	search = func(order []int, depth int) {
+		if len(order) == 0 {
+			return
+		}
+		mid := len(order) / 2
+		i := order[mid]
+		if d := dvec3.Distance(&p, &t.points[i]); d < distance {
+			best, distance = i, d
+		}
+		axis := depth % 3
+		delta := p[axis] - t.points[i][axis]
+		near, far := order[:mid], order[mid+1:]
+		if delta > 0 {
+			near, far = far, near
+		}
+		search(near, depth+1)
+		if math.Abs(delta) < distance {
+			search(far, depth+1)
+		}
+	}
+	search(t.order, 0)
+	return best, distance
+}
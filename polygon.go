@@ -0,0 +1,324 @@
+package obj
+
+import (
+	"math"
+	"sort"
+
+	"github.com/flywave/go3d/vec3"
+)
+
+// AddHole declares an inner loop of the face, cutting a hole into it. The
+// corners of holes follow the opposite winding of the outer loop, as seen
+// from the front of the face.
+func (f *Face) AddHole(corners []FaceCorner) {
+	f.Holes = append(f.Holes, corners)
+}
+
+// Normal returns the unit normal of the face, on the side its outer loop
+// is counterclockwise, computed with Newell's method so that polygons that
+// are not quite planar get their average normal. Faces without area, or
+// referencing missing vertices, return the zero vector.
+func (f *Face) Normal(V []vec3.T) vec3.T {
+	n, ok := newellVector(f.Corners, V)
+	length := math.Sqrt(n[0]*n[0] + n[1]*n[1] + n[2]*n[2])
+	if !ok || length == 0 {
+		return vec3.T{}
+	}
+	return vec3.T{float32(n[0] / length), float32(n[1] / length), float32(n[2] / length)}
+}
+
+// Area returns the area of the face, that of its holes excluded, computed
+// with Newell's method. Faces referencing missing vertices have no area.
+func (f *Face) Area(V []vec3.T) float64 {
+	area := 0.0
+	for i, loop := range f.loops() {
+		n, ok := newellVector(loop, V)
+		if !ok {
+			return 0
+		}
+		a := math.Sqrt(n[0]*n[0]+n[1]*n[1]+n[2]*n[2]) / 2
+		if i > 0 {
+			a = -a
+		}
+		area += a
+	}
+	return math.Max(area, 0)
+}
+
+// newellVector returns the normal of the loop computed with Newell's
+// method, whose length is twice the area of the loop. It returns false if
+// a corner references a missing vertex.
+func newellVector(corners []FaceCorner, V []vec3.T) ([3]float64, bool) {
+	var n [3]float64
+	for j := range corners {
+		a, b := corners[j].VertexIndex, corners[(j+1)%len(corners)].VertexIndex
+		if a < 0 || a >= len(V) || b < 0 || b >= len(V) {
+			return n, false
+		}
+		p, q := V[a], V[b]
+		n[0] += float64(p[1]-q[1]) * float64(p[2]+q[2])
+		n[1] += float64(p[2]-q[2]) * float64(p[0]+q[0])
+		n[2] += float64(p[0]-q[0]) * float64(p[1]+q[1])
+	}
+	return n, true
+}
+
+// polygonNode is a corner of a polygon projected onto its plane.
+type polygonNode struct {
+	p      [2]float64
+	corner FaceCorner
+	// id identifies the corner among the loops of the face, so that the
+	// copies made by bridging are recognized.
+	id int
+}
+
+// projectLoops projects the outer loop and the holes of f onto the plane
+// they lie in, oriented so that the outer loop is counterclockwise and the
+// holes clockwise. It returns false if a corner references a missing
+// vertex or the outer loop has no area.
+func (f *Face) projectLoops(V []vec3.T) ([][]polygonNode, bool) {
+	normal, ok := newellVector(f.Corners, V)
+	if !ok {
+		return nil, false
+	}
+	// Drop the dominant axis of the normal, keeping the others in an order
+	// that sees the face from its front.
+	axes := [2]int{1, 2}
+	dominant := 0
+	if math.Abs(normal[1]) > math.Abs(normal[dominant]) {
+		dominant, axes = 1, [2]int{2, 0}
+	}
+	if math.Abs(normal[2]) > math.Abs(normal[dominant]) {
+		dominant, axes = 2, [2]int{0, 1}
+	}
+	if normal[dominant] == 0 {
+		return nil, false
+	}
+	flip := normal[dominant] < 0
+
+	loops := make([][]polygonNode, 0, 1+len(f.Holes))
+	id := 0
+	for i, corners := range f.loops() {
+		loop := make([]polygonNode, 0, len(corners))
+		for _, c := range corners {
+			if c.VertexIndex < 0 || c.VertexIndex >= len(V) {
+				return nil, false
+			}
+			v := V[c.VertexIndex]
+			p := [2]float64{float64(v[axes[0]]), float64(v[axes[1]])}
+			if flip {
+				p[0] = -p[0]
+			}
+			loop = append(loop, polygonNode{p, c, id})
+			id++
+		}
+		if hole := i > 0; hole == (signedArea(loop) > 0) {
+			for j, k := 0, len(loop)-1; j < k; j, k = j+1, k-1 {
+				loop[j], loop[k] = loop[k], loop[j]
+			}
+		}
+		if len(loop) >= 3 {
+			loops = append(loops, loop)
+		}
+	}
+	return loops, true
+}
+
+// Outline returns the corners of a single loop describing the face with
+// its holes, joined to the outer loop by degenerate bridges as some
+// exporters write them. Faces without holes return their corners.
+func (f *Face) Outline(V []vec3.T) []FaceCorner {
+	if len(f.Holes) == 0 {
+		return f.Corners
+	}
+	loops, ok := f.projectLoops(V)
+	if !ok {
+		return f.Corners
+	}
+	ring := bridgeHoles(loops[0], loops[1:])
+	corners := make([]FaceCorner, len(ring))
+	for i, n := range ring {
+		corners[i] = n.corner
+	}
+	return corners
+}
+
+// triangulateWithHoles triangulates a face with holes by ear clipping,
+// after bridging every hole to the outer loop.
+func (f *Face) triangulateWithHoles(V []vec3.T) [][]FaceCorner {
+	loops, ok := f.projectLoops(V)
+	if !ok {
+		return nil
+	}
+	ring := bridgeHoles(loops[0], loops[1:])
+	return triangleCorners(earClip(ring))
+}
+
+// triangleCorners returns the corners of the triangles.
+func triangleCorners(triangles [][3]polygonNode) [][]FaceCorner {
+	corners := make([][]FaceCorner, len(triangles))
+	for i, t := range triangles {
+		corners[i] = []FaceCorner{t[0].corner, t[1].corner, t[2].corner}
+	}
+	return corners
+}
+
+// bridgeHoles joins the holes to the outer loop by pairs of coincident
+// edges, returning a single weakly simple loop. Holes are bridged from
+// their rightmost corner to a visible corner of the loop, rightmost holes
+// first.
+func bridgeHoles(outer []polygonNode, holes [][]polygonNode) []polygonNode {
+	rightmost := func(loop []polygonNode) int {
+		best := 0
+		for i, n := range loop {
+			if n.p[0] > loop[best].p[0] || n.p[0] == loop[best].p[0] && n.p[1] < loop[best].p[1] {
+				best = i
+			}
+		}
+		return best
+	}
+	sort.SliceStable(holes, func(i, j int) bool {
+		return holes[i][rightmost(holes[i])].p[0] > holes[j][rightmost(holes[j])].p[0]
+	})
+
+	ring := append([]polygonNode(nil), outer...)
+	for _, hole := range holes {
+		m := rightmost(hole)
+		p := visibleCorner(ring, hole[m].p)
+		if p < 0 {
+			continue
+		}
+		bridged := make([]polygonNode, 0, len(ring)+len(hole)+2)
+		bridged = append(bridged, ring[:p+1]...)
+		for k := 0; k <= len(hole); k++ {
+			bridged = append(bridged, hole[(m+k)%len(hole)])
+		}
+		bridged = append(bridged, ring[p])
+		bridged = append(bridged, ring[p+1:]...)
+		ring = bridged
+	}
+	return ring
+}
+
+// visibleCorner returns the index of a corner of the counterclockwise ring
+// visible from the point m inside it, or -1.
+func visibleCorner(ring []polygonNode, m [2]float64) int {
+	// Cast a ray from m towards +x and find the closest edge it hits.
+	hitX, edge := math.Inf(1), -1
+	for i := range ring {
+		a, b := ring[i].p, ring[(i+1)%len(ring)].p
+		if a[1] == b[1] || math.Min(a[1], b[1]) > m[1] || math.Max(a[1], b[1]) < m[1] {
+			continue
+		}
+		x := a[0] + (m[1]-a[1])*(b[0]-a[0])/(b[1]-a[1])
+		if x >= m[0] && x < hitX {
+			hitX, edge = x, i
+		}
+	}
+	if edge < 0 {
+		return -1
+	}
+	a, b := edge, (edge+1)%len(ring)
+	p := a
+	if ring[b].p[0] > ring[a].p[0] {
+		p = b
+	}
+	if hitX == ring[p].p[0] && m[1] == ring[p].p[1] {
+		return p
+	}
+
+	// Corners of the ring inside the triangle (m, hit, p) may hide p; the
+	// one closest in angle to the ray is visible.
+	hit := [2]float64{hitX, m[1]}
+	best, bestTan := p, math.Inf(1)
+	for i, n := range ring {
+		if i == p || !pointInTriangle(m, hit, ring[p].p, n.p) {
+			continue
+		}
+		dx := n.p[0] - m[0]
+		if dx <= 0 {
+			continue
+		}
+		if tan := math.Abs(n.p[1]-m[1]) / dx; tan < bestTan || tan == bestTan && n.p[0] > ring[best].p[0] {
+			best, bestTan = i, tan
+		}
+	}
+	return best
+}
+
+// earClip triangulates the counterclockwise, weakly simple ring.
+func earClip(ring []polygonNode) [][3]polygonNode {
+	var triangles [][3]polygonNode
+	ring = append([]polygonNode(nil), ring...)
+	for len(ring) > 3 {
+		n := len(ring)
+		ear := -1
+		for i := 0; i < n && ear < 0; i++ {
+			if isEar(ring, i) {
+				ear = i
+			}
+		}
+		if ear < 0 {
+			// Degenerate input: clip the most convex corner to make progress.
+			best := math.Inf(-1)
+			for i := 0; i < n; i++ {
+				a, b, c := ring[(i+n-1)%n].p, ring[i].p, ring[(i+1)%n].p
+				if cross := cross2(a, b, c); cross > best {
+					best, ear = cross, i
+				}
+			}
+		}
+		triangles = append(triangles, [3]polygonNode{ring[(ear+n-1)%n], ring[ear], ring[(ear+1)%n]})
+		ring = append(ring[:ear], ring[ear+1:]...)
+	}
+	if len(ring) == 3 {
+		triangles = append(triangles, [3]polygonNode{ring[0], ring[1], ring[2]})
+	}
+	return triangles
+}
+
+// isEar reports whether corner i of the ring is convex and its triangle
+// contains no other corner of the ring.
+func isEar(ring []polygonNode, i int) bool {
+	n := len(ring)
+	a, b, c := ring[(i+n-1)%n].p, ring[i].p, ring[(i+1)%n].p
+	if cross2(a, b, c) <= 0 {
+		return false
+	}
+	for j := range ring {
+		p := ring[j].p
+		if j == i || j == (i+n-1)%n || j == (i+1)%n || p == a || p == b || p == c {
+			continue
+		}
+		if pointInTriangle(a, b, c, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// cross2 returns the z component of the cross product of b-a and c-b,
+// positive when a, b, c turn counterclockwise.
+func cross2(a, b, c [2]float64) float64 {
+	return (b[0]-a[0])*(c[1]-b[1]) - (b[1]-a[1])*(c[0]-b[0])
+}
+
+// pointInTriangle reports whether p lies inside or on the boundary of the
+// triangle a, b, c of any orientation.
+func pointInTriangle(a, b, c, p [2]float64) bool {
+	d1, d2, d3 := cross2(a, b, p), cross2(b, c, p), cross2(c, a, p)
+	negative := d1 < 0 || d2 < 0 || d3 < 0
+	positive := d1 > 0 || d2 > 0 || d3 > 0
+	return !(negative && positive)
+}
+
+// signedArea returns the area of the loop, positive when it is
+// counterclockwise.
+func signedArea(loop []polygonNode) float64 {
+	area := 0.0
+	for i := range loop {
+		a, b := loop[i].p, loop[(i+1)%len(loop)].p
+		area += a[0]*b[1] - a[1]*b[0]
+	}
+	return area / 2
+}
@@ -0,0 +1,161 @@
+package obj
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+// createSquareWithHole returns a buffer with a 4x4 square face cut by a
+// 2x2 square hole in its middle.
+func createSquareWithHole() *ObjBuffer {
+	buffer := &ObjBuffer{
+		V: []vec3.T{
+			{0, 0, 0}, {4, 0, 0}, {4, 4, 0}, {0, 4, 0},
+			{1, 1, 0}, {1, 3, 0}, {3, 3, 0}, {3, 1, 0},
+		},
+	}
+	corners := func(vertices ...int) []FaceCorner {
+		c := make([]FaceCorner, len(vertices))
+		for i, v := range vertices {
+			c[i] = FaceCorner{VertexIndex: v, NormalIndex: -1, TexcoordIndex: -1}
+		}
+		return c
+	}
+	f := Face{Corners: corners(0, 1, 2, 3)}
+	f.AddHole(corners(4, 5, 6, 7))
+	buffer.F = []Face{f}
+	buffer.G = []Group{{Name: "plate", FaceCount: 1}}
+	buffer.FaceGroup = faceGroupsOf(buffer.F)
+	return buffer
+}
+
+func TestFace_Triangulate_Hole_CoversRingOnly(t *testing.T) {
+	// Arrange
+	buffer := createSquareWithHole()
+
+	// Act
+	triangles := buffer.F[0].Triangulate(buffer.V)
+
+	// Assert
+	assert.Len(t, triangles, 8)
+	area := float32(0)
+	for _, tri := range triangles {
+		a, b, c := buffer.V[tri[0].VertexIndex], buffer.V[tri[1].VertexIndex], buffer.V[tri[2].VertexIndex]
+		e1, e2 := vec3.Sub(&b, &a), vec3.Sub(&c, &a)
+		cross := vec3.Cross(&e1, &e2)
+		assert.True(t, cross[2] > 0, "triangle is not front facing")
+		area += cross[2] / 2
+		centroid := [2]float32{(a[0] + b[0] + c[0]) / 3, (a[1] + b[1] + c[1]) / 3}
+		inHole := centroid[0] > 1 && centroid[0] < 3 && centroid[1] > 1 && centroid[1] < 3
+		assert.False(t, inHole, "triangle %v lies in the hole", tri)
+	}
+	assert.InDelta(t, 12, area, 1e-5)
+}
+
+func TestFace_Outline_Hole_BridgesLoops(t *testing.T) {
+	// Arrange
+	buffer := createSquareWithHole()
+
+	// Act
+	outline := buffer.F[0].Outline(buffer.V)
+
+	// Assert
+	assert.Len(t, outline, 4+4+2)
+	assert.Len(t, buffer.F[0].Corners, 4)
+}
+
+func TestObjBuffer_Triangulate_Hole_ReplacesFace(t *testing.T) {
+	// Arrange
+	buffer := createSquareWithHole()
+
+	// Act
+	buffer.Triangulate()
+
+	// Assert
+	assert.Len(t, buffer.F, 8)
+	for _, f := range buffer.F {
+		assert.Len(t, f.Corners, 3)
+		assert.Empty(t, f.Holes)
+	}
+	assert.Equal(t, 8, buffer.G[0].FaceCount)
+}
+
+func TestObjBuffer_Write_Hole_WritesBridgedFace(t *testing.T) {
+	// Arrange
+	buffer := createSquareWithHole()
+	var out bytes.Buffer
+
+	// Act
+	err := buffer.Write(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	loader := ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader(out.String())))
+	assert.Len(t, loader.F, 1)
+	assert.Len(t, loader.F[0].Corners, 10)
+	area := float32(0)
+	loader.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		e1, e2 := vec3.Sub(&tri[1], &tri[0]), vec3.Sub(&tri[2], &tri[0])
+		cross := vec3.Cross(&e1, &e2)
+		area += cross[2] / 2
+		return true
+	})
+	assert.InDelta(t, 12, area, 1e-5)
+}
+
+func TestObjBuffer_ExtractGroups_Hole_RemapsHoleCorners(t *testing.T) {
+	// Arrange
+	buffer := createSquareWithHole()
+
+	// Act
+	extracted := buffer.ExtractGroups("plate")
+
+	// Assert
+	assert.Len(t, extracted.V, 8)
+	assert.Len(t, extracted.F[0].Holes, 1)
+	assert.Equal(t, buffer.F[0].Holes[0], extracted.F[0].Holes[0])
+}
+
+func TestFace_Area_Hole_SubtractsHole(t *testing.T) {
+	// Arrange
+	buffer := createSquareWithHole()
+
+	// Act
+	area := buffer.F[0].Area(buffer.V)
+
+	// Assert
+	assert.Equal(t, 12.0, area)
+}
+
+func TestFace_Normal_TiltedQuad_ReturnsUnitNormal(t *testing.T) {
+	// Arrange
+	V := []vec3.T{{0, 0, 0}, {0, 0, 2}, {3, 4, 2}, {3, 4, 0}}
+	f := Face{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {2, -1, -1}, {3, -1, -1}}}
+
+	// Act
+	normal := f.Normal(V)
+	area := f.Area(V)
+
+	// Assert
+	assert.InDeltaSlice(t, []float32{-0.8, 0.6, 0}, normal[:], 1e-6)
+	assert.InDelta(t, 10, area, 1e-9)
+}
+
+func TestFace_NormalAndArea_MissingVertex_ReturnZero(t *testing.T) {
+	// Arrange
+	f := Face{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {5, -1, -1}}}
+	V := []vec3.T{{0, 0, 0}, {1, 0, 0}}
+
+	// Act
+	normal := f.Normal(V)
+	area := f.Area(V)
+
+	// Assert
+	assert.Equal(t, vec3.T{}, normal)
+	assert.Equal(t, 0.0, area)
+}
@@ -0,0 +1,267 @@
+package obj
+
+import (
+	"math"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+)
+
+// LineLength returns the length of line i, the sum of the lengths of its
+// segments. Segments referencing missing vertices are skipped.
+func (b *ObjBuffer) LineLength(i int) float64 {
+	corners := b.L[i].Corners
+	length := 0.0
+	for j := 1; j < len(corners); j++ {
+		u, v := corners[j-1], corners[j]
+		if u < 0 || u >= len(b.V) || v < 0 || v >= len(b.V) {
+			continue
+		}
+		p, q := b.positionD(u), b.positionD(v)
+		length += dvec3.Distance(&p, &q)
+	}
+	return length
+}
+
+// ResampleLine replaces the corners of line i by points spaced evenly along
+// it, at most spacing apart, keeping its end points. The points are added
+// as new vertices, with the color interpolated along their segment and the
+// attributes of the vertex the segment starts from; the vertices of the
+// old corners are left in the buffer. Lines referencing missing vertices,
+// and spacings that are not positive, leave the line unchanged.
+func (b *ObjBuffer) ResampleLine(i int, spacing float64) {
+	l := &b.L[i]
+	if spacing <= 0 || len(l.Corners) < 2 || !b.validLine(l.Corners) {
+		return
+	}
+	total := b.LineLength(i)
+	n := int(math.Ceil(total / spacing))
+	if n < 1 {
+		n = 1
+	}
+	step := total / float64(n)
+
+	corners := []int{l.Corners[0]}
+	segment, start := 1, 0.0
+	for k := 1; k < n; k++ {
+		at := float64(k) * step
+		u, v := l.Corners[segment-1], l.Corners[segment]
+		p, q := b.positionD(u), b.positionD(v)
+		length := dvec3.Distance(&p, &q)
+		for segment < len(l.Corners)-1 && start+length < at {
+			start += length
+			segment++
+			u, v = l.Corners[segment-1], l.Corners[segment]
+			p, q = b.positionD(u), b.positionD(v)
+			length = dvec3.Distance(&p, &q)
+		}
+		t := 0.0
+		if length > 0 {
+			t = math.Min((at-start)/length, 1)
+		}
+		corners = append(corners, b.interpolateVertex(u, v, t))
+	}
+	l.Corners = append(corners, l.Corners[len(l.Corners)-1])
+}
+
+// SimplifyLine removes the corners of line i that lie within tolerance of
+// the simplified line with the Douglas-Peucker algorithm, keeping its end
+// points, and returns the number of corners removed. The vertices are left
+// in the buffer. Lines referencing missing vertices are left unchanged.
+func (b *ObjBuffer) SimplifyLine(i int, tolerance float64) int {
+	l := &b.L[i]
+	if len(l.Corners) < 3 || !b.validLine(l.Corners) {
+		return 0
+	}
+	points := make([]dvec3.T, len(l.Corners))
+	for j, c := range l.Corners {
+		points[j] = b.positionD(c)
+	}
+	keep := make([]bool, len(points))
+	keep[0], keep[len(points)-1] = true, true
+	simplifyPolyline(points, 0, len(points)-1, tolerance, keep)
+
+	corners := l.Corners[:0]
+	for j, c := range l.Corners {
+		if keep[j] {
+			corners = append(corners, c)
+		}
+	}
+	removed := len(l.Corners) - len(corners)
+	l.Corners = corners
+	return removed
+}
+
+// simplifyPolyline marks in keep the points between first and last that
+// Douglas-Peucker keeps.
+func simplifyPolyline(points []dvec3.T, first, last int, tolerance float64, keep []bool) {
+	farthest, distance := -1, tolerance
+	for j := first + 1; j < last; j++ {
+		if d := segmentDistance(&points[j], &points[first], &points[last]); d > distance {
+			farthest, distance = j, d
+		}
+	}
+	if farthest < 0 {
+		return
+	}
+	keep[farthest] = true
+	simplifyPolyline(points, first, farthest, tolerance, keep)
+	simplifyPolyline(points, farthest, last, tolerance, keep)
+}
+
+// segmentDistance returns the distance from p to the segment from a to b.
+func segmentDistance(p, a, b *dvec3.T) float64 {
+	ab, ap := dvec3.Sub(b, a), dvec3.Sub(p, a)
+	t := 0.0
+	if l := ab.LengthSqr(); l > 0 {
+		t = math.Max(0, math.Min(1, dvec3.Dot(&ap, &ab)/l))
+	}
+	closest := ab.Scaled(t)
+	closest.Add(a)
+	return dvec3.Distance(p, &closest)
+}
+
+// validLine reports whether the corners of a line all reference vertices.
+func (b *ObjBuffer) validLine(corners []int) bool {
+	for _, c := range corners {
+		if c < 0 || c >= len(b.V) {
+			return false
+		}
+	}
+	return true
+}
+
+// interpolateVertex adds the vertex at t along the segment from vertex u to
+// vertex v and returns its index.
+func (b *ObjBuffer) interpolateVertex(u, v int, t float64) int {
+	p, q := b.positionD(u), b.positionD(v)
+	d := dvec3.Sub(&q, &p)
+	d.Scale(t)
+	p.Add(&d)
+	if b.hasDoublePrecision() {
+		b.VD = append(b.VD, p)
+	}
+	if b.hasVertexColors() {
+		c := vec3.Interpolate(&b.VC[u], &b.VC[v], float32(t))
+		b.VC = append(b.VC, c)
+	}
+	for name, a := range b.Attributes {
+		b.Attributes[name] = a.appendVertex(a, u)
+	}
+	b.V = append(b.V, vec3.T{float32(p[0]), float32(p[1]), float32(p[2])})
+	return len(b.V) - 1
+}
+
+// LinesToPolygons turns the closed polylines into faces and returns the
+// number of polygons made. A polyline is closed when it ends on the vertex
+// it starts from, or when lines joined end to end, each end vertex shared
+// by exactly two lines of the chain, form a loop, as boundary exports often
+// write one line per segment. Every loop of at least 3 vertices is
+// triangulated into faces with the material of its first line, in the
+// winding of the loop, and appended in a group of its own; its lines are
+// removed. The other lines are kept.
+func (b *ObjBuffer) LinesToPolygons() int {
+	// ends maps every vertex to the lines starting or ending on it.
+	ends := make(map[int][]int)
+	for i, l := range b.L {
+		if len(l.Corners) < 2 || !b.validLine(l.Corners) {
+			continue
+		}
+		first, last := l.Corners[0], l.Corners[len(l.Corners)-1]
+		ends[first] = append(ends[first], i)
+		if last != first {
+			ends[last] = append(ends[last], i)
+		}
+	}
+
+	used := make([]bool, len(b.L))
+	var faces []Face
+	polygons := 0
+	for i := range b.L {
+		if used[i] || len(b.L[i].Corners) < 2 || !b.validLine(b.L[i].Corners) {
+			continue
+		}
+		loop, lines := b.closedLoop(i, ends)
+		if lines == nil {
+			continue
+		}
+		polygon := Face{Material: b.L[i].Material}
+		for _, v := range loop {
+			polygon.Corners = append(polygon.Corners, FaceCorner{VertexIndex: v, NormalIndex: -1, TexcoordIndex: -1})
+		}
+		triangles := polygon.Triangulate(b.V)
+		if len(triangles) == 0 {
+			continue
+		}
+		for _, t := range triangles {
+			faces = append(faces, Face{Corners: t, Material: polygon.Material})
+		}
+		for _, j := range lines {
+			used[j] = true
+		}
+		polygons++
+	}
+	if polygons == 0 {
+		return 0
+	}
+
+	kept := b.L[:0]
+	for i, l := range b.L {
+		if !used[i] {
+			kept = append(kept, l)
+		}
+	}
+	b.L = kept
+	b.G = append(b.G, Group{Name: defaultGroupName, FirstFaceIndex: len(b.F), FaceCount: len(faces)})
+	b.F = append(b.F, faces...)
+	b.FaceGroup = faceGroupsOf(b.F)
+	return polygons
+}
+
+// closedLoop follows the lines joined end to end from line start and
+// returns the vertices of the loop they close, without repeating the first
+// one, and the lines of the loop. It returns nil if they do not close a
+// loop of at least 3 distinct vertices.
+func (b *ObjBuffer) closedLoop(start int, ends map[int][]int) ([]int, []int) {
+	first := b.L[start].Corners[0]
+	loop := append([]int(nil), b.L[start].Corners...)
+	lines := []int{start}
+	current := start
+	for loop[len(loop)-1] != first {
+		end := loop[len(loop)-1]
+		if len(ends[end]) != 2 {
+			return nil, nil
+		}
+		next := ends[end][0]
+		if next == current {
+			next = ends[end][1]
+		}
+		if next == start || next == current {
+			return nil, nil
+		}
+		corners := b.L[next].Corners
+		if corners[0] != end {
+			corners = reversedInts(corners)
+		}
+		loop = append(loop, corners[1:]...)
+		lines = append(lines, next)
+		current = next
+		if len(lines) > len(b.L) {
+			return nil, nil
+		}
+	}
+	loop = loop[:len(loop)-1]
+	if len(uniqueInts(append([]int(nil), loop...))) < 3 {
+		return nil, nil
+	}
+	return loop, lines
+}
+
+// reversedInts returns a reversed copy of s.
+func reversedInts(s []int) []int {
+	reversed := make([]int, len(s))
+	for i, v := range s {
+		reversed[len(s)-1-i] = v
+	}
+	return reversed
+}
@@ -0,0 +1,125 @@
+package obj
+
+import (
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+// createPolyline returns a buffer with a single line through points.
+func createPolyline(points ...vec3.T) *ObjBuffer {
+	b := &ObjBuffer{V: points}
+	l := line{}
+	for i := range points {
+		l.Corners = append(l.Corners, i)
+	}
+	b.L = []line{l}
+	return b
+}
+
+func TestObjBuffer_LineLength_Polyline_SumsSegments(t *testing.T) {
+	// Arrange
+	buffer := createPolyline(vec3.T{0, 0, 0}, vec3.T{3, 4, 0}, vec3.T{3, 4, 2})
+
+	// Act
+	length := buffer.LineLength(0)
+
+	// Assert
+	assert.Equal(t, 7.0, length)
+}
+
+func TestObjBuffer_ResampleLine_Polyline_SpacesPointsEvenly(t *testing.T) {
+	// Arrange
+	buffer := createPolyline(vec3.T{0, 0, 0}, vec3.T{3, 0, 0}, vec3.T{3, 3, 0})
+	buffer.VC = []vec3.T{{0, 0, 0}, {1, 1, 1}, {1, 1, 1}}
+
+	// Act
+	buffer.ResampleLine(0, 1.6)
+
+	// Assert
+	corners := buffer.L[0].Corners
+	assert.Equal(t, 0, corners[0])
+	assert.Equal(t, 2, corners[len(corners)-1])
+	var points []vec3.T
+	for _, c := range corners {
+		points = append(points, buffer.V[c])
+	}
+	assert.Equal(t, []vec3.T{{0, 0, 0}, {1.5, 0, 0}, {3, 0, 0}, {3, 1.5, 0}, {3, 3, 0}}, points)
+	assert.Equal(t, vec3.T{0.5, 0.5, 0.5}, buffer.VC[corners[1]])
+	assert.InDelta(t, 6, buffer.LineLength(0), 1e-9)
+}
+
+func TestObjBuffer_SimplifyLine_NearlyStraight_DropsInnerCorners(t *testing.T) {
+	// Arrange
+	buffer := createPolyline(
+		vec3.T{0, 0, 0}, vec3.T{1, 0.05, 0}, vec3.T{2, -0.05, 0}, vec3.T{3, 0, 0},
+		vec3.T{3, 1, 0}, vec3.T{3, 2, 0})
+
+	// Act
+	removed := buffer.SimplifyLine(0, 0.1)
+
+	// Assert
+	assert.Equal(t, 3, removed)
+	assert.Equal(t, []int{0, 3, 5}, buffer.L[0].Corners)
+}
+
+func TestObjBuffer_SimplifyLine_MissingVertex_LeavesLine(t *testing.T) {
+	// Arrange
+	buffer := createPolyline(vec3.T{0, 0, 0}, vec3.T{1, 0, 0}, vec3.T{2, 0, 0})
+	buffer.L[0].Corners = append(buffer.L[0].Corners, 7)
+
+	// Act
+	removed := buffer.SimplifyLine(0, 1)
+	buffer.ResampleLine(0, 0.5)
+
+	// Assert
+	assert.Equal(t, 0, removed)
+	assert.Equal(t, []int{0, 1, 2, 7}, buffer.L[0].Corners)
+}
+
+func TestObjBuffer_LinesToPolygons_ClosedAndChainedLoops_MakesFaces(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{V: []vec3.T{
+		{0, 0, 0}, {2, 0, 0}, {2, 2, 0}, {0, 2, 0},
+		{5, 0, 0}, {6, 0, 0}, {6, 1, 0},
+		{9, 0, 0}, {9, 1, 0},
+	}}
+	buffer.L = []line{
+		{Corners: []int{0, 1, 2, 3, 0}, Material: "roof"},
+		{Corners: []int{4, 5}, Material: "ground"},
+		{Corners: []int{6, 5}},
+		{Corners: []int{6, 4}},
+		{Corners: []int{7, 8}, Material: "fence"},
+	}
+
+	// Act
+	polygons := buffer.LinesToPolygons()
+
+	// Assert
+	assert.Equal(t, 2, polygons)
+	assert.Equal(t, []line{{Corners: []int{7, 8}, Material: "fence"}}, buffer.L)
+	assert.Len(t, buffer.F, 3)
+	area := 0.0
+	for _, f := range buffer.F {
+		area += f.Area(buffer.V)
+	}
+	assert.InDelta(t, 4.5, area, 1e-9)
+	assert.Equal(t, []Group{{Name: "default group", FirstFaceIndex: 0, FaceCount: 3}}, buffer.G)
+	assert.Equal(t, "roof", buffer.F[0].Material)
+	assert.Equal(t, "ground", buffer.F[2].Material)
+	assert.Equal(t, []int{4, 5, 6}, uniqueInts(vertexIndices(buffer.F[2].Corners)))
+}
+
+func TestObjBuffer_LinesToPolygons_OpenLines_LeavesBuffer(t *testing.T) {
+	// Arrange
+	buffer := createPolyline(vec3.T{0, 0, 0}, vec3.T{1, 0, 0}, vec3.T{1, 1, 0})
+
+	// Act
+	polygons := buffer.LinesToPolygons()
+
+	// Assert
+	assert.Equal(t, 0, polygons)
+	assert.Len(t, buffer.L, 1)
+	assert.Empty(t, buffer.F)
+}
@@ -0,0 +1,66 @@
+package obj
+
+import (
+	"io"
+	"sync"
+)
+
+// ReaderPool reuses readers across reads, so that a service reading many
+// small files does not allocate the slices of every buffer anew. It is
+// backed by a sync.Pool and is safe for concurrent use; the zero value is
+// ready to use.
+//
+//	l, err := pool.Read(r, options)
+//	if err != nil { ... }
+//	process(&l.ObjBuffer)
+//	pool.Put(l)
+type ReaderPool struct {
+	pool sync.Pool
+}
+
+// Get returns an empty reader with the options, from the pool if it holds
+// one.
+func (p *ReaderPool) Get(options ReadOptions) *ObjReader {
+	l, ok := p.pool.Get().(*ObjReader)
+	if !ok {
+		l = &ObjReader{}
+	}
+	l.SetOptions(options)
+	return l
+}
+
+// Put resets the reader and returns it to the pool. Its buffer must no
+// longer be in use.
+func (p *ReaderPool) Put(l *ObjReader) {
+	l.Reset()
+	p.pool.Put(l)
+}
+
+// Read reads r with a reader of the pool, which the caller returns to the
+// pool with Put once done with its buffer. On error, the reader is returned
+// to the pool and nil is returned.
+func (p *ReaderPool) Read(r io.Reader, options ReadOptions) (*ObjReader, error) {
+	l := p.Get(options)
+	if err := l.Read(r); err != nil {
+		p.Put(l)
+		return nil, err
+	}
+	return l, nil
+}
+
+// ReadFile reads the file at path like ReadFile, with a reader of the pool
+// the caller returns with Put.
+func (p *ReaderPool) ReadFile(path string, options ReadOptions) (*ObjReader, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unmap()
+
+	l := p.Get(options)
+	if err := l.readBytes(data); err != nil {
+		p.Put(l)
+		return nil, err
+	}
+	return l, nil
+}
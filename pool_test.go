@@ -0,0 +1,118 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjReader_Reset_ReadsNextFileLikeNewReader(t *testing.T) {
+	// Arrange
+	first := "mtllib a.mtl\nv 0 0 0\nv 1 0 0\nv 0 1 0\nv 1 1 0\nvt 0 0\no first\ng walls\nusemtl brick\nf 1 2 3 4\nl 1 2\nfoo bar\n"
+	second := readFileTestObj
+	loader := &ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader(first)))
+	fresh := &ObjReader{}
+	assert.NoError(t, fresh.Read(strings.NewReader(second)))
+
+	// Act
+	loader.Reset()
+	err := loader.Read(strings.NewReader(second))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, fresh.MTL, loader.MTL)
+	assert.Equal(t, fresh.V, loader.V)
+	assert.Equal(t, fresh.VN, loader.VN)
+	assert.Empty(t, loader.VT)
+	assert.Equal(t, fresh.F, loader.F)
+	assert.Empty(t, loader.L)
+	assert.Equal(t, fresh.G, loader.G)
+	assert.Equal(t, fresh.FaceGroup, loader.FaceGroup)
+	assert.Empty(t, loader.Warnings)
+	assert.Empty(t, loader.objects)
+}
+
+func TestObjReader_Reset_ReusesMemory(t *testing.T) {
+	// Arrange
+	loader := &ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader(readFileTestObj)))
+	vertices, corners := &loader.V[0], &loader.F[0].Corners[0]
+
+	// Act
+	loader.Reset()
+	err := loader.Read(strings.NewReader(readFileTestObj))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, vertices == &loader.V[0])
+	assert.True(t, corners == &loader.F[0].Corners[0])
+}
+
+func TestObjReader_Reset_ClearsGroupsAndFaceGroups(t *testing.T) {
+	// Arrange
+	loader := &ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader(readFileTestObj)))
+	groups, faceGroups := len(loader.G), len(loader.FaceGroup)
+
+	// Act
+	loader.Reset()
+
+	// Assert
+	assert.Empty(t, loader.G)
+	assert.Empty(t, loader.FaceGroup)
+	assert.Equal(t, make([]Group, groups), loader.G[:groups])
+	assert.Equal(t, make([]*FaceGroup, faceGroups), loader.FaceGroup[:faceGroups])
+}
+
+func TestReaderPool_ReadFile_ReturnsBufferAndReusesReader(t *testing.T) {
+	// Arrange
+	path := writeTempObj(t, readFileTestObj)
+	expected, err := ReadFile(path, ReadOptions{})
+	assert.NoError(t, err)
+	pool := &ReaderPool{}
+
+	// Act
+	var results []*ObjBuffer
+	for i := 0; i < 2; i++ {
+		l, err := pool.ReadFile(path, ReadOptions{})
+		assert.NoError(t, err)
+		results = append(results, l.Clone())
+		pool.Put(l)
+	}
+
+	// Assert
+	for _, b := range results {
+		assert.Equal(t, expected.V, b.V)
+		assert.Equal(t, expected.F, b.F)
+		assert.Equal(t, expected.G, b.G)
+	}
+}
+
+func TestReaderPool_Read_InvalidInput_ReturnsError(t *testing.T) {
+	// Arrange
+	pool := &ReaderPool{}
+
+	// Act
+	l, err := pool.Read(strings.NewReader("v 0 0\n"), ReadOptions{})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, l)
+}
+
+func TestReaderPool_ReadFile_InvalidStatement_ErrorOutlivesFile(t *testing.T) {
+	// Arrange
+	path := writeTempObj(t, "v 0 0 0\nv 1 0 zzz\n")
+	pool := &ReaderPool{}
+
+	// Act
+	l, err := pool.ReadFile(path, ReadOptions{})
+
+	// Assert
+	assert.Nil(t, l)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "'v 1 0 zzz'")
+	}
+}
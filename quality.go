@@ -0,0 +1,130 @@
+package obj
+
+import (
+	"math"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+)
+
+// QualityReport describes the shape of the triangles of a buffer and the
+// curvature of its surface.
+type QualityReport struct {
+	// Triangles is the number of triangles of the faces.
+	Triangles int
+	// Degenerate is the number of triangles without area. They are left
+	// out of the other measures.
+	Degenerate int
+	// MinAngle is the smallest angle of any triangle, in degrees.
+	MinAngle float64
+	// MinAngleHistogram counts the triangles by their smallest angle, in
+	// bins of 10 degrees: bin k holds the angles from 10k to 10k+10
+	// degrees, the last one including the 60 degrees of equilateral
+	// triangles.
+	MinAngleHistogram [6]int
+	// MeanAspectRatio and MaxAspectRatio are the mean and the largest ratio
+	// of the circumradius to twice the inradius of the triangles, which is
+	// 1 for equilateral triangles and grows as they get thinner.
+	MeanAspectRatio float64
+	MaxAspectRatio  float64
+	// MeanCurvature holds the mean curvature at every vertex, estimated
+	// with the cotangent Laplacian, and GaussianCurvature the Gaussian
+	// curvature, estimated by the angle deficit. Both are 0 on boundaries,
+	// where they cannot be estimated, and for vertices no triangle
+	// references.
+	MeanCurvature     []float64
+	GaussianCurvature []float64
+}
+
+// Quality measures the triangles of the faces and estimates the curvature
+// of the surface at every vertex.
+func (b *ObjBuffer) Quality() QualityReport {
+	report := QualityReport{
+		MinAngle:          180,
+		MeanCurvature:     make([]float64, len(b.V)),
+		GaussianCurvature: make([]float64, len(b.V)),
+	}
+	areas := make([]float64, len(b.V))
+	angles := make([]float64, len(b.V))
+	laplacians := make([]dvec3.T, len(b.V))
+	edges := make(map[Edge]int)
+	aspectSum := 0.0
+
+	b.EachTriangle(func(_ [3]vec3.T, corners [3]FaceCorner, _ int) bool {
+		report.Triangles++
+		var p [3]dvec3.T
+		var v [3]int
+		for k := range corners {
+			v[k] = corners[k].VertexIndex
+			p[k] = b.positionD(v[k])
+		}
+		for k := 0; k < 3; k++ {
+			edges[NewEdge(v[k], v[(k+1)%3])]++
+		}
+		// Edge k is opposite to corner k.
+		var lengths [3]float64
+		for k := 0; k < 3; k++ {
+			lengths[k] = dvec3.Distance(&p[(k+1)%3], &p[(k+2)%3])
+		}
+		e1, e2 := dvec3.Sub(&p[1], &p[0]), dvec3.Sub(&p[2], &p[0])
+		cross := dvec3.Cross(&e1, &e2)
+		area := cross.Length() / 2
+		if area == 0 {
+			report.Degenerate++
+			return true
+		}
+
+		smallest := math.Pi
+		for k := 0; k < 3; k++ {
+			a, c := dvec3.Sub(&p[(k+1)%3], &p[k]), dvec3.Sub(&p[(k+2)%3], &p[k])
+			angle := math.Atan2(2*area, dvec3.Dot(&a, &c))
+			smallest = math.Min(smallest, angle)
+			angles[v[k]] += angle
+			areas[v[k]] += area / 3
+
+			// The cotangent of the angle weighs the edge opposite to it.
+			cot := dvec3.Dot(&a, &c) / (2 * area)
+			i, j := v[(k+1)%3], v[(k+2)%3]
+			d := dvec3.Sub(&p[(k+1)%3], &p[(k+2)%3])
+			d.Scale(cot)
+			laplacians[i].Add(&d)
+			laplacians[j].Sub(&d)
+		}
+		degrees := smallest * 180 / math.Pi
+		report.MinAngle = math.Min(report.MinAngle, degrees)
+		bin := int(degrees / 10)
+		if bin > len(report.MinAngleHistogram)-1 {
+			bin = len(report.MinAngleHistogram) - 1
+		}
+		report.MinAngleHistogram[bin]++
+
+		s := (lengths[0] + lengths[1] + lengths[2]) / 2
+		circumradius := lengths[0] * lengths[1] * lengths[2] / (4 * area)
+		inradius := area / s
+		aspect := circumradius / (2 * inradius)
+		aspectSum += aspect
+		report.MaxAspectRatio = math.Max(report.MaxAspectRatio, aspect)
+		return true
+	})
+
+	if measured := report.Triangles - report.Degenerate; measured > 0 {
+		report.MeanAspectRatio = aspectSum / float64(measured)
+	} else {
+		report.MinAngle = 0
+	}
+	boundary := make([]bool, len(b.V))
+	for e, n := range edges {
+		if n == 1 {
+			boundary[e[0]] = true
+			boundary[e[1]] = true
+		}
+	}
+	for i := range b.V {
+		if areas[i] == 0 || boundary[i] {
+			continue
+		}
+		report.MeanCurvature[i] = laplacians[i].Length() / (4 * areas[i])
+		report.GaussianCurvature[i] = (2*math.Pi - angles[i]) / areas[i]
+	}
+	return report
+}
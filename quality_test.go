@@ -0,0 +1,107 @@
+package obj
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+// createSphere returns a UV sphere of the given radius with the given
+// number of slices around and stacks from pole to pole.
+func createSphere(radius float64, slices, stacks int) *ObjBuffer {
+	buffer := &ObjBuffer{}
+	corner := func(v int) FaceCorner { return FaceCorner{VertexIndex: v, NormalIndex: -1, TexcoordIndex: -1} }
+	buffer.V = append(buffer.V, vec3.T{0, 0, float32(radius)})
+	for i := 1; i < stacks; i++ {
+		theta := math.Pi * float64(i) / float64(stacks)
+		for j := 0; j < slices; j++ {
+			phi := 2 * math.Pi * float64(j) / float64(slices)
+			buffer.V = append(buffer.V, vec3.T{
+				float32(radius * math.Sin(theta) * math.Cos(phi)),
+				float32(radius * math.Sin(theta) * math.Sin(phi)),
+				float32(radius * math.Cos(theta)),
+			})
+		}
+	}
+	buffer.V = append(buffer.V, vec3.T{0, 0, float32(-radius)})
+	ring := func(i, j int) int { return 1 + (i-1)*slices + (j+slices)%slices }
+	south := len(buffer.V) - 1
+	for j := 0; j < slices; j++ {
+		buffer.F = append(buffer.F, Face{Corners: []FaceCorner{corner(0), corner(ring(1, j)), corner(ring(1, j+1))}})
+		for i := 1; i < stacks-1; i++ {
+			buffer.F = append(buffer.F, Face{Corners: []FaceCorner{
+				corner(ring(i, j)), corner(ring(i+1, j)), corner(ring(i+1, j+1)), corner(ring(i, j+1))}})
+		}
+		buffer.F = append(buffer.F, Face{Corners: []FaceCorner{corner(south), corner(ring(stacks-1, j+1)), corner(ring(stacks-1, j))}})
+	}
+	return buffer
+}
+
+func TestObjBuffer_Quality_EquilateralTriangle_IsIdeal(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{
+		V: []vec3.T{{0, 0, 0}, {2, 0, 0}, {1, float32(math.Sqrt(3)), 0}, {5, 0, 0}},
+		F: []Face{
+			{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {2, -1, -1}}},
+			{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {3, -1, -1}}},
+		},
+	}
+
+	// Act
+	report := buffer.Quality()
+
+	// Assert
+	assert.Equal(t, 2, report.Triangles)
+	assert.Equal(t, 1, report.Degenerate)
+	assert.InDelta(t, 60, report.MinAngle, 1e-4)
+	assert.Equal(t, [6]int{0, 0, 0, 0, 0, 1}, report.MinAngleHistogram)
+	assert.InDelta(t, 1, report.MeanAspectRatio, 1e-6)
+	assert.InDelta(t, 1, report.MaxAspectRatio, 1e-6)
+}
+
+func TestObjBuffer_Quality_Sphere_EstimatesCurvature(t *testing.T) {
+	// Arrange
+	buffer := createSphere(2, 48, 24)
+
+	// Act
+	report := buffer.Quality()
+
+	// Assert
+	assert.Equal(t, 48*2+48*22*2, report.Triangles)
+	assert.Equal(t, 0, report.Degenerate)
+	// Vertices on the equator.
+	for j := 1 + 11*48; j < 1+12*48; j++ {
+		assert.InDelta(t, 0.5, report.MeanCurvature[j], 0.02)
+		assert.InDelta(t, 0.25, report.GaussianCurvature[j], 0.02)
+	}
+}
+
+func TestObjBuffer_Quality_Plane_HasNoCurvature(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{}
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			buffer.V = append(buffer.V, vec3.T{float32(x), float32(y) * 2, 0})
+		}
+	}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			v := y*3 + x
+			buffer.F = append(buffer.F, Face{Corners: []FaceCorner{
+				{v, -1, -1}, {v + 1, -1, -1}, {v + 4, -1, -1}, {v + 3, -1, -1}}})
+		}
+	}
+
+	// Act
+	report := buffer.Quality()
+
+	// Assert
+	assert.Equal(t, 8, report.Triangles)
+	for i := range buffer.V {
+		assert.InDelta(t, 0, report.MeanCurvature[i], 1e-6)
+		assert.InDelta(t, 0, report.GaussianCurvature[i], 1e-6)
+	}
+	assert.True(t, report.MaxAspectRatio > 1)
+}
@@ -0,0 +1,187 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+
+	"github.com/flywave/go3d/vec3"
+)
+
+// RawLayout controls the blobs written by ExportRawBuffers.
+type RawLayout struct {
+	// Normals and TexCoords add the normals and texture coordinates to the
+	// positions. Corners without them get zero values.
+	Normals   bool
+	TexCoords bool
+	// Interleaved writes the attributes of each vertex next to each other
+	// in a single blob, instead of a blob per attribute.
+	Interleaved bool
+	// Index16 writes the indices as uint16 when there are few enough
+	// vertices, and as uint32 otherwise.
+	Index16 bool
+}
+
+// The header of ExportRawBuffers: the magic, the version and the length of
+// the JSON.
+const (
+	rawMagic        = "oraw"
+	rawVersion      = 1
+	rawHeaderLength = 12
+)
+
+// rawHeader is the JSON header of ExportRawBuffers.
+type rawHeader struct {
+	VertexCount int        `json:"vertexCount"`
+	IndexCount  int        `json:"indexCount"`
+	Offset      [3]float64 `json:"offset"`
+	Blobs       []rawBlob  `json:"blobs"`
+}
+
+// rawBlob describes a blob of ExportRawBuffers. ByteOffset is relative to
+// the end of the header.
+type rawBlob struct {
+	Name       string         `json:"name"`
+	ByteOffset int            `json:"byteOffset"`
+	ByteLength int            `json:"byteLength"`
+	Stride     int            `json:"stride"`
+	Attributes []rawAttribute `json:"attributes"`
+}
+
+// rawAttribute describes a value of each element of a blob, at Offset
+// bytes from the start of the element.
+type rawAttribute struct {
+	Name       string `json:"name"`
+	Offset     int    `json:"offset"`
+	Components int    `json:"components"`
+	Type       string `json:"type"`
+}
+
+// ExportRawBuffers writes the triangulated faces of the buffer as raw
+// little-endian blobs that game engines can map into memory and upload as
+// they are: the float32 positions, optionally normals and texture
+// coordinates, of the vertices, and the indices of the triangles. Corners
+// with the same vertex, normal and texture coordinates share a vertex.
+// Positions are relative to the offset of the buffer and texture
+// coordinates are written as in OBJ, with v pointing up.
+//
+// The data starts with the 4 bytes "oraw", the version 1 and the length of
+// a JSON header as uint32. The header gives the number of vertices and
+// indices, the offset, and for every blob its name ("position", "normal",
+// "texcoord" or "vertex" when interleaved, and "index"), its byte offset
+// from the end of the header, its length and stride, and the offset,
+// number of components and type of each attribute it holds. The header and
+// the blobs are padded to 8 bytes.
+func (b *ObjBuffer) ExportRawBuffers(w io.Writer, layout RawLayout) error {
+	type vertexKey struct{ v, n, t int }
+	vertices := map[vertexKey]uint32{}
+	var keys []vertexKey
+	var indices []uint32
+	b.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		for _, c := range corners {
+			key := vertexKey{c.VertexIndex, -1, -1}
+			if layout.Normals && c.NormalIndex >= 0 && c.NormalIndex < len(b.VN) {
+				key.n = c.NormalIndex
+			}
+			if layout.TexCoords && c.TexcoordIndex >= 0 && c.TexcoordIndex < len(b.VT) {
+				key.t = c.TexcoordIndex
+			}
+			index, ok := vertices[key]
+			if !ok {
+				index = uint32(len(keys))
+				vertices[key] = index
+				keys = append(keys, key)
+			}
+			indices = append(indices, index)
+		}
+		return true
+	})
+
+	attributes := []rawAttribute{{Name: "position", Components: 3, Type: "float32"}}
+	if layout.Normals {
+		attributes = append(attributes, rawAttribute{Name: "normal", Components: 3, Type: "float32"})
+	}
+	if layout.TexCoords {
+		attributes = append(attributes, rawAttribute{Name: "texcoord", Components: 2, Type: "float32"})
+	}
+	// values returns the floats of attribute j of a vertex.
+	values := func(j int, key vertexKey) []float32 {
+		switch attributes[j].Name {
+		case "normal":
+			if key.n < 0 {
+				return []float32{0, 0, 0}
+			}
+			return b.VN[key.n][:]
+		case "texcoord":
+			if key.t < 0 {
+				return []float32{0, 0}
+			}
+			return b.VT[key.t][:]
+		}
+		return b.V[key.v][:]
+	}
+
+	header := rawHeader{VertexCount: len(keys), IndexCount: len(indices), Offset: b.Offset}
+	var data bytes.Buffer
+	addBlob := func(blob rawBlob, write func()) {
+		blob.ByteOffset = data.Len()
+		write()
+		blob.ByteLength = data.Len() - blob.ByteOffset
+		for data.Len()%8 != 0 {
+			data.WriteByte(0)
+		}
+		header.Blobs = append(header.Blobs, blob)
+	}
+	if layout.Interleaved {
+		blob := rawBlob{Name: "vertex"}
+		for j := range attributes {
+			attributes[j].Offset = blob.Stride
+			blob.Stride += 4 * attributes[j].Components
+		}
+		blob.Attributes = attributes
+		addBlob(blob, func() {
+			for _, key := range keys {
+				for j := range attributes {
+					binary.Write(&data, binary.LittleEndian, values(j, key))
+				}
+			}
+		})
+	} else {
+		for j, a := range attributes {
+			j := j
+			addBlob(rawBlob{Name: a.Name, Stride: 4 * a.Components, Attributes: []rawAttribute{a}}, func() {
+				for _, key := range keys {
+					binary.Write(&data, binary.LittleEndian, values(j, key))
+				}
+			})
+		}
+	}
+	if layout.Index16 && len(keys) <= math.MaxUint16+1 {
+		addBlob(rawBlob{Name: "index", Stride: 2, Attributes: []rawAttribute{{Name: "index", Components: 1, Type: "uint16"}}}, func() {
+			short := make([]uint16, len(indices))
+			for i, index := range indices {
+				short[i] = uint16(index)
+			}
+			binary.Write(&data, binary.LittleEndian, short)
+		})
+	} else {
+		addBlob(rawBlob{Name: "index", Stride: 4, Attributes: []rawAttribute{{Name: "index", Components: 1, Type: "uint32"}}}, func() {
+			binary.Write(&data, binary.LittleEndian, indices)
+		})
+	}
+
+	js, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	js = padJSON(js, rawHeaderLength)
+	var out bytes.Buffer
+	out.WriteString(rawMagic)
+	binary.Write(&out, binary.LittleEndian, []uint32{rawVersion, uint32(len(js))})
+	out.Write(js)
+	out.Write(data.Bytes())
+	_, err = w.Write(out.Bytes())
+	return err
+}
@@ -0,0 +1,107 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+// readRawExport splits the output of ExportRawBuffers into its header and
+// data.
+func readRawExport(t *testing.T, out []byte) (rawHeader, []byte) {
+	rd := bytes.NewReader(out)
+	var magic [4]byte
+	var version, length uint32
+	readLittleByte(rd, &magic)
+	readLittleByte(rd, &version)
+	readLittleByte(rd, &length)
+	assert.Equal(t, "oraw", string(magic[:]))
+	assert.Equal(t, uint32(1), version)
+	assert.Equal(t, 0, (rawHeaderLength+int(length))%8)
+	var header rawHeader
+	assert.NoError(t, json.Unmarshal(out[rawHeaderLength:rawHeaderLength+length], &header))
+	return header, out[rawHeaderLength+length:]
+}
+
+func TestObjBuffer_ExportRawBuffers_Separate_WritesBlobPerAttribute(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{
+		V:      []vec3.T{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}},
+		VT:     []vec2.T{{0, 0}, {1, 0}, {1, 1}, {0, 1}},
+		F:      []Face{{Corners: []FaceCorner{{0, -1, 0}, {1, -1, 1}, {2, -1, 2}, {3, -1, 3}}}},
+		Offset: dvec3.T{100, 200, 0},
+	}
+
+	// Act
+	var out bytes.Buffer
+	err := buffer.ExportRawBuffers(&out, RawLayout{TexCoords: true, Index16: true})
+
+	// Assert
+	assert.NoError(t, err)
+	header, data := readRawExport(t, out.Bytes())
+	assert.Equal(t, 4, header.VertexCount)
+	assert.Equal(t, 6, header.IndexCount)
+	assert.Equal(t, [3]float64{100, 200, 0}, header.Offset)
+	if assert.Equal(t, 3, len(header.Blobs)) {
+		position, texcoord, index := header.Blobs[0], header.Blobs[1], header.Blobs[2]
+		assert.Equal(t, rawBlob{Name: "position", ByteOffset: 0, ByteLength: 48, Stride: 12,
+			Attributes: []rawAttribute{{Name: "position", Components: 3, Type: "float32"}}}, position)
+		assert.Equal(t, 48, texcoord.ByteOffset)
+		assert.Equal(t, 8, texcoord.Stride)
+		assert.Equal(t, "uint16", index.Attributes[0].Type)
+		assert.Equal(t, 80, index.ByteOffset)
+		assert.Equal(t, 12, index.ByteLength)
+
+		positions := make([]vec3.T, 4)
+		readLittleByte(bytes.NewReader(data[position.ByteOffset:]), positions)
+		assert.Equal(t, buffer.V, positions)
+		uvs := make([]vec2.T, 4)
+		readLittleByte(bytes.NewReader(data[texcoord.ByteOffset:]), uvs)
+		assert.Equal(t, buffer.VT, uvs)
+		indices := make([]uint16, 6)
+		readLittleByte(bytes.NewReader(data[index.ByteOffset:]), indices)
+		for _, i := range indices {
+			assert.True(t, i < 4)
+		}
+		assert.Equal(t, 96, len(data))
+	}
+}
+
+func TestObjBuffer_ExportRawBuffers_Interleaved_WritesVertexRecords(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{
+		V:  []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+		VN: []vec3.T{{0, 0, 1}},
+		F:  []Face{{Corners: []FaceCorner{{0, 0, -1}, {1, 0, -1}, {2, -1, -1}}}},
+	}
+
+	// Act
+	var out bytes.Buffer
+	err := buffer.ExportRawBuffers(&out, RawLayout{Normals: true, TexCoords: true, Interleaved: true})
+
+	// Assert
+	assert.NoError(t, err)
+	header, data := readRawExport(t, out.Bytes())
+	if assert.Equal(t, 2, len(header.Blobs)) {
+		vertex, index := header.Blobs[0], header.Blobs[1]
+		assert.Equal(t, "vertex", vertex.Name)
+		assert.Equal(t, 32, vertex.Stride)
+		assert.Equal(t, []rawAttribute{
+			{Name: "position", Offset: 0, Components: 3, Type: "float32"},
+			{Name: "normal", Offset: 12, Components: 3, Type: "float32"},
+			{Name: "texcoord", Offset: 24, Components: 2, Type: "float32"},
+		}, vertex.Attributes)
+		records := make([][8]float32, 3)
+		readLittleByte(bytes.NewReader(data[vertex.ByteOffset:]), records)
+		assert.Equal(t, [8]float32{1, 0, 0, 0, 0, 1, 0, 0}, records[1])
+		assert.Equal(t, [8]float32{0, 1, 0, 0, 0, 0, 0, 0}, records[2])
+		indices := make([]uint32, 3)
+		readLittleByte(bytes.NewReader(data[index.ByteOffset:]), indices)
+		assert.Equal(t, []uint32{0, 1, 2}, indices)
+	}
+}
@@ -2,12 +2,15 @@ package obj
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
 
+	dvec3 "github.com/flywave/go3d/float64/vec3"
 	"github.com/flywave/go3d/vec2"
 	"github.com/flywave/go3d/vec3"
 )
@@ -19,15 +22,19 @@ var faceVertexAndNormalRegex *regexp.Regexp
 var groupRegex *regexp.Regexp
 var usemtlRegex *regexp.Regexp
 var mtllibRegex *regexp.Regexp
+var offsetRegex *regexp.Regexp
+var bannerRegex *regexp.Regexp
 
 func init() {
-	faceVertexOnlyRegex = regexp.MustCompile(`^(\d+)$`)
-	faceVertexAndTexcoordRegex = regexp.MustCompile(`^(\d+)\/(\d+)$`)
-	faceVertexAndNormalTexcoordRegex = regexp.MustCompile(`^(\d+)\/(\d+)\/(\d+)$`)
-	faceVertexAndNormalRegex = regexp.MustCompile(`^(\d+)\/\/(\d+)$`)
+	faceVertexOnlyRegex = regexp.MustCompile(`^(-?\d+)$`)
+	faceVertexAndTexcoordRegex = regexp.MustCompile(`^(-?\d+)\/(-?\d+)$`)
+	faceVertexAndNormalTexcoordRegex = regexp.MustCompile(`^(-?\d+)\/(-?\d+)\/(-?\d+)$`)
+	faceVertexAndNormalRegex = regexp.MustCompile(`^(-?\d+)\/\/(-?\d+)$`)
 	groupRegex = regexp.MustCompile(`^g\s*(.*)$`)
-	usemtlRegex = regexp.MustCompile(`^usemtl\s+(.*)$`)
+	usemtlRegex = regexp.MustCompile(`^usemtl(?:\s+(.*))?$`)
 	mtllibRegex = regexp.MustCompile(`^mtllib\s+(.*)$`)
+	bannerRegex = regexp.MustCompile(`^(Exported using .*|\d+ vertices, \d+ normals, .*)$`)
+	offsetRegex = regexp.MustCompile(`^#\s*offset\s+(\S+)\s+(\S+)\s+(\S+)$`)
 }
 
 func FirstError(errs ...error) error {
@@ -39,143 +46,700 @@ func FirstError(errs ...error) error {
 	return nil
 }
 
+// cornerSlabSize is the number of face corners allocated at once when the
+// reader runs out of preallocated corner storage.
+const cornerSlabSize = 4096
+
 type ObjReader struct {
 	ObjBuffer
 
-	options ReadOptions
+	// Warnings lists the statements that were read but ignored, unless
+	// ReadOptions.OnWarning is set.
+	Warnings []Warning
+
+	options    ReadOptions
+	cornerSlab []FaceCorner
+	// slab is the largest corner slab allocated, which Reset reuses.
+	slab []FaceCorner
+
+	// recenterOrigin is the position subtracted from every vertex when
+	// recentering on the first vertex.
+	recenterOrigin dvec3.T
+
+	// borrowed is set while parsing lines that alias memory the reader
+	// does not own, such as a memory-mapped file.
+	borrowed bool
+
+	// materials holds the distinct material names used so far, when
+	// Limits.MaxMaterials is set.
+	materials map[string]bool
+
+	// faceLines and lineLines hold the line number of every face and line,
+	// when the indices are validated.
+	faceLines []int
+	lineLines []int
+
+	// metadataFace is the index, plus one, of the face read by the last
+	// statement, which a following "#fm" comment applies to, or 0. It is
+	// -1 when the face was skipped by a filter.
+	metadataFace int
+
+	// facesFiltered is set while the faces of the current group and material
+	// are skipped by ReadOptions.GroupFilter or MaterialFilter, and
+	// filterChecked once that has been decided for them.
+	facesFiltered bool
+	filterChecked bool
+
+	// smoothingGroup is the smoothing group of the faces read, set by the
+	// last "s" statement.
+	smoothingGroup uint32
+
+	// attributeOrder holds the names of the attributes in the order they
+	// were declared, which is the order of the values of "#va" comments.
+	attributeOrder []string
+
+	// objects holds the "o" statements read, which ReadMulti splits the
+	// faces on.
+	objects []objectStart
 }
 
 func (l *ObjReader) SetOptions(options ReadOptions) {
 	l.options = options
 }
 
-func (l *ObjReader) Read(reader io.Reader) error {
-	scanner := bufio.NewScanner(reader)
+// Reset empties the reader for reading another file, keeping its options
+// and the memory of its slices: the elements read next are stored where the
+// previous ones were, without allocating until the reader holds more of
+// them than it ever did. Everything read before, including the faces and
+// their corners, must no longer be in use; Clone the buffer to keep it.
+func (l *ObjReader) Reset() {
+	b := &l.ObjBuffer
+	for i := range b.F {
+		b.F[i] = Face{}
+	}
+	for i := range b.L {
+		b.L[i] = line{}
+	}
+	for i := range b.G {
+		b.G[i] = Group{}
+	}
+	for i := range b.FaceGroup {
+		b.FaceGroup[i] = nil
+	}
+	for i := range b.Comments {
+		b.Comments[i] = Comment{}
+	}
+	for i := range b.Statements {
+		b.Statements[i] = Statement{}
+	}
+	*b = ObjBuffer{
+		V:          b.V[:0],
+		VN:         b.VN[:0],
+		VT:         b.VT[:0],
+		F:          b.F[:0],
+		L:          b.L[:0],
+		G:          b.G[:0],
+		FaceGroup:  b.FaceGroup[:0],
+		VD:         b.VD[:0],
+		VC:         b.VC[:0],
+		Comments:   b.Comments[:0],
+		Statements: b.Statements[:0],
+	}
+
+	for i := range l.Warnings {
+		l.Warnings[i] = Warning{}
+	}
+	*l = ObjReader{
+		ObjBuffer:      *b,
+		Warnings:       l.Warnings[:0],
+		options:        l.options,
+		cornerSlab:     l.slab,
+		slab:           l.slab,
+		faceLines:      l.faceLines[:0],
+		lineLines:      l.lineLines[:0],
+		attributeOrder: l.attributeOrder[:0],
+		objects:        l.objects[:0],
+	}
+}
+
+func (l *ObjReader) Read(reader io.Reader) (err error) {
+	metrics := startMetrics(l.options.Metrics, "read")
+	counting := &countingReader{r: reader}
+	defer func() { metrics.finish(counting.n, err) }()
+
+	hint := l.options.PreallocHint
+	if l.options.TwoPass {
+		if seeker, ok := reader.(io.ReadSeeker); ok {
+			start, err := seeker.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return err
+			}
+			if hint, err = countElements(seeker); err != nil {
+				return err
+			}
+			if _, err = seeker.Seek(start, io.SeekStart); err != nil {
+				return err
+			}
+			metrics.endStage("count")
+		}
+	}
+	l.preallocate(hint)
+
+	scanner := bufio.NewScanner(counting)
+	if max := l.options.Limits.MaxLineLen; max > 0 {
+		// Leave room for the line terminator.
+		scanner.Buffer(make([]byte, 0, minInt(max+2, bufio.MaxScanTokenSize)), max+2)
+	}
 	i := 0
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
 		i++
-		if hashPos := strings.IndexRune(line, '#'); hashPos != -1 {
-			line = line[0:hashPos]
-		}
-		if len(line) == 0 {
-			continue
-		}
-
-		var err error
-		fields := strings.Fields(line)
-		switch strings.ToLower(fields[0]) {
-		case "vt":
-			err = l.processVertexTexCoord(fields[1:])
-		case "v":
-			err = l.processVertex(fields[1:])
-		case "vn":
-			err = l.processVertexNormal(fields[1:])
-		case "f":
-			err = l.processFace(fields[1:])
-		case "l":
-			err = l.processLine(fields[1:])
-		case "g":
-			err = l.processGroup(line)
-		case "mtllib":
-			err = l.processMaterialLibrary(line)
-		case "usemtl":
-			fsz := len(l.F)
-			if len(l.FaceGroup) > 0 {
-				fg := l.FaceGroup[len(l.FaceGroup)-1]
-				fg.Size = fsz - fg.Offset
-			}
-			ng := &faceGroup{Offset: fsz}
-			l.FaceGroup = append(l.FaceGroup, ng)
-			err = l.processUseMaterial(line)
-		case "o":
-		case "s":
-		case "vp":
-			break
+		if err := l.processStatement(i, scanner.Text()); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if err == bufio.ErrTooLong && l.options.Limits.MaxLineLen > 0 {
+			return &LimitError{Limit: "MaxLineLen", Max: l.options.Limits.MaxLineLen, What: "bytes in a line", Line: i + 1}
+		}
+		return err
+	}
+	metrics.endStage("parse")
+	l.finish()
+	metrics.endStage("finish")
+	if l.options.validatesIndices() {
+		err = l.validateIndices()
+		metrics.endStage("validate")
+	}
+	return err
+}
 
-		default:
-			err = fmt.Errorf("Unknown keyword '%s'", fields[0])
+// processStatement parses a single line of input. lineNumber is only used
+// for error reporting.
+func (l *ObjReader) processStatement(lineNumber int, raw string) error {
+	if max := l.options.Limits.MaxLineLen; max > 0 && len(raw) > max {
+		return &LimitError{Limit: "MaxLineLen", Max: max, What: "bytes in a line", Line: lineNumber}
+	}
+	line := strings.TrimSpace(raw)
+	if strings.HasPrefix(line, "#") {
+		l.processComment(lineNumber, line)
+		l.recordStatement(StatementRaw, -1, raw)
+		return nil
+	}
+	if hashPos := strings.IndexRune(line, '#'); hashPos != -1 {
+		if l.options.KeepComments {
+			l.keepComment(lineNumber, line[hashPos+1:], true)
 		}
+		line = strings.TrimSpace(line[0:hashPos])
+	}
+	if len(line) == 0 {
+		l.recordStatement(StatementRaw, -1, raw)
+		return nil
+	}
 
-		if err != nil {
-			return lineError{i, line, err}
+	var err error
+	kind, index := StatementRaw, -1
+	fields := strings.Fields(line)
+	l.metadataFace = 0
+	switch strings.ToLower(fields[0]) {
+	case "vt":
+		err = l.processVertexTexCoord(fields[1:])
+		kind, index = StatementTexCoord, len(l.VT)-1
+	case "v":
+		err = l.processVertex(fields[1:])
+		kind, index = StatementVertex, len(l.V)-1
+	case "vn":
+		err = l.processVertexNormal(fields[1:])
+		kind, index = StatementNormal, len(l.VN)-1
+	case "f":
+		faces := len(l.F)
+		err = l.processFace(fields[1:])
+		kind = StatementFace
+		if len(l.F) > faces {
+			index = faces
+			l.metadataFace = faces + 1
+			if l.options.validatesIndices() {
+				l.faceLines = append(l.faceLines, lineNumber)
+			}
+		} else if l.facesFiltered {
+			l.metadataFace = -1
+		}
+	case "l":
+		lines := len(l.L)
+		err = l.processLine(fields[1:])
+		kind, index = StatementLine, len(l.L)-1
+		if len(l.L) > lines && l.options.validatesIndices() {
+			l.lineLines = append(l.lineLines, lineNumber)
+		}
+	case "g":
+		err = l.processGroup(line)
+	case "mtllib":
+		err = l.processMaterialLibrary(line)
+	case "usemtl":
+		if err = l.processUseMaterial(line); err == nil {
+			l.startFaceGroup()
+		}
+	case "s":
+		err = l.processSmoothingGroup(fields[1:])
+	case "o":
+		l.objects = append(l.objects, objectStart{Name: l.keep(strings.TrimSpace(line[1:])), FirstFaceIndex: len(l.F)})
+	case "vp":
+		l.warn(Warning{Line: lineNumber, Keyword: fields[0], Text: line})
+
+	default:
+		if l.options.OnUnknown != nil {
+			err = l.options.OnUnknown(fields[0], fields[1:], lineNumber)
+		} else {
+			l.warn(Warning{Line: lineNumber, Keyword: fields[0], Text: line})
 		}
 	}
+
+	if err != nil {
+		// The error outlives borrowed lines, such as those of a file
+		// unmapped once ReadFile returns.
+		return newLineError(lineNumber, raw, l.keep(line), err)
+	}
+	l.recordStatement(kind, index, raw)
+	return nil
+}
+
+// recordStatement appends a statement to Statements when reading in lossless
+// mode. Element statements without an element, such as discarded faces, are
+// not recorded.
+func (l *ObjReader) recordStatement(kind StatementKind, index int, raw string) {
+	if !l.options.Lossless || (kind != StatementRaw && index < 0) {
+		return
+	}
+	l.Statements = append(l.Statements, Statement{kind, index, l.keep(raw)})
+}
+
+// finish closes the open group and face group once all input is consumed.
+func (l *ObjReader) finish() {
+	l.finishClip()
+	l.finishRecenter()
+	l.padAttributes(len(l.V))
 	l.endGroup()
-	if len(l.FaceGroup) > 0 {
-		fg := l.FaceGroup[len(l.FaceGroup)-1]
-		fg.Size = len(l.F) - fg.Offset
-	} else {
-		ng := &faceGroup{Offset: 0, Size: len(l.F)}
-		l.FaceGroup = append(l.FaceGroup, ng)
+	l.endFaceGroup()
+}
+
+// validateIndices checks that the faces and lines only reference elements
+// the input defines.
+func (l *ObjReader) validateIndices() error {
+	for i, f := range l.F {
+		for j, c := range f.Corners {
+			err := FirstError(
+				checkIndex(c.VertexIndex, false, len(l.V), "vertex", "vertices"),
+				checkIndex(c.TexcoordIndex, true, len(l.VT), "texture coordinate", "texture coordinates"),
+				checkIndex(c.NormalIndex, true, len(l.VN), "normal", "normals"))
+			if err != nil {
+				return &LineError{Line: l.faceLines[i], Err: atField(err, j)}
+			}
+		}
+	}
+	for i, ll := range l.L {
+		for j, c := range ll.Corners {
+			if err := checkIndex(c, false, len(l.V), "vertex", "vertices"); err != nil {
+				return &LineError{Line: l.lineLines[i], Err: atField(err, j)}
+			}
+		}
+	}
+	return nil
+}
+
+// checkIndex returns an error if the 0-based index does not reference one of
+// count elements. -1 marks an absent element, which is accepted if optional.
+func checkIndex(index int, optional bool, count int, what, plural string) error {
+	if (optional && index == -1) || (index >= 0 && index < count) {
+		return nil
+	}
+	return badStatement(ErrBadIndex, "Index %d references an undefined %s (%d %s defined)", index+1, what, count, plural)
+}
+
+// startFaceGroup starts a face group for the active material. Faces read
+// before the first usemtl get a face group of their own, a face group that
+// got no faces is reused, and switching to the material already in use
+// keeps the current face group.
+func (l *ObjReader) startFaceGroup() {
+	fsz := len(l.F)
+	if n := len(l.FaceGroup); n > 0 {
+		fg := l.FaceGroup[n-1]
+		fg.Size = fsz - fg.Offset
+		if fg.Size == 0 && n > 1 && l.FaceGroup[n-2].Material == l.activeMaterial {
+			// Switching back to the previous material: continue its group.
+			l.FaceGroup = l.FaceGroup[:n-1]
+			return
+		}
+		if fg.Size == 0 || fg.Material == l.activeMaterial {
+			fg.Material = l.activeMaterial
+			return
+		}
+	} else if fsz > 0 {
+		l.FaceGroup = append(l.FaceGroup, &FaceGroup{Offset: 0, Size: fsz})
+	}
+	l.FaceGroup = append(l.FaceGroup, &FaceGroup{Offset: fsz, Material: l.activeMaterial})
+}
+
+// endFaceGroup closes the last face group, dropping it if it got no faces.
+// A buffer always ends up with at least one face group.
+func (l *ObjReader) endFaceGroup() {
+	n := len(l.FaceGroup)
+	if n == 0 {
+		l.FaceGroup = append(l.FaceGroup, &FaceGroup{Offset: 0, Size: len(l.F), Material: l.activeMaterial})
+		return
+	}
+	fg := l.FaceGroup[n-1]
+	fg.Size = len(l.F) - fg.Offset
+	if fg.Size == 0 && n > 1 {
+		l.FaceGroup = l.FaceGroup[:n-1]
+	}
+}
+
+// processComment handles a comment line. The offset header written by
+// ObjBuffer.Write is restored into Offset, the attribute comments into
+// Attributes and the face metadata comments into Face.Metadata; other
+// comments are only kept when
+// the KeepComments option is set.
+func (l *ObjReader) processComment(lineNumber int, line string) {
+	if l.processAttributeComment(lineNumber, line) || l.processMetadataComment(lineNumber, line) {
+		return
+	}
+	if offset, ok := parseOffsetComment(line); ok {
+		l.Offset = offset
+		return
+	}
+	if l.options.KeepComments {
+		l.keepComment(lineNumber, line[1:], false)
+	}
+}
+
+// parseOffsetComment parses the offset header written by ObjBuffer.Write.
+func parseOffsetComment(line string) (dvec3.T, bool) {
+	match := offsetRegex.FindStringSubmatch(line)
+	if match == nil {
+		return dvec3.T{}, false
+	}
+	x, errX := parseFloat(match[1], 64)
+	y, errY := parseFloat(match[2], 64)
+	z, errZ := parseFloat(match[3], 64)
+	return dvec3.T{x, y, z}, FirstError(errX, errY, errZ) == nil
+}
+
+// keepComment appends a comment to Comments. The banner written by
+// ObjBuffer.Write is skipped, so that it does not pile up when a file is
+// read and written repeatedly.
+func (l *ObjReader) keepComment(lineNumber int, text string, trailing bool) {
+	text = strings.TrimSpace(text)
+	if bannerRegex.MatchString(text) {
+		return
+	}
+	l.Comments = append(l.Comments, Comment{lineNumber, l.keep(text), trailing})
+}
+
+// stagesDoublePrecision reports whether positions are kept in VD while
+// parsing, either because they are wanted in the result or because they are
+// needed to recenter on the bounding box.
+func (l *ObjReader) stagesDoublePrecision() bool {
+	return l.options.DoublePrecision || l.options.AutoRecenter == RecenterBoundingBox
+}
+
+// finishRecenter moves the positions by the amount selected by the
+// AutoRecenter option and records it in Offset.
+func (l *ObjReader) finishRecenter() {
+	if len(l.V) == 0 {
+		return
+	}
+	switch l.options.AutoRecenter {
+	case RecenterFirstVertex:
+		l.Offset.Add(&l.recenterOrigin)
+	case RecenterBoundingBox:
+		box := dvec3.Box{Min: dvec3.MaxVal, Max: dvec3.MinVal}
+		for i := range l.VD {
+			box.Extend(&l.VD[i])
+		}
+		center := box.Center()
+		for i := range l.VD {
+			l.VD[i].Sub(&center)
+			l.V[i] = vec3.T{float32(l.VD[i][0]), float32(l.VD[i][1]), float32(l.VD[i][2])}
+		}
+		l.Offset.Add(&center)
+		if !l.options.DoublePrecision {
+			l.VD = nil
+		}
+	}
+}
+
+// countElements scans the input once and counts the statements that end up
+// as elements of the buffer.
+func countElements(reader io.Reader) (PreallocHint, error) {
+	var hint PreallocHint
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		hint.count(scanner.Bytes())
+	}
+	return hint, scanner.Err()
+}
+
+// count adds the element declared by line, if any, to the hint.
+func (hint *PreallocHint) count(line []byte) {
+	line = bytes.TrimLeft(line, " \t")
+	n := bytes.IndexAny(line, " \t")
+	if n == -1 {
+		return
+	}
+	switch string(line[:n]) {
+	case "v":
+		hint.Vertices++
+	case "vn":
+		hint.Normals++
+	case "vt":
+		hint.TexCoords++
+	case "f":
+		hint.Faces++
+	case "l":
+		hint.Lines++
+	}
+}
+
+// preallocate grows the buffer slices to hold at least the number of
+// elements given by hint.
+func (l *ObjReader) preallocate(hint PreallocHint) {
+	if max := l.options.Limits.MaxVertices; max > 0 {
+		hint.Vertices = minInt(hint.Vertices, max)
+		hint.Normals = minInt(hint.Normals, max)
+		hint.TexCoords = minInt(hint.TexCoords, max)
+	}
+	if max := l.options.Limits.MaxFaces; max > 0 {
+		hint.Faces = minInt(hint.Faces, max)
+		hint.Lines = minInt(hint.Lines, max)
+	}
+	if hint.Vertices > cap(l.V) {
+		v := make([]vec3.T, len(l.V), hint.Vertices)
+		copy(v, l.V)
+		l.V = v
+		if l.stagesDoublePrecision() {
+			vd := make([]dvec3.T, len(l.VD), hint.Vertices)
+			copy(vd, l.VD)
+			l.VD = vd
+		}
+	}
+	if hint.Normals > cap(l.VN) {
+		vn := make([]vec3.T, len(l.VN), hint.Normals)
+		copy(vn, l.VN)
+		l.VN = vn
+	}
+	if hint.TexCoords > cap(l.VT) {
+		vt := make([]vec2.T, len(l.VT), hint.TexCoords)
+		copy(vt, l.VT)
+		l.VT = vt
+	}
+	if hint.Faces > cap(l.F) {
+		f := make([]Face, len(l.F), hint.Faces)
+		copy(f, l.F)
+		l.F = f
+		// Most faces are triangles, so this covers the common case with a
+		// single allocation.
+		l.newCornerSlab(3 * (hint.Faces - len(l.F)))
+	}
+	if hint.Lines > cap(l.L) {
+		ll := make([]line, len(l.L), hint.Lines)
+		copy(ll, l.L)
+		l.L = ll
+	}
+}
+
+// allocCorners hands out n face corners from the corner slab, allocating a
+// new slab when the current one is exhausted. The returned slice is capped
+// so that appending to it never overwrites the corners of another face.
+func (l *ObjReader) allocCorners(n int) []FaceCorner {
+	if len(l.cornerSlab) < n {
+		size := cornerSlabSize
+		if n > size {
+			size = n
+		}
+		l.newCornerSlab(size)
+	}
+	corners := l.cornerSlab[:n:n]
+	l.cornerSlab = l.cornerSlab[n:]
+	return corners
+}
+
+// newCornerSlab allocates a corner slab of the given size.
+func (l *ObjReader) newCornerSlab(size int) {
+	l.cornerSlab = make([]FaceCorner, size)
+	if size > len(l.slab) {
+		l.slab = l.cornerSlab
 	}
-	return scanner.Err()
 }
 
 func (l *ObjReader) processVertex(fields []string) error {
-	if len(fields) != 3 && len(fields) != 4 {
-		return fmt.Errorf("Expected 3 or 4 fields, but got %d", len(fields))
+	if err := checkLimit(len(l.V), l.options.Limits.MaxVertices, "MaxVertices", "vertices"); err != nil {
+		return err
+	}
+	if len(fields) != 3 && len(fields) != 4 && len(fields) != 6 {
+		return badStatement(ErrBadVertex, "Expected 3, 4 or 6 fields, but got %d", len(fields))
 	}
-	x, errX := strconv.ParseFloat(fields[0], 32)
-	y, errY := strconv.ParseFloat(fields[1], 32)
-	z, errZ := strconv.ParseFloat(fields[2], 32)
-	if err := FirstError(errX, errY, errZ); err != nil {
+	bitSize := 32
+	if l.options.DoublePrecision || l.options.AutoRecenter != RecenterNone {
+		bitSize = 64
+	}
+	var v dvec3.T
+	if err := parseFloatFields(fields, 0, v[:], bitSize, ErrBadVertex); err != nil {
 		return err
 	}
-	l.V = append(l.V, vec3.T{float32(x), float32(y), float32(z)})
+	var color [3]float64
+	if len(fields) == 6 {
+		if err := parseFloatFields(fields, 3, color[:], 32, ErrBadVertex); err != nil {
+			return err
+		}
+	}
+	if l.options.AutoRecenter == RecenterFirstVertex {
+		if len(l.V) == 0 {
+			l.recenterOrigin = v
+		}
+		v.Sub(&l.recenterOrigin)
+	}
+	l.V = append(l.V, vec3.T{float32(v[0]), float32(v[1]), float32(v[2])})
+	if l.stagesDoublePrecision() {
+		l.VD = append(l.VD, v)
+	}
+	l.addVertexColor(len(fields) == 6, color)
 	return nil
 }
 
+// addVertexColor records the color of the vertex just read, if it has one.
+// Vertices without a color are white once any vertex declares one.
+func (l *ObjReader) addVertexColor(hasColor bool, color [3]float64) {
+	white := vec3.T{1, 1, 1}
+	if !hasColor {
+		if len(l.VC) > 0 {
+			l.VC = append(l.VC, white)
+		}
+		return
+	}
+	for len(l.VC) < len(l.V)-1 {
+		l.VC = append(l.VC, white)
+	}
+	l.VC = append(l.VC, vec3.T{float32(color[0]), float32(color[1]), float32(color[2])})
+}
+
 func (l *ObjReader) processVertexTexCoord(fields []string) error {
+	if err := checkLimit(len(l.VT), l.options.Limits.MaxVertices, "MaxVertices", "texture coordinates"); err != nil {
+		return err
+	}
 	if len(fields) < 2 {
-		return fmt.Errorf("Expected 2 fields, but got %d", len(fields))
+		return badStatement(ErrBadVertex, "Expected 2 fields, but got %d", len(fields))
 	}
-	s, errS := strconv.ParseFloat(fields[0], 32)
-	t, errT := strconv.ParseFloat(fields[1], 32)
-	if err := FirstError(errS, errT); err != nil {
+	var t [2]float64
+	if err := parseFloatFields(fields, 0, t[:], 32, ErrBadVertex); err != nil {
 		return err
 	}
-	l.VT = append(l.VT, vec2.T{float32(s), float32(t)})
+	l.VT = append(l.VT, vec2.T{float32(t[0]), float32(t[1])})
 	return nil
 }
 
 func (l *ObjReader) processVertexNormal(fields []string) error {
+	if err := checkLimit(len(l.VN), l.options.Limits.MaxVertices, "MaxVertices", "normals"); err != nil {
+		return err
+	}
 	if len(fields) != 3 {
-		return fmt.Errorf("Expected 3 fields, but got %d", len(fields))
+		return badStatement(ErrBadVertex, "Expected 3 fields, but got %d", len(fields))
 	}
-	x, errX := strconv.ParseFloat(fields[0], 32)
-	y, errY := strconv.ParseFloat(fields[1], 32)
-	z, errZ := strconv.ParseFloat(fields[2], 32)
-	if err := FirstError(errX, errY, errZ); err != nil {
+	var n [3]float64
+	if err := parseFloatFields(fields, 0, n[:], 32, ErrBadVertex); err != nil {
 		return err
 	}
-	l.VN = append(l.VN, vec3.T{float32(x), float32(y), float32(z)})
+	l.VN = append(l.VN, vec3.T{float32(n[0]), float32(n[1]), float32(n[2])})
 	return nil
 }
 
-func parseFaceField(field string) (faceCorner, error) {
+// elementCounts holds the number of vertices, texture coordinates and
+// normals defined before a statement, which relative indices refer to.
+type elementCounts struct {
+	v, vt, vn int
+}
+
+func (b *ObjBuffer) counts() elementCounts {
+	return elementCounts{len(b.V), len(b.VT), len(b.VN)}
+}
+
+func parseFaceField(field string, counts elementCounts) (FaceCorner, error) {
 	if match := faceVertexOnlyRegex.FindStringSubmatch(field); match != nil {
-		v, err := strconv.Atoi(match[1])
-		return faceCorner{v - 1, -1, -1}, err
+		v, err := parseIndex(match[1], counts.v)
+		return FaceCorner{v, -1, -1}, err
 	} else if match := faceVertexAndTexcoordRegex.FindStringSubmatch(field); match != nil {
-		v, errV := strconv.Atoi(match[1])
-		t, errN := strconv.Atoi(match[2])
-		return faceCorner{v - 1, -1, t - 1}, FirstError(errV, errN)
+		v, errV := parseIndex(match[1], counts.v)
+		t, errN := parseIndex(match[2], counts.vt)
+		return FaceCorner{v, -1, t}, FirstError(errV, errN)
 	} else if match := faceVertexAndNormalTexcoordRegex.FindStringSubmatch(field); match != nil {
-		v, errV := strconv.Atoi(match[1])
-		t, errN := strconv.Atoi(match[2])
-		n, errT := strconv.Atoi(match[3])
-		return faceCorner{v - 1, n - 1, t - 1}, FirstError(errV, errN, errT)
+		v, errV := parseIndex(match[1], counts.v)
+		t, errN := parseIndex(match[2], counts.vt)
+		n, errT := parseIndex(match[3], counts.vn)
+		return FaceCorner{v, n, t}, FirstError(errV, errN, errT)
 	} else if match := faceVertexAndNormalRegex.FindStringSubmatch(field); match != nil {
-		v, errV := strconv.Atoi(match[1])
-		n, errT := strconv.Atoi(match[2])
-		return faceCorner{v - 1, n - 1, -1}, FirstError(errV, errT)
+		v, errV := parseIndex(match[1], counts.v)
+		n, errT := parseIndex(match[2], counts.vn)
+		return FaceCorner{v, n, -1}, FirstError(errV, errT)
 	} else {
-		return faceCorner{-1, -1, -1}, fmt.Errorf("Face field '%s' is not on a supported format", field)
+		return FaceCorner{-1, -1, -1}, badStatement(ErrBadFace, "Face field '%s' is not on a supported format", field)
+	}
+}
+
+// parseIndex parses a 1-based element reference and returns it 0-based.
+// Negative references are relative to the count elements defined so far,
+// -1 being the last of them.
+func parseIndex(s string, count int) (int, error) {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return -1, badStatement(ErrBadIndex, "%v", err)
+	}
+	if i == 0 {
+		return -1, badStatement(ErrBadIndex, "Index 0 does not reference an element")
 	}
+	if i < 0 {
+		if count+i < 0 {
+			return -1, badStatement(ErrBadIndex, "Relative index %d references before the first element (%d defined)", i, count)
+		}
+		return count + i, nil
+	}
+	return i - 1, nil
+}
+
+// parseFloat parses a finite floating point number.
+func parseFloat(s string, bitSize int) (float64, error) {
+	f, err := strconv.ParseFloat(s, bitSize)
+	if err == nil && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return 0, fmt.Errorf("'%s' is not a finite number", s)
+	}
+	return f, err
+}
+
+// parseFloatFields parses len(values) finite numbers from fields, starting
+// at fields[first]. Errors are classified as kind.
+func parseFloatFields(fields []string, first int, values []float64, bitSize int, kind error) error {
+	for i := range values {
+		f, err := parseFloat(fields[first+i], bitSize)
+		if err != nil {
+			return &statementError{kind: kind, field: first + i, msg: err.Error()}
+		}
+		values[i] = f
+	}
+	return nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
 }
 
-func (l *ObjReader) isFaceAccepted(f *face) bool {
+func (l *ObjReader) isFaceAccepted(f *Face) bool {
 	if l.options.DiscardDegeneratedFaces {
 		occurences := make(map[int]bool, len(f.Corners))
 		for _, c := range f.Corners {
@@ -189,36 +753,77 @@ func (l *ObjReader) isFaceAccepted(f *face) bool {
 	return true
 }
 
+// isFiltered reports whether the faces of the current group and material
+// are skipped by ReadOptions.GroupFilter or MaterialFilter.
+func (l *ObjReader) isFiltered() bool {
+	if l.filterChecked {
+		return l.facesFiltered
+	}
+	l.filterChecked = true
+	l.facesFiltered = false
+	if filter := l.options.MaterialFilter; filter != nil && !filter(l.activeMaterial) {
+		l.facesFiltered = true
+	} else if filter := l.options.GroupFilter; filter != nil {
+		names := []string{defaultGroupName}
+		if n := len(l.G); n > 0 && len(l.G[n-1].Names()) > 0 {
+			names = l.G[n-1].Names()
+		}
+		l.facesFiltered = true
+		for _, name := range names {
+			if filter(name) {
+				l.facesFiltered = false
+				break
+			}
+		}
+	}
+	return l.facesFiltered
+}
+
 func (l *ObjReader) processLine(fields []string) error {
+	if err := checkLimit(len(l.L), l.options.Limits.MaxFaces, "MaxFaces", "lines"); err != nil {
+		return err
+	}
 	if len(fields) < 2 {
-		return fmt.Errorf("Expected %d fields, but got %d", 2, len(fields))
+		return badStatement(ErrBadPolyline, "Expected %d fields, but got %d", 2, len(fields))
 	}
 	ll := line{make([]int, len(fields)), l.activeMaterial}
 	for i, field := range fields {
-		corner, err := strconv.Atoi(field)
+		corner, err := parseIndex(field, len(l.V))
 		if err != nil {
-			return err
+			return atField(err, i)
 		}
-		ll.Corners[i] = corner - 1
+		ll.Corners[i] = corner
+	}
+	if l.isClipped(len(ll.Corners), func(j int) int { return ll.Corners[j] }) {
+		return nil
 	}
 	l.L = append(l.L, ll)
 	return nil
 }
 
 func (l *ObjReader) processFace(fields []string) error {
+	if err := checkLimit(len(l.F), l.options.Limits.MaxFaces, "MaxFaces", "faces"); err != nil {
+		return err
+	}
 	if len(fields) < 3 {
-		return fmt.Errorf("Expected %d fields, but got %d", 3, len(fields))
+		return badStatement(ErrBadFace, "Expected %d fields, but got %d", 3, len(fields))
 	}
 
-	f := face{make([]faceCorner, len(fields)), l.activeMaterial}
+	if l.isFiltered() {
+		return nil
+	}
+
+	f := Face{Corners: l.allocCorners(len(fields)), Material: l.activeMaterial, SmoothingGroup: l.smoothingGroup}
+	counts := l.counts()
 	for i, field := range fields {
-		corner, err := parseFaceField(field)
+		corner, err := parseFaceField(field, counts)
 		if err != nil {
-			return err
+			return atField(err, i)
 		}
 		f.Corners[i] = corner
 	}
-	if l.isFaceAccepted(&f) {
+	clipped := l.isClipped(len(f.Corners), func(j int) int { return f.Corners[j].VertexIndex })
+	if l.isFaceAccepted(&f) && !clipped {
 		l.F = append(l.F, f)
 	}
 	return nil
@@ -227,33 +832,84 @@ func (l *ObjReader) processFace(fields []string) error {
 func (l *ObjReader) processGroup(line string) error {
 	if match := groupRegex.FindStringSubmatch(line); match != nil {
 		l.endGroup()
-		l.startGroup(match[1])
+		l.startGroup(l.keep(match[1]))
+		l.filterChecked = false
+		return nil
+	}
+	return badStatement(ErrBadStatement, "Could not parse group")
+}
+
+func (l *ObjReader) processSmoothingGroup(fields []string) error {
+	if len(fields) != 1 {
+		return badStatement(ErrBadStatement, "Expected %d fields, but got %d", 1, len(fields))
+	}
+	if strings.EqualFold(fields[0], "off") {
+		l.smoothingGroup = 0
 		return nil
 	}
-	return fmt.Errorf("Could not parse group")
+	group, err := strconv.ParseUint(fields[0], 10, 32)
+	if err != nil {
+		return atField(badStatement(ErrBadStatement, "Could not parse smoothing group '%s'", fields[0]), 0)
+	}
+	l.smoothingGroup = uint32(group)
+	return nil
 }
 
 func (l *ObjReader) processMaterialLibrary(line string) error {
 	if l.MTL != "" {
-		return fmt.Errorf("Material library already set")
+		return badStatement(ErrBadStatement, "Material library already set")
 	}
 	if match := mtllibRegex.FindStringSubmatch(line); match != nil {
-		l.MTL = match[1]
+		l.MTL = l.keep(match[1])
 		return nil
 	}
-	return fmt.Errorf("Could not parse 'mtllib'-line")
+	return badStatement(ErrBadStatement, "Could not parse 'mtllib'-line")
 }
 
 func (l *ObjReader) processUseMaterial(line string) error {
 	if match := usemtlRegex.FindStringSubmatch(line); match != nil {
-		l.activeMaterial = match[1]
+		// A usemtl without a name resets the material of the next faces.
+		if max := l.options.Limits.MaxMaterials; max > 0 && match[1] != "" && !l.materials[match[1]] {
+			if err := checkLimit(len(l.materials), max, "MaxMaterials", "materials"); err != nil {
+				return err
+			}
+			if l.materials == nil {
+				l.materials = make(map[string]bool)
+			}
+			l.materials[l.keep(match[1])] = true
+		}
+		l.activeMaterial = l.keep(match[1])
+		l.filterChecked = false
 		return nil
 	}
-	return fmt.Errorf("Could not parse 'usemtl'-line")
+	return badStatement(ErrBadStatement, "Could not parse 'usemtl'-line")
+}
+
+// warn reports a warning to ReadOptions.OnWarning, or records it in
+// Warnings.
+func (l *ObjReader) warn(w Warning) {
+	w.Keyword = l.keep(w.Keyword)
+	w.Text = l.keep(w.Text)
+	if l.options.OnWarning != nil {
+		l.options.OnWarning(w)
+		return
+	}
+	l.Warnings = append(l.Warnings, w)
+}
+
+// keep returns a copy of s that is safe to retain after parsing when the
+// current line is borrowed, and s itself otherwise.
+func (l *ObjReader) keep(s string) string {
+	if !l.borrowed {
+		return s
+	}
+	b := make([]byte, len(s))
+	copy(b, s)
+	return string(b)
 }
 
 func (l *ObjReader) startGroup(name string) {
-	g := group{
+	g := Group{
 		Name:           name,
 		FirstFaceIndex: len(l.F),
 		FaceCount:      -1,
@@ -261,7 +917,7 @@ func (l *ObjReader) startGroup(name string) {
 	l.G = append(l.G, g)
 }
 
-func (l *ObjReader) isGroupAccepted(f *face) bool {
+func (l *ObjReader) isGroupAccepted(f *Face) bool {
 	if l.options.DiscardDegeneratedFaces {
 		occurences := make(map[int]bool, len(f.Corners))
 		for _, c := range f.Corners {
@@ -288,9 +944,9 @@ func (l *ObjReader) endGroup() {
 				l.G = nil
 			}
 		}
-	} else {
-		g := group{
-			Name:           "default group",
+	} else if len(l.F) > 0 {
+		g := Group{
+			Name:           defaultGroupName,
 			FirstFaceIndex: 0,
 			FaceCount:      len(l.F),
 		}
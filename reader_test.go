@@ -2,6 +2,8 @@ package obj
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"image"
 	"image/png"
 	"math"
@@ -11,6 +13,7 @@ import (
 	"strings"
 	"testing"
 
+	dvec3 "github.com/flywave/go3d/float64/vec3"
 	"github.com/flywave/go3d/vec2"
 	"github.com/flywave/go3d/vec3"
 	"github.com/stretchr/testify/assert"
@@ -37,8 +40,8 @@ func TestObjReader_ProcessMaterialLibrary_AlreadySet_ReturnsError(t *testing.T)
 func TestObjReader_ProcessGroup_ValidLine_EndsAndStartsGroup(t *testing.T) {
 	// Arrange
 	loader := ObjReader{}
-	loader.F = []face{face{}}
-	loader.G = append(loader.G, group{FirstFaceIndex: 0, FaceCount: -1})
+	loader.F = []Face{Face{}}
+	loader.G = append(loader.G, Group{FirstFaceIndex: 0, FaceCount: -1})
 
 	// Act
 	err := loader.processGroup("g   group")
@@ -59,7 +62,7 @@ func TestObjReader_ProcessGroup_InvalidLine_ReturnsError(t *testing.T) {
 func TestObjReader_ProcessUseMaterial_ValidLine_SetsActiveMaterial(t *testing.T) {
 	// Arrange
 	loader := ObjReader{}
-	loader.F = []face{face{}}
+	loader.F = []Face{Face{}}
 
 	// Act
 	err := loader.processUseMaterial("usemtl       material_name")
@@ -175,7 +178,7 @@ func TestObjReader_EndGroup_NoGroups_DoesNotPanic(t *testing.T) {
 func TestObjReader_EndGroup_GroupStarted_UpdatesFaceCount(t *testing.T) {
 	// Arrange
 	loader := ObjReader{}
-	loader.G = append(loader.G, group{
+	loader.G = append(loader.G, Group{
 		Name:           "Test",
 		FirstFaceIndex: 0,
 		FaceCount:      -1,
@@ -186,7 +189,7 @@ func TestObjReader_EndGroup_GroupStarted_UpdatesFaceCount(t *testing.T) {
 	loader.endGroup()
 
 	// Assert
-	assert.Equal(t, []group{group{"Test", 0, 1}}, loader.G)
+	assert.Equal(t, []Group{Group{"Test", 0, 1}}, loader.G)
 }
 
 func TestObjReader_ProcessFace_UsesActiveMaterial(t *testing.T) {
@@ -206,7 +209,7 @@ func TestObjReader_ProcessFace_UsesActiveMaterial(t *testing.T) {
 func TestObjReader_EndGroup_EmptyGroup_DiscardsLast(t *testing.T) {
 	// Arrange
 	loader := ObjReader{}
-	origGroups := []group{group{Name: "first"}}
+	origGroups := []Group{Group{Name: "first"}}
 	loader.G = origGroups
 
 	// Act
@@ -267,12 +270,7 @@ func WalkDir(dir string) {
 			file, _ := os.Open(fname)
 			loader.Read(file)
 
-			bbox := loader.BoundingBox()
-			center := bbox.Center()
-
-			for i, v := range loader.V {
-				loader.V[i] = vec3.Sub(&v, &center)
-			}
+			loader.Center()
 
 			f, _ := os.Create(fname)
 
@@ -300,12 +298,7 @@ func TestBBox2(t *testing.T) {
 		t.Error(err)
 	}
 
-	bbox := loader.BoundingBox()
-	center := bbox.Center()
-
-	for i, v := range loader.V {
-		loader.V[i] = vec3.Sub(&v, &center)
-	}
+	loader.Center()
 
 	f, _ := os.Create("./aa.obj")
 
@@ -391,3 +384,484 @@ func TestProssTexture(t *testing.T) {
 
 	WalkDirTexture("./model")
 }
+
+func TestObjReader_Read_PreallocHint_AllocatesCapacity(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{PreallocHint: PreallocHint{Vertices: 100, Faces: 50}})
+
+	// Act
+	err := loader.Read(strings.NewReader("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(loader.V))
+	assert.Equal(t, 100, cap(loader.V))
+	assert.Equal(t, 1, len(loader.F))
+	assert.Equal(t, 50, cap(loader.F))
+}
+
+func TestObjReader_Read_TwoPass_AllocatesExactCapacity(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{TwoPass: true})
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\nv 1 1 0\n" +
+		"vn 0 0 1\nvt 0 0\nvt 1 1\n" +
+		"f 1 2 3\nf 2 4 3\nl 1 2\n"
+
+	// Act
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4, cap(loader.V))
+	assert.Equal(t, 1, cap(loader.VN))
+	assert.Equal(t, 2, cap(loader.VT))
+	assert.Equal(t, 2, cap(loader.F))
+	assert.Equal(t, 1, cap(loader.L))
+	assert.Equal(t, 4, len(loader.V))
+	assert.Equal(t, 2, len(loader.F))
+}
+
+func TestObjReader_AllocCorners_DoesNotShareStorage(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+
+	// Act
+	a := loader.allocCorners(3)
+	b := loader.allocCorners(3)
+	a = append(a, FaceCorner{VertexIndex: 42})
+
+	// Assert
+	assert.Equal(t, 4, len(a))
+	assert.Equal(t, FaceCorner{}, b[0])
+}
+
+func TestObjReader_ProcessVertex_DoublePrecision_KeepsFloat64(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{DoublePrecision: true})
+
+	// Act
+	err := loader.processVertex([]string{"500000.123", "5400000.456", "12.5"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(loader.V))
+	assert.Equal(t, 1, len(loader.VD))
+	assert.Equal(t, dvec3.T{500000.123, 5400000.456, 12.5}, loader.VD[0])
+}
+
+func TestObjReader_Read_RecenterFirstVertex_RecordsOffset(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{AutoRecenter: RecenterFirstVertex})
+
+	// Act
+	err := loader.Read(strings.NewReader("v 500000.5 5400000.25 10\nv 500001.5 5400002.25 12\n"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, dvec3.T{500000.5, 5400000.25, 10}, loader.Offset)
+	assert.Equal(t, []vec3.T{{0, 0, 0}, {1, 2, 2}}, loader.V)
+	assert.Nil(t, loader.VD)
+}
+
+func TestObjReader_Read_RecenterBoundingBox_RecordsOffset(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{AutoRecenter: RecenterBoundingBox})
+
+	// Act
+	err := loader.Read(strings.NewReader("v 500000 5400000 10\nv 500002 5400004 12\n"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, dvec3.T{500001, 5400002, 11}, loader.Offset)
+	assert.Equal(t, []vec3.T{{-1, -2, -1}, {1, 2, 1}}, loader.V)
+	assert.Nil(t, loader.VD)
+}
+
+func TestObjReader_Read_OffsetComment_RestoresOffset(t *testing.T) {
+	loader := ObjReader{}
+
+	err := loader.Read(strings.NewReader("# offset 100 200.5 -3\nv 1 2 3\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, dvec3.T{100, 200.5, -3}, loader.Offset)
+}
+
+func TestObjReader_Read_KeepComments_CapturesComments(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{KeepComments: true})
+	input := "# Exported using RenderDB\n" +
+		"# captured 2021-05-04\n" +
+		"# CRS: EPSG:32633\n" +
+		"v 1 2 3 # first vertex\n"
+
+	// Act
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []Comment{
+		{Line: 2, Text: "captured 2021-05-04"},
+		{Line: 3, Text: "CRS: EPSG:32633"},
+		{Line: 4, Text: "first vertex", Trailing: true},
+	}, loader.Comments)
+	assert.Equal(t, 1, len(loader.V))
+}
+
+func TestObjReader_Read_WithoutKeepComments_DropsComments(t *testing.T) {
+	loader := ObjReader{}
+
+	err := loader.Read(strings.NewReader("# CRS: EPSG:32633\nv 1 2 3\n"))
+
+	assert.NoError(t, err)
+	assert.Nil(t, loader.Comments)
+}
+
+func TestObjReader_Read_UnknownKeyword_IsSkippedByDefault(t *testing.T) {
+	loader := ObjReader{}
+
+	err := loader.Read(strings.NewReader("v 1 2 3\nintensity 0.5\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(loader.V))
+}
+
+func TestObjReader_Read_OnUnknown_ReceivesStatement(t *testing.T) {
+	// Arrange
+	var intensities []string
+	var lines []int
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{OnUnknown: func(keyword string, fields []string, line int) error {
+		assert.Equal(t, "intensity", keyword)
+		intensities = append(intensities, fields...)
+		lines = append(lines, line)
+		return nil
+	}})
+
+	// Act
+	err := loader.Read(strings.NewReader("v 1 2 3\nintensity 0.5\nv 4 5 6\nintensity 0.75\n"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0.5", "0.75"}, intensities)
+	assert.Equal(t, []int{2, 4}, lines)
+}
+
+func TestObjReader_Read_OnUnknownReturnsError_AbortsRead(t *testing.T) {
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{OnUnknown: func(keyword string, fields []string, line int) error {
+		return fmt.Errorf("unsupported statement '%s'", keyword)
+	}})
+
+	err := loader.Read(strings.NewReader("v 1 2 3\nbogus\n"))
+
+	assert.Error(t, err)
+}
+
+// readTestObj reads input with the options, failing the test on error.
+func readTestObj(t *testing.T, input string, options ReadOptions) *ObjReader {
+	loader := &ObjReader{}
+	loader.SetOptions(options)
+	if err := loader.Read(strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	return loader
+}
+
+func readFaceGroups(t *testing.T, input string) []FaceGroup {
+	loader := readTestObj(t, "v 0 0 0\nv 1 0 0\nv 0 1 0\n"+input, ReadOptions{})
+	groups := make([]FaceGroup, len(loader.FaceGroup))
+	for i, fg := range loader.FaceGroup {
+		groups[i] = *fg
+	}
+	return groups
+}
+
+func TestObjReader_Read_NoUsemtl_SingleFaceGroup(t *testing.T) {
+	groups := readFaceGroups(t, "f 1 2 3\nf 1 2 3\n")
+	assert.Equal(t, []FaceGroup{{Offset: 0, Size: 2}}, groups)
+}
+
+func TestObjReader_Read_FacesBeforeFirstUsemtl_GetOwnFaceGroup(t *testing.T) {
+	groups := readFaceGroups(t, "f 1 2 3\nusemtl a\nf 1 2 3\nf 1 2 3\n")
+	assert.Equal(t, []FaceGroup{
+		{Offset: 0, Size: 1},
+		{Offset: 1, Size: 2, Material: "a"},
+	}, groups)
+}
+
+func TestObjReader_Read_ConsecutiveUsemtl_CollapsesEmptyFaceGroups(t *testing.T) {
+	groups := readFaceGroups(t, "usemtl a\nusemtl b\nusemtl c\nf 1 2 3\nusemtl d\nusemtl e\nf 1 2 3\nusemtl f\n")
+	assert.Equal(t, []FaceGroup{
+		{Offset: 0, Size: 1, Material: "c"},
+		{Offset: 1, Size: 1, Material: "e"},
+	}, groups)
+}
+
+func TestObjReader_Read_RepeatedMaterialSwitches_MergesFaceGroups(t *testing.T) {
+	groups := readFaceGroups(t, "usemtl a\nf 1 2 3\nusemtl a\nf 1 2 3\nusemtl b\nusemtl a\nf 1 2 3\nusemtl b\nf 1 2 3\n")
+	assert.Equal(t, []FaceGroup{
+		{Offset: 0, Size: 3, Material: "a"},
+		{Offset: 3, Size: 1, Material: "b"},
+	}, groups)
+}
+
+func TestObjReader_Read_FaceGroupsMatchFaceMaterials(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\n"
+	for i := 0; i < 50; i++ {
+		input += fmt.Sprintf("usemtl m%d\nusemtl m%d\nf 1 2 3\n", i%3, i%5)
+	}
+
+	// Act
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	assert.NoError(t, err)
+	next := 0
+	for _, fg := range loader.FaceGroup {
+		assert.Equal(t, next, fg.Offset)
+		assert.True(t, fg.Size > 0)
+		for i := fg.Offset; i < fg.Offset+fg.Size; i++ {
+			assert.Equal(t, fg.Material, loader.F[i].Material)
+		}
+		next = fg.Offset + fg.Size
+	}
+	assert.Equal(t, len(loader.F), next)
+}
+
+func TestObjReader_Read_GroupBeforeFaces_NoEmptyDefaultGroup(t *testing.T) {
+	loader := ObjReader{}
+
+	err := loader.Read(strings.NewReader("v 0 0 0\nv 1 0 0\nv 0 1 0\ng roof\nf 1 2 3\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Group{{Name: "roof", FirstFaceIndex: 0, FaceCount: 1}}, loader.G)
+}
+
+func TestObjReader_Read_VertexColors_FillsMissingWithWhite(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0 1 0 0\nv 0 1 0\nv 0 0 1 0 0.5 1\n"
+
+	// Act
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []vec3.T{{1, 1, 1}, {1, 0, 0}, {1, 1, 1}, {0, 0.5, 1}}, loader.VC)
+	assert.Equal(t, vec3.T{0, 0, 1}, loader.V[3])
+}
+
+func TestObjReader_Read_NoVertexColors_LeavesColorsEmpty(t *testing.T) {
+	loader := ObjReader{}
+
+	err := loader.Read(strings.NewReader("v 0 0 0\nv 1 0 0 1\n"))
+
+	assert.NoError(t, err)
+	assert.Nil(t, loader.VC)
+}
+
+func TestObjReader_Read_Limits_RejectsTooManyElements(t *testing.T) {
+	tests := []struct {
+		input  string
+		limits Limits
+	}{
+		{"v 0 0 0\nv 1 0 0\nv 0 1 0\n", Limits{MaxVertices: 2}},
+		{"vn 0 0 1\nvn 0 0 1\nvn 0 0 1\n", Limits{MaxVertices: 2}},
+		{"vt 0 0\nvt 0 0\nvt 0 0\n", Limits{MaxVertices: 2}},
+		{"v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\nf 1 2 3\n", Limits{MaxFaces: 1}},
+		{"v 0 0 0\nv 1 0 0\nl 1 2\nl 1 2\n", Limits{MaxFaces: 1}},
+	}
+	for _, test := range tests {
+		loader := ObjReader{}
+		loader.SetOptions(ReadOptions{Limits: test.limits, TwoPass: true})
+
+		err := loader.Read(strings.NewReader(test.input))
+
+		assert.Error(t, err, test.input)
+	}
+}
+
+func TestObjReader_Read_MaxLineLen_RejectsLongLines(t *testing.T) {
+	// Arrange
+	input := "v 0 0 0\n# " + strings.Repeat("x", 100) + "\n"
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{Limits: Limits{MaxLineLen: 64}})
+
+	// Act
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	assert.EqualError(t, err, "Line #2: More than 64 bytes in a line (MaxLineLen)")
+	assert.True(t, errors.Is(err, ErrLimitExceeded))
+}
+
+func TestObjReader_Read_MaxLineLen_AcceptsLinesAtLimit(t *testing.T) {
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{Limits: Limits{MaxLineLen: 9}})
+
+	err := loader.Read(strings.NewReader("v 0 0 0.5\r\nv 1 0 0\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(loader.V))
+}
+
+func TestObjReader_Read_NonFiniteNumbers_ReturnsError(t *testing.T) {
+	for _, input := range []string{"v NaN 0 0\n", "v 0 Inf 0\n", "vn 0 0 -infinity\n", "vt nan 0\n", "v 0 0 1e39\n", "v 0 0 0 1 nan 0\n"} {
+		loader := ObjReader{}
+
+		err := loader.Read(strings.NewReader(input))
+
+		assert.Error(t, err, input)
+	}
+}
+
+func TestObjReader_Read_IndexZero_ReturnsError(t *testing.T) {
+	for _, input := range []string{"v 0 0 0\nf 0 1 1\n", "v 0 0 0\nvt 0 0\nf 1/0 1/1 1/1\n", "v 0 0 0\nl 1 0\n"} {
+		loader := ObjReader{}
+
+		err := loader.Read(strings.NewReader(input))
+
+		assert.Error(t, err, input)
+	}
+}
+
+func TestObjReader_Read_RepeatedUsemtl_KeepsSingleFaceGroup(t *testing.T) {
+	// Arrange
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\n" + strings.Repeat("usemtl a\nusemtl b\n", 10000) + "f 1 2 3\n"
+	loader := ObjReader{}
+
+	// Act
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []*FaceGroup{{Offset: 0, Size: 1, Material: "b"}}, loader.FaceGroup)
+}
+
+func TestObjReader_Read_ValidateIndices_AcceptsLateVertices(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{ValidateIndices: true})
+	input := "f 1/1 2/2 3/3\nl 1 3\nv 0 0 0\nv 1 0 0\nv 0 1 0\nvt 0 0\nvt 1 0\nvt 0 1\n"
+
+	// Act
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(loader.F))
+	assert.Equal(t, 1, len(loader.L))
+}
+
+func TestObjReader_Read_ValidateIndices_UndefinedElement_ReturnsError(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{ValidateIndices: true})
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\nf 1//1 2//1 3//1\nvn 0 0 1\nf 1 2 4\n"
+
+	// Act
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	var lineErr *LineError
+	assert.True(t, errors.Is(err, ErrBadIndex))
+	if assert.True(t, errors.As(err, &lineErr)) {
+		assert.Equal(t, 7, lineErr.Line)
+	}
+	assert.Equal(t, "Line #7: Index 4 references an undefined vertex (3 vertices defined)", err.Error())
+}
+
+func TestObjReader_Read_RelativeIndices_ResolvesAgainstCountsSoFar(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\nvt 0 0\nvn 0 0 1\nf -3/-1/-1 -2/-1/-1 -1/-1/-1\n" +
+		"v 1 1 0\nvn 0 0 -1\nf 2//-2 4//-1 -2//-1\nl -4 -1\n"
+
+	// Act
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []FaceCorner{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}}, loader.F[0].Corners)
+	assert.Equal(t, []FaceCorner{{1, 0, -1}, {3, 1, -1}, {2, 1, -1}}, loader.F[1].Corners)
+	assert.Equal(t, []int{0, 3}, loader.L[0].Corners)
+}
+
+func TestObjReader_Read_UnresolvableRelativeIndex_ReturnsError(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+
+	// Act
+	err := loader.Read(strings.NewReader("v 0 0 0\nv 1 0 0\nv 0 1 0\nf -3/-1 -2/-1 -1/-1\n"))
+
+	// Assert
+	var lineErr *LineError
+	assert.True(t, errors.Is(err, ErrBadIndex))
+	if assert.True(t, errors.As(err, &lineErr)) {
+		assert.Equal(t, 4, lineErr.Line)
+		assert.Equal(t, 3, lineErr.Column)
+	}
+	assert.Contains(t, err.Error(), "Relative index -1 references before the first element (0 defined)")
+}
+
+func TestObjReader_Read_GroupFilter_SkipsOtherGroups(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{GroupFilter: func(name string) bool { return name == "terrain" }})
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\n" +
+		"g buildings\nusemtl brick\nf 1 2 3\n#fm building=4\n" +
+		"g terrain ground\nusemtl grass\nf 3 2 1\nf 1 3 2\n" +
+		"g trees\nf 2 1 3\n"
+
+	// Act
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, loader.F, 2)
+	assert.Equal(t, []string{"terrain", "ground"}, loader.GroupNames())
+	assert.Equal(t, 0, loader.G[0].FirstFaceIndex)
+	assert.Equal(t, 2, loader.G[0].FaceCount)
+	assert.Len(t, loader.FaceGroup, 1)
+	assert.Equal(t, "grass", loader.FaceGroup[0].Material)
+	assert.Empty(t, loader.Warnings)
+}
+
+func TestObjReader_Read_MaterialFilter_SkipsOtherMaterials(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{MaterialFilter: func(material string) bool { return material != "glass" }})
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\n" +
+		"f 1 2 3\nusemtl glass\nf 3 2 1\nusemtl steel\nf 1 3 2\n"
+
+	// Act
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, loader.F, 2)
+	assert.Equal(t, "", loader.F[0].Material)
+	assert.Equal(t, "steel", loader.F[1].Material)
+	assert.Len(t, loader.V, 3)
+}
+
+func TestObjReader_Read_LimitsSet_ValidatesIndices(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{Limits: Limits{MaxVertices: 16}})
+	input := "v 0 0 0\nf 99999999999 1 -1\n"
+
+	// Act
+	err := loader.Read(strings.NewReader(input))
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrBadIndex))
+}
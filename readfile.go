@@ -0,0 +1,86 @@
+package obj
+
+import (
+	"bytes"
+	"unsafe"
+)
+
+// ReadFile reads the OBJ file at path. Where the platform supports it the
+// file is memory-mapped and parsed in place, so the data is neither copied
+// into a read buffer nor into per-line strings.
+func ReadFile(path string, options ReadOptions) (*ObjBuffer, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unmap()
+
+	loader := &ObjReader{}
+	loader.SetOptions(options)
+	if err := loader.readBytes(data); err != nil {
+		return nil, err
+	}
+	return &loader.ObjBuffer, nil
+}
+
+// readBytes parses data like Read does. Lines are sliced out of data without
+// copying, so anything retained from them must go through keep.
+func (l *ObjReader) readBytes(data []byte) (err error) {
+	metrics := startMetrics(l.options.Metrics, "read")
+	defer func() { metrics.finish(int64(len(data)), err) }()
+
+	hint := l.options.PreallocHint
+	if l.options.TwoPass {
+		hint = PreallocHint{}
+		forEachLine(data, func(line []byte) {
+			hint.count(line)
+		})
+		metrics.endStage("count")
+	}
+	l.preallocate(hint)
+
+	l.borrowed = true
+	defer func() { l.borrowed = false }()
+
+	i := 0
+	forEachLine(data, func(line []byte) {
+		if err != nil {
+			return
+		}
+		i++
+		err = l.processStatement(i, bytesToString(line))
+	})
+	if err != nil {
+		return err
+	}
+	metrics.endStage("parse")
+	l.finish()
+	metrics.endStage("finish")
+	return nil
+}
+
+// forEachLine calls fn for every line in data, without the line terminator.
+func forEachLine(data []byte, fn func(line []byte)) {
+	for len(data) > 0 {
+		n := bytes.IndexByte(data, '\n')
+		if n == -1 {
+			fn(dropCR(data))
+			return
+		}
+		fn(dropCR(data[:n]))
+		data = data[n+1:]
+	}
+}
+
+// dropCR drops a terminal \r from line, like bufio.ScanLines does.
+func dropCR(line []byte) []byte {
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		return line[:len(line)-1]
+	}
+	return line
+}
+
+// bytesToString converts b to a string that shares its memory.
+func bytesToString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
@@ -0,0 +1,98 @@
+package obj
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const readFileTestObj = "mtllib scene.mtl\r\n" +
+	"v 0 0 0\nv 1 0 0\nv 0 1 0\nv 1 1 0\n" +
+	"vn 0 0 1\n" +
+	"g roof\n" +
+	"usemtl tiles\n" +
+	"f 1//1 2//1 3//1\n" +
+	"f 2//1 4//1 3//1"
+
+func writeTempObj(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "test.obj")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadFile_MatchesRead(t *testing.T) {
+	// Arrange
+	path := writeTempObj(t, readFileTestObj)
+	loader := ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader(readFileTestObj)))
+
+	// Act
+	buffer, err := ReadFile(path, ReadOptions{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, loader.V, buffer.V)
+	assert.Equal(t, loader.VN, buffer.VN)
+	assert.Equal(t, loader.F, buffer.F)
+	assert.Equal(t, loader.G, buffer.G)
+	assert.Equal(t, "scene.mtl", buffer.MTL)
+	assert.Equal(t, "roof", buffer.G[len(buffer.G)-1].Name)
+	assert.Equal(t, "tiles", buffer.F[1].Material)
+}
+
+func TestReadFile_TwoPass_AllocatesExactCapacity(t *testing.T) {
+	// Arrange
+	path := writeTempObj(t, readFileTestObj)
+
+	// Act
+	buffer, err := ReadFile(path, ReadOptions{TwoPass: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4, cap(buffer.V))
+	assert.Equal(t, 2, cap(buffer.F))
+}
+
+func TestReadFile_EmptyFile_ReturnsEmptyBuffer(t *testing.T) {
+	path := writeTempObj(t, "")
+
+	buffer, err := ReadFile(path, ReadOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(buffer.V))
+}
+
+func TestReadFile_MissingFile_ReturnsError(t *testing.T) {
+	_, err := ReadFile(filepath.Join(t.TempDir(), "missing.obj"), ReadOptions{})
+	assert.Error(t, err)
+}
+
+func TestReadFile_MaxLineLen_RejectsLongLines(t *testing.T) {
+	path := writeTempObj(t, "v 0 0 0\nv 1 0 0 "+strings.Repeat("0", 100)+"\n")
+
+	_, err := ReadFile(path, ReadOptions{Limits: Limits{MaxLineLen: 64}})
+
+	assert.EqualError(t, err, "Line #2: More than 64 bytes in a line (MaxLineLen)")
+}
+
+func TestReadFile_InvalidStatement_ErrorOutlivesFile(t *testing.T) {
+	// Arrange
+	path := writeTempObj(t, "v 0 0 0\nv 1 0 zzz\n")
+
+	// Act
+	_, err := ReadFile(path, ReadOptions{})
+
+	// Assert
+	var lineErr *LineError
+	if assert.True(t, errors.As(err, &lineErr)) {
+		assert.Equal(t, "v 1 0 zzz", lineErr.Text)
+		assert.Contains(t, err.Error(), "Line #2")
+		assert.Contains(t, err.Error(), "zzz")
+	}
+}
@@ -0,0 +1,224 @@
+package obj
+
+import (
+	"fmt"
+	"math"
+
+	dmat4 "github.com/flywave/go3d/float64/mat4"
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+)
+
+// RegisterOptions controls how Register aligns two buffers.
+type RegisterOptions struct {
+	// Initial is the transform to start from, such as a rough manual
+	// alignment. The zero value starts from the identity.
+	Initial dmat4.T
+	// MaxIterations bounds the number of iterations. It defaults to 50.
+	MaxIterations int
+	// MaxDistance ignores the source vertices farther than this from the
+	// target once moved, such as the parts of a scan the reference model
+	// does not cover. 0 uses all vertices.
+	MaxDistance float64
+	// Tolerance stops the iterations once a step moves the vertices by less
+	// than this. It defaults to 1e-9 times the size of the target.
+	Tolerance float64
+}
+
+// Register returns the rigid transform that best aligns the vertices of
+// source on the surface of target, using the point-to-plane variant of the
+// iterative closest point algorithm: every iteration pairs each source
+// vertex with the closest target vertex, and finds the rotation and
+// translation minimizing the distances from the source vertices to the
+// tangent planes of the target at these vertices.
+//
+// The transform maps the positions of source, offset included, to the
+// positions of target, offset included, like Node.Transform. ICP only
+// converges to the right alignment from a close enough start; use Initial
+// or AlignToPrincipalAxes for a rough alignment first.
+func Register(source, target *ObjBuffer, options RegisterOptions) (dmat4.T, error) {
+	transform := options.Initial
+	if transform.IsZero() {
+		transform = dmat4.Ident
+	}
+	if options.MaxIterations <= 0 {
+		options.MaxIterations = 50
+	}
+
+	// Work around the centroid of the target, to keep the rotations well
+	// conditioned far from the origin.
+	points, normals := target.vertexNormals()
+	if len(points) == 0 {
+		return transform, fmt.Errorf("Target has no faces to register to")
+	}
+	var center dvec3.T
+	for i := range points {
+		points[i].Add(&target.Offset)
+		center.Add(&points[i])
+	}
+	center.Scale(1 / float64(len(points)))
+	size := 0.0
+	for i := range points {
+		points[i].Sub(&center)
+		size = math.Max(size, points[i].Length())
+	}
+	if options.Tolerance <= 0 {
+		options.Tolerance = 1e-9 * math.Max(size, 1)
+	}
+	tree := newPointTree(points)
+
+	sources := make([]dvec3.T, len(source.V))
+	for i := range source.V {
+		sources[i] = source.positionD(i)
+		sources[i].Add(&source.Offset)
+	}
+	// current maps the source positions, relative to the centroid of the
+	// target.
+	minusCenter := center.Inverted()
+	toCenter, fromCenter := dmat4.Ident, dmat4.Ident
+	toCenter.SetTranslation(&minusCenter)
+	fromCenter.SetTranslation(&center)
+	var current dmat4.T
+	current.AssignMul(&toCenter, &transform)
+
+	for iteration := 0; iteration < options.MaxIterations; iteration++ {
+		var ata [6][6]float64
+		var atb [6]float64
+		pairs := 0
+		for _, s := range sources {
+			p := current.MulVec3(&s)
+			j, d := tree.nearest(p)
+			if options.MaxDistance > 0 && d > options.MaxDistance {
+				continue
+			}
+			n := normals[j]
+			c := dvec3.Cross(&p, &n)
+			row := [6]float64{c[0], c[1], c[2], n[0], n[1], n[2]}
+			diff := dvec3.Sub(&points[j], &p)
+			r := dvec3.Dot(&diff, &n)
+			for a := 0; a < 6; a++ {
+				for b := 0; b < 6; b++ {
+					ata[a][b] += row[a] * row[b]
+				}
+				atb[a] += row[a] * r
+			}
+			pairs++
+		}
+		if pairs < 6 {
+			return transform, fmt.Errorf("Only %d source vertices are close enough to the target to register", pairs)
+		}
+		x, ok := solveDamped(ata, atb)
+		if !ok {
+			return transform, fmt.Errorf("Registration is degenerate")
+		}
+
+		step := rigidTransform(dvec3.T{x[0], x[1], x[2]}, dvec3.T{x[3], x[4], x[5]})
+		var next dmat4.T
+		next.AssignMul(&step, &current)
+		current = next
+		angle := math.Sqrt(x[0]*x[0] + x[1]*x[1] + x[2]*x[2])
+		shift := math.Sqrt(x[3]*x[3] + x[4]*x[4] + x[5]*x[5])
+		if angle*size+shift < options.Tolerance {
+			break
+		}
+	}
+	transform.AssignMul(&fromCenter, &current)
+	return transform, nil
+}
+
+// vertexNormals returns the positions of the vertices used by faces and
+// the area-weighted average of the normals of their faces, skipping the
+// vertices whose faces have no area.
+func (b *ObjBuffer) vertexNormals() ([]dvec3.T, []dvec3.T) {
+	sums := make([]dvec3.T, len(b.V))
+	for i := range b.F {
+		n := b.newellNormal(i)
+		for _, loop := range b.F[i].loops() {
+			for _, c := range loop {
+				if c.VertexIndex >= 0 && c.VertexIndex < len(b.V) {
+					sums[c.VertexIndex].Add(&n)
+				}
+			}
+		}
+	}
+	var points, normals []dvec3.T
+	for i, n := range sums {
+		if n.Length() == 0 {
+			continue
+		}
+		points = append(points, b.positionD(i))
+		normals = append(normals, n.Normalized())
+	}
+	return points, normals
+}
+
+// rigidTransform returns the rotation by the angle |rotation| around the
+// axis rotation followed by the translation.
+func rigidTransform(rotation, translation dvec3.T) dmat4.T {
+	m := dmat4.Ident
+	if angle := rotation.Length(); angle > 0 {
+		axis := rotation.Scaled(1 / angle)
+		c, s := math.Cos(angle), math.Sin(angle)
+		var r [3]dvec3.T
+		for i := 0; i < 3; i++ {
+			var e dvec3.T
+			e[i] = 1
+			// Rodrigues' rotation formula, applied to the basis vectors.
+			k := dvec3.Cross(&axis, &e)
+			r[i] = e.Scaled(c)
+			k.Scale(s)
+			r[i].Add(&k)
+			along := axis.Scaled(dvec3.Dot(&axis, &e) * (1 - c))
+			r[i].Add(&along)
+		}
+		for col := 0; col < 3; col++ {
+			for row := 0; row < 3; row++ {
+				m[col][row] = r[col][row]
+			}
+		}
+	}
+	m.SetTranslation(&translation)
+	return m
+}
+
+// solveDamped solves the normal equations a x = b, adding a little to the
+// diagonal so that directions the data does not constrain, such as sliding
+// along a plane, are left unchanged instead of making a singular.
+func solveDamped(a [6][6]float64, b [6]float64) ([6]float64, bool) {
+	trace := 0.0
+	for i := 0; i < 6; i++ {
+		trace += a[i][i]
+	}
+	for i := 0; i < 6; i++ {
+		a[i][i] += 1e-9 * trace
+	}
+	var x [6]float64
+	// Gaussian elimination with partial pivoting.
+	for col := 0; col < 6; col++ {
+		pivot := col
+		for row := col + 1; row < 6; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if a[pivot][col] == 0 || math.IsNaN(a[pivot][col]) {
+			return x, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+		for row := col + 1; row < 6; row++ {
+			f := a[row][col] / a[col][col]
+			for k := col; k < 6; k++ {
+				a[row][k] -= f * a[col][k]
+			}
+			b[row] -= f * b[col]
+		}
+	}
+	for row := 5; row >= 0; row-- {
+		sum := b[row]
+		for k := row + 1; k < 6; k++ {
+			sum -= a[row][k] * x[k]
+		}
+		x[row] = sum / a[row][row]
+	}
+	return x, true
+}
@@ -0,0 +1,83 @@
+package obj
+
+import (
+	"math"
+	"testing"
+
+	dmat4 "github.com/flywave/go3d/float64/mat4"
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+// createTerrain returns a bumpy n x n grid of unit cells.
+func createTerrain(n int) *ObjBuffer {
+	buffer := createGrid(float32(n), n)
+	for i := range buffer.V {
+		x, y := float64(buffer.V[i][0]), float64(buffer.V[i][1])
+		buffer.V[i][2] = float32(math.Sin(x/2) * math.Cos(y/3) * 2)
+	}
+	return buffer
+}
+
+// transformed returns a copy of the vertices of b moved by m.
+func transformed(b *ObjBuffer, m dmat4.T) *ObjBuffer {
+	moved := &ObjBuffer{F: b.F, G: b.G, FaceGroup: b.FaceGroup}
+	for i := range b.V {
+		p := b.positionD(i)
+		p = m.MulVec3(&p)
+		moved.V = append(moved.V, vec3.T{float32(p[0]), float32(p[1]), float32(p[2])})
+		moved.VD = append(moved.VD, p)
+	}
+	return moved
+}
+
+func TestRegister_MovedTerrain_FindsInverse(t *testing.T) {
+	// Arrange
+	target := createTerrain(20)
+	target.Offset = dvec3.T{1000, 2000, 0}
+	motion := rigidTransform(dvec3.T{0.02, -0.03, 0.05}, dvec3.T{0.3, -0.2, 0.1})
+	source := transformed(target, motion)
+	source.Offset = target.Offset
+
+	// Act
+	transform, err := Register(source, target, RegisterOptions{})
+
+	// Assert
+	assert.NoError(t, err)
+	for i := range source.VD {
+		p := source.positionD(i)
+		p.Add(&source.Offset)
+		p = transform.MulVec3(&p)
+		q := target.positionD(i)
+		q.Add(&target.Offset)
+		assert.True(t, dvec3.Distance(&p, &q) < 1e-4, "vertex %d is off by %g", i, dvec3.Distance(&p, &q))
+	}
+}
+
+func TestRegister_Initial_IsKeptWhenAligned(t *testing.T) {
+	// Arrange
+	target := createTerrain(10)
+	motion := rigidTransform(dvec3.T{0, 0, 0.5}, dvec3.T{4, 0, 0})
+	source := transformed(target, motion)
+	inverse := motion.Inverted()
+
+	// Act
+	transform, err := Register(source, target, RegisterOptions{Initial: inverse, MaxDistance: 1})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, inverse.Slice(), transform.Slice(), 1e-6)
+}
+
+func TestRegister_NoTargetFaces_Fails(t *testing.T) {
+	// Arrange
+	source := createTerrain(2)
+	target := &ObjBuffer{V: source.V}
+
+	// Act
+	_, err := Register(source, target, RegisterOptions{})
+
+	// Assert
+	assert.Error(t, err)
+}
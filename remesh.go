@@ -0,0 +1,485 @@
+package obj
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+)
+
+// Remesh turns the faces into triangles whose edges are close to
+// targetEdgeLength, repeating the given number of times a pass splitting
+// the long edges, collapsing the short ones, flipping edges to even out the
+// number of edges per vertex and relaxing the vertices within the surface.
+//
+// Boundaries, and the edges between faces of different groups, materials,
+// smoothing groups or metadata, are kept: they are split but never
+// flipped, and their vertices do not move. Vertices used by lines do not
+// move either. The triangles keep the attributes of the faces they come
+// from, in the same order. Normals, texture coordinates, vertex colors and
+// custom attributes cannot follow the new vertices and are dropped; use
+// ComputeNormals to restore the normals. Remesh does nothing if
+// targetEdgeLength is not positive.
+func (b *ObjBuffer) Remesh(targetEdgeLength float64, iterations int) {
+	if targetEdgeLength <= 0 {
+		return
+	}
+	m := b.newRemesher()
+	high := targetEdgeLength * 4 / 3
+	low := targetEdgeLength * 4 / 5
+	for i := 0; i < iterations; i++ {
+		m.findFeatures()
+		m.splitLongEdges(high)
+		m.collapseShortEdges(low, high)
+		m.flipEdges()
+		m.relax()
+	}
+	m.apply(b)
+}
+
+// remesher holds the triangles being remeshed.
+type remesher struct {
+	pos []dvec3.T
+	// around lists the triangles around every vertex. It may hold triangles
+	// that were removed or no longer use the vertex, which trianglesAt
+	// drops.
+	around  [][]int
+	removed []bool
+	// fixed marks the vertices that must not move or be removed; feature
+	// the vertices on an edge that is kept.
+	fixed   []bool
+	feature []bool
+
+	tris  [][3]int
+	alive []bool
+	// origin is the face every triangle comes from, and class identifies
+	// the attributes of the face.
+	origin []int
+	class  []int
+}
+
+// newRemesher triangulates the faces of b.
+func (b *ObjBuffer) newRemesher() *remesher {
+	m := &remesher{
+		pos:     make([]dvec3.T, len(b.V)),
+		around:  make([][]int, len(b.V)),
+		removed: make([]bool, len(b.V)),
+		fixed:   make([]bool, len(b.V)),
+	}
+	for i := range b.V {
+		m.pos[i] = b.positionD(i)
+	}
+	for _, l := range b.L {
+		for _, v := range l.Corners {
+			if v >= 0 && v < len(b.V) {
+				m.fixed[v] = true
+			}
+		}
+	}
+
+	groupOf := b.groupOfFaces()
+	classes := make(map[string]int)
+	b.EachTriangle(func(_ [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		f := &b.F[faceIdx]
+		var key strings.Builder
+		fmt.Fprintf(&key, "%d %q %d", groupOf[faceIdx], f.Material, f.SmoothingGroup)
+		for _, name := range f.metadataNames() {
+			fmt.Fprintf(&key, " %q=%d", name, f.Metadata[name])
+		}
+		class, ok := classes[key.String()]
+		if !ok {
+			class = len(classes)
+			classes[key.String()] = class
+		}
+		m.addTriangle([3]int{corners[0].VertexIndex, corners[1].VertexIndex, corners[2].VertexIndex}, faceIdx, class)
+		return true
+	})
+	return m
+}
+
+func (m *remesher) addTriangle(tri [3]int, origin, class int) int {
+	t := len(m.tris)
+	m.tris = append(m.tris, tri)
+	m.alive = append(m.alive, true)
+	m.origin = append(m.origin, origin)
+	m.class = append(m.class, class)
+	for _, v := range tri {
+		m.around[v] = append(m.around[v], t)
+	}
+	return t
+}
+
+func (m *remesher) addVertex(p dvec3.T, feature bool) int {
+	m.pos = append(m.pos, p)
+	m.around = append(m.around, nil)
+	m.removed = append(m.removed, false)
+	m.fixed = append(m.fixed, false)
+	m.feature = append(m.feature, feature)
+	return len(m.pos) - 1
+}
+
+// trianglesAt returns the triangles using vertex v.
+func (m *remesher) trianglesAt(v int) []int {
+	kept := m.around[v][:0]
+	for _, t := range m.around[v] {
+		if m.alive[t] && m.uses(t, v) && (len(kept) == 0 || kept[len(kept)-1] != t) {
+			kept = append(kept, t)
+		}
+	}
+	m.around[v] = kept
+	return kept
+}
+
+func (m *remesher) uses(t, v int) bool {
+	tri := m.tris[t]
+	return tri[0] == v || tri[1] == v || tri[2] == v
+}
+
+// edgeTriangles returns the triangles using the edge between a and b.
+func (m *remesher) edgeTriangles(a, b int) []int {
+	var tris []int
+	for _, t := range m.trianglesAt(a) {
+		if m.uses(t, b) {
+			tris = append(tris, t)
+		}
+	}
+	return tris
+}
+
+// neighbors returns the vertices sharing an edge with v.
+func (m *remesher) neighbors(v int) []int {
+	var vertices []int
+	for _, t := range m.trianglesAt(v) {
+		for _, w := range m.tris[t] {
+			if w != v {
+				vertices = append(vertices, w)
+			}
+		}
+	}
+	return uniqueInts(vertices)
+}
+
+// isFeatureEdge reports whether the edge between a and b is kept: it is on
+// a boundary, not manifold, or between triangles of different classes.
+func (m *remesher) isFeatureEdge(a, b int) bool {
+	tris := m.edgeTriangles(a, b)
+	return len(tris) != 2 || m.class[tris[0]] != m.class[tris[1]]
+}
+
+// edges returns the edges of the triangles, each once.
+func (m *remesher) edges() []Edge {
+	seen := make(map[Edge]bool)
+	var edges []Edge
+	for t, tri := range m.tris {
+		if !m.alive[t] {
+			continue
+		}
+		for k := 0; k < 3; k++ {
+			e := NewEdge(tri[k], tri[(k+1)%3])
+			if !seen[e] {
+				seen[e] = true
+				edges = append(edges, e)
+			}
+		}
+	}
+	return edges
+}
+
+// findFeatures marks the vertices on kept edges.
+func (m *remesher) findFeatures() {
+	m.feature = make([]bool, len(m.pos))
+	for _, e := range m.edges() {
+		if m.isFeatureEdge(e[0], e[1]) {
+			m.feature[e[0]] = true
+			m.feature[e[1]] = true
+		}
+	}
+}
+
+func (m *remesher) length(e Edge) float64 {
+	return dvec3.Distance(&m.pos[e[0]], &m.pos[e[1]])
+}
+
+// splitLongEdges splits the edges longer than high at their middle, longest
+// first.
+func (m *remesher) splitLongEdges(high float64) {
+	var long []Edge
+	for _, e := range m.edges() {
+		if m.length(e) > high {
+			long = append(long, e)
+		}
+	}
+	sort.SliceStable(long, func(i, j int) bool { return m.length(long[i]) > m.length(long[j]) })
+	for _, e := range long {
+		tris := m.edgeTriangles(e[0], e[1])
+		if len(tris) == 0 {
+			continue
+		}
+		mid := dvec3.Interpolate(&m.pos[e[0]], &m.pos[e[1]], 0.5)
+		v := m.addVertex(mid, m.isFeatureEdge(e[0], e[1]))
+		for _, t := range tris {
+			// Rotate the triangle to (x, y, c), with x and y the ends of the
+			// edge in the order of the triangle.
+			tri := m.tris[t]
+			for tri[2] == e[0] || tri[2] == e[1] {
+				tri = [3]int{tri[2], tri[0], tri[1]}
+			}
+			m.tris[t] = [3]int{tri[0], v, tri[2]}
+			m.around[v] = append(m.around[v], t)
+			m.addTriangle([3]int{v, tri[1], tri[2]}, m.origin[t], m.class[t])
+		}
+	}
+}
+
+// collapseShortEdges merges the ends of the edges shorter than low, unless
+// that would create an edge longer than high, change the topology or turn
+// a triangle over.
+func (m *remesher) collapseShortEdges(low, high float64) {
+	var short []Edge
+	for _, e := range m.edges() {
+		if m.length(e) < low {
+			short = append(short, e)
+		}
+	}
+	sort.SliceStable(short, func(i, j int) bool { return m.length(short[i]) < m.length(short[j]) })
+	for _, e := range short {
+		if m.removed[e[0]] || m.removed[e[1]] {
+			continue
+		}
+		tris := m.edgeTriangles(e[0], e[1])
+		if len(tris) != 2 {
+			continue
+		}
+		remove, keep := e[0], e[1]
+		if m.fixed[remove] || m.feature[remove] {
+			remove, keep = keep, remove
+		}
+		if m.fixed[remove] || m.feature[remove] {
+			continue
+		}
+		target := m.pos[keep]
+		if !m.feature[keep] && !m.fixed[keep] {
+			target = dvec3.Interpolate(&m.pos[remove], &m.pos[keep], 0.5)
+		}
+		if !m.canCollapse(remove, keep, tris, target, high) {
+			continue
+		}
+		for _, t := range tris {
+			m.alive[t] = false
+		}
+		for _, t := range m.trianglesAt(remove) {
+			for k := range m.tris[t] {
+				if m.tris[t][k] == remove {
+					m.tris[t][k] = keep
+				}
+			}
+			m.around[keep] = append(m.around[keep], t)
+		}
+		m.pos[keep] = target
+		m.removed[remove] = true
+		m.around[remove] = nil
+	}
+}
+
+// canCollapse reports whether vertex remove can be merged into keep, moved
+// to target.
+func (m *remesher) canCollapse(remove, keep int, tris []int, target dvec3.T, high float64) bool {
+	// The ends of the edge may only share the neighbors opposite to it.
+	opposite := make(map[int]bool)
+	for _, t := range tris {
+		for _, v := range m.tris[t] {
+			if v != remove && v != keep {
+				opposite[v] = true
+			}
+		}
+	}
+	keepNeighbors := make(map[int]bool)
+	for _, v := range m.neighbors(keep) {
+		keepNeighbors[v] = true
+		if v != remove && dvec3.Distance(&m.pos[v], &target) > high {
+			return false
+		}
+	}
+	for _, v := range m.neighbors(remove) {
+		if v != keep && keepNeighbors[v] && !opposite[v] {
+			return false
+		}
+		if v != keep && dvec3.Distance(&m.pos[v], &target) > high {
+			return false
+		}
+	}
+	for _, v := range []int{remove, keep} {
+		for _, t := range m.trianglesAt(v) {
+			if t == tris[0] || t == tris[1] {
+				continue
+			}
+			before := m.normal(m.tris[t], -1, dvec3.T{})
+			after := m.normal(m.tris[t], v, target)
+			if dvec3.Dot(&before, &after) <= 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// normal returns the unnormalized normal of the triangle, with vertex moved
+// placed at p.
+func (m *remesher) normal(tri [3]int, moved int, p dvec3.T) dvec3.T {
+	var q [3]dvec3.T
+	for k, v := range tri {
+		q[k] = m.pos[v]
+		if v == moved {
+			q[k] = p
+		}
+	}
+	e1, e2 := dvec3.Sub(&q[1], &q[0]), dvec3.Sub(&q[2], &q[0])
+	return dvec3.Cross(&e1, &e2)
+}
+
+// flipEdges flips the edges between two triangles when that brings the
+// number of edges of the four vertices involved closer to 6, or 4 on
+// boundaries.
+func (m *remesher) flipEdges() {
+	boundary := make([]bool, len(m.pos))
+	for _, e := range m.edges() {
+		if len(m.edgeTriangles(e[0], e[1])) == 1 {
+			boundary[e[0]] = true
+			boundary[e[1]] = true
+		}
+	}
+	deviation := func(v, change int) int {
+		target := 6
+		if boundary[v] {
+			target = 4
+		}
+		d := len(m.neighbors(v)) + change - target
+		return d * d
+	}
+	for _, e := range m.edges() {
+		if m.isFeatureEdge(e[0], e[1]) {
+			continue
+		}
+		tris := m.edgeTriangles(e[0], e[1])
+		// Orient the edge as (a, b) in the first triangle (a, b, c); the
+		// second one is (b, a, d).
+		tri := m.tris[tris[0]]
+		for tri[2] == e[0] || tri[2] == e[1] {
+			tri = [3]int{tri[2], tri[0], tri[1]}
+		}
+		a, b, c := tri[0], tri[1], tri[2]
+		d := -1
+		for _, v := range m.tris[tris[1]] {
+			if v != a && v != b {
+				d = v
+			}
+		}
+		if d == -1 || c == d || len(m.edgeTriangles(c, d)) > 0 {
+			continue
+		}
+		before := deviation(a, 0) + deviation(b, 0) + deviation(c, 0) + deviation(d, 0)
+		after := deviation(a, -1) + deviation(b, -1) + deviation(c, 1) + deviation(d, 1)
+		if after >= before {
+			continue
+		}
+		n1 := m.normal(m.tris[tris[0]], -1, dvec3.T{})
+		n2 := m.normal(m.tris[tris[1]], -1, dvec3.T{})
+		n := dvec3.Add(&n1, &n2)
+		first, second := [3]int{a, d, c}, [3]int{d, b, c}
+		nf, ns := m.normal(first, -1, dvec3.T{}), m.normal(second, -1, dvec3.T{})
+		if dvec3.Dot(&nf, &n) <= 0 || dvec3.Dot(&ns, &n) <= 0 {
+			continue
+		}
+		m.tris[tris[0]], m.tris[tris[1]] = first, second
+		m.around[d] = append(m.around[d], tris[0])
+		m.around[c] = append(m.around[c], tris[1])
+	}
+}
+
+// relax moves every free vertex towards the center of its neighbors,
+// within the tangent plane of the surface.
+func (m *remesher) relax() {
+	moved := make(map[int]dvec3.T)
+	for v := range m.pos {
+		if m.removed[v] || m.fixed[v] || m.feature[v] {
+			continue
+		}
+		neighbors := m.neighbors(v)
+		if len(neighbors) == 0 {
+			continue
+		}
+		var center, normal dvec3.T
+		for _, w := range neighbors {
+			center.Add(&m.pos[w])
+		}
+		center.Scale(1 / float64(len(neighbors)))
+		for _, t := range m.trianglesAt(v) {
+			n := m.normal(m.tris[t], -1, dvec3.T{})
+			normal.Add(&n)
+		}
+		step := dvec3.Sub(&center, &m.pos[v])
+		if normal.Length() > 0 {
+			normal.Normalize()
+			along := normal.Scaled(dvec3.Dot(&step, &normal))
+			step.Sub(&along)
+		}
+		moved[v] = dvec3.Add(&m.pos[v], &step)
+	}
+	for v, p := range moved {
+		m.pos[v] = p
+	}
+}
+
+// apply replaces the faces of b by the triangles, in the order of the
+// faces they come from.
+func (m *remesher) apply(b *ObjBuffer) {
+	var tris []int
+	for t := range m.tris {
+		if m.alive[t] {
+			tris = append(tris, t)
+		}
+	}
+	sort.SliceStable(tris, func(i, j int) bool { return m.origin[tris[i]] < m.origin[tris[j]] })
+
+	newFirst := make([]int, len(b.F)+1)
+	faces := make([]Face, 0, len(tris))
+	next := 0
+	for _, t := range tris {
+		for ; next <= m.origin[t]; next++ {
+			newFirst[next] = len(faces)
+		}
+		f := &b.F[m.origin[t]]
+		corners := make([]FaceCorner, 3)
+		for k, v := range m.tris[t] {
+			corners[k] = FaceCorner{VertexIndex: v, NormalIndex: -1, TexcoordIndex: -1}
+		}
+		faces = append(faces, Face{Corners: corners, Material: f.Material, SmoothingGroup: f.SmoothingGroup, Metadata: f.Metadata})
+	}
+	for ; next <= len(b.F); next++ {
+		newFirst[next] = len(faces)
+	}
+	for i := range b.G {
+		first, count := b.G[i].FirstFaceIndex, b.G[i].FaceCount
+		if first >= 0 && count >= 0 && first+count <= len(b.F) {
+			b.G[i].FirstFaceIndex, b.G[i].FaceCount = newFirst[first], newFirst[first+count]-newFirst[first]
+		}
+	}
+	b.F = faces
+	b.FaceGroup = faceGroupsOf(faces)
+
+	double := b.hasDoublePrecision()
+	b.V = make([]vec3.T, len(m.pos))
+	if double {
+		b.VD = make([]dvec3.T, len(m.pos))
+	}
+	for i, p := range m.pos {
+		b.V[i] = vec3.T{float32(p[0]), float32(p[1]), float32(p[2])}
+		if double {
+			b.VD[i] = p
+		}
+	}
+	b.VN, b.VT, b.VC, b.Attributes = nil, nil, nil, nil
+	b.removeUnreferenced()
+}
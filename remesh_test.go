@@ -0,0 +1,137 @@
+package obj
+
+import (
+	"math"
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+// createGrid returns a buffer with a single size x size square face split
+// into n x n quads, in the group "floor".
+func createGrid(size float32, n int) *ObjBuffer {
+	buffer := &ObjBuffer{}
+	for y := 0; y <= n; y++ {
+		for x := 0; x <= n; x++ {
+			buffer.V = append(buffer.V, vec3.T{size * float32(x) / float32(n), size * float32(y) / float32(n), 0})
+		}
+	}
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			v := y*(n+1) + x
+			buffer.F = append(buffer.F, Face{Corners: []FaceCorner{
+				{v, -1, -1}, {v + 1, -1, -1}, {v + n + 2, -1, -1}, {v + n + 1, -1, -1}}})
+		}
+	}
+	buffer.G = []Group{{Name: "floor", FaceCount: len(buffer.F)}}
+	buffer.FaceGroup = faceGroupsOf(buffer.F)
+	return buffer
+}
+
+// edgeLengths returns the mean edge length of the triangles and their
+// total area along Z.
+func edgeLengths(t *testing.T, buffer *ObjBuffer) (mean, area float64) {
+	count := 0
+	for _, f := range buffer.F {
+		assert.Len(t, f.Corners, 3)
+		var p [3]dvec3.T
+		for k := range f.Corners {
+			p[k] = buffer.positionD(f.Corners[k].VertexIndex)
+		}
+		for k := 0; k < 3; k++ {
+			mean += dvec3.Distance(&p[k], &p[(k+1)%3])
+			count++
+		}
+		e1, e2 := dvec3.Sub(&p[1], &p[0]), dvec3.Sub(&p[2], &p[0])
+		cross := dvec3.Cross(&e1, &e2)
+		assert.True(t, cross[2] > 0, "triangle is not front facing")
+		area += cross[2] / 2
+	}
+	return mean / float64(count), area
+}
+
+func TestObjBuffer_Remesh_Plane_ReachesTargetLength(t *testing.T) {
+	// Arrange
+	buffer := createGrid(4, 2)
+
+	// Act
+	buffer.Remesh(0.5, 5)
+
+	// Assert
+	mean, area := edgeLengths(t, buffer)
+	assert.InDelta(t, 16, area, 1e-4)
+	assert.InDelta(t, 0.5, mean, 0.15)
+	assert.Equal(t, len(buffer.F), buffer.G[0].FaceCount)
+	assert.Equal(t, len(buffer.F), buffer.FaceGroup[0].Size)
+	for _, v := range buffer.V {
+		assert.InDelta(t, 0, v[2], 1e-6)
+	}
+}
+
+func TestObjBuffer_Remesh_Groups_KeepsBorder(t *testing.T) {
+	// Arrange
+	buffer := createGrid(4, 2)
+	buffer.G = []Group{{Name: "left", FaceCount: 2}, {Name: "right", FirstFaceIndex: 2, FaceCount: 2}}
+	buffer.F[0].Material, buffer.F[1].Material = "stone", "stone"
+	buffer.FaceGroup = faceGroupsOf(buffer.F)
+
+	// Act
+	buffer.Remesh(0.5, 3)
+
+	// Assert
+	_, area := edgeLengths(t, buffer)
+	assert.InDelta(t, 16, area, 1e-4)
+	assert.Equal(t, len(buffer.F), buffer.G[0].FaceCount+buffer.G[1].FaceCount)
+	for i, f := range buffer.F {
+		left := i < buffer.G[0].FaceCount
+		for _, c := range f.Corners {
+			y := buffer.V[c.VertexIndex][1]
+			if left {
+				assert.True(t, y <= 2+1e-5)
+				assert.Equal(t, "stone", f.Material)
+			} else {
+				assert.True(t, y >= 2-1e-5)
+				assert.Equal(t, "", f.Material)
+			}
+		}
+	}
+}
+
+func TestObjBuffer_Remesh_Sphere_StaysOnSurface(t *testing.T) {
+	// Arrange
+	buffer := createSphere(2, 12, 6)
+
+	// Act
+	buffer.Remesh(0.4, 4)
+
+	// Assert
+	assert.True(t, len(buffer.F) > 12*10)
+	report := buffer.Quality()
+	assert.Equal(t, 0, report.Degenerate)
+	edges := make(map[Edge]int)
+	for _, f := range buffer.F {
+		for k := 0; k < 3; k++ {
+			edges[NewEdge(f.Corners[k].VertexIndex, f.Corners[(k+1)%3].VertexIndex)]++
+		}
+	}
+	for _, n := range edges {
+		assert.Equal(t, 2, n)
+	}
+	for _, v := range buffer.V {
+		assert.InDelta(t, 2, math.Sqrt(float64(v[0]*v[0]+v[1]*v[1]+v[2]*v[2])), 0.25)
+	}
+}
+
+func TestObjBuffer_Remesh_NoTarget_DoesNothing(t *testing.T) {
+	// Arrange
+	buffer := createGrid(4, 2)
+
+	// Act
+	buffer.Remesh(0, 5)
+
+	// Assert
+	assert.Len(t, buffer.F, 4)
+	assert.Len(t, buffer.V, 9)
+}
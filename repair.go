@@ -0,0 +1,213 @@
+package obj
+
+import (
+	"sort"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+)
+
+// RepairReport describes the changes made by RepairDegenerateFaces.
+type RepairReport struct {
+	// MergedCorners is the number of corners removed because they repeated
+	// the position of the previous corner.
+	MergedCorners int
+	// CollapsedFaces lists the indices, before the repair, of the faces
+	// removed because they had no area left.
+	CollapsedFaces []int
+	// InsertedCorners is the number of corners inserted into the neighbours
+	// of collapsed faces.
+	InsertedCorners int
+}
+
+// Empty reports whether the repair changed nothing.
+func (r *RepairReport) Empty() bool {
+	return r.MergedCorners == 0 && len(r.CollapsedFaces) == 0 && r.InsertedCorners == 0
+}
+
+// RepairDegenerateFaces repairs faces instead of discarding them like
+// ReadOptions.DiscardDegeneratedFaces does. Consecutive corners at the same
+// position are merged, in the holes too. Faces left with less than three
+// corners, or without area, are removed, as are holes left with less than
+// three corners. The corners in the middle of a sliver, a face whose
+// corners are collinear, are inserted into the faces sharing its longest
+// edge, so that no crack opens where it was. Normals and texture
+// coordinates of inserted corners are interpolated along the edge.
+func (b *ObjBuffer) RepairDegenerateFaces() *RepairReport {
+	report := &RepairReport{}
+	collapse := make([]bool, len(b.F))
+	var slivers []sliver
+	for i := range b.F {
+		f := &b.F[i]
+		corners := b.mergeCorners(f.Corners, report)
+		f.Corners = corners
+		if len(f.Holes) > 0 {
+			holes := make([][]FaceCorner, 0, len(f.Holes))
+			for _, hole := range f.Holes {
+				if hole = b.mergeCorners(hole, report); len(hole) >= 3 {
+					holes = append(holes, hole)
+				}
+			}
+			f.Holes = holes
+		}
+
+		if len(corners) < 3 {
+			collapse[i] = true
+		} else if s, ok := b.sliver(i); ok {
+			collapse[i] = true
+			slivers = append(slivers, s)
+		}
+		if collapse[i] {
+			report.CollapsedFaces = append(report.CollapsedFaces, i)
+		}
+	}
+
+	for _, s := range slivers {
+		for i := range b.F {
+			if !collapse[i] {
+				report.InsertedCorners += b.insertSliverCorners(&b.F[i], s)
+			}
+		}
+	}
+	b.RemoveFaces(func(i int, f *Face) bool {
+		return collapse[i]
+	})
+	return report
+}
+
+// mergeCorners returns the corners of a loop without those at the position
+// of the previous corner, counting them in the report.
+func (b *ObjBuffer) mergeCorners(loop []FaceCorner, report *RepairReport) []FaceCorner {
+	corners := make([]FaceCorner, 0, len(loop))
+	for _, c := range loop {
+		if len(corners) > 0 && b.sameCornerPosition(corners[len(corners)-1], c) {
+			report.MergedCorners++
+			continue
+		}
+		corners = append(corners, c)
+	}
+	for len(corners) > 1 && b.sameCornerPosition(corners[len(corners)-1], corners[0]) {
+		corners = corners[:len(corners)-1]
+		report.MergedCorners++
+	}
+	return corners
+}
+
+// sliver is a face without area: its corners lie on the edge from u to w,
+// middle sorted from u to w.
+type sliver struct {
+	u, w   FaceCorner
+	middle []FaceCorner
+}
+
+// sameCornerPosition reports whether corners c1 and c2 are at the same
+// position.
+func (b *ObjBuffer) sameCornerPosition(c1, c2 FaceCorner) bool {
+	return c1.VertexIndex == c2.VertexIndex || b.uvPosition(c1.VertexIndex) == b.uvPosition(c2.VertexIndex)
+}
+
+// sliver returns the sliver of face i, if the face has no area.
+func (b *ObjBuffer) sliver(i int) (sliver, bool) {
+	corners := b.F[i].Corners
+	var s sliver
+	longest := -1.0
+	for j := range corners {
+		for k := j + 1; k < len(corners); k++ {
+			p, q := b.uvPosition(corners[j].VertexIndex), b.uvPosition(corners[k].VertexIndex)
+			if d := dvec3.SquareDistance(&p, &q); d > longest {
+				longest = d
+				s.u, s.w = corners[j], corners[k]
+			}
+		}
+	}
+	n := b.newellNormal(i)
+	if n.Length() > longest*1e-9 {
+		return sliver{}, false
+	}
+
+	u := b.uvPosition(s.u.VertexIndex)
+	for _, c := range corners {
+		if c != s.u && c != s.w {
+			s.middle = append(s.middle, c)
+		}
+	}
+	sort.SliceStable(s.middle, func(j, k int) bool {
+		p, q := b.uvPosition(s.middle[j].VertexIndex), b.uvPosition(s.middle[k].VertexIndex)
+		return dvec3.SquareDistance(&u, &p) < dvec3.SquareDistance(&u, &q)
+	})
+	return s, true
+}
+
+// insertSliverCorners inserts the middle corners of s into every edge of f,
+// its holes included, joining the ends of s, and returns the number of
+// corners inserted.
+func (b *ObjBuffer) insertSliverCorners(f *Face, s sliver) int {
+	if len(s.middle) == 0 {
+		return 0
+	}
+	corners, inserted := b.insertSliverLoop(f.Corners, s)
+	f.Corners = corners
+	if len(f.Holes) > 0 {
+		holes := make([][]FaceCorner, len(f.Holes))
+		for j, hole := range f.Holes {
+			var n int
+			holes[j], n = b.insertSliverLoop(hole, s)
+			inserted += n
+		}
+		f.Holes = holes
+	}
+	return inserted
+}
+
+// insertSliverLoop returns the corners of a loop with the middle corners of
+// s inserted into its edges joining the ends of s, and the number of
+// corners inserted.
+func (b *ObjBuffer) insertSliverLoop(loop []FaceCorner, s sliver) ([]FaceCorner, int) {
+	inserted := 0
+	corners := make([]FaceCorner, 0, len(loop))
+	for j, c := range loop {
+		corners = append(corners, c)
+		next := loop[(j+1)%len(loop)]
+		switch {
+		case c.VertexIndex == s.u.VertexIndex && next.VertexIndex == s.w.VertexIndex:
+			for _, m := range s.middle {
+				corners = append(corners, b.edgeCorner(c, next, m.VertexIndex))
+			}
+		case c.VertexIndex == s.w.VertexIndex && next.VertexIndex == s.u.VertexIndex:
+			for k := len(s.middle) - 1; k >= 0; k-- {
+				corners = append(corners, b.edgeCorner(c, next, s.middle[k].VertexIndex))
+			}
+		default:
+			continue
+		}
+		inserted += len(s.middle)
+	}
+	return corners, inserted
+}
+
+// edgeCorner returns a corner at vertex on the edge from corner a to corner
+// c, with its normal and texture coordinate interpolated along the edge.
+func (b *ObjBuffer) edgeCorner(a, c FaceCorner, vertex int) FaceCorner {
+	pa, pc, p := b.uvPosition(a.VertexIndex), b.uvPosition(c.VertexIndex), b.uvPosition(vertex)
+	t := float32(0)
+	if length := dvec3.Distance(&pa, &pc); length > 0 {
+		t = float32(dvec3.Distance(&pa, &p) / length)
+	}
+	corner := FaceCorner{VertexIndex: vertex, NormalIndex: -1, TexcoordIndex: -1}
+	if a.NormalIndex >= 0 && a.NormalIndex < len(b.VN) && c.NormalIndex >= 0 && c.NormalIndex < len(b.VN) {
+		n := vec3.Interpolate(&b.VN[a.NormalIndex], &b.VN[c.NormalIndex], t)
+		if n.Length() > 0 {
+			n.Normalize()
+		}
+		corner.NormalIndex = len(b.VN)
+		b.VN = append(b.VN, n)
+	}
+	if a.TexcoordIndex >= 0 && a.TexcoordIndex < len(b.VT) && c.TexcoordIndex >= 0 && c.TexcoordIndex < len(b.VT) {
+		ta, tc := b.VT[a.TexcoordIndex], b.VT[c.TexcoordIndex]
+		ta[0] += (tc[0] - ta[0]) * t
+		ta[1] += (tc[1] - ta[1]) * t
+		corner.TexcoordIndex = len(b.VT)
+		b.VT = append(b.VT, ta)
+	}
+	return corner
+}
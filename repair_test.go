@@ -0,0 +1,63 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flywave/go3d/vec2"
+	"github.com/stretchr/testify/assert"
+)
+
+const repairTestObj = "v 0 0 0\nv 2 0 0\nv 1 0 0\nv 1 1 0\n" +
+	"vt 0 0\nvt 1 0\nvt 0.5 1\n" +
+	"g wall\nf 1/1 2/2 4/3\nf 2 1 3\nf 1 1 2 4\nf 4 4 3\n"
+
+func TestObjBuffer_RepairDegenerateFaces_Sliver_InsertsCornerIntoNeighbour(t *testing.T) {
+	// Arrange
+	loader := &ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader(repairTestObj)))
+
+	// Act
+	report := loader.RepairDegenerateFaces()
+
+	// Assert
+	assert.Equal(t, []int{1, 3}, report.CollapsedFaces)
+	assert.Equal(t, 2, report.MergedCorners)
+	assert.Equal(t, 2, report.InsertedCorners)
+	assert.False(t, report.Empty())
+	if assert.Equal(t, 2, len(loader.F)) {
+		assert.Equal(t, []FaceCorner{{0, -1, 0}, {2, -1, 3}, {1, -1, 1}, {3, -1, 2}}, loader.F[0].Corners)
+		assert.Equal(t, vec2.T{0.5, 0}, loader.VT[3])
+		assert.Equal(t, []FaceCorner{{0, -1, -1}, {2, -1, -1}, {1, -1, -1}, {3, -1, -1}}, loader.F[1].Corners)
+	}
+	assert.Equal(t, 2, loader.G[0].FaceCount)
+}
+
+func TestObjBuffer_RepairDegenerateFaces_CleanMesh_ReportsNothing(t *testing.T) {
+	// Arrange
+	loader := &ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n")))
+
+	// Act
+	report := loader.RepairDegenerateFaces()
+
+	// Assert
+	assert.True(t, report.Empty())
+	assert.Equal(t, 1, len(loader.F))
+}
+
+func TestObjBuffer_RepairDegenerateFaces_FaceWithHoles_MergesHoleCorners(t *testing.T) {
+	// Arrange
+	buffer := createSquareWithHole()
+	hole := buffer.F[0].Holes[0]
+	buffer.F[0].Holes[0] = append([]FaceCorner{hole[0]}, hole...)
+	buffer.F[0].AddHole([]FaceCorner{{4, -1, -1}, {4, -1, -1}, {5, -1, -1}})
+
+	// Act
+	report := buffer.RepairDegenerateFaces()
+
+	// Assert
+	assert.Equal(t, 2, report.MergedCorners)
+	assert.Empty(t, report.CollapsedFaces)
+	assert.Equal(t, [][]FaceCorner{hole}, buffer.F[0].Holes)
+}
@@ -0,0 +1,138 @@
+package obj
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+
+	dmat4 "github.com/flywave/go3d/float64/mat4"
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+)
+
+// Scene is a mesh together with the materials of its material library.
+type Scene struct {
+	Buffer    *ObjBuffer
+	Materials map[string]*Material
+	// Nodes places further buffers in the scene. Nodes sharing a buffer
+	// instance it, so repeated objects are stored once.
+	Nodes []Node
+	// Dir is the directory the relative texture paths of the materials are
+	// relative to. ReadScene sets it to the directory of the OBJ file.
+	Dir string
+}
+
+// Node places a buffer in a scene.
+type Node struct {
+	// Name prefixes the names of the groups of the buffer, separated by a
+	// slash, when the instances are flattened.
+	Name   string
+	Buffer *ObjBuffer
+	// Transform maps the positions of the buffer, offset included, to the
+	// coordinates of the scene.
+	Transform dmat4.T
+}
+
+// AddInstance adds a node placing buffer in the scene with transform.
+func (s *Scene) AddInstance(name string, buffer *ObjBuffer, transform dmat4.T) {
+	s.Nodes = append(s.Nodes, Node{Name: name, Buffer: buffer, Transform: transform})
+}
+
+// FlattenInstances returns a single buffer holding the scene buffer
+// followed by a transformed copy of the buffer of every node. Normals are
+// transformed with the inverse transpose of the transform, and the corners
+// of the faces are reversed when the transform mirrors the geometry. The
+// result uses the offset of the scene buffer.
+func (s *Scene) FlattenInstances() *ObjBuffer {
+	var buffers []*ObjBuffer
+	if s.Buffer != nil {
+		buffers = append(buffers, s.Buffer)
+	}
+	for i := range s.Nodes {
+		buffers = append(buffers, s.Nodes[i].instance())
+	}
+	return Merge(buffers...)
+}
+
+// instance returns a copy of the buffer of the node in scene coordinates,
+// sharing the elements the transform does not change.
+func (n *Node) instance() *ObjBuffer {
+	b := n.Buffer
+	instance := &ObjBuffer{MTL: b.MTL, VC: b.VC, VT: b.VT, F: b.F, L: b.L, Attributes: b.Attributes}
+
+	instance.VD = make([]dvec3.T, len(b.V))
+	instance.V = make([]vec3.T, len(b.V))
+	for i := range b.V {
+		p := b.positionD(i)
+		p.Add(&b.Offset)
+		n.Transform.TransformVec3(&p)
+		instance.VD[i] = p
+		instance.V[i] = vec3.T{float32(p[0]), float32(p[1]), float32(p[2])}
+	}
+
+	normalMatrix := n.Transform.Inverted()
+	normalMatrix.Transpose()
+	instance.VN = make([]vec3.T, len(b.VN))
+	for i, vn := range b.VN {
+		d := dvec3.T{float64(vn[0]), float64(vn[1]), float64(vn[2])}
+		normalMatrix.TransformVec3W(&d, 0)
+		if d.Length() > 0 {
+			d.Normalize()
+		}
+		instance.VN[i] = vec3.T{float32(d[0]), float32(d[1]), float32(d[2])}
+	}
+
+	if n.Transform.IsReflective() {
+		instance.F = cloneFaces(b.F)
+		for i := range instance.F {
+			instance.F[i].reverse()
+		}
+	}
+
+	groups := b.G
+	if len(groups) == 0 && len(b.F) > 0 {
+		groups = []Group{{Name: defaultGroupName, FirstFaceIndex: 0, FaceCount: len(b.F)}}
+	}
+	for _, g := range groups {
+		if n.Name != "" {
+			g.Name = n.Name + "/" + g.Name
+		}
+		instance.G = append(instance.G, g)
+	}
+	return instance
+}
+
+// ReadScene reads the OBJ file at path and the material library it
+// references, which is looked up relative to the directory of the file.
+func ReadScene(path string, options ReadOptions) (*Scene, error) {
+	buffer, err := ReadFile(path, options)
+	if err != nil {
+		return nil, err
+	}
+	scene := &Scene{Buffer: buffer, Materials: map[string]*Material{}, Dir: filepath.Dir(path)}
+	if buffer.MTL != "" {
+		mtlPath := buffer.MTL
+		if !filepath.IsAbs(mtlPath) {
+			mtlPath = filepath.Join(filepath.Dir(path), mtlPath)
+		}
+		if scene.Materials, err = ReadMaterials(mtlPath); err != nil {
+			return nil, err
+		}
+	}
+	return scene, nil
+}
+
+// Hash returns a hex encoded SHA-256 digest of the content of the buffer,
+// of the nodes and of the materials, suitable as a cache key.
+func (s *Scene) Hash() string {
+	h := sha256.New()
+	if s.Buffer != nil {
+		h.Write([]byte(s.Buffer.Hash()))
+	}
+	for _, n := range s.Nodes {
+		fmt.Fprintf(h, "node %q %s %v\n", n.Name, n.Buffer.Hash(), n.Transform.Slice())
+	}
+	h.Write([]byte(HashMaterials(s.Materials)))
+	return hex.EncodeToString(h.Sum(nil))
+}
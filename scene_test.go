@@ -0,0 +1,126 @@
+package obj
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	dmat4 "github.com/flywave/go3d/float64/mat4"
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadScene_ReferencedLibrary_ReadsMaterials(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	objData := "mtllib scene.mtl\nv 0 0 0\nv 1 0 0\nv 0 1 0\nusemtl red\nf 1 2 3\n"
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "scene.obj"), []byte(objData), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "scene.mtl"), []byte("newmtl red\nKd 1 0 0\n"), 0644))
+
+	// Act
+	scene, err := ReadScene(filepath.Join(dir, "scene.obj"), ReadOptions{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(scene.Buffer.F))
+	if assert.Contains(t, scene.Materials, "red") {
+		assert.Equal(t, float32(1), scene.Materials["red"].Diffuse[0])
+	}
+}
+
+func TestReadScene_MissingLibrary_ReturnsError(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "scene.obj"), []byte("mtllib missing.mtl\n"), 0644))
+
+	// Act
+	_, err := ReadScene(filepath.Join(dir, "scene.obj"), ReadOptions{})
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestScene_Hash_MaterialChange_ChangesHash(t *testing.T) {
+	// Arrange
+	scene := &Scene{
+		Buffer:    createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "red"),
+		Materials: map[string]*Material{"red": {Name: "red", Diffuse: []float32{1, 0, 0}}},
+	}
+	before := scene.Hash()
+
+	// Act
+	scene.Materials["red"].Diffuse[1] = 0.5
+
+	// Assert
+	assert.Equal(t, 64, len(before))
+	assert.NotEqual(t, before, scene.Hash())
+}
+
+func createTriangle() *ObjBuffer {
+	b := &ObjBuffer{
+		V:  []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+		VN: []vec3.T{{0, 0, 1}},
+		F: []Face{{Corners: []FaceCorner{
+			{VertexIndex: 0, NormalIndex: 0, TexcoordIndex: -1},
+			{VertexIndex: 1, NormalIndex: 0, TexcoordIndex: -1},
+			{VertexIndex: 2, NormalIndex: 0, TexcoordIndex: -1},
+		}}},
+		G: []Group{{Name: "bench", FirstFaceIndex: 0, FaceCount: 1}},
+	}
+	b.FaceGroup = faceGroupsOf(b.F)
+	return b
+}
+
+func TestScene_FlattenInstances_Translated_CopiesBuffer(t *testing.T) {
+	// Arrange
+	bench := createTriangle()
+	scene := &Scene{}
+	for i, x := range []float64{10, 20} {
+		transform := dmat4.Ident
+		transform.SetTranslation(&dvec3.T{x, 0, 0})
+		scene.AddInstance([]string{"a", "b"}[i], bench, transform)
+	}
+
+	// Act
+	flat := scene.FlattenInstances()
+
+	// Assert
+	assert.Equal(t, 6, len(flat.V))
+	assert.Equal(t, vec3.T{11, 0, 0}, flat.V[1])
+	assert.Equal(t, vec3.T{20, 1, 0}, flat.V[5])
+	assert.Equal(t, []string{"a/bench", "b/bench"}, flat.GroupNames())
+	assert.Equal(t, 3, flat.F[1].Corners[0].VertexIndex)
+	assert.Equal(t, vec3.T{0, 0, 1}, flat.VN[1])
+}
+
+func TestScene_FlattenInstances_Mirrored_ReversesFaces(t *testing.T) {
+	// Arrange
+	bench := createTriangle()
+	scene := &Scene{}
+	transform := dmat4.Ident
+	transform.ScaleVec3(&dvec3.T{1, 1, -1})
+	scene.AddInstance("", bench, transform)
+
+	// Act
+	flat := scene.FlattenInstances()
+
+	// Assert
+	corners := flat.F[0].Corners
+	assert.Equal(t, []int{2, 1, 0}, []int{corners[0].VertexIndex, corners[1].VertexIndex, corners[2].VertexIndex})
+	assert.Equal(t, vec3.T{0, 0, -1}, flat.VN[0])
+	assert.Equal(t, 0, bench.F[0].Corners[0].VertexIndex)
+}
+
+func TestScene_Hash_NodeTransformChange_ChangesHash(t *testing.T) {
+	// Arrange
+	scene := &Scene{}
+	scene.AddInstance("bench", createTriangle(), dmat4.Ident)
+	before := scene.Hash()
+
+	// Act
+	scene.Nodes[0].Transform[3][0] = 5
+
+	// Assert
+	assert.NotEqual(t, before, scene.Hash())
+}
@@ -0,0 +1,138 @@
+package obj
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+)
+
+// LoadSequence reads the numbered OBJ files matching the glob pattern, such
+// as "frames/walk_*.obj", as the frames of a vertex animation. The frames
+// are ordered by the last number in their file name, so that frame 10
+// follows frame 9, and must share the topology of the first one: the same
+// number of vertices, normals and texture coordinates, and the same faces.
+// Only the positions and normals may change from frame to frame.
+func LoadSequence(pattern string) ([]*ObjBuffer, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("No files match '%s'", pattern)
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		ni, nj := frameNumber(paths[i]), frameNumber(paths[j])
+		if ni != nj {
+			return ni < nj
+		}
+		return paths[i] < paths[j]
+	})
+
+	frames := make([]*ObjBuffer, len(paths))
+	for i, path := range paths {
+		if frames[i], err = ReadFile(path, ReadOptions{}); err != nil {
+			return nil, fmt.Errorf("Frame %s: %v", path, err)
+		}
+		if i > 0 {
+			if err := frames[0].sameTopology(frames[i]); err != nil {
+				return nil, fmt.Errorf("Frame %s: %v", path, err)
+			}
+		}
+	}
+	return frames, nil
+}
+
+// frameNumber returns the last number in the file name of path, or -1 if
+// it has none.
+func frameNumber(path string) int {
+	name := filepath.Base(path)
+	end := len(name)
+	for end > 0 && (name[end-1] < '0' || name[end-1] > '9') {
+		end--
+	}
+	start := end
+	for start > 0 && name[start-1] >= '0' && name[start-1] <= '9' {
+		start--
+	}
+	n, err := strconv.Atoi(name[start:end])
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// sameTopology checks that frame has the elements and faces of b.
+func (b *ObjBuffer) sameTopology(frame *ObjBuffer) error {
+	if len(frame.V) != len(b.V) {
+		return fmt.Errorf("%d vertices, expected %d", len(frame.V), len(b.V))
+	}
+	if len(frame.VN) != len(b.VN) {
+		return fmt.Errorf("%d normals, expected %d", len(frame.VN), len(b.VN))
+	}
+	if len(frame.VT) != len(b.VT) {
+		return fmt.Errorf("%d texture coordinates, expected %d", len(frame.VT), len(b.VT))
+	}
+	if len(frame.F) != len(b.F) {
+		return fmt.Errorf("%d faces, expected %d", len(frame.F), len(b.F))
+	}
+	for i := range b.F {
+		a, c := b.F[i].loops(), frame.F[i].loops()
+		same := len(a) == len(c)
+		for k := 0; same && k < len(a); k++ {
+			same = len(a[k]) == len(c[k])
+			for j := 0; same && j < len(a[k]); j++ {
+				same = a[k][j] == c[k][j]
+			}
+		}
+		if !same {
+			return fmt.Errorf("face %d differs", i+1)
+		}
+	}
+	return nil
+}
+
+// InterpolateFrames returns the frame at time t of a sequence, in frames:
+// 1.5 is halfway between the second and third frames. Positions are
+// interpolated linearly, offset included, and normals linearly then
+// normalized. The frame is a copy of the frame before t, with its offset;
+// t is clamped to the sequence. The frames must share their topology, as
+// LoadSequence checks.
+func InterpolateFrames(frames []*ObjBuffer, t float64) (*ObjBuffer, error) {
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("No frames to interpolate")
+	}
+	t = math.Max(0, math.Min(t, float64(len(frames)-1)))
+	i := int(t)
+	if i == len(frames)-1 {
+		return frames[i].Clone(), nil
+	}
+	a, b := frames[i], frames[i+1]
+	if err := a.sameTopology(b); err != nil {
+		return nil, fmt.Errorf("Frame %d: %v", i+2, err)
+	}
+	s := t - float64(i)
+
+	frame := a.Clone()
+	delta := b.Offset
+	delta.Sub(&a.Offset)
+	positions := make([]dvec3.T, len(a.V))
+	for j := range positions {
+		p, q := a.positionD(j), b.positionD(j)
+		q.Add(&delta)
+		positions[j] = dvec3.Interpolate(&p, &q, s)
+	}
+	frame.setPositions(positions, func(*dvec3.T) {})
+	for j := range frame.VN {
+		n := vec3.Interpolate(&a.VN[j], &b.VN[j], float32(s))
+		if n.Length() > 0 {
+			n.Normalize()
+		}
+		frame.VN[j] = n
+	}
+	return frame, nil
+}
@@ -0,0 +1,76 @@
+package obj
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeSequenceFrame writes a triangle frame whose first vertex is at x.
+func writeSequenceFrame(t *testing.T, dir, name string, x float64, faces string) {
+	data := fmt.Sprintf("v %g 0 0\nv 1 0 0\nv 0 1 0\nvn 0 0 1\n%s", x, faces)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644))
+}
+
+func TestLoadSequence_NumberedFrames_OrdersNumerically(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeSequenceFrame(t, dir, "walk_10.obj", 10, "f 1//1 2//1 3//1\n")
+	writeSequenceFrame(t, dir, "walk_2.obj", 2, "f 1//1 2//1 3//1\n")
+	writeSequenceFrame(t, dir, "walk_1.obj", 1, "f 1//1 2//1 3//1\n")
+
+	// Act
+	frames, err := LoadSequence(filepath.Join(dir, "walk_*.obj"))
+
+	// Assert
+	assert.NoError(t, err)
+	var xs []float32
+	for _, f := range frames {
+		xs = append(xs, f.V[0][0])
+	}
+	assert.Equal(t, []float32{1, 2, 10}, xs)
+}
+
+func TestLoadSequence_DifferentFaces_ReturnsError(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeSequenceFrame(t, dir, "walk_1.obj", 1, "f 1//1 2//1 3//1\n")
+	writeSequenceFrame(t, dir, "walk_2.obj", 2, "f 3//1 2//1 1//1\n")
+
+	// Act
+	_, err := LoadSequence(filepath.Join(dir, "walk_*.obj"))
+
+	// Assert
+	assert.EqualError(t, err, fmt.Sprintf("Frame %s: face 1 differs", filepath.Join(dir, "walk_2.obj")))
+}
+
+func TestLoadSequence_NoMatch_ReturnsError(t *testing.T) {
+	// Act
+	_, err := LoadSequence(filepath.Join(t.TempDir(), "*.obj"))
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestInterpolateFrames_BetweenFrames_InterpolatesPositionsAndNormals(t *testing.T) {
+	// Arrange
+	a := &ObjBuffer{V: []vec3.T{{0, 0, 0}}, VN: []vec3.T{{1, 0, 0}}}
+	b := &ObjBuffer{V: []vec3.T{{4, 2, 0}}, VN: []vec3.T{{0, 1, 0}}}
+	c := &ObjBuffer{V: []vec3.T{{8, 8, 8}}, VN: []vec3.T{{0, 1, 0}}}
+
+	// Act
+	half, err := InterpolateFrames([]*ObjBuffer{a, b, c}, 0.5)
+	last, lastErr := InterpolateFrames([]*ObjBuffer{a, b, c}, 7)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []vec3.T{{2, 1, 0}}, half.V)
+	assert.InDeltaSlice(t, []float32{0.70710677, 0.70710677, 0}, half.VN[0][:], 1e-6)
+	assert.Equal(t, []vec3.T{{0, 0, 0}}, a.V)
+	assert.NoError(t, lastErr)
+	assert.Equal(t, c.V, last.V)
+}
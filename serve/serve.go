@@ -0,0 +1,187 @@
+// Package serve serves scenes over HTTP in the format a client asks for,
+// negotiated by file extension or Accept header, with gzip compression and
+// ETags derived from the content.
+package serve
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	obj "github.com/flywave/go-obj"
+)
+
+// Format is an output format ServeMesh can produce.
+type Format struct {
+	// Extension is the file extension selecting the format, without dot.
+	Extension string
+	// ContentType is the media type the format is served with.
+	ContentType string
+	// Aliases lists other media types accepted for the format.
+	Aliases []string
+	write   func(w io.Writer, scene *obj.Scene) error
+}
+
+// Formats lists the supported formats. The first is served to clients that
+// accept any format. The formats without instancing, OBJ and STL, write the
+// scene with its instances flattened.
+var Formats = []*Format{
+	{Extension: "obj", ContentType: "model/obj", Aliases: []string{"text/plain"}, write: func(w io.Writer, scene *obj.Scene) error {
+		return scene.FlattenInstances().Write(w)
+	}},
+	{Extension: "mtl", ContentType: "model/mtl", write: func(w io.Writer, scene *obj.Scene) error {
+		return obj.WriteMaterialsTo(w, scene.Materials)
+	}},
+	{Extension: "gltf", ContentType: "model/gltf+json", write: func(w io.Writer, scene *obj.Scene) error {
+		return scene.WriteGLTF(w)
+	}},
+	{Extension: "glb", ContentType: "model/gltf-binary", write: func(w io.Writer, scene *obj.Scene) error {
+		return scene.WriteGLB(w)
+	}},
+	{Extension: "stl", ContentType: "model/stl", Aliases: []string{"application/sla", "model/x.stl-binary"}, write: func(w io.Writer, scene *obj.Scene) error {
+		return scene.FlattenInstances().WriteSTL(w)
+	}},
+}
+
+// ServeMesh writes scene in the format selected by the extension of the
+// request path or, without a known extension, by its Accept header. The
+// response is gzip-compressed when the client accepts it, and carries an
+// ETag derived from the content so that conditional requests are answered
+// with 304 Not Modified. Clients accepting none of the Formats get 406 Not
+// Acceptable.
+func ServeMesh(w http.ResponseWriter, r *http.Request, scene *obj.Scene) {
+	w.Header().Add("Vary", "Accept")
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	format := Negotiate(r)
+	if format == nil {
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		return
+	}
+	compress := acceptsGzip(r.Header.Get("Accept-Encoding"))
+
+	etag := `"` + scene.Hash()[:32] + "-" + format.Extension
+	if compress {
+		etag += "-gz"
+	}
+	etag += `"`
+	w.Header().Set("ETag", etag)
+	if matchesETag(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	var body bytes.Buffer
+	var err error
+	if compress {
+		zw := gzip.NewWriter(&body)
+		if err = format.write(zw, scene); err == nil {
+			err = zw.Close()
+		}
+	} else {
+		err = format.write(&body, scene)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType)
+	if compress {
+		w.Header().Set("Content-Encoding", "gzip")
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(body.Len()))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(body.Bytes())
+	}
+}
+
+// Negotiate returns the format requested by r, or nil if the client accepts
+// none of the Formats.
+func Negotiate(r *http.Request) *Format {
+	if ext := strings.ToLower(strings.TrimPrefix(path.Ext(r.URL.Path), ".")); ext != "" {
+		for _, f := range Formats {
+			if f.Extension == ext {
+				return f
+			}
+		}
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return Formats[0]
+	}
+	var best *Format
+	bestQ := 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, q := parseQuality(part)
+		if q <= bestQ {
+			continue
+		}
+		for _, f := range Formats {
+			if f.matches(mediaType) {
+				best, bestQ = f, q
+				break
+			}
+		}
+	}
+	return best
+}
+
+// matches reports whether the format is acceptable for mediaType, which may
+// be a wildcard.
+func (f *Format) matches(mediaType string) bool {
+	if mediaType == "*/*" {
+		return true
+	}
+	for _, t := range append([]string{f.ContentType}, f.Aliases...) {
+		if t == mediaType || (strings.HasSuffix(mediaType, "/*") &&
+			strings.HasPrefix(t, strings.TrimSuffix(mediaType, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseQuality splits an element of an Accept header into its lower-cased
+// value and its quality, which defaults to 1.
+func parseQuality(part string) (string, float64) {
+	params := strings.Split(part, ";")
+	value := strings.ToLower(strings.TrimSpace(params[0]))
+	q := 1.0
+	for _, p := range params[1:] {
+		p = strings.TrimSpace(p)
+		if strings.HasPrefix(p, "q=") {
+			if f, err := strconv.ParseFloat(p[2:], 64); err == nil {
+				q = f
+			}
+		}
+	}
+	return value, q
+}
+
+// acceptsGzip reports whether an Accept-Encoding header accepts gzip.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		if coding, q := parseQuality(part); (coding == "gzip" || coding == "*") && q > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesETag reports whether an If-None-Match header matches etag.
+func matchesETag(header, etag string) bool {
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimPrefix(strings.TrimSpace(part), "W/")
+		if part == "*" || part == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package serve
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	obj "github.com/flywave/go-obj"
+	dmat4 "github.com/flywave/go3d/float64/mat4"
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func createScene(t *testing.T) *obj.Scene {
+	loader := obj.ObjReader{}
+	input := "mtllib scene.mtl\nv 0 0 0\nv 1 0 0\nv 0 1 0\ng tri\nusemtl red\nf 1 2 3\n"
+	if err := loader.Read(strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	return &obj.Scene{
+		Buffer:    &loader.ObjBuffer,
+		Materials: map[string]*obj.Material{"red": {Name: "red", Diffuse: []float32{1, 0, 0}, Opacity: 1}},
+	}
+}
+
+func serve(t *testing.T, scene *obj.Scene, target string, header map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	ServeMesh(w, r, scene)
+	return w
+}
+
+func TestNegotiate_ExtensionAndAccept_SelectsFormat(t *testing.T) {
+	cases := []struct {
+		target, accept, want string
+	}{
+		{"/tiles/1.glb", "model/obj", "glb"},
+		{"/tiles/1.STL", "", "stl"},
+		{"/tiles/1", "", "obj"},
+		{"/tiles/1", "*/*", "obj"},
+		{"/tiles/1", "model/obj;q=0.5, model/gltf-binary", "glb"},
+		{"/tiles/1", "text/html, model/gltf+json;q=0.8, */*;q=0.1", "gltf"},
+		{"/tiles/1", "application/sla", "stl"},
+		{"/tiles/1.json", "model/*", "obj"},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodGet, c.target, nil)
+		r.Header.Set("Accept", c.accept)
+
+		format := Negotiate(r)
+
+		if assert.NotNil(t, format, c.target+" "+c.accept) {
+			assert.Equal(t, c.want, format.Extension, c.target+" "+c.accept)
+		}
+	}
+}
+
+func TestServeMesh_Unacceptable_Returns406(t *testing.T) {
+	// Act
+	w := serve(t, createScene(t), "/tiles/1", map[string]string{"Accept": "text/html, model/obj;q=0"})
+
+	// Assert
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+}
+
+func TestServeMesh_Obj_WritesBufferWithETag(t *testing.T) {
+	// Arrange
+	scene := createScene(t)
+
+	// Act
+	w := serve(t, scene, "/tiles/1.obj", nil)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "model/obj", w.Header().Get("Content-Type"))
+	assert.Equal(t, `"`+scene.Hash()[:32]+`-obj"`, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), "usemtl red\nf 1 2 3\n")
+	assert.Equal(t, []string{"Accept", "Accept-Encoding"}, w.Header()["Vary"])
+}
+
+func TestServeMesh_Gzip_CompressesBody(t *testing.T) {
+	// Act
+	w := serve(t, createScene(t), "/tiles/1.mtl", map[string]string{"Accept-Encoding": "br, gzip"})
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.True(t, strings.HasSuffix(w.Header().Get("ETag"), `-mtl-gz"`))
+	zr, err := gzip.NewReader(w.Body)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(zr)
+		assert.Contains(t, string(body), "newmtl red\n")
+	}
+}
+
+func TestServeMesh_IfNoneMatch_Returns304(t *testing.T) {
+	// Arrange
+	scene := createScene(t)
+	etag := serve(t, scene, "/tiles/1.glb", nil).Header().Get("ETag")
+
+	// Act
+	unchanged := serve(t, scene, "/tiles/1.glb", map[string]string{"If-None-Match": etag})
+	scene.Buffer.V[0][0] = 0.5
+	changed := serve(t, scene, "/tiles/1.glb", map[string]string{"If-None-Match": etag})
+
+	// Assert
+	assert.Equal(t, http.StatusNotModified, unchanged.Code)
+	assert.Equal(t, 0, unchanged.Body.Len())
+	assert.Equal(t, http.StatusOK, changed.Code)
+	assert.Equal(t, "model/gltf-binary", changed.Header().Get("Content-Type"))
+}
+
+func TestServeMesh_NodeOnlyScene_WritesFlattenedInstances(t *testing.T) {
+	// Arrange
+	tri := createScene(t).Buffer
+	scene := &obj.Scene{}
+	scene.AddInstance("a", tri, dmat4.Ident)
+	translation := dmat4.Ident
+	translation.SetTranslation(&dvec3.T{10, 0, 0})
+	scene.AddInstance("b", tri, translation)
+
+	// Act
+	objResponse := serve(t, scene, "/tiles/1.obj", nil)
+	stlResponse := serve(t, scene, "/tiles/1.stl", nil)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, objResponse.Code)
+	assert.Contains(t, objResponse.Body.String(), "v 11 0 0\n")
+	assert.Contains(t, objResponse.Body.String(), "f 4 5 6\n")
+	assert.Equal(t, http.StatusOK, stlResponse.Code)
+	assert.Equal(t, 84+2*50, stlResponse.Body.Len())
+}
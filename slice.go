@@ -0,0 +1,132 @@
+package obj
+
+import (
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+)
+
+// Contour is a polyline where a mesh crosses a slicing plane. The points are
+// in the frame of the buffer. Closed contours do not repeat their first
+// point; for closed meshes with outward facing triangles, outer contours run
+// counterclockwise when seen from above and holes run clockwise.
+type Contour struct {
+	Points []dvec3.T
+	Closed bool
+}
+
+// Slice intersects the buffer with the horizontal planes z = levels[i] and
+// returns the contours found at each level, in the order of levels. Open
+// contours are only returned where the mesh has boundary or non-manifold
+// edges.
+func (b *ObjBuffer) Slice(levels []float64) [][]Contour {
+	contours := make([][]Contour, len(levels))
+	for i, z := range levels {
+		contours[i] = b.sliceAt(z)
+	}
+	return contours
+}
+
+// sliceSegment runs between the crossing points of two edges of a triangle.
+type sliceSegment struct {
+	from, to Edge
+	used     bool
+}
+
+func (b *ObjBuffer) sliceAt(z float64) []Contour {
+	points := make(map[Edge]dvec3.T)
+	var segments []sliceSegment
+	b.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		var p [3]dvec3.T
+		var above [3]bool
+		for k, c := range corners {
+			p[k] = b.positionD(c.VertexIndex)
+			// Vertices on the plane count as above it, so that every
+			// crossing lies on exactly two edges of a triangle.
+			above[k] = p[k][2] >= z
+		}
+		var up, down Edge
+		crossings := 0
+		for k := 0; k < 3; k++ {
+			j := (k + 1) % 3
+			if above[k] == above[j] {
+				continue
+			}
+			e := NewEdge(corners[k].VertexIndex, corners[j].VertexIndex)
+			if _, ok := points[e]; !ok {
+				t := (z - p[k][2]) / (p[j][2] - p[k][2])
+				points[e] = dvec3.Interpolate(&p[k], &p[j], t)
+			}
+			if above[j] {
+				up = e
+			} else {
+				down = e
+			}
+			crossings++
+		}
+		if crossings == 2 {
+			segments = append(segments, sliceSegment{from: down, to: up})
+		}
+		return true
+	})
+
+	startsAt := make(map[Edge][]int, len(segments))
+	endsAt := make(map[Edge]int, len(segments))
+	for i, s := range segments {
+		startsAt[s.from] = append(startsAt[s.from], i)
+		endsAt[s.to]++
+	}
+
+	var contours []Contour
+	follow := func(first int) {
+		c := Contour{}
+		start := segments[first].from
+		appendPoint := func(e Edge) {
+			p := points[e]
+			if n := len(c.Points); n > 0 && c.Points[n-1] == p {
+				return
+			}
+			c.Points = append(c.Points, p)
+		}
+		appendPoint(start)
+		i := first
+		for {
+			segments[i].used = true
+			end := segments[i].to
+			if end == start {
+				c.Closed = true
+				break
+			}
+			appendPoint(end)
+			next := -1
+			for _, j := range startsAt[end] {
+				if !segments[j].used {
+					next = j
+					break
+				}
+			}
+			if next < 0 {
+				break
+			}
+			i = next
+		}
+		if c.Closed && len(c.Points) > 1 && c.Points[0] == c.Points[len(c.Points)-1] {
+			c.Points = c.Points[:len(c.Points)-1]
+		}
+		if len(c.Points) > 1 {
+			contours = append(contours, c)
+		}
+	}
+	// Open contours start at crossings no segment leads to; what is left
+	// afterwards forms closed loops.
+	for i := range segments {
+		if !segments[i].used && endsAt[segments[i].from] == 0 {
+			follow(i)
+		}
+	}
+	for i := range segments {
+		if !segments[i].used {
+			follow(i)
+		}
+	}
+	return contours
+}
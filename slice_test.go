@@ -0,0 +1,84 @@
+package obj
+
+import (
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+// contourArea returns the signed area of the contour projected onto the XY
+// plane, positive for counterclockwise contours.
+func contourArea(c Contour) float64 {
+	area := 0.0
+	for i := range c.Points {
+		p, q := c.Points[i], c.Points[(i+1)%len(c.Points)]
+		area += p[0]*q[1] - q[0]*p[1]
+	}
+	return area / 2
+}
+
+func TestObjBuffer_Slice_Cube_ReturnsCounterclockwiseSquare(t *testing.T) {
+	// Arrange
+	cube := createCube(vec3.T{0, 0, 0}, vec3.T{2, 3, 4}, "")
+
+	// Act
+	contours := cube.Slice([]float64{1, 5})
+
+	// Assert
+	assert.Equal(t, 2, len(contours))
+	assert.Equal(t, 1, len(contours[0]))
+	assert.Equal(t, 0, len(contours[1]))
+	c := contours[0][0]
+	assert.True(t, c.Closed)
+	assert.InDelta(t, 6, contourArea(c), 1e-9)
+	for _, p := range c.Points {
+		assert.Equal(t, 1.0, p[2])
+	}
+}
+
+func TestObjBuffer_Slice_Hollow_ReturnsOuterAndHole(t *testing.T) {
+	// Arrange: an outer cube with an inverted inner cube forming a cavity.
+	outer := createCube(vec3.T{0, 0, 0}, vec3.T{4, 4, 4}, "")
+	inner := createCube(vec3.T{1, 1, 1}, vec3.T{3, 3, 3}, "")
+	for i := range inner.F {
+		corners := inner.F[i].Corners
+		for j, k := 0, len(corners)-1; j < k; j, k = j+1, k-1 {
+			corners[j], corners[k] = corners[k], corners[j]
+		}
+		for j := range corners {
+			corners[j].VertexIndex += len(outer.V)
+		}
+	}
+	outer.V = append(outer.V, inner.V...)
+	outer.F = append(outer.F, inner.F...)
+
+	// Act
+	contours := outer.Slice([]float64{2})
+
+	// Assert
+	assert.Equal(t, 2, len(contours[0]))
+	assert.InDelta(t, 16, contourArea(contours[0][0]), 1e-9)
+	assert.InDelta(t, -4, contourArea(contours[0][1]), 1e-9)
+}
+
+func TestObjBuffer_Slice_OpenSurface_ReturnsOpenPolyline(t *testing.T) {
+	// Arrange: a vertical strip of two quads.
+	buffer := &ObjBuffer{
+		V: []vec3.T{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {0, 0, 1}, {1, 0, 1}, {2, 0, 1}},
+		F: []Face{
+			{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {4, -1, -1}, {3, -1, -1}}},
+			{Corners: []FaceCorner{{1, -1, -1}, {2, -1, -1}, {5, -1, -1}, {4, -1, -1}}},
+		},
+	}
+
+	// Act
+	contours := buffer.Slice([]float64{0.5})
+
+	// Assert: the diagonals of the triangulated quads add crossings.
+	assert.Equal(t, []Contour{{
+		Points: []dvec3.T{{0, 0, 0.5}, {0.5, 0, 0.5}, {1, 0, 0.5}, {1.5, 0, 0.5}, {2, 0, 0.5}},
+		Closed: false,
+	}}, contours[0])
+}
@@ -0,0 +1,92 @@
+package obj
+
+import (
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+)
+
+// Smooth removes noise from the surface by moving every vertex towards the
+// center of the vertices sharing an edge of a face with it. Each iteration
+// moves the vertices by lambda times the distance to that center, then,
+// unless mu is 0, by mu times the new distance. With a negative mu slightly
+// larger in magnitude than lambda, such as lambda 0.5 and mu -0.53, this is
+// Taubin smoothing, which keeps the volume; with mu 0 it is plain Laplacian
+// smoothing, which shrinks the surface.
+//
+// With preserveBoundary, the vertices on an edge used by a single face do
+// not move, so that open surfaces keep their outline. Vertices used by
+// lines never move. The normals are left unchanged; use ComputeNormals to
+// update them.
+func (b *ObjBuffer) Smooth(iterations int, lambda, mu float64, preserveBoundary bool) {
+	neighbors := make([][]int, len(b.V))
+	fixed := make([]bool, len(b.V))
+	edges := make(map[Edge]int)
+	for i := range b.F {
+		for _, loop := range b.F[i].loops() {
+			for k := range loop {
+				a, c := loop[k].VertexIndex, loop[(k+1)%len(loop)].VertexIndex
+				if a == c || a < 0 || c < 0 || a >= len(b.V) || c >= len(b.V) {
+					continue
+				}
+				e := NewEdge(a, c)
+				if edges[e] == 0 {
+					neighbors[a] = append(neighbors[a], c)
+					neighbors[c] = append(neighbors[c], a)
+				}
+				edges[e]++
+			}
+		}
+	}
+	if preserveBoundary {
+		for e, n := range edges {
+			if n == 1 {
+				fixed[e[0]] = true
+				fixed[e[1]] = true
+			}
+		}
+	}
+	for _, l := range b.L {
+		for _, v := range l.Corners {
+			if v >= 0 && v < len(b.V) {
+				fixed[v] = true
+			}
+		}
+	}
+
+	positions := make([]dvec3.T, len(b.V))
+	for i := range b.V {
+		positions[i] = b.positionD(i)
+	}
+	moved := make([]dvec3.T, len(b.V))
+	step := func(factor float64) {
+		for i, p := range positions {
+			moved[i] = p
+			if fixed[i] || len(neighbors[i]) == 0 {
+				continue
+			}
+			var center dvec3.T
+			for _, j := range neighbors[i] {
+				center.Add(&positions[j])
+			}
+			center.Scale(1 / float64(len(neighbors[i])))
+			d := dvec3.Sub(&center, &p)
+			d.Scale(factor)
+			moved[i].Add(&d)
+		}
+		positions, moved = moved, positions
+	}
+	for i := 0; i < iterations; i++ {
+		step(lambda)
+		if mu != 0 {
+			step(mu)
+		}
+	}
+
+	double := b.hasDoublePrecision()
+	for i, p := range positions {
+		b.V[i] = vec3.T{float32(p[0]), float32(p[1]), float32(p[2])}
+		if double {
+			b.VD[i] = p
+		}
+	}
+}
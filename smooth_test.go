@@ -0,0 +1,77 @@
+package obj
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+// meanRadius returns the mean distance of the vertices to the origin.
+func meanRadius(V []vec3.T) float64 {
+	sum := 0.0
+	for _, v := range V {
+		sum += float64(v.Length())
+	}
+	return sum / float64(len(V))
+}
+
+func TestObjBuffer_Smooth_NoisyPlane_FlattensInterior(t *testing.T) {
+	// Arrange
+	buffer := createGrid(4, 4)
+	for i := range buffer.V {
+		if i%2 == 0 {
+			buffer.V[i][2] = 0.1
+		} else {
+			buffer.V[i][2] = -0.1
+		}
+	}
+	before := append([]vec3.T(nil), buffer.V...)
+
+	// Act
+	buffer.Smooth(10, 0.5, -0.53, true)
+
+	// Assert
+	for i, v := range buffer.V {
+		x, y := i%5, i/5
+		if x == 0 || y == 0 || x == 4 || y == 4 {
+			assert.Equal(t, before[i], v)
+		} else {
+			assert.True(t, math.Abs(float64(v[2])) < 0.05, "vertex %d is still noisy: %v", i, v)
+		}
+	}
+}
+
+func TestObjBuffer_Smooth_Taubin_ShrinksLessThanLaplacian(t *testing.T) {
+	// Arrange
+	laplacian := createSphere(2, 24, 12)
+	taubin := createSphere(2, 24, 12)
+
+	// Act
+	laplacian.Smooth(10, 0.5, 0, false)
+	taubin.Smooth(10, 0.5, -0.53, false)
+
+	// Assert
+	assert.True(t, meanRadius(laplacian.V) < 1.9)
+	assert.InDelta(t, 2, meanRadius(taubin.V), 0.05)
+}
+
+func TestObjBuffer_Smooth_DoublePrecision_UpdatesBoth(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{
+		V: []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+		F: []Face{{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {2, -1, -1}}}},
+	}
+	for _, v := range buffer.V {
+		buffer.VD = append(buffer.VD, [3]float64{float64(v[0]) + 1e6, float64(v[1]), float64(v[2])})
+	}
+
+	// Act
+	buffer.Smooth(1, 1, 0, false)
+
+	// Assert
+	assert.InDelta(t, 1e6+0.5, buffer.VD[0][0], 1e-9)
+	assert.InDelta(t, 0.5, buffer.VD[0][1], 1e-9)
+	assert.Equal(t, float32(1e6+0.5), buffer.V[0][0])
+}
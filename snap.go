@@ -0,0 +1,32 @@
+package obj
+
+import "math"
+
+// SnapVertices moves every vertex to the closest point of a grid with cells
+// of gridSize, aligned on the world origin, offset included. Faces left
+// without area, because corners collapsed or became collinear, are removed.
+// It returns the number of faces removed.
+func (b *ObjBuffer) SnapVertices(gridSize float64) int {
+	if gridSize <= 0 {
+		return 0
+	}
+	double := b.hasDoublePrecision()
+	for i := range b.V {
+		p := b.positionD(i)
+		for j := range p {
+			p[j] = math.Round((p[j]+b.Offset[j])/gridSize)*gridSize - b.Offset[j]
+		}
+		if double {
+			b.VD[i] = p
+		}
+		b.V[i] = [3]float32{float32(p[0]), float32(p[1]), float32(p[2])}
+	}
+
+	// Twice the area of a face of a single cell, scaled down to absorb
+	// rounding errors of collinear corners.
+	minArea := gridSize * gridSize * 1e-6
+	return b.RemoveFaces(func(i int, f *Face) bool {
+		n := b.newellNormal(i)
+		return n.Length() < minArea
+	})
+}
@@ -0,0 +1,44 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_SnapVertices_RemovesCollapsedFaces(t *testing.T) {
+	// Arrange
+	loader := &ObjReader{}
+	err := loader.Read(strings.NewReader("v 0.02 0 0\nv 1.01 0 0\nv 0 0.98 0\nv 0 0.04 0\nv 2 0.01 0\n" +
+		"g noisy\nf 1 2 3\nf 1 4 3\nf 1 2 5\n"))
+	assert.NoError(t, err)
+
+	// Act
+	removed := loader.SnapVertices(0.1)
+
+	// Assert
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, vec3.T{1, 0, 0}, loader.V[1])
+	assert.Equal(t, vec3.T{0, 0, 0}, loader.V[3])
+	assert.Equal(t, 1, len(loader.F))
+	assert.Equal(t, 1, loader.G[0].FaceCount)
+}
+
+func TestObjBuffer_SnapVertices_Offset_SnapsWorldPositions(t *testing.T) {
+	// Arrange
+	loader := &ObjReader{}
+	loader.SetOptions(ReadOptions{DoublePrecision: true})
+	err := loader.Read(strings.NewReader("v 0.3 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n"))
+	assert.NoError(t, err)
+	loader.Offset = dvec3.T{0.4, 0, 0}
+
+	// Act
+	loader.SnapVertices(1)
+
+	// Assert
+	assert.InDelta(t, 0.6, loader.VD[0][0], 1e-12)
+	assert.InDelta(t, 0.6, loader.VD[1][0], 1e-12)
+}
@@ -0,0 +1,55 @@
+package obj
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+
+	"github.com/flywave/go3d/vec3"
+)
+
+// WriteSTL writes the triangulated faces of the buffer as binary STL, with
+// the facet normals computed from the positions. STL has neither materials
+// nor texture coordinates, and the buffer offset is not applied.
+func (b *ObjBuffer) WriteSTL(w io.Writer) error {
+	var count uint32
+	b.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		count++
+		return true
+	})
+
+	bw := bufio.NewWriter(w)
+	var header [80]byte
+	copy(header[:], "Exported using "+DefaultGenerator)
+	if _, err := bw.Write(header[:]); err != nil {
+		return err
+	}
+	if err := binary.Write(bw, binary.LittleEndian, count); err != nil {
+		return err
+	}
+
+	var err error
+	b.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		e1 := vec3.Sub(&tri[1], &tri[0])
+		e2 := vec3.Sub(&tri[2], &tri[0])
+		n := vec3.Cross(&e1, &e2)
+		if n.Length() > 0 {
+			n.Normalize()
+		}
+		var facet [12]float32
+		copy(facet[0:3], n[:])
+		copy(facet[3:6], tri[0][:])
+		copy(facet[6:9], tri[1][:])
+		copy(facet[9:12], tri[2][:])
+		if err = binary.Write(bw, binary.LittleEndian, facet); err != nil {
+			return false
+		}
+		// Attribute byte count, unused.
+		err = binary.Write(bw, binary.LittleEndian, uint16(0))
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
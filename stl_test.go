@@ -0,0 +1,31 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_WriteSTL_Cube_WritesTwelveFacets(t *testing.T) {
+	// Arrange
+	buffer := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "")
+
+	// Act
+	var out bytes.Buffer
+	err := buffer.WriteSTL(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	data := out.Bytes()
+	assert.Equal(t, 84+12*50, len(data))
+	assert.Equal(t, uint32(12), binary.LittleEndian.Uint32(data[80:84]))
+	var normal vec3.T
+	for i := range normal {
+		normal[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[84+4*i:]))
+	}
+	assert.InDelta(t, 1, normal.Length(), 1e-6)
+}
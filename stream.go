@@ -0,0 +1,138 @@
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+)
+
+// ObjStreamWriter writes an OBJ file statement by statement as the data is
+// produced, without building an ObjBuffer. Faces and lines may only
+// reference elements written before them. Output is buffered; call Flush
+// when done.
+type ObjStreamWriter struct {
+	w         *bufio.Writer
+	options   WriteOptions
+	counts    elementCounts
+	material  string
+	smoothing uint32
+}
+
+// NewObjStreamWriter returns a writer writing to w. Of the options, Header,
+// Generator, OmitBanner and RelativeIndices apply. The banner does not
+// include element counts, which are not known in advance.
+func NewObjStreamWriter(w io.Writer, options WriteOptions) (*ObjStreamWriter, error) {
+	s := &ObjStreamWriter{w: bufio.NewWriter(w), options: options}
+	var err error
+	if options.Header != "" {
+		err = writeComments(s.w, strings.Split(options.Header, "\n"))
+	} else if !options.OmitBanner {
+		generator := options.Generator
+		if generator == "" {
+			generator = DefaultGenerator
+		}
+		_, err = io.WriteString(s.w, fmt.Sprintf("# Exported using %s\n", generator))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetMaterialLibrary writes the mtllib statement.
+func (s *ObjStreamWriter) SetMaterialLibrary(name string) error {
+	_, err := io.WriteString(s.w, fmt.Sprintf("mtllib %s\n", name))
+	return err
+}
+
+// WriteVertex writes a vertex and returns its index.
+func (s *ObjStreamWriter) WriteVertex(v vec3.T) (int, error) {
+	if _, err := io.WriteString(s.w, fmt.Sprintf("v %g %g %g\n", v[0], v[1], v[2])); err != nil {
+		return -1, err
+	}
+	s.counts.v++
+	return s.counts.v - 1, nil
+}
+
+// WriteNormal writes a normal and returns its index.
+func (s *ObjStreamWriter) WriteNormal(vn vec3.T) (int, error) {
+	if _, err := io.WriteString(s.w, fmt.Sprintf("vn %g %g %g\n", vn[0], vn[1], vn[2])); err != nil {
+		return -1, err
+	}
+	s.counts.vn++
+	return s.counts.vn - 1, nil
+}
+
+// WriteTexcoord writes a texture coordinate and returns its index.
+func (s *ObjStreamWriter) WriteTexcoord(vt vec2.T) (int, error) {
+	if _, err := io.WriteString(s.w, fmt.Sprintf("vt %g %g\n", vt[0], vt[1])); err != nil {
+		return -1, err
+	}
+	s.counts.vt++
+	return s.counts.vt - 1, nil
+}
+
+// BeginGroup starts a group. The faces written next belong to it.
+func (s *ObjStreamWriter) BeginGroup(name string) error {
+	_, err := io.WriteString(s.w, fmt.Sprintf("g %s\n", name))
+	return err
+}
+
+// WriteFace writes a face, preceded by a usemtl statement when its material
+// differs from the material of the previous face.
+func (s *ObjStreamWriter) WriteFace(f Face) error {
+	for _, c := range f.Corners {
+		err := FirstError(
+			checkIndex(c.VertexIndex, false, s.counts.v, "vertex", "vertices"),
+			checkIndex(c.TexcoordIndex, true, s.counts.vt, "texture coordinate", "texture coordinates"),
+			checkIndex(c.NormalIndex, true, s.counts.vn, "normal", "normals"))
+		if err != nil {
+			return err
+		}
+	}
+	if f.Material != s.material {
+		s.material = f.Material
+		if err := writeUseMaterial(s.w, f.Material); err != nil {
+			return err
+		}
+	}
+	if f.SmoothingGroup != s.smoothing {
+		s.smoothing = f.SmoothingGroup
+		if err := writeSmoothingGroup(s.w, f.SmoothingGroup); err != nil {
+			return err
+		}
+	}
+	if err := writeFace(s.w, f, s.relative()); err != nil {
+		return err
+	}
+	return writeFaceMetadata(s.w, f)
+}
+
+// WriteLine writes a line through the vertices with the given indices.
+func (s *ObjStreamWriter) WriteLine(corners []int) error {
+	for _, c := range corners {
+		if err := checkIndex(c, false, s.counts.v, "vertex", "vertices"); err != nil {
+			return err
+		}
+	}
+	return writeLine(s.w, line{Corners: corners}, s.relative())
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (s *ObjStreamWriter) Flush() error {
+	return s.w.Flush()
+}
+
+// relative returns the counts relative indices are written against, or nil
+// when writing absolute indices.
+func (s *ObjStreamWriter) relative() *elementCounts {
+	if !s.options.RelativeIndices {
+		return nil
+	}
+	counts := s.counts
+	return &counts
+}
@@ -0,0 +1,91 @@
+package obj
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjStreamWriter_WriteFace_EmitsStatementsInOrder(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	s, err := NewObjStreamWriter(&out, WriteOptions{Generator: "TileForge 2.1"})
+	assert.NoError(t, err)
+
+	// Act
+	assert.NoError(t, s.SetMaterialLibrary("scene.mtl"))
+	assert.NoError(t, s.BeginGroup("strip"))
+	for i := 0; i < 3; i++ {
+		_, err = s.WriteVertex(vec3.T{float32(i), float32(i % 2), 0})
+		assert.NoError(t, err)
+	}
+	vt, _ := s.WriteTexcoord(vec2.T{0.5, 0.5})
+	assert.NoError(t, s.WriteFace(Face{Corners: []FaceCorner{{0, -1, vt}, {1, -1, vt}, {2, -1, vt}}, Material: "red"}))
+	v, _ := s.WriteVertex(vec3.T{3, 1, 0})
+	assert.NoError(t, s.WriteFace(Face{Corners: []FaceCorner{{1, -1, -1}, {v, -1, -1}, {2, -1, -1}}, Material: "red"}))
+	assert.NoError(t, s.WriteLine([]int{0, v}))
+	assert.NoError(t, s.Flush())
+
+	// Assert
+	assert.Equal(t, "# Exported using TileForge 2.1\nmtllib scene.mtl\ng strip\n"+
+		"v 0 0 0\nv 1 1 0\nv 2 0 0\nvt 0.5 0.5\nusemtl red\nf 1/1 2/1 3/1\n"+
+		"v 3 1 0\nf 2 4 3\nl 1 4\n", out.String())
+}
+
+func TestObjStreamWriter_WriteFace_RelativeIndices_ReadsBack(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	s, _ := NewObjStreamWriter(&out, WriteOptions{OmitBanner: true, RelativeIndices: true})
+
+	// Act
+	for i := 0; i < 2; i++ {
+		a, _ := s.WriteVertex(vec3.T{0, 0, float32(i)})
+		b, _ := s.WriteVertex(vec3.T{1, 0, float32(i)})
+		c, _ := s.WriteVertex(vec3.T{0, 1, float32(i)})
+		assert.NoError(t, s.WriteFace(Face{Corners: []FaceCorner{{a, -1, -1}, {b, -1, -1}, {c, -1, -1}}}))
+	}
+	assert.NoError(t, s.Flush())
+
+	// Assert
+	assert.Equal(t, 2, strings.Count(out.String(), "f -3 -2 -1\n"))
+	loader := ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader(out.String())))
+	assert.Equal(t, []FaceCorner{{3, -1, -1}, {4, -1, -1}, {5, -1, -1}}, loader.F[1].Corners)
+}
+
+func TestObjStreamWriter_WriteFace_UnwrittenVertex_ReturnsError(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	s, _ := NewObjStreamWriter(&out, WriteOptions{OmitBanner: true})
+	_, _ = s.WriteVertex(vec3.T{0, 0, 0})
+
+	// Act
+	err := s.WriteFace(Face{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {2, -1, -1}}})
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrBadIndex))
+}
+
+func TestObjStreamWriter_WriteFace_MaterialThenNone_WritesReset(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	s, err := NewObjStreamWriter(&out, WriteOptions{OmitBanner: true})
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = s.WriteVertex(vec3.T{float32(i), float32(i % 2), 0})
+		assert.NoError(t, err)
+	}
+
+	// Act
+	assert.NoError(t, s.WriteFace(Face{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {2, -1, -1}}, Material: "red"}))
+	assert.NoError(t, s.WriteFace(Face{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {2, -1, -1}}}))
+	assert.NoError(t, s.Flush())
+
+	// Assert
+	assert.Equal(t, "v 0 0 0\nv 1 1 0\nv 2 0 0\nusemtl red\nf 1 2 3\nusemtl\nf 1 2 3\n", out.String())
+}
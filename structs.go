@@ -1,24 +1,14 @@
 package obj
 
 import (
-	"fmt"
 	"math"
 
+	dvec3 "github.com/flywave/go3d/float64/vec3"
 	"github.com/flywave/go3d/vec2"
 	"github.com/flywave/go3d/vec3"
 )
 
-type lineError struct {
-	lineNumber int
-	line       string
-	err        error
-}
-
-func (e lineError) Error() string {
-	return fmt.Sprintf("Line #%d: %v ('%s')", e.lineNumber, e.line, e.err)
-}
-
-type faceCorner struct {
+type FaceCorner struct {
 	VertexIndex   int
 	NormalIndex   int
 	TexcoordIndex int
@@ -29,9 +19,18 @@ type line struct {
 	Material string
 }
 
-type face struct {
-	Corners  []faceCorner
+type Face struct {
+	Corners  []FaceCorner
 	Material string
+	// Holes holds the inner loops of the face, if it has holes.
+	Holes [][]FaceCorner
+	// SmoothingGroup is the smoothing group set by the last "s" statement
+	// before the face, or 0 when smoothing is off.
+	SmoothingGroup uint32
+	// Metadata holds identifiers of the face by name, such as feature or
+	// building IDs. It is written to OBJ files as a "#fm name=id ..."
+	// comment following the face, and to glTF with EXT_mesh_features.
+	Metadata map[string]uint32
 }
 
 func pnpoly(nvert int, vertx, verty []float32, testx, testy float32) bool {
@@ -50,17 +49,23 @@ func pnpoly(nvert int, vertx, verty []float32, testx, testy float32) bool {
 	return c
 }
 
-func (f *face) Triangulate(V []vec3.T) [][]faceCorner {
+// Triangulate splits the face into triangles by ear clipping. The corners of
+// the face are left unchanged. Holes are bridged to the outer loop first,
+// so that no triangle covers them.
+func (f *Face) Triangulate(V []vec3.T) [][]FaceCorner {
+	if len(f.Holes) > 0 {
+		return f.triangulateWithHoles(V)
+	}
 	npolys := len(f.Corners)
 	if npolys == 3 {
-		return [][]faceCorner{f.Corners}
+		return [][]FaceCorner{f.Corners}
 	}
 
 	axes := [2]int{1, 2}
 	faces := f.Corners
 
-	var ret [][]faceCorner
-	var i1 faceCorner
+	var ret [][]FaceCorner
+	var i1 FaceCorner
 	i0, i2 := faces[0], faces[1]
 
 	for k := 0; k < npolys; k++ {
@@ -72,8 +77,8 @@ func (f *face) Triangulate(V []vec3.T) [][]faceCorner {
 		vi1 := i1.VertexIndex
 		vi2 := i2.VertexIndex
 
-		if vi0 >= len(V) || vi1 >= len(V) ||
-			vi2 >= len(V) {
+		if vi0 < 0 || vi0 >= len(V) || vi1 < 0 || vi1 >= len(V) ||
+			vi2 < 0 || vi2 >= len(V) {
 			continue
 		}
 		v0x := V[vi0][0]
@@ -113,7 +118,7 @@ func (f *face) Triangulate(V []vec3.T) [][]faceCorner {
 		i1 := faces[(k+1)%npolys]
 		vi0 := i0.VertexIndex
 		vi1 := i1.VertexIndex
-		if vi0+axes[0] >= len(V) || vi0+axes[1] >= len(V) || vi1+axes[0] >= len(V) || vi1+axes[1] >= len(V) {
+		if vi0 < 0 || vi0 >= len(V) || vi1 < 0 || vi1 >= len(V) {
 			continue
 		}
 		v0x := V[vi0][axes[0]]
@@ -125,9 +130,9 @@ func (f *face) Triangulate(V []vec3.T) [][]faceCorner {
 
 	maxRounds := 10
 
-	remainingFace := faces
+	remainingFace := append([]FaceCorner(nil), faces...)
 	guessVert := 0
-	var ind [3]faceCorner
+	var ind [3]FaceCorner
 	var vx [3]float32
 	var vy [3]float32
 
@@ -140,7 +145,7 @@ func (f *face) Triangulate(V []vec3.T) [][]faceCorner {
 		for k := 0; k < 3; k++ {
 			ind[k] = remainingFace[(guessVert+k)%npolys]
 			vi := ind[k].VertexIndex
-			if vi+axes[0] >= len(V) || vi+axes[1] >= len(V) {
+			if vi < 0 || vi >= len(V) {
 				vx[k] = 0.0
 				vy[k] = 0.0
 			} else {
@@ -169,8 +174,7 @@ func (f *face) Triangulate(V []vec3.T) [][]faceCorner {
 
 			ovi := remainingFace[idx].VertexIndex
 
-			if ((ovi + axes[0]) >= len(V)) ||
-				((ovi + axes[1]) >= len(V)) {
+			if ovi < 0 || ovi >= len(V) {
 				continue
 			}
 			tx := V[ovi][axes[0]]
@@ -186,7 +190,7 @@ func (f *face) Triangulate(V []vec3.T) [][]faceCorner {
 			continue
 		}
 
-		var idx0, idx1, idx2 faceCorner
+		var idx0, idx1, idx2 FaceCorner
 		idx0.VertexIndex = ind[0].VertexIndex
 		idx0.NormalIndex = ind[0].NormalIndex
 		idx0.TexcoordIndex = ind[0].TexcoordIndex
@@ -197,7 +201,7 @@ func (f *face) Triangulate(V []vec3.T) [][]faceCorner {
 		idx2.NormalIndex = ind[2].NormalIndex
 		idx2.TexcoordIndex = ind[2].TexcoordIndex
 
-		ret = append(ret, []faceCorner{idx0, idx1, idx2})
+		ret = append(ret, []FaceCorner{idx0, idx1, idx2})
 
 		removedVertIndex := (guessVert + 1) % npolys
 		for removedVertIndex+1 < npolys {
@@ -213,7 +217,7 @@ func (f *face) Triangulate(V []vec3.T) [][]faceCorner {
 		i1 = remainingFace[1]
 		i2 = remainingFace[2]
 
-		var idx0, idx1, idx2 faceCorner
+		var idx0, idx1, idx2 FaceCorner
 		idx0.VertexIndex = i0.VertexIndex
 		idx0.NormalIndex = i0.NormalIndex
 		idx0.TexcoordIndex = i0.TexcoordIndex
@@ -224,7 +228,7 @@ func (f *face) Triangulate(V []vec3.T) [][]faceCorner {
 		idx2.NormalIndex = i2.NormalIndex
 		idx2.TexcoordIndex = i2.TexcoordIndex
 
-		ret = append(ret, []faceCorner{idx0, idx1, idx2})
+		ret = append(ret, []FaceCorner{idx0, idx1, idx2})
 	}
 	return ret
 }
@@ -236,20 +240,238 @@ type ObjBuffer struct {
 	V         []vec3.T
 	VN        []vec3.T
 	VT        []vec2.T
-	F         []face
+	F         []Face
 	L         []line
-	G         []group
-	FaceGroup []*faceGroup
+	G         []Group
+	FaceGroup []*FaceGroup
+
+	// VD holds the vertex positions in double precision. It is only filled
+	// when reading with ReadOptions.DoublePrecision and, when it has one entry
+	// per vertex, takes precedence over V when writing.
+	VD []dvec3.T
+	// VC holds the vertex colors declared as "v x y z r g b". It is empty
+	// when no vertex declares a color and has one entry per vertex
+	// otherwise; vertices declared without a color are white.
+	VC []vec3.T
+	// Offset is the origin of the vertex positions: the world position of a
+	// vertex is its position plus Offset.
+	Offset dvec3.T
+	// Comments holds the comments of the input when reading with
+	// ReadOptions.KeepComments.
+	Comments []Comment
+	// Statements holds every line of the input, in order, when reading with
+	// ReadOptions.Lossless.
+	Statements []Statement
+	// Attributes holds custom vertex attributes by name, each with a value
+	// per vertex.
+	Attributes map[string]AttributeBuffer
+}
+
+// Comment is a comment captured from an OBJ file.
+type Comment struct {
+	// Line is the line number the comment was read from.
+	Line int
+	// Text is the comment without the leading '#'.
+	Text string
+	// Trailing is set when the comment followed a statement on the same
+	// line.
+	Trailing bool
 }
 
+// BoundingBox returns the box bounding the vertices. A buffer without
+// vertices, see IsEmpty, has no bounds and returns the zero box rather than
+// an inverted one, so that its center and size are zero.
 func (b *ObjBuffer) BoundingBox() vec3.Box {
-	box := vec3.Box{vec3.MaxVal, vec3.MinVal}
-	for _, v := range b.V {
-		box.Join(&vec3.Box{v, v})
+	if b.IsEmpty() {
+		return vec3.Box{}
+	}
+	box := vec3.Box{Min: b.V[0], Max: b.V[0]}
+	for _, v := range b.V[1:] {
+		box.Join(&vec3.Box{Min: v, Max: v})
 	}
 	return box
 }
 
+// IsEmpty reports whether the buffer has no vertices.
+func (b *ObjBuffer) IsEmpty() bool {
+	return len(b.V) == 0
+}
+
+// PreallocHint holds the expected number of elements of each kind. The reader
+// uses it to size the buffer slices up front instead of growing them while
+// parsing.
+type PreallocHint struct {
+	Vertices  int
+	Normals   int
+	TexCoords int
+	Faces     int
+	Lines     int
+}
+
+// positionD returns the position of vertex i in double precision, without
+// Offset applied.
+func (b *ObjBuffer) positionD(i int) dvec3.T {
+	if b.hasDoublePrecision() {
+		return b.VD[i]
+	}
+	v := b.V[i]
+	return dvec3.T{float64(v[0]), float64(v[1]), float64(v[2])}
+}
+
+// hasVertexColors reports whether VC holds a color for every vertex.
+func (b *ObjBuffer) hasVertexColors() bool {
+	return len(b.VC) > 0 && len(b.VC) == len(b.V)
+}
+
+// hasDoublePrecision reports whether VD holds a position for every vertex.
+func (b *ObjBuffer) hasDoublePrecision() bool {
+	return len(b.VD) > 0 && len(b.VD) == len(b.V)
+}
+
+// RecenterMode selects how the reader moves large coordinates close to the
+// origin while parsing.
+type RecenterMode int
+
+const (
+	// RecenterNone keeps the positions as read.
+	RecenterNone RecenterMode = iota
+	// RecenterFirstVertex subtracts the first vertex from all positions.
+	RecenterFirstVertex
+	// RecenterBoundingBox subtracts the center of the bounding box from all
+	// positions.
+	RecenterBoundingBox
+)
+
 type ReadOptions struct {
 	DiscardDegeneratedFaces bool
+
+	// PreallocHint sizes the buffer slices before parsing starts.
+	PreallocHint PreallocHint
+	// TwoPass counts the elements of a seekable input before parsing it,
+	// so every slice is allocated exactly once. It takes precedence over
+	// PreallocHint when the input implements io.Seeker.
+	TwoPass bool
+	// DoublePrecision keeps the parsed vertex positions in ObjBuffer.VD
+	// without truncating them to float32, which matters for large
+	// georeferenced coordinates.
+	DoublePrecision bool
+	// AutoRecenter moves the positions close to the origin, in double
+	// precision, and adds the subtracted amount to ObjBuffer.Offset.
+	AutoRecenter RecenterMode
+	// KeepComments captures the comments of the input in
+	// ObjBuffer.Comments.
+	KeepComments bool
+	// Lossless records every line of the input in ObjBuffer.Statements,
+	// including object names, smoothing groups and unknown keywords, so that
+	// the file can be written back with WriteOptions.Lossless.
+	Lossless bool
+	// OnUnknown is called for every statement with a keyword the reader does
+	// not know, with the arguments of the statement and its line number.
+	// The strings may alias the input and must be copied to be retained.
+	// Returning an error aborts reading. When nil, unknown statements are
+	// skipped.
+	OnUnknown func(keyword string, fields []string, line int) error
+	// OnWarning, when set, is called for every statement that is read but
+	// ignored, instead of recording it in ObjReader.Warnings.
+	OnWarning func(Warning)
+	// Limits bounds the size of the input the reader accepts, for reading
+	// files from untrusted sources. Setting any limit also validates the
+	// indices, as with ValidateIndices.
+	Limits Limits
+	// ValidateIndices checks, once the whole input is read, that every face
+	// and line references defined vertices, normals and texture
+	// coordinates. Elements may be referenced before the line defining
+	// them, as some exporters write them.
+	ValidateIndices bool
+	// GroupFilter, when set, is called with the names of the group of every
+	// face, and the face is skipped unless it returns true for one of them.
+	// Faces outside of any named group are checked against "default
+	// group". Vertices, normals and texture coordinates are still read, as
+	// the faces that are kept may reference them.
+	GroupFilter func(name string) bool
+	// MaterialFilter, when set, is called with the material of every face,
+	// or "" for faces without one, and the face is skipped unless it returns
+	// true.
+	MaterialFilter func(material string) bool
+	// ClipBox, when set, discards the faces and lines whose bounding box
+	// lies entirely outside of it, in the coordinates of the input. Once
+	// the input is read, the vertices, normals and texture coordinates the
+	// remaining elements do not reference are dropped and the references
+	// remapped. It is ignored in Lossless mode, which keeps every element.
+	ClipBox *dvec3.Box
+	// Metrics, when set, receives the time, throughput and allocations of
+	// the read.
+	Metrics Metrics
+}
+
+// validatesIndices reports whether the reader checks the indices of the
+// faces and lines, which it does for untrusted input read with Limits.
+func (o *ReadOptions) validatesIndices() bool {
+	return o.ValidateIndices || o.Limits != (Limits{})
 }
+
+// Limits bounds the resources used to read a file. Zero fields are
+// unlimited, except MaxLineLen which defaults to bufio.MaxScanTokenSize
+// when reading from an io.Reader. Exceeding a limit fails reading with an
+// error matching ErrLimitExceeded.
+type Limits struct {
+	// MaxVertices is the largest number of vertices, and separately of
+	// normals and of texture coordinates, the file may declare.
+	MaxVertices int
+	// MaxFaces is the largest number of faces, and separately of lines, the
+	// file may declare.
+	MaxFaces int
+	// MaxLineLen is the length in bytes of the longest line accepted.
+	MaxLineLen int
+	// MaxMaterials is the largest number of distinct materials a file may
+	// use, or a material library may define.
+	MaxMaterials int
+	// MaxTextures is the largest number of distinct texture files a
+	// material library may reference.
+	MaxTextures int
+}
+
+// OffsetMode selects how the writer handles ObjBuffer.Offset.
+type OffsetMode int
+
+const (
+	// OffsetComment writes the positions as stored and records a non-zero
+	// offset in an "# offset x y z" comment, which the reader restores.
+	OffsetComment OffsetMode = iota
+	// OffsetApply adds the offset back to every position.
+	OffsetApply
+	// OffsetDiscard writes the positions as stored and drops the offset.
+	OffsetDiscard
+)
+
+type WriteOptions struct {
+	Offset OffsetMode
+	// Header replaces the default banner at the top of the file. Each line
+	// of it is written as a comment.
+	Header string
+	// Generator names the exporting product in the default banner. It
+	// defaults to DefaultGenerator.
+	Generator string
+	// OmitBanner suppresses the default banner. A Header is still written.
+	OmitBanner bool
+	// Lossless replays ObjBuffer.Statements instead of writing the buffer
+	// element by element. All other options are ignored.
+	Lossless bool
+	// RelativeIndices writes the references of faces and lines as negative
+	// indices, relative to the last vertex, normal and texture coordinate
+	// written, so that written files can be concatenated.
+	RelativeIndices bool
+	// PreserveFaces writes quads and n-gons exactly as they are, and checks
+	// first that they can be: every face must have at least 3 corners,
+	// reference existing elements, and give a texture coordinate and a
+	// normal to all or none of its corners. Faces with holes, which OBJ
+	// cannot express, are rejected rather than bridged to their outline.
+	// Nothing is written if a face fails these checks.
+	PreserveFaces bool
+	// Metrics, when set, receives the time, throughput and allocations of
+	// the write.
+	Metrics Metrics
+}
+
+// DefaultGenerator is the product named in the banner of written files.
+const DefaultGenerator = "RenderDB"
@@ -9,12 +9,23 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestObjBuffer_BoundingBox_NoVertices_ReturnsEmptyBox(t *testing.T) {
+func TestObjBuffer_BoundingBox_NoVertices_ReturnsZeroBox(t *testing.T) {
 	buffer := ObjBuffer{}
 
 	box := buffer.BoundingBox()
 
-	assert.Equal(t, vec3.Box{vec3.MaxVal, vec3.MinVal}, box)
+	assert.True(t, buffer.IsEmpty())
+	assert.Equal(t, vec3.Box{}, box)
+	assert.Equal(t, vec3.T{}, box.Center())
+}
+
+func TestObjBuffer_IsEmpty_WithVertices_ReturnsFalse(t *testing.T) {
+	buffer := ObjBuffer{V: []vec3.T{{1, 2, 3}}}
+
+	box := buffer.BoundingBox()
+
+	assert.False(t, buffer.IsEmpty())
+	assert.Equal(t, vec3.Box{Min: vec3.T{1, 2, 3}, Max: vec3.T{1, 2, 3}}, box)
 }
 
 func TestObjBuffer_BoundingBox_WithVertices_ReturnsCorrectBoundingBox(t *testing.T) {
@@ -0,0 +1,207 @@
+// Package testobj provides helpers for regression tests of meshes, comparing
+// buffers and OBJ files and describing their differences.
+package testobj
+
+import (
+	"fmt"
+	"math"
+
+	obj "github.com/flywave/go-obj"
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+)
+
+// Tolerances are the largest differences between two values that are still
+// considered equal, measured as the largest difference of any component.
+type Tolerances struct {
+	Position float64
+	Normal   float64
+	TexCoord float64
+	Color    float64
+}
+
+// DefaultTolerances absorbs the rounding of single precision values written
+// to text and read back.
+var DefaultTolerances = Tolerances{
+	Position: 1e-5,
+	Normal:   1e-5,
+	TexCoord: 1e-5,
+	Color:    1e-5,
+}
+
+// MaxDifferences is the number of differences reported before the rest are
+// summarized in a single line.
+const MaxDifferences = 100
+
+// CompareBuffers returns a description of each difference between a and b,
+// or nil if they are equal within the tolerances. Elements are numbered from
+// 1, as in OBJ files.
+func CompareBuffers(a, b *obj.ObjBuffer, tolerances Tolerances) []string {
+	d := &differences{}
+	if a.MTL != b.MTL {
+		d.add("material library differs: %q != %q", a.MTL, b.MTL)
+	}
+	if a.Offset != b.Offset {
+		d.add("offset differs: %s != %s", formatFloats(a.Offset[:]), formatFloats(b.Offset[:]))
+	}
+
+	va, vb := positions(a), positions(b)
+	d.compareCounts("vertices", len(va), len(vb))
+	for i := 0; i < len(va) && i < len(vb); i++ {
+		d.compareVectors("vertex", i, va[i][:], vb[i][:], tolerances.Position)
+	}
+	d.compareCounts("vertex colors", len(a.VC), len(b.VC))
+	for i := 0; i < len(a.VC) && i < len(b.VC); i++ {
+		d.compareVectors("vertex color", i, float64s(a.VC[i][:]), float64s(b.VC[i][:]), tolerances.Color)
+	}
+	d.compareCounts("normals", len(a.VN), len(b.VN))
+	for i := 0; i < len(a.VN) && i < len(b.VN); i++ {
+		d.compareVectors("normal", i, float64s(a.VN[i][:]), float64s(b.VN[i][:]), tolerances.Normal)
+	}
+	d.compareCounts("texture coordinates", len(a.VT), len(b.VT))
+	for i := 0; i < len(a.VT) && i < len(b.VT); i++ {
+		d.compareVectors("texture coordinate", i, float64s(a.VT[i][:]), float64s(b.VT[i][:]), tolerances.TexCoord)
+	}
+
+	d.compareCounts("faces", len(a.F), len(b.F))
+	for i := 0; i < len(a.F) && i < len(b.F); i++ {
+		fa, fb := &a.F[i], &b.F[i]
+		if fa.Material != fb.Material {
+			d.add("face %d material differs: %q != %q", i+1, fa.Material, fb.Material)
+		}
+		if !equalCorners(fa.Corners, fb.Corners) {
+			d.add("face %d differs: %s != %s", i+1, formatCorners(fa.Corners), formatCorners(fb.Corners))
+		}
+	}
+	d.compareCounts("lines", len(a.L), len(b.L))
+	for i := 0; i < len(a.L) && i < len(b.L); i++ {
+		la, lb := a.L[i], b.L[i]
+		if la.Material != lb.Material || fmt.Sprint(la.Corners) != fmt.Sprint(lb.Corners) {
+			d.add("line %d differs: %v %q != %v %q", i+1, la.Corners, la.Material, lb.Corners, lb.Material)
+		}
+	}
+	d.compareCounts("groups", len(a.G), len(b.G))
+	for i := 0; i < len(a.G) && i < len(b.G); i++ {
+		if a.G[i] != b.G[i] {
+			d.add("group %d differs: %q faces %d-%d != %q faces %d-%d", i+1,
+				a.G[i].Name, a.G[i].FirstFaceIndex+1, a.G[i].FirstFaceIndex+a.G[i].FaceCount,
+				b.G[i].Name, b.G[i].FirstFaceIndex+1, b.G[i].FirstFaceIndex+b.G[i].FaceCount)
+		}
+	}
+	return d.list()
+}
+
+// CompareOBJFiles reads the OBJ files at pathA and pathB and compares them
+// with CompareBuffers using DefaultTolerances.
+func CompareOBJFiles(pathA, pathB string) ([]string, error) {
+	options := obj.ReadOptions{DoublePrecision: true}
+	a, err := obj.ReadFile(pathA, options)
+	if err != nil {
+		return nil, err
+	}
+	b, err := obj.ReadFile(pathB, options)
+	if err != nil {
+		return nil, err
+	}
+	return CompareBuffers(a, b, DefaultTolerances), nil
+}
+
+type differences struct {
+	lines   []string
+	omitted int
+}
+
+func (d *differences) add(format string, args ...interface{}) {
+	if len(d.lines) >= MaxDifferences {
+		d.omitted++
+		return
+	}
+	d.lines = append(d.lines, fmt.Sprintf(format, args...))
+}
+
+func (d *differences) list() []string {
+	if d.omitted > 0 {
+		return append(d.lines, fmt.Sprintf("... and %d more differences", d.omitted))
+	}
+	return d.lines
+}
+
+func (d *differences) compareCounts(what string, a, b int) {
+	if a != b {
+		d.add("number of %s differs: %d != %d", what, a, b)
+	}
+}
+
+func (d *differences) compareVectors(what string, i int, a, b []float64, tolerance float64) {
+	delta := 0.0
+	for k := range a {
+		delta = math.Max(delta, math.Abs(a[k]-b[k]))
+	}
+	if delta > tolerance || math.IsNaN(delta) {
+		d.add("%s %d differs by %g: %s != %s", what, i+1, delta, formatFloats(a), formatFloats(b))
+	}
+}
+
+// positions returns the vertex positions of b, in double precision when the
+// buffer has them.
+func positions(b *obj.ObjBuffer) []dvec3.T {
+	if len(b.VD) == len(b.V) {
+		return b.VD
+	}
+	p := make([]dvec3.T, len(b.V))
+	for i, v := range b.V {
+		p[i] = dvec3.T{float64(v[0]), float64(v[1]), float64(v[2])}
+	}
+	return p
+}
+
+func float64s(values []float32) []float64 {
+	f := make([]float64, len(values))
+	for i, v := range values {
+		f[i] = float64(v)
+	}
+	return f
+}
+
+func formatFloats(values []float64) string {
+	s := "("
+	for i, v := range values {
+		if i > 0 {
+			s += " "
+		}
+		s += fmt.Sprintf("%g", v)
+	}
+	return s + ")"
+}
+
+func equalCorners(a, b []obj.FaceCorner) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// formatCorners formats corners as in an OBJ face statement.
+func formatCorners(corners []obj.FaceCorner) string {
+	s := ""
+	for i, c := range corners {
+		if i > 0 {
+			s += " "
+		}
+		s += fmt.Sprintf("%d", c.VertexIndex+1)
+		if c.TexcoordIndex >= 0 || c.NormalIndex >= 0 {
+			s += "/"
+			if c.TexcoordIndex >= 0 {
+				s += fmt.Sprintf("%d", c.TexcoordIndex+1)
+			}
+		}
+		if c.NormalIndex >= 0 {
+			s += fmt.Sprintf("/%d", c.NormalIndex+1)
+		}
+	}
+	return s
+}
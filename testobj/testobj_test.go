@@ -0,0 +1,94 @@
+package testobj
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	obj "github.com/flywave/go-obj"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func triangle() *obj.ObjBuffer {
+	return &obj.ObjBuffer{
+		V: []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+		F: []obj.Face{{
+			Corners:  []obj.FaceCorner{{VertexIndex: 0, NormalIndex: -1, TexcoordIndex: -1}, {VertexIndex: 1, NormalIndex: -1, TexcoordIndex: -1}, {VertexIndex: 2, NormalIndex: -1, TexcoordIndex: -1}},
+			Material: "roof",
+		}},
+	}
+}
+
+func TestCompareBuffers_WithinTolerance_ReturnsNil(t *testing.T) {
+	// Arrange
+	a, b := triangle(), triangle()
+	b.V[1][0] += 1e-6
+
+	// Act
+	diffs := CompareBuffers(a, b, DefaultTolerances)
+
+	// Assert
+	assert.Nil(t, diffs)
+}
+
+func TestCompareBuffers_Differences_DescribesEach(t *testing.T) {
+	// Arrange
+	a, b := triangle(), triangle()
+	b.V[1][2] = 0.5
+	b.F[0].Material = "wall"
+	b.F[0].Corners[2].NormalIndex = 0
+	b.VN = []vec3.T{{0, 0, 1}}
+
+	// Act
+	diffs := CompareBuffers(a, b, DefaultTolerances)
+
+	// Assert
+	assert.Equal(t, []string{
+		"vertex 2 differs by 0.5: (1 0 0) != (1 0 0.5)",
+		"number of normals differs: 0 != 1",
+		`face 1 material differs: "roof" != "wall"`,
+		"face 1 differs: 1 2 3 != 1 2 3//1",
+	}, diffs)
+}
+
+func TestCompareBuffers_ManyDifferences_SummarizesRest(t *testing.T) {
+	// Arrange
+	a, b := &obj.ObjBuffer{}, &obj.ObjBuffer{}
+	for i := 0; i < MaxDifferences+5; i++ {
+		a.V = append(a.V, vec3.T{0, 0, 0})
+		b.V = append(b.V, vec3.T{1, 0, 0})
+	}
+
+	// Act
+	diffs := CompareBuffers(a, b, DefaultTolerances)
+
+	// Assert
+	assert.Len(t, diffs, MaxDifferences+1)
+	assert.Equal(t, "... and 5 more differences", diffs[MaxDifferences])
+}
+
+func TestCompareOBJFiles_ReformattedFile_ReturnsNoDifferences(t *testing.T) {
+	// Arrange
+	dir, err := ioutil.TempDir("", "testobj")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	pathA := filepath.Join(dir, "a.obj")
+	pathB := filepath.Join(dir, "b.obj")
+	assert.NoError(t, ioutil.WriteFile(pathA, []byte("v 1 0 0\nv 0 1 0\nv 0 0 1\nf 1 2 3\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(pathB, []byte("v 1.0 0 0\nv 0 1.0 0\nv 0 0 1.000001\nf 1 2 3\n"), 0644))
+
+	// Act
+	diffs, err := CompareOBJFiles(pathA, pathB)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Nil(t, diffs)
+}
+
+func TestCompareOBJFiles_MissingFile_ReturnsError(t *testing.T) {
+	_, err := CompareOBJFiles("does-not-exist.obj", "does-not-exist.obj")
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,309 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TextureFormat is the encoding of a texture file, detected from its
+// content.
+type TextureFormat string
+
+// The texture formats ValidateTextures recognizes.
+const (
+	TexturePNG  TextureFormat = "png"
+	TextureJPEG TextureFormat = "jpeg"
+	TextureTGA  TextureFormat = "tga"
+	TextureDDS  TextureFormat = "dds"
+	TextureBMP  TextureFormat = "bmp"
+	TextureTIFF TextureFormat = "tiff"
+)
+
+// TextureInfo describes a texture referenced by the materials of a scene.
+type TextureInfo struct {
+	// Path is the path of the texture as the materials reference it.
+	Path string
+	// Materials holds the names of the materials referencing the texture,
+	// sorted.
+	Materials []string
+	Format    TextureFormat
+	Width     int
+	Height    int
+	// Channels is the number of color channels, such as 3 for RGB and 4
+	// for RGBA.
+	Channels int
+	// Err reports why the texture cannot be used, or is nil.
+	Err error
+}
+
+// Textures returns the paths of the textures of the material, in a fixed
+// order and without duplicates.
+func (m *Material) Textures() []string {
+	var textures []string
+	seen := make(map[string]bool)
+	for _, t := range m.texturePaths() {
+		if *t != "" && !seen[*t] {
+			seen[*t] = true
+			textures = append(textures, *t)
+		}
+	}
+	return textures
+}
+
+// texturePaths returns the texture fields of the material, those of the
+// reflection map last.
+func (m *Material) texturePaths() []*string {
+	r := &m.ReflectionMap
+	return []*string{
+		&m.AmbientTexture, &m.DiffuseTexture, &m.SpecularTexture, &m.EmissiveTexture,
+		&m.AlphaTexture, &m.BumpTexture, &m.DisplacementTexture, &m.DecalTexture,
+		&m.NormalTexture, &m.RoughnessTexture, &m.MetallicTexture, &m.SheenTexture,
+		&r.Sphere, &r.CubeTop, &r.CubeBottom, &r.CubeFront, &r.CubeBack, &r.CubeLeft, &r.CubeRight,
+	}
+}
+
+// ValidateTextures checks every texture referenced by the materials of the
+// scene: the file must exist and hold a PNG, JPEG, TGA, DDS, BMP or TIFF
+// image, which is decoded, except for TGA and DDS whose header is checked.
+// Relative paths are looked up in Dir. The textures are returned sorted by
+// path with their format, resolution and number of channels, and with the
+// reason they cannot be used if so; the error reports the broken textures.
+func (s *Scene) ValidateTextures() ([]TextureInfo, error) {
+	materials := make(map[string][]string)
+	for name, m := range s.Materials {
+		for _, t := range m.Textures() {
+			materials[t] = append(materials[t], name)
+		}
+	}
+	infos := make([]TextureInfo, 0, len(materials))
+	var broken []string
+	for path, names := range materials {
+		sort.Strings(names)
+		info := TextureInfo{Path: path, Materials: names}
+		info.Err = info.inspect(s.texturePath(path))
+		if info.Err != nil {
+			broken = append(broken, fmt.Sprintf("%s (%v)", path, info.Err))
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Path < infos[j].Path })
+	if len(broken) > 0 {
+		sort.Strings(broken)
+		return infos, fmt.Errorf("%d of %d textures are broken: %s", len(broken), len(infos), strings.Join(broken, ", "))
+	}
+	return infos, nil
+}
+
+// texturePath returns the path of the file of a texture of the scene.
+func (s *Scene) texturePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(path))
+}
+
+// inspect reads the texture file at path and fills in its format and
+// size.
+func (info *TextureInfo) inspect(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	info.Format = detectTextureFormat(data, path)
+	switch info.Format {
+	case TexturePNG:
+		return info.inspectPNG(data)
+	case TextureJPEG:
+		return info.inspectJPEG(data)
+	case TextureTGA:
+		return info.inspectTGA(data)
+	case TextureDDS:
+		return info.inspectDDS(data)
+	case TextureBMP, TextureTIFF:
+		decode := decodeBMP
+		if info.Format == TextureTIFF {
+			decode = decodeTIFF
+		}
+		img, channels, err := decode(data)
+		if err != nil {
+			return err
+		}
+		info.Width, info.Height, info.Channels = img.Bounds().Dx(), img.Bounds().Dy(), channels
+		return nil
+	}
+	return fmt.Errorf("Unknown image format")
+}
+
+// detectTextureFormat returns the format of the image data, or "" if it is
+// not recognized. TGA has no signature and is recognized by the extension
+// of path.
+func detectTextureFormat(data []byte, path string) TextureFormat {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return TexturePNG
+	case bytes.HasPrefix(data, []byte{0xff, 0xd8, 0xff}):
+		return TextureJPEG
+	case bytes.HasPrefix(data, []byte("DDS ")):
+		return TextureDDS
+	case bytes.HasPrefix(data, []byte("BM")):
+		return TextureBMP
+	case bytes.HasPrefix(data, []byte("II*\x00")), bytes.HasPrefix(data, []byte("MM\x00*")):
+		return TextureTIFF
+	case strings.EqualFold(filepath.Ext(path), ".tga"):
+		return TextureTGA
+	}
+	return ""
+}
+
+func (info *TextureInfo) inspectPNG(data []byte) error {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	info.Width, info.Height = img.Bounds().Dx(), img.Bounds().Dy()
+	// The color type of the IHDR chunk, which follows the signature, the
+	// chunk header and the size and bit depth of the image.
+	switch data[25] {
+	case 0:
+		info.Channels = 1
+	case 4:
+		info.Channels = 2
+	case 6:
+		info.Channels = 4
+	default:
+		info.Channels = 3
+	}
+	return nil
+}
+
+func (info *TextureInfo) inspectJPEG(data []byte) error {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	info.Width, info.Height = img.Bounds().Dx(), img.Bounds().Dy()
+	switch img.ColorModel() {
+	case color.GrayModel:
+		info.Channels = 1
+	case color.CMYKModel:
+		info.Channels = 4
+	default:
+		info.Channels = 3
+	}
+	return nil
+}
+
+// inspectTGA checks the header of a TGA image, and the size of the data of
+// uncompressed ones.
+func (info *TextureInfo) inspectTGA(data []byte) error {
+	if len(data) < 18 {
+		return fmt.Errorf("Truncated TGA header")
+	}
+	idLength, colorMapType, imageType := int(data[0]), data[1], data[2]
+	colorMapLength, colorMapDepth := int(binary.LittleEndian.Uint16(data[5:])), int(data[7])
+	info.Width = int(binary.LittleEndian.Uint16(data[12:]))
+	info.Height = int(binary.LittleEndian.Uint16(data[14:]))
+	depth, alphaBits := int(data[16]), data[17]&0x0f
+	if colorMapType > 1 || info.Width == 0 || info.Height == 0 {
+		return fmt.Errorf("Invalid TGA header")
+	}
+
+	switch imageType &^ 8 {
+	case 1:
+		// Color mapped: the channels are those of the palette.
+		if colorMapType != 1 || (depth != 8 && depth != 16) {
+			return fmt.Errorf("Invalid TGA color map")
+		}
+		info.Channels = 3
+		if colorMapDepth == 32 {
+			info.Channels = 4
+		}
+	case 2:
+		switch depth {
+		case 15, 16, 24:
+			info.Channels = 3
+		case 32:
+			info.Channels = 4
+		default:
+			return fmt.Errorf("Unsupported TGA pixel depth %d", depth)
+		}
+		if alphaBits > 0 {
+			info.Channels = 4
+		}
+	case 3:
+		if depth != 8 && depth != 16 {
+			return fmt.Errorf("Unsupported TGA pixel depth %d", depth)
+		}
+		info.Channels = 1
+		if alphaBits > 0 {
+			info.Channels = 2
+		}
+	default:
+		return fmt.Errorf("Unsupported TGA image type %d", imageType)
+	}
+
+	if imageType&8 == 0 {
+		size := 18 + idLength + colorMapLength*((colorMapDepth+7)/8) + info.Width*info.Height*((depth+7)/8)
+		if len(data) < size {
+			return fmt.Errorf("Truncated TGA image data")
+		}
+	}
+	return nil
+}
+
+// DDS pixel format flags.
+const (
+	ddsAlphaPixels = 0x1
+	ddsFourCC      = 0x4
+	ddsRGB         = 0x40
+	ddsLuminance   = 0x20000
+)
+
+// inspectDDS checks the header of a DDS image.
+func (info *TextureInfo) inspectDDS(data []byte) error {
+	if len(data) < 128 || binary.LittleEndian.Uint32(data[4:]) != 124 {
+		return fmt.Errorf("Invalid DDS header")
+	}
+	info.Height = int(binary.LittleEndian.Uint32(data[12:]))
+	info.Width = int(binary.LittleEndian.Uint32(data[16:]))
+	if info.Width == 0 || info.Height == 0 {
+		return fmt.Errorf("Invalid DDS header")
+	}
+
+	// The pixel format follows the size, flags, dimensions, pitch, depth,
+	// mipmap count and reserved words of the header.
+	flags := binary.LittleEndian.Uint32(data[80:])
+	switch {
+	case flags&ddsFourCC != 0:
+		switch fourCC := string(data[84:88]); fourCC {
+		case "DXT1":
+			info.Channels = 3
+		case "DXT2", "DXT3", "DXT4", "DXT5", "DX10":
+			info.Channels = 4
+		case "ATI1", "BC4U", "BC4S":
+			info.Channels = 1
+		case "ATI2", "BC5U", "BC5S":
+			info.Channels = 2
+		default:
+			return fmt.Errorf("Unsupported DDS compression '%s'", strings.TrimRight(fourCC, "\x00"))
+		}
+	case flags&ddsRGB != 0:
+		info.Channels = 3
+	case flags&ddsLuminance != 0:
+		info.Channels = 1
+	default:
+		return fmt.Errorf("Unsupported DDS pixel format")
+	}
+	if flags&ddsAlphaPixels != 0 && flags&ddsFourCC == 0 {
+		info.Channels++
+	}
+	return nil
+}
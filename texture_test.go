@@ -0,0 +1,107 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestTextures writes files to dir.
+func writeTestTextures(t *testing.T, dir string, files map[string][]byte) {
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func encodeTestPNG(img image.Image) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+// createTGA returns an uncompressed 32-bit TGA image of the given size.
+func createTGA(width, height int) []byte {
+	header := make([]byte, 18)
+	header[2] = 2
+	binary.LittleEndian.PutUint16(header[12:], uint16(width))
+	binary.LittleEndian.PutUint16(header[14:], uint16(height))
+	header[16], header[17] = 32, 8
+	return append(header, make([]byte, width*height*4)...)
+}
+
+func TestScene_ValidateTextures_ReportsFormats(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	var jpg bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&jpg, image.NewGray(image.Rect(0, 0, 8, 4)), nil))
+	rgb := image.NewRGBA(image.Rect(0, 0, 16, 8))
+	for i := range rgb.Pix {
+		rgb.Pix[i] = 255
+	}
+	dds := make([]byte, 128)
+	copy(dds, "DDS ")
+	binary.LittleEndian.PutUint32(dds[4:], 124)
+	binary.LittleEndian.PutUint32(dds[12:], 64)
+	binary.LittleEndian.PutUint32(dds[16:], 32)
+	binary.LittleEndian.PutUint32(dds[80:], ddsFourCC)
+	copy(dds[84:], "DXT5")
+	writeTestTextures(t, dir, map[string][]byte{
+		"wall.png":  encodeTestPNG(image.NewNRGBA(image.Rect(0, 0, 2, 2))),
+		"floor.jpg": jpg.Bytes(),
+		"roof.tga":  createTGA(4, 2),
+		"sky.dds":   dds,
+		"rgb.png":   encodeTestPNG(rgb),
+	})
+	scene := &Scene{Dir: dir, Materials: map[string]*Material{
+		"wall":  {Name: "wall", DiffuseTexture: "wall.png", NormalTexture: "rgb.png"},
+		"floor": {Name: "floor", DiffuseTexture: "floor.jpg", BumpTexture: "wall.png"},
+		"roof":  {Name: "roof", DiffuseTexture: "roof.tga", ReflectionMap: ReflectionMap{Sphere: "sky.dds"}},
+	}}
+
+	// Act
+	infos, err := scene.ValidateTextures()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []TextureInfo{
+		{Path: "floor.jpg", Materials: []string{"floor"}, Format: TextureJPEG, Width: 8, Height: 4, Channels: 1},
+		{Path: "rgb.png", Materials: []string{"wall"}, Format: TexturePNG, Width: 16, Height: 8, Channels: 3},
+		{Path: "roof.tga", Materials: []string{"roof"}, Format: TextureTGA, Width: 4, Height: 2, Channels: 4},
+		{Path: "sky.dds", Materials: []string{"roof"}, Format: TextureDDS, Width: 32, Height: 64, Channels: 4},
+		{Path: "wall.png", Materials: []string{"floor", "wall"}, Format: TexturePNG, Width: 2, Height: 2, Channels: 4},
+	}, infos)
+}
+
+func TestScene_ValidateTextures_BrokenTextures_ReturnsError(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeTestTextures(t, dir, map[string][]byte{
+		"text.png":      []byte("not an image"),
+		"truncated.tga": createTGA(4, 4)[:40],
+		"cut.png":       encodeTestPNG(image.NewGray(image.Rect(0, 0, 64, 64)))[:40],
+	})
+	scene := &Scene{Dir: dir, Materials: map[string]*Material{
+		"a": {Name: "a", DiffuseTexture: "missing.png", AlphaTexture: "text.png", BumpTexture: "truncated.tga", SpecularTexture: "cut.png"},
+	}}
+
+	// Act
+	infos, err := scene.ValidateTextures()
+
+	// Assert
+	assert.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "4 of 4 textures are broken: cut.png ("))
+	for _, info := range infos {
+		assert.Error(t, info.Err, info.Path)
+	}
+	assert.True(t, os.IsNotExist(infos[1].Err))
+}
@@ -0,0 +1,536 @@
+package obj
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// convertTextures converts the TGA, BMP and TIFF textures of the materials
+// of the scene as set by options, and returns the paths of the converted
+// textures keyed by the paths of the originals.
+func (s *Scene) convertTextures(options GLTFOptions) (map[string]string, error) {
+	if options.TextureFormat != TexturePNG && options.TextureFormat != TextureJPEG {
+		return nil, fmt.Errorf("Cannot convert textures to %s", options.TextureFormat)
+	}
+	dir := options.TextureDir
+	if dir == "" {
+		dir = s.Dir
+	}
+	quality := options.JPEGQuality
+	if quality <= 0 {
+		quality = 90
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, m := range s.Materials {
+		for _, t := range m.Textures() {
+			switch strings.ToLower(filepath.Ext(t)) {
+			case ".tga", ".bmp", ".tif", ".tiff":
+				if !seen[t] {
+					seen[t] = true
+					paths = append(paths, t)
+				}
+			}
+		}
+	}
+	sort.Strings(paths)
+
+	uris := make(map[string]string, len(paths))
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(s.texturePath(path))
+		if err != nil {
+			return nil, err
+		}
+		img, err := decodeTexture(data, detectTextureFormat(data, path))
+		if err != nil {
+			return nil, fmt.Errorf("Texture %s: %v", path, err)
+		}
+
+		var buf bytes.Buffer
+		uri := strings.TrimSuffix(path, filepath.Ext(path))
+		if opaque, ok := img.(interface{ Opaque() bool }); options.TextureFormat == TextureJPEG && ok && opaque.Opaque() {
+			uri += ".jpg"
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+		} else {
+			uri += ".png"
+			err = png.Encode(&buf, img)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out := uri
+		if !filepath.IsAbs(uri) {
+			out = filepath.Join(dir, filepath.FromSlash(uri))
+		}
+		if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(out, buf.Bytes(), 0644); err != nil {
+			return nil, err
+		}
+		uris[path] = uri
+	}
+	return uris, nil
+}
+
+// decodeTexture decodes a texture in any of the formats detected by
+// detectTextureFormat but DDS, which holds compressed GPU data.
+func decodeTexture(data []byte, format TextureFormat) (image.Image, error) {
+	switch format {
+	case TexturePNG:
+		return png.Decode(bytes.NewReader(data))
+	case TextureJPEG:
+		return jpeg.Decode(bytes.NewReader(data))
+	case TextureTGA:
+		return decodeTGA(data)
+	case TextureBMP:
+		img, _, err := decodeBMP(data)
+		return img, err
+	case TextureTIFF:
+		img, _, err := decodeTIFF(data)
+		return img, err
+	}
+	return nil, fmt.Errorf("Cannot decode %s images", format)
+}
+
+// decodeTGA decodes a TGA image, color mapped, true color or grayscale,
+// uncompressed or run-length encoded.
+func decodeTGA(data []byte) (image.Image, error) {
+	var info TextureInfo
+	if err := info.inspectTGA(data); err != nil {
+		return nil, err
+	}
+	idLength, imageType := int(data[0]), data[2]
+	colorMapFirst := int(binary.LittleEndian.Uint16(data[3:]))
+	colorMapLength, colorMapDepth := int(binary.LittleEndian.Uint16(data[5:])), int(data[7])
+	depth, descriptor := int(data[16]), data[17]
+	alpha := descriptor&0x0f > 0
+	pos := 18 + idLength
+
+	var palette []color.NRGBA
+	if data[1] == 1 {
+		size := (colorMapDepth + 7) / 8
+		if pos+colorMapLength*size > len(data) {
+			return nil, fmt.Errorf("Truncated TGA color map")
+		}
+		for i := 0; i < colorMapLength; i++ {
+			palette = append(palette, tgaColor(data[pos+i*size:], colorMapDepth, colorMapDepth == 32))
+		}
+		pos += colorMapLength * size
+	}
+
+	pixelSize := (depth + 7) / 8
+	pixel := func(p []byte) (color.NRGBA, error) {
+		switch imageType &^ 8 {
+		case 1:
+			index := int(p[0])
+			if depth == 16 {
+				index = int(binary.LittleEndian.Uint16(p))
+			}
+			index -= colorMapFirst
+			if index < 0 || index >= len(palette) {
+				return color.NRGBA{}, fmt.Errorf("TGA color index out of range")
+			}
+			return palette[index], nil
+		case 3:
+			a := uint8(255)
+			if depth == 16 && alpha {
+				a = p[1]
+			}
+			return color.NRGBA{p[0], p[0], p[0], a}, nil
+		}
+		return tgaColor(p, depth, alpha), nil
+	}
+
+	width, height := info.Width, info.Height
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	put := func(i int, c color.NRGBA) {
+		x, y := i%width, i/width
+		if descriptor&0x10 != 0 {
+			x = width - 1 - x
+		}
+		if descriptor&0x20 == 0 {
+			y = height - 1 - y
+		}
+		img.SetNRGBA(x, y, c)
+	}
+	for i := 0; i < width*height; {
+		count, repeat := width*height-i, false
+		if imageType&8 != 0 {
+			if pos >= len(data) {
+				return nil, fmt.Errorf("Truncated TGA image data")
+			}
+			count, repeat = int(data[pos]&0x7f)+1, data[pos]&0x80 != 0
+			pos++
+		}
+		for k := 0; k < count && i < width*height; k++ {
+			if pos+pixelSize > len(data) {
+				return nil, fmt.Errorf("Truncated TGA image data")
+			}
+			c, err := pixel(data[pos:])
+			if err != nil {
+				return nil, err
+			}
+			if !repeat || k == count-1 {
+				pos += pixelSize
+			}
+			put(i, c)
+			i++
+		}
+	}
+	return img, nil
+}
+
+// tgaColor decodes a true color pixel of depth bits.
+func tgaColor(p []byte, depth int, alpha bool) color.NRGBA {
+	switch depth {
+	case 15, 16:
+		v := binary.LittleEndian.Uint16(p)
+		c := color.NRGBA{uint8(v >> 10 & 31 * 255 / 31), uint8(v >> 5 & 31 * 255 / 31), uint8(v & 31 * 255 / 31), 255}
+		if depth == 16 && alpha && v&0x8000 == 0 {
+			c.A = 0
+		}
+		return c
+	case 32:
+		c := color.NRGBA{p[2], p[1], p[0], 255}
+		if alpha {
+			c.A = p[3]
+		}
+		return c
+	}
+	return color.NRGBA{p[2], p[1], p[0], 255}
+}
+
+// BMP compression methods.
+const (
+	bmpRGB       = 0
+	bmpBitFields = 3
+)
+
+// decodeBMP decodes an uncompressed BMP image with a palette of 1, 4 or 8
+// bits, or with 16, 24 or 32 bits per pixel, and returns it with its number
+// of channels.
+func decodeBMP(data []byte) (image.Image, int, error) {
+	if len(data) < 54 || data[0] != 'B' || data[1] != 'M' {
+		return nil, 0, fmt.Errorf("Invalid BMP header")
+	}
+	offset := int(binary.LittleEndian.Uint32(data[10:]))
+	headerSize := int(binary.LittleEndian.Uint32(data[14:]))
+	if headerSize < 40 || 14+headerSize > len(data) {
+		return nil, 0, fmt.Errorf("Unsupported BMP header")
+	}
+	width := int(int32(binary.LittleEndian.Uint32(data[18:])))
+	height := int(int32(binary.LittleEndian.Uint32(data[22:])))
+	bits := int(binary.LittleEndian.Uint16(data[28:]))
+	compression := binary.LittleEndian.Uint32(data[30:])
+	colorsUsed := int(binary.LittleEndian.Uint32(data[46:]))
+	topDown := height < 0
+	if topDown {
+		height = -height
+	}
+	if width <= 0 || height == 0 {
+		return nil, 0, fmt.Errorf("Invalid BMP size %dx%d", width, height)
+	}
+
+	// The masks of the channels, which follow a 40 byte header for
+	// BI_BITFIELDS and are part of the larger headers.
+	var masks [4]uint32
+	switch {
+	case compression == bmpBitFields && (bits == 16 || bits == 32):
+		if 54+12 > len(data) {
+			return nil, 0, fmt.Errorf("Truncated BMP header")
+		}
+		for k := 0; k < 3; k++ {
+			masks[k] = binary.LittleEndian.Uint32(data[54+4*k:])
+		}
+		if headerSize >= 56 {
+			masks[3] = binary.LittleEndian.Uint32(data[66:])
+		}
+	case compression == bmpRGB && bits == 16:
+		masks = [4]uint32{0x7c00, 0x03e0, 0x001f, 0}
+	case compression == bmpRGB && bits == 32:
+		masks = [4]uint32{0xff0000, 0x00ff00, 0x0000ff, 0}
+	case compression == bmpRGB && (bits == 1 || bits == 4 || bits == 8 || bits == 24):
+	default:
+		return nil, 0, fmt.Errorf("Unsupported BMP compression %d with %d bits", compression, bits)
+	}
+
+	var palette []color.NRGBA
+	if bits <= 8 {
+		if colorsUsed == 0 {
+			colorsUsed = 1 << uint(bits)
+		}
+		start := 14 + headerSize
+		if start+4*colorsUsed > len(data) {
+			return nil, 0, fmt.Errorf("Truncated BMP palette")
+		}
+		for i := 0; i < colorsUsed; i++ {
+			p := data[start+4*i:]
+			palette = append(palette, color.NRGBA{p[2], p[1], p[0], 255})
+		}
+	}
+
+	stride := (width*bits + 31) / 32 * 4
+	if offset < 0 || offset+stride*height > len(data) {
+		return nil, 0, fmt.Errorf("Truncated BMP image data")
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for row := 0; row < height; row++ {
+		y := height - 1 - row
+		if topDown {
+			y = row
+		}
+		line := data[offset+row*stride:]
+		for x := 0; x < width; x++ {
+			var c color.NRGBA
+			switch bits {
+			case 1, 4, 8:
+				bit := x * bits
+				index := int(line[bit/8]>>uint(8-bits-bit%8)) & (1<<uint(bits) - 1)
+				if index >= len(palette) {
+					return nil, 0, fmt.Errorf("BMP color index out of range")
+				}
+				c = palette[index]
+			case 24:
+				c = color.NRGBA{line[3*x+2], line[3*x+1], line[3*x], 255}
+			case 16:
+				c = maskedColor(uint32(binary.LittleEndian.Uint16(line[2*x:])), masks)
+			case 32:
+				c = maskedColor(binary.LittleEndian.Uint32(line[4*x:]), masks)
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	channels := 3
+	if masks[3] != 0 {
+		channels = 4
+	}
+	return img, channels, nil
+}
+
+// maskedColor extracts the channels of a pixel with the masks of red,
+// green, blue and alpha. Without an alpha mask, the pixel is opaque.
+func maskedColor(v uint32, masks [4]uint32) color.NRGBA {
+	channel := func(mask uint32) uint8 {
+		if mask == 0 {
+			return 255
+		}
+		shift := uint(0)
+		for mask>>shift&1 == 0 {
+			shift++
+		}
+		max := mask >> shift
+		return uint8((v & mask >> shift) * 255 / max)
+	}
+	return color.NRGBA{channel(masks[0]), channel(masks[1]), channel(masks[2]), channel(masks[3])}
+}
+
+// TIFF tags.
+const (
+	tiffImageWidth      = 256
+	tiffImageLength     = 257
+	tiffBitsPerSample   = 258
+	tiffCompression     = 259
+	tiffPhotometric     = 262
+	tiffStripOffsets    = 273
+	tiffSamplesPerPixel = 277
+	tiffRowsPerStrip    = 278
+	tiffStripByteCounts = 279
+	tiffPlanarConfig    = 284
+	tiffPredictor       = 317
+	tiffColorMap        = 320
+	tiffExtraSamples    = 338
+)
+
+// decodeTIFF decodes the first image of a TIFF file, stored in strips of 8
+// bit samples, grayscale, RGB or color mapped, uncompressed or compressed
+// with PackBits or Deflate, and returns it with its number of channels.
+func decodeTIFF(data []byte) (image.Image, int, error) {
+	if len(data) < 8 {
+		return nil, 0, fmt.Errorf("Invalid TIFF header")
+	}
+	var order binary.ByteOrder
+	switch string(data[:4]) {
+	case "II*\x00":
+		order = binary.LittleEndian
+	case "MM\x00*":
+		order = binary.BigEndian
+	default:
+		return nil, 0, fmt.Errorf("Invalid TIFF header")
+	}
+
+	ifd := int(order.Uint32(data[4:]))
+	if ifd < 8 || ifd+2 > len(data) {
+		return nil, 0, fmt.Errorf("Invalid TIFF directory offset")
+	}
+	tags := make(map[uint16][]uint32)
+	count := int(order.Uint16(data[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + 12*i
+		if entry+12 > len(data) {
+			return nil, 0, fmt.Errorf("Truncated TIFF directory")
+		}
+		tag, kind, n := order.Uint16(data[entry:]), order.Uint16(data[entry+2:]), int(order.Uint32(data[entry+4:]))
+		size := map[uint16]int{1: 1, 3: 2, 4: 4}[kind]
+		if size == 0 {
+			continue
+		}
+		values := data[entry+8:]
+		if size*n > 4 {
+			at := int(order.Uint32(values))
+			if at < 0 || n < 0 || at+size*n > len(data) {
+				return nil, 0, fmt.Errorf("Truncated TIFF tag %d", tag)
+			}
+			values = data[at:]
+		}
+		for k := 0; k < n; k++ {
+			switch size {
+			case 1:
+				tags[tag] = append(tags[tag], uint32(values[k]))
+			case 2:
+				tags[tag] = append(tags[tag], uint32(order.Uint16(values[2*k:])))
+			default:
+				tags[tag] = append(tags[tag], order.Uint32(values[4*k:]))
+			}
+		}
+	}
+	tagValue := func(tag uint16, def uint32) uint32 {
+		if v := tags[tag]; len(v) > 0 {
+			return v[0]
+		}
+		return def
+	}
+
+	width, height := int(tagValue(tiffImageWidth, 0)), int(tagValue(tiffImageLength, 0))
+	samples := int(tagValue(tiffSamplesPerPixel, 1))
+	photometric := tagValue(tiffPhotometric, 1)
+	if width <= 0 || height <= 0 || samples < 1 || samples > 4 {
+		return nil, 0, fmt.Errorf("Invalid TIFF image")
+	}
+	for _, bits := range tags[tiffBitsPerSample] {
+		if bits != 8 {
+			return nil, 0, fmt.Errorf("Unsupported TIFF sample size %d", bits)
+		}
+	}
+	if tagValue(tiffPlanarConfig, 1) != 1 {
+		return nil, 0, fmt.Errorf("Unsupported TIFF planar configuration")
+	}
+	offsets, counts := tags[tiffStripOffsets], tags[tiffStripByteCounts]
+	if len(offsets) == 0 || len(offsets) != len(counts) {
+		return nil, 0, fmt.Errorf("Unsupported TIFF layout")
+	}
+
+	// Decompress the strips into the samples of the whole image.
+	var pixels []byte
+	compression := tagValue(tiffCompression, 1)
+	for i := range offsets {
+		start, end := int(offsets[i]), int(offsets[i])+int(counts[i])
+		if start < 0 || end > len(data) || end < start {
+			return nil, 0, fmt.Errorf("Truncated TIFF strip")
+		}
+		strip := data[start:end]
+		switch compression {
+		case 1:
+		case 8, 32946:
+			r, err := zlib.NewReader(bytes.NewReader(strip))
+			if err != nil {
+				return nil, 0, err
+			}
+			if strip, err = ioutil.ReadAll(r); err != nil {
+				return nil, 0, err
+			}
+		case 32773:
+			strip = unpackBits(strip)
+		default:
+			return nil, 0, fmt.Errorf("Unsupported TIFF compression %d", compression)
+		}
+		pixels = append(pixels, strip...)
+	}
+	stride := width * samples
+	if len(pixels) < stride*height {
+		return nil, 0, fmt.Errorf("Truncated TIFF image data")
+	}
+	if tagValue(tiffPredictor, 1) == 2 {
+		for y := 0; y < height; y++ {
+			row := pixels[y*stride : (y+1)*stride]
+			for k := samples; k < stride; k++ {
+				row[k] += row[k-samples]
+			}
+		}
+	}
+
+	colorMap := tags[tiffColorMap]
+	alpha := len(tags[tiffExtraSamples]) > 0
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := pixels[y*stride+x*samples:]
+			var c color.NRGBA
+			switch photometric {
+			case 0, 1:
+				v := p[0]
+				if photometric == 0 {
+					v = 255 - v
+				}
+				c = color.NRGBA{v, v, v, 255}
+				if alpha && samples > 1 {
+					c.A = p[1]
+				}
+			case 2:
+				if samples < 3 {
+					return nil, 0, fmt.Errorf("Invalid TIFF RGB image")
+				}
+				c = color.NRGBA{p[0], p[1], p[2], 255}
+				if alpha && samples > 3 {
+					c.A = p[3]
+				}
+			case 3:
+				if len(colorMap) != 3*256 {
+					return nil, 0, fmt.Errorf("Invalid TIFF color map")
+				}
+				i := int(p[0])
+				c = color.NRGBA{uint8(colorMap[i] >> 8), uint8(colorMap[256+i] >> 8), uint8(colorMap[512+i] >> 8), 255}
+			default:
+				return nil, 0, fmt.Errorf("Unsupported TIFF photometric interpretation %d", photometric)
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	if photometric == 3 {
+		samples = 3
+	}
+	return img, samples, nil
+}
+
+// unpackBits decompresses PackBits data.
+func unpackBits(data []byte) []byte {
+	var out []byte
+	for i := 0; i < len(data); {
+		n := int(int8(data[i]))
+		i++
+		switch {
+		case n >= 0:
+			end := minInt(i+n+1, len(data))
+			out = append(out, data[i:end]...)
+			i = end
+		case n > -128 && i < len(data):
+			for k := 0; k < 1-n; k++ {
+				out = append(out, data[i])
+			}
+			i++
+		}
+	}
+	return out
+}
@@ -0,0 +1,244 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTexturePixels = []color.NRGBA{
+	{255, 0, 0, 255}, {0, 255, 0, 255}, {0, 0, 255, 255},
+	{255, 255, 0, 255}, {0, 255, 255, 255}, {255, 255, 255, 128},
+}
+
+// assertTestTexture checks that img holds the 3x2 testTexturePixels,
+// ignoring alpha if it is not kept.
+func assertTestTexture(t *testing.T, img image.Image, alpha bool) {
+	if !assert.Equal(t, image.Rect(0, 0, 3, 2), img.Bounds()) {
+		return
+	}
+	for i, want := range testTexturePixels {
+		if !alpha {
+			want.A = 255
+		}
+		assert.Equal(t, want, color.NRGBAModel.Convert(img.At(i%3, i/3)), "pixel %d", i)
+	}
+}
+
+// encodeTestTGA encodes testTexturePixels as a 32-bit TGA, stored from the
+// bottom row, with run-length packets if rle is set.
+func encodeTestTGA(rle bool) []byte {
+	header := createTGA(3, 2)[:18]
+	if rle {
+		header[2] = 10
+	}
+	data := append([]byte(nil), header...)
+	for _, row := range [][]color.NRGBA{testTexturePixels[3:], testTexturePixels[:3]} {
+		if rle {
+			// A raw packet of the three pixels of the row.
+			data = append(data, 2)
+		}
+		for _, c := range row {
+			data = append(data, c.B, c.G, c.R, c.A)
+		}
+	}
+	return data
+}
+
+// encodeTestBMP encodes testTexturePixels as a 24-bit BMP.
+func encodeTestBMP() []byte {
+	const stride = 12
+	data := make([]byte, 54+2*stride)
+	copy(data, "BM")
+	binary.LittleEndian.PutUint32(data[2:], uint32(len(data)))
+	binary.LittleEndian.PutUint32(data[10:], 54)
+	binary.LittleEndian.PutUint32(data[14:], 40)
+	binary.LittleEndian.PutUint32(data[18:], 3)
+	binary.LittleEndian.PutUint32(data[22:], 2)
+	binary.LittleEndian.PutUint16(data[26:], 1)
+	binary.LittleEndian.PutUint16(data[28:], 24)
+	for i, c := range testTexturePixels {
+		row := 1 - i/3
+		p := data[54+row*stride+3*(i%3):]
+		p[0], p[1], p[2] = c.B, c.G, c.R
+	}
+	return data
+}
+
+// encodeTestTIFF encodes testTexturePixels as an RGBA TIFF with the byte
+// order, compressed with PackBits if packBits is set.
+func encodeTestTIFF(order binary.ByteOrder, packBits bool) []byte {
+	var pixels []byte
+	for _, c := range testTexturePixels {
+		pixels = append(pixels, c.R, c.G, c.B, c.A)
+	}
+	compression := uint32(1)
+	if packBits {
+		// A single literal run.
+		pixels = append([]byte{byte(len(pixels) - 1)}, pixels...)
+		compression = 32773
+	}
+	type entry struct {
+		tag, kind uint16
+		values    []uint32
+	}
+	entries := []entry{
+		{tiffImageWidth, 4, []uint32{3}},
+		{tiffImageLength, 4, []uint32{2}},
+		{tiffBitsPerSample, 3, []uint32{8, 8, 8, 8}},
+		{tiffCompression, 3, []uint32{compression}},
+		{tiffPhotometric, 3, []uint32{2}},
+		{tiffStripOffsets, 4, []uint32{0}},
+		{tiffSamplesPerPixel, 3, []uint32{4}},
+		{tiffRowsPerStrip, 4, []uint32{2}},
+		{tiffStripByteCounts, 4, []uint32{uint32(len(pixels))}},
+		{tiffExtraSamples, 3, []uint32{2}},
+	}
+	ifdSize := 2 + 12*len(entries) + 4
+	extra := 8 + ifdSize
+	bitsAt := extra
+	pixelsAt := bitsAt + 8
+	entries[5].values[0] = uint32(pixelsAt)
+
+	data := make([]byte, pixelsAt+len(pixels))
+	if order == binary.LittleEndian {
+		copy(data, "II*\x00")
+	} else {
+		copy(data, "MM\x00*")
+	}
+	order.PutUint32(data[4:], 8)
+	order.PutUint16(data[8:], uint16(len(entries)))
+	for i, e := range entries {
+		p := data[10+12*i:]
+		order.PutUint16(p, e.tag)
+		order.PutUint16(p[2:], e.kind)
+		order.PutUint32(p[4:], uint32(len(e.values)))
+		switch {
+		case e.tag == tiffBitsPerSample:
+			order.PutUint32(p[8:], uint32(bitsAt))
+			for k, v := range e.values {
+				order.PutUint16(data[bitsAt+2*k:], uint16(v))
+			}
+		case e.kind == 3:
+			order.PutUint16(p[8:], uint16(e.values[0]))
+		default:
+			order.PutUint32(p[8:], e.values[0])
+		}
+	}
+	copy(data[pixelsAt:], pixels)
+	return data
+}
+
+func TestDecodeTGA_UncompressedAndRLE_DecodePixels(t *testing.T) {
+	for _, rle := range []bool{false, true} {
+		// Arrange
+		data := encodeTestTGA(rle)
+
+		// Act
+		img, err := decodeTexture(data, TextureTGA)
+
+		// Assert
+		assert.NoError(t, err)
+		assertTestTexture(t, img, true)
+	}
+}
+
+func TestDecodeTGA_RunLength_RepeatsPixel(t *testing.T) {
+	// Arrange
+	data := createTGA(3, 1)[:18]
+	data[2], data[17] = 10, 0x28
+	data = append(data, 0x82, 1, 2, 3, 255)
+
+	// Act
+	img, err := decodeTGA(data)
+
+	// Assert
+	assert.NoError(t, err)
+	for x := 0; x < 3; x++ {
+		assert.Equal(t, color.NRGBA{3, 2, 1, 255}, img.At(x, 0))
+	}
+}
+
+func TestDecodeBMP_24Bit_DecodesBottomUpRows(t *testing.T) {
+	// Act
+	img, channels, err := decodeBMP(encodeTestBMP())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, channels)
+	assertTestTexture(t, img, false)
+}
+
+func TestDecodeTIFF_ByteOrdersAndPackBits_DecodePixels(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		for _, packBits := range []bool{false, true} {
+			// Act
+			img, channels, err := decodeTIFF(encodeTestTIFF(order, packBits))
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, 4, channels)
+			assertTestTexture(t, img, true)
+		}
+	}
+}
+
+func TestScene_WriteGLTFWith_ConvertsTextures(t *testing.T) {
+	// Arrange
+	dir, out := t.TempDir(), t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "maps"), 0755))
+	writeTestTextures(t, dir, map[string][]byte{
+		"maps/wall.tga": encodeTestTGA(false),
+		"floor.bmp":     encodeTestBMP(),
+		"roof.png":      encodeTestPNG(image.NewGray(image.Rect(0, 0, 1, 1))),
+	})
+	buffer := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "wall")
+	buffer.F[0].Material = "floor"
+	buffer.F[1].Material = "roof"
+	scene := &Scene{Buffer: buffer, Dir: dir, Materials: map[string]*Material{
+		"wall":  {Name: "wall", Opacity: 1, DiffuseTexture: "maps/wall.tga"},
+		"floor": {Name: "floor", Opacity: 1, DiffuseTexture: "floor.bmp"},
+		"roof":  {Name: "roof", Opacity: 1, DiffuseTexture: "roof.png"},
+	}}
+
+	// Act
+	var buf bytes.Buffer
+	err := scene.WriteGLTFWith(&buf, GLTFOptions{TextureFormat: TextureJPEG, TextureDir: out})
+
+	// Assert
+	assert.NoError(t, err)
+	var doc gltfDocument
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	var uris []string
+	for _, image := range doc.Images {
+		uris = append(uris, image.URI)
+	}
+	assert.ElementsMatch(t, []string{"maps/wall.png", "floor.jpg", "roof.png"}, uris)
+	wall, err := os.ReadFile(filepath.Join(out, "maps", "wall.png"))
+	assert.NoError(t, err)
+	img, err := decodeTexture(wall, TexturePNG)
+	assert.NoError(t, err)
+	assertTestTexture(t, img, true)
+	_, err = os.Stat(filepath.Join(out, "floor.jpg"))
+	assert.NoError(t, err)
+}
+
+func TestScene_WriteGLTFWith_MissingTexture_Fails(t *testing.T) {
+	// Arrange
+	scene := &Scene{Buffer: createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "a"), Dir: t.TempDir(),
+		Materials: map[string]*Material{"a": {Name: "a", DiffuseTexture: "a.tga"}}}
+
+	// Act
+	err := scene.WriteGLTFWith(&bytes.Buffer{}, GLTFOptions{TextureFormat: TexturePNG})
+
+	// Assert
+	assert.True(t, os.IsNotExist(err))
+}
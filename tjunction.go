@@ -0,0 +1,187 @@
+package obj
+
+import (
+	"math"
+	"sort"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+)
+
+// ResolveTJunctions splits the edges of faces that have a vertex of another
+// face lying on them, within tolerance, by inserting that vertex as a new
+// corner. This closes the hairline cracks between adjacent tiles whose
+// borders are not subdivided alike. Normals and texture coordinates of
+// inserted corners are interpolated along the edge. The edges of holes are
+// split like those of the outer boundaries. Buffers with out of
+// range vertex indices are left untouched. It returns the number of corners
+// inserted.
+func (b *ObjBuffer) ResolveTJunctions(tolerance float64) int {
+	for _, f := range b.F {
+		for _, loop := range f.loops() {
+			for _, c := range loop {
+				if c.VertexIndex < 0 || c.VertexIndex >= len(b.V) {
+					return 0
+				}
+			}
+		}
+	}
+	used := make([]bool, len(b.V))
+	edges, length := 0, 0.0
+	for _, f := range b.F {
+		for _, loop := range f.loops() {
+			for j, c := range loop {
+				used[c.VertexIndex] = true
+				p := b.positionD(c.VertexIndex)
+				q := b.positionD(loop[(j+1)%len(loop)].VertexIndex)
+				length += dvec3.Distance(&p, &q)
+				edges++
+			}
+		}
+	}
+	if edges == 0 {
+		return 0
+	}
+
+	// Bucket the vertices in a grid with cells of the average edge length,
+	// so that an edge only tests the vertices around it.
+	cellSize := math.Max(length/float64(edges), tolerance)
+	if cellSize == 0 {
+		return 0
+	}
+	cellOf := func(v float64) int64 { return int64(math.Floor(v / cellSize)) }
+	grid := make(map[[3]int64][]int)
+	for i := range b.V {
+		if used[i] {
+			p := b.positionD(i)
+			key := [3]int64{cellOf(p[0]), cellOf(p[1]), cellOf(p[2])}
+			grid[key] = append(grid[key], i)
+		}
+	}
+
+	type split struct {
+		t      float64
+		vertex int
+	}
+	inserted := 0
+	var splits []split
+	// splitLoop returns the corners of loop with the vertices on its edges
+	// inserted.
+	splitLoop := func(loop []FaceCorner) []FaceCorner {
+		corners := make([]FaceCorner, 0, len(loop))
+		for j, c := range loop {
+			corners = append(corners, c)
+			next := loop[(j+1)%len(loop)]
+			p, q := b.positionD(c.VertexIndex), b.positionD(next.VertexIndex)
+			edge := dvec3.Sub(&q, &p)
+			edgeLength := edge.Length()
+			if edgeLength <= 2*tolerance {
+				continue
+			}
+
+			// The tolerance is at most the size of a cell, so the vertices
+			// close to the edge are in the cells around those it passes
+			// through.
+			splits = splits[:0]
+			visited := make(map[[3]int64]bool)
+			segmentCells(p, q, cellSize, func(cell [3]int64) {
+				for x := cell[0] - 1; x <= cell[0]+1; x++ {
+					for y := cell[1] - 1; y <= cell[1]+1; y++ {
+						for z := cell[2] - 1; z <= cell[2]+1; z++ {
+							key := [3]int64{x, y, z}
+							if visited[key] {
+								continue
+							}
+							visited[key] = true
+							for _, v := range grid[key] {
+								if v == c.VertexIndex || v == next.VertexIndex {
+									continue
+								}
+								r := b.positionD(v)
+								toR := dvec3.Sub(&r, &p)
+								along := dvec3.Dot(&toR, &edge) / edgeLength
+								if along <= tolerance || along >= edgeLength-tolerance {
+									continue
+								}
+								closest := edge.Scaled(along / edgeLength)
+								if dvec3.Distance(&closest, &toR) <= tolerance {
+									splits = append(splits, split{along / edgeLength, v})
+								}
+							}
+						}
+					}
+				}
+			})
+			sort.Slice(splits, func(k, l int) bool {
+				return splits[k].t < splits[l].t || splits[k].t == splits[l].t && splits[k].vertex < splits[l].vertex
+			})
+			for k, s := range splits {
+				if k > 0 && s.t-splits[k-1].t <= tolerance/edgeLength {
+					// Coincident vertices split the edge once.
+					continue
+				}
+				corners = append(corners, b.edgeCorner(c, next, s.vertex))
+				inserted++
+			}
+		}
+		return corners
+	}
+	for i := range b.F {
+		f := &b.F[i]
+		f.Corners = splitLoop(f.Corners)
+		if len(f.Holes) > 0 {
+			holes := make([][]FaceCorner, len(f.Holes))
+			for j, hole := range f.Holes {
+				holes[j] = splitLoop(hole)
+			}
+			f.Holes = holes
+		}
+	}
+	return inserted
+}
+
+// segmentCells calls fn for every cell of a grid with cells of the given
+// size that the segment from p to q passes through, from p to q, walking
+// the grid with a 3D DDA.
+func segmentCells(p, q dvec3.T, cellSize float64, fn func(cell [3]int64)) {
+	var cell, step [3]int64
+	var next, delta [3]float64
+	steps := int64(0)
+	for k := range cell {
+		cell[k] = int64(math.Floor(p[k] / cellSize))
+		last := int64(math.Floor(q[k] / cellSize))
+		d := q[k] - p[k]
+		switch {
+		case d > 0:
+			step[k] = 1
+			next[k] = (float64(cell[k]+1)*cellSize - p[k]) / d
+			delta[k] = cellSize / d
+		case d < 0:
+			step[k] = -1
+			next[k] = (float64(cell[k])*cellSize - p[k]) / d
+			delta[k] = -cellSize / d
+		default:
+			next[k], delta[k] = math.Inf(1), math.Inf(1)
+		}
+		if last > cell[k] {
+			steps += last - cell[k]
+		} else {
+			steps += cell[k] - last
+		}
+	}
+	fn(cell)
+	for ; steps > 0; steps-- {
+		k := 0
+		if next[1] < next[k] {
+			k = 1
+		}
+		if next[2] < next[k] {
+			k = 2
+		}
+		if next[k] > 1 {
+			return
+		}
+		cell[k] += step[k]
+		next[k] += delta[k]
+		fn(cell)
+	}
+}
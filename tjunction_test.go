@@ -0,0 +1,105 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_ResolveTJunctions_SplitsEdgeOfNeighbourTile(t *testing.T) {
+	// Arrange
+	// The left tile has one edge along x = 1, the right tile two.
+	loader := &ObjReader{}
+	err := loader.Read(strings.NewReader("v 0 0 0\nv 1 0 0\nv 1 2 0\nv 1 0 0\nv 1 1.0001 0\nv 1 2 0\nv 2 1 0\n" +
+		"g left\nf 1 2 3\ng right\nf 4 7 5\nf 5 7 6\n"))
+	assert.NoError(t, err)
+
+	// Act
+	inserted := loader.ResolveTJunctions(0.001)
+
+	// Assert
+	assert.Equal(t, 1, inserted)
+	assert.Equal(t, []FaceCorner{{0, -1, -1}, {1, -1, -1}, {4, -1, -1}, {2, -1, -1}}, loader.F[0].Corners)
+	assert.Equal(t, 3, len(loader.F[1].Corners))
+	assert.Equal(t, 3, len(loader.F[2].Corners))
+}
+
+func TestObjBuffer_ResolveTJunctions_ConformingMesh_InsertsNothing(t *testing.T) {
+	// Arrange
+	loader := &ObjReader{}
+	err := loader.Read(strings.NewReader("v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nf 1 2 3\nf 1 3 4\n"))
+	assert.NoError(t, err)
+
+	// Act
+	inserted := loader.ResolveTJunctions(0.001)
+
+	// Assert
+	assert.Equal(t, 0, inserted)
+}
+
+func TestObjBuffer_ResolveTJunctions_BadTrailingIndex_LeavesBufferUntouched(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{
+		V: []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+		F: []Face{{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {98, -1, -1}}}},
+	}
+
+	// Act
+	inserted := buffer.ResolveTJunctions(0.001)
+
+	// Assert
+	assert.Equal(t, 0, inserted)
+	assert.Equal(t, []FaceCorner{{0, -1, -1}, {1, -1, -1}, {98, -1, -1}}, buffer.F[0].Corners)
+}
+
+func TestObjBuffer_ResolveTJunctions_LongDiagonalEdge_SplitsAtVertexOnIt(t *testing.T) {
+	// Arrange
+	// A large triangle whose diagonal passes through a corner of a small
+	// one, far from the ends of the diagonal.
+	buffer := &ObjBuffer{
+		V: []vec3.T{{0, 0, 0}, {1000, 1000, 0}, {0, 1000, 0}, {370, 370, 0}, {371, 370, 0}, {371, 369, 0}},
+		F: []Face{
+			{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {2, -1, -1}}},
+			{Corners: []FaceCorner{{3, -1, -1}, {5, -1, -1}, {4, -1, -1}}},
+		},
+	}
+
+	// Act
+	inserted := buffer.ResolveTJunctions(0.001)
+
+	// Assert
+	assert.Equal(t, 1, inserted)
+	assert.Equal(t, []FaceCorner{{0, -1, -1}, {3, -1, -1}, {1, -1, -1}, {2, -1, -1}}, buffer.F[0].Corners)
+}
+
+func TestSegmentCells_VisitsCellsAlongSegment(t *testing.T) {
+	// Arrange
+	var cells [][3]int64
+
+	// Act
+	segmentCells(dvec3.T{0.5, 0.5, 0.5}, dvec3.T{2.5, 1.5, 0.5}, 1, func(cell [3]int64) {
+		cells = append(cells, cell)
+	})
+
+	// Assert
+	assert.Equal(t, [][3]int64{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {2, 1, 0}}, cells)
+}
+
+func TestObjBuffer_ResolveTJunctions_VertexOnHoleEdge_SplitsHole(t *testing.T) {
+	// Arrange
+	// A triangle inside the hole has a corner on its bottom edge.
+	buffer := createSquareWithHole()
+	buffer.V = append(buffer.V, vec3.T{2, 1, 0}, vec3.T{2, 2, 0})
+	buffer.F = append(buffer.F, Face{Corners: []FaceCorner{{4, -1, -1}, {8, -1, -1}, {9, -1, -1}}})
+
+	// Act
+	inserted := buffer.ResolveTJunctions(0.001)
+
+	// Assert
+	assert.Equal(t, 1, inserted)
+	assert.Equal(t, 4, len(buffer.F[0].Corners))
+	assert.Equal(t, []FaceCorner{{4, -1, -1}, {5, -1, -1}, {6, -1, -1}, {7, -1, -1}, {8, -1, -1}}, buffer.F[0].Holes[0])
+}
@@ -0,0 +1,167 @@
+package obj
+
+import "sort"
+
+// Edge is an undirected edge between two vertices, stored with the smaller
+// vertex index first.
+type Edge [2]int
+
+// NewEdge returns the edge between vertices a and b.
+func NewEdge(a, b int) Edge {
+	if a > b {
+		a, b = b, a
+	}
+	return Edge{a, b}
+}
+
+// Topology holds the adjacency of the faces of a buffer.
+type Topology struct {
+	// VertexFaces lists, for every vertex, the faces using it.
+	VertexFaces [][]int
+	// EdgeFaces lists, for every edge of a face, the faces sharing it.
+	EdgeFaces map[Edge][]int
+	// FaceNeighbors lists, for every face, the faces sharing an edge with
+	// it, in ascending order.
+	FaceNeighbors [][]int
+}
+
+// BuildTopology computes the adjacency of the faces, the edges of their
+// holes included. Corners referencing
+// missing vertices and edges between a vertex and itself are ignored.
+func (b *ObjBuffer) BuildTopology() *Topology {
+	t := &Topology{
+		VertexFaces:   make([][]int, len(b.V)),
+		EdgeFaces:     make(map[Edge][]int),
+		FaceNeighbors: make([][]int, len(b.F)),
+	}
+	for i := range b.F {
+		for _, corners := range b.F[i].loops() {
+			for j, c := range corners {
+				v := c.VertexIndex
+				if v < 0 || v >= len(b.V) {
+					continue
+				}
+				if n := len(t.VertexFaces[v]); n == 0 || t.VertexFaces[v][n-1] != i {
+					t.VertexFaces[v] = append(t.VertexFaces[v], i)
+				}
+				w := corners[(j+1)%len(corners)].VertexIndex
+				if w < 0 || w >= len(b.V) || w == v {
+					continue
+				}
+				e := NewEdge(v, w)
+				t.EdgeFaces[e] = append(t.EdgeFaces[e], i)
+			}
+		}
+	}
+	for _, faces := range t.EdgeFaces {
+		for _, f := range faces {
+			for _, g := range faces {
+				if f != g {
+					t.FaceNeighbors[f] = append(t.FaceNeighbors[f], g)
+				}
+			}
+		}
+	}
+	for i, neighbors := range t.FaceNeighbors {
+		t.FaceNeighbors[i] = uniqueInts(neighbors)
+	}
+	return t
+}
+
+// uniqueInts sorts values and removes duplicates in place.
+func uniqueInts(values []int) []int {
+	if len(values) < 2 {
+		return values
+	}
+	sort.Ints(values)
+	unique := values[:1]
+	for _, v := range values[1:] {
+		if v != unique[len(unique)-1] {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// BoundaryEdges returns the edges used by a single face, sorted.
+func (t *Topology) BoundaryEdges() []Edge {
+	var edges []Edge
+	for e, faces := range t.EdgeFaces {
+		if len(faces) == 1 {
+			edges = append(edges, e)
+		}
+	}
+	sortEdges(edges)
+	return edges
+}
+
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] != edges[j][0] {
+			return edges[i][0] < edges[j][0]
+		}
+		return edges[i][1] < edges[j][1]
+	})
+}
+
+// IsManifold reports whether every edge is shared by at most two faces and
+// the faces around every vertex form a single fan.
+func (t *Topology) IsManifold() bool {
+	for _, faces := range t.EdgeFaces {
+		if len(faces) > 2 {
+			return false
+		}
+	}
+
+	// Union the faces around each vertex that are connected through an
+	// edge incident to the vertex; a vertex is manifold when this leaves a
+	// single set.
+	type vertexFace [2]int
+	parent := make(map[vertexFace]vertexFace)
+	find := func(x vertexFace) vertexFace {
+		for {
+			p, ok := parent[x]
+			if !ok || p == x {
+				return x
+			}
+			if pp, ok := parent[p]; ok {
+				parent[x] = pp
+			}
+			x = p
+		}
+	}
+	for e, faces := range t.EdgeFaces {
+		for _, v := range e {
+			for _, f := range faces[1:] {
+				a, b := find(vertexFace{v, faces[0]}), find(vertexFace{v, f})
+				if a != b {
+					parent[a] = b
+				}
+			}
+		}
+	}
+	for v, faces := range t.VertexFaces {
+		if len(faces) < 2 {
+			continue
+		}
+		root := find(vertexFace{v, faces[0]})
+		for _, f := range faces[1:] {
+			if find(vertexFace{v, f}) != root {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// EulerCharacteristic returns V - E + F, counting only the vertices used by
+// faces.
+func (t *Topology) EulerCharacteristic() int {
+	vertices := 0
+	for _, faces := range t.VertexFaces {
+		if len(faces) > 0 {
+			vertices++
+		}
+	}
+	return vertices - len(t.EdgeFaces) + len(t.FaceNeighbors)
+}
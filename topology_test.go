@@ -0,0 +1,69 @@
+package obj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const tetrahedronObj = "v 0 0 0\nv 1 0 0\nv 0 1 0\nv 0 0 1\n" +
+	"f 1 3 2\nf 1 2 4\nf 2 3 4\nf 3 1 4\n"
+
+func TestObjBuffer_BuildTopology_ClosedMesh(t *testing.T) {
+	// Arrange
+	loader := readTestObj(t, tetrahedronObj, ReadOptions{})
+
+	// Act
+	topo := loader.BuildTopology()
+
+	// Assert
+	assert.Equal(t, 6, len(topo.EdgeFaces))
+	assert.Equal(t, []int{0, 1, 3}, topo.VertexFaces[0])
+	assert.Equal(t, []int{1, 2, 3}, topo.FaceNeighbors[0])
+	assert.Equal(t, []int{0, 1}, topo.EdgeFaces[NewEdge(1, 0)])
+	assert.True(t, topo.IsManifold())
+	assert.Equal(t, 2, topo.EulerCharacteristic())
+	assert.Nil(t, topo.BoundaryEdges())
+}
+
+func TestObjBuffer_BuildTopology_OpenMesh(t *testing.T) {
+	// Arrange
+	loader := readTestObj(t, "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nf 1 2 3\nf 1 3 4\n", ReadOptions{})
+
+	// Act
+	topo := loader.BuildTopology()
+
+	// Assert
+	assert.True(t, topo.IsManifold())
+	assert.Equal(t, 1, topo.EulerCharacteristic())
+	assert.Equal(t, []Edge{{0, 1}, {0, 3}, {1, 2}, {2, 3}}, topo.BoundaryEdges())
+}
+
+func TestTopology_IsManifold_EdgeWithThreeFaces_ReturnsFalse(t *testing.T) {
+	loader := readTestObj(t, "v 0 0 0\nv 1 0 0\nv 0 1 0\nv 0 -1 0\nv 0 0 1\n"+
+		"f 1 2 3\nf 2 1 4\nf 1 2 5\n", ReadOptions{})
+
+	assert.False(t, loader.BuildTopology().IsManifold())
+}
+
+func TestTopology_IsManifold_BowtieVertex_ReturnsFalse(t *testing.T) {
+	loader := readTestObj(t, "v 0 0 0\nv 1 0 0\nv 1 1 0\nv -1 0 0\nv -1 -1 0\n"+
+		"f 1 2 3\nf 1 4 5\n", ReadOptions{})
+
+	assert.False(t, loader.BuildTopology().IsManifold())
+}
+
+func TestObjBuffer_BuildTopology_FaceWithHole_IncludesHoleEdges(t *testing.T) {
+	// Arrange
+	buffer := createSquareWithHole()
+
+	// Act
+	topo := buffer.BuildTopology()
+
+	// Assert
+	assert.Equal(t, 8, len(topo.EdgeFaces))
+	assert.Equal(t, []int{0}, topo.EdgeFaces[NewEdge(4, 5)])
+	assert.Equal(t, []int{0}, topo.VertexFaces[6])
+	assert.True(t, topo.IsManifold())
+	assert.Equal(t, 8, len(topo.BoundaryEdges()))
+}
@@ -0,0 +1,108 @@
+package obj
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MIMETypeKTX2 is the MIME type of KTX2 textures, such as those compressed
+// with Basis Universal.
+const MIMETypeKTX2 = "image/ktx2"
+
+// TextureTranscoder transcodes the textures of a scene exported as glTF,
+// typically to a GPU compressed format for web delivery. The package has
+// no transcoder of its own, so that it does not depend on cgo; wrap an
+// encoder such as the Basis Universal one to use it.
+type TextureTranscoder interface {
+	// Transcode transcodes the texture at path, whose file content is data.
+	Transcode(path string, data []byte) (TranscodedTexture, error)
+}
+
+// TextureTranscoderFunc adapts a function to the TextureTranscoder
+// interface.
+type TextureTranscoderFunc func(path string, data []byte) (TranscodedTexture, error)
+
+// Transcode calls f.
+func (f TextureTranscoderFunc) Transcode(path string, data []byte) (TranscodedTexture, error) {
+	return f(path, data)
+}
+
+// TranscodedTexture is the result of a TextureTranscoder.
+type TranscodedTexture struct {
+	Data []byte
+	// MIMEType is the MIME type of Data, such as MIMETypeKTX2.
+	MIMEType string
+	// Extension is the file extension of Data, such as ".ktx2", which
+	// replaces the one of the texture.
+	Extension string
+}
+
+// transcodedTexture is a texture written by transcodeTextures.
+type transcodedTexture struct {
+	uri      string
+	mimeType string
+}
+
+// transcodeTextures transcodes the textures of the scene with the
+// transcoder of options and writes the results like convertTextures. The
+// converted textures, whose paths uris maps to the converted files, are
+// transcoded from the converted file. It returns the transcoded textures by
+// path.
+func (s *Scene) transcodeTextures(options GLTFOptions, uris map[string]string) (map[string]transcodedTexture, error) {
+	dir := options.TextureDir
+	if dir == "" {
+		dir = s.Dir
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, m := range s.Materials {
+		for _, t := range m.Textures() {
+			if !seen[t] {
+				seen[t] = true
+				paths = append(paths, t)
+			}
+		}
+	}
+	sort.Strings(paths)
+
+	transcoded := make(map[string]transcodedTexture, len(paths))
+	for _, path := range paths {
+		source := s.texturePath(path)
+		if uri, ok := uris[path]; ok {
+			source = uri
+			if !filepath.IsAbs(uri) {
+				source = filepath.Join(dir, filepath.FromSlash(uri))
+			}
+		}
+		data, err := ioutil.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		t, err := options.Transcoder.Transcode(path, data)
+		if err != nil {
+			return nil, fmt.Errorf("Texture %s: %v", path, err)
+		}
+		if t.Extension == "" || t.MIMEType == "" {
+			return nil, fmt.Errorf("Texture %s: transcoder returned no extension or MIME type", path)
+		}
+
+		uri := strings.TrimSuffix(path, filepath.Ext(path)) + t.Extension
+		out := uri
+		if !filepath.IsAbs(uri) {
+			out = filepath.Join(dir, filepath.FromSlash(uri))
+		}
+		if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(out, t.Data, 0644); err != nil {
+			return nil, err
+		}
+		transcoded[path] = transcodedTexture{uri: uri, mimeType: t.MIMEType}
+	}
+	return transcoded, nil
+}
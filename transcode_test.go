@@ -0,0 +1,99 @@
+package obj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScene_WriteGLTFWith_KTX2Transcoder_UsesBasisuExtension(t *testing.T) {
+	// Arrange
+	dir, out := t.TempDir(), t.TempDir()
+	writeTestTextures(t, dir, map[string][]byte{
+		"wall.tga": encodeTestTGA(false),
+		"roof.png": encodeTestPNG(image.NewGray(image.Rect(0, 0, 1, 1))),
+	})
+	buffer := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "wall")
+	buffer.F[0].Material = "roof"
+	scene := &Scene{Buffer: buffer, Dir: dir, Materials: map[string]*Material{
+		"wall": {Name: "wall", Opacity: 1, DiffuseTexture: "wall.tga"},
+		"roof": {Name: "roof", Opacity: 1, DiffuseTexture: "roof.png"},
+	}}
+	var sources []string
+	transcoder := TextureTranscoderFunc(func(path string, data []byte) (TranscodedTexture, error) {
+		format := detectTextureFormat(data, path)
+		sources = append(sources, fmt.Sprintf("%s:%s", path, format))
+		return TranscodedTexture{Data: []byte("KTX2 " + path), MIMEType: MIMETypeKTX2, Extension: ".ktx2"}, nil
+	})
+
+	// Act
+	var buf bytes.Buffer
+	err := scene.WriteGLTFWith(&buf, GLTFOptions{TextureFormat: TexturePNG, TextureDir: out, Transcoder: transcoder})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"roof.png:png", "wall.tga:png"}, sources)
+	var doc gltfDocument
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Contains(t, doc.ExtensionsUsed, "KHR_texture_basisu")
+	var pairs [][2]string
+	for _, texture := range doc.Textures {
+		assert.NotNil(t, texture.Extensions)
+		ktx2 := doc.Images[texture.Extensions.Basisu.Source]
+		assert.Equal(t, MIMETypeKTX2, ktx2.MimeType)
+		pairs = append(pairs, [2]string{doc.Images[texture.Source].URI, ktx2.URI})
+	}
+	assert.ElementsMatch(t, [][2]string{{"wall.png", "wall.ktx2"}, {"roof.png", "roof.ktx2"}}, pairs)
+	data, err := os.ReadFile(filepath.Join(out, "wall.ktx2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "KTX2 wall.tga", string(data))
+}
+
+func TestScene_WriteGLTFWith_WebPTranscoder_ReplacesImage(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeTestTextures(t, dir, map[string][]byte{"roof.png": encodeTestPNG(image.NewGray(image.Rect(0, 0, 1, 1)))})
+	scene := &Scene{Buffer: createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "roof"), Dir: dir,
+		Materials: map[string]*Material{"roof": {Name: "roof", Opacity: 1, DiffuseTexture: "roof.png"}}}
+	transcoder := TextureTranscoderFunc(func(path string, data []byte) (TranscodedTexture, error) {
+		return TranscodedTexture{Data: data, MIMEType: "image/webp", Extension: ".webp"}, nil
+	})
+
+	// Act
+	var buf bytes.Buffer
+	err := scene.WriteGLTFWith(&buf, GLTFOptions{Transcoder: transcoder})
+
+	// Assert
+	assert.NoError(t, err)
+	var doc gltfDocument
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, []gltfImage{{URI: "roof.webp", MimeType: "image/webp"}}, doc.Images)
+	assert.Nil(t, doc.Textures[0].Extensions)
+	assert.NotContains(t, doc.ExtensionsUsed, "KHR_texture_basisu")
+	_, err = os.Stat(filepath.Join(dir, "roof.webp"))
+	assert.NoError(t, err)
+}
+
+func TestScene_WriteGLTFWith_TranscoderError_Fails(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeTestTextures(t, dir, map[string][]byte{"roof.png": encodeTestPNG(image.NewGray(image.Rect(0, 0, 1, 1)))})
+	scene := &Scene{Buffer: createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "roof"), Dir: dir,
+		Materials: map[string]*Material{"roof": {Name: "roof", DiffuseTexture: "roof.png"}}}
+	transcoder := TextureTranscoderFunc(func(path string, data []byte) (TranscodedTexture, error) {
+		return TranscodedTexture{}, fmt.Errorf("encoder unavailable")
+	})
+
+	// Act
+	err := scene.WriteGLTFWith(&bytes.Buffer{}, GLTFOptions{Transcoder: transcoder})
+
+	// Assert
+	assert.EqualError(t, err, "Texture roof.png: encoder unavailable")
+}
@@ -0,0 +1,50 @@
+package obj
+
+import "github.com/flywave/go3d/vec3"
+
+// Triangle is a triangle of a triangulated face.
+type Triangle struct {
+	// V holds the positions of the corners.
+	V       [3]vec3.T
+	Corners [3]FaceCorner
+	// Face is the index of the face the triangle belongs to.
+	Face int
+}
+
+// EachTriangle triangulates the faces one at a time and calls fn for every
+// triangle with its vertex positions, its corners and the index of the face
+// it belongs to. Triangles referencing missing vertices are skipped.
+// Iteration stops when fn returns false.
+func (b *ObjBuffer) EachTriangle(fn func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool) {
+	for i := range b.F {
+		f := &b.F[i]
+		if len(f.Corners) == 3 && len(f.Holes) == 0 {
+			if !b.emitTriangle(fn, f.Corners, i) {
+				return
+			}
+			continue
+		}
+		if len(f.Corners) < 3 {
+			continue
+		}
+		for _, t := range f.Triangulate(b.V) {
+			if !b.emitTriangle(fn, t, i) {
+				return
+			}
+		}
+	}
+}
+
+func (b *ObjBuffer) emitTriangle(fn func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool, t []FaceCorner, faceIdx int) bool {
+	var tri [3]vec3.T
+	var corners [3]FaceCorner
+	for k := 0; k < 3; k++ {
+		vi := t[k].VertexIndex
+		if vi < 0 || vi >= len(b.V) {
+			return true
+		}
+		tri[k] = b.V[vi]
+		corners[k] = t[k]
+	}
+	return fn(tri, corners, faceIdx)
+}
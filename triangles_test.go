@@ -0,0 +1,67 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_EachTriangle_TriangulatesPolygons(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nv 2 0 0\n" +
+		"f 1 2 3 4\nf 2 5 3\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+	quad := append([]FaceCorner(nil), loader.F[0].Corners...)
+
+	// Act
+	var faces []int
+	area := float32(0)
+	loader.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		faces = append(faces, faceIdx)
+		e1 := vec3.Sub(&tri[1], &tri[0])
+		e2 := vec3.Sub(&tri[2], &tri[0])
+		cross := vec3.Cross(&e1, &e2)
+		area += cross.Length() / 2
+		assert.Equal(t, loader.V[corners[0].VertexIndex], tri[0])
+		return true
+	})
+
+	// Assert
+	assert.Equal(t, []int{0, 0, 1}, faces)
+	assert.InDelta(t, 1.5, area, 1e-6)
+	assert.Equal(t, quad, loader.F[0].Corners)
+}
+
+func TestObjBuffer_EachTriangle_StopsWhenCallbackReturnsFalse(t *testing.T) {
+	// Arrange
+	buffer := ObjBuffer{}
+	buffer.V = []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}
+	buffer.F = []Face{createFace("", 0, 1, 2), createFace("", 0, 1, 2), createFace("", 0, 1, 2)}
+
+	// Act
+	count := 0
+	buffer.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		count++
+		return count < 2
+	})
+
+	// Assert
+	assert.Equal(t, 2, count)
+}
+
+func TestObjBuffer_EachTriangle_MissingVertex_SkipsTriangle(t *testing.T) {
+	buffer := ObjBuffer{}
+	buffer.V = []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}
+	buffer.F = []Face{createFace("", 0, 1, 7), createFace("", 0, 1, 2)}
+
+	var faces []int
+	buffer.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		faces = append(faces, faceIdx)
+		return true
+	})
+
+	assert.Equal(t, []int{1}, faces)
+}
@@ -0,0 +1,514 @@
+package obj
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+)
+
+// UVMode selects how GenerateUVs maps the surface to texture space.
+type UVMode int
+
+const (
+	// UVPlanar projects the whole mesh onto the plane facing its average
+	// normal.
+	UVPlanar UVMode = iota
+	// UVBox projects each face along the axis its normal is closest to.
+	// Faces projected along the same axis may overlap in texture space.
+	UVBox
+	// UVLSCM splits the mesh into charts of faces facing roughly the same
+	// direction, flattens each chart with least squares conformal maps and
+	// packs the charts without overlap.
+	UVLSCM
+)
+
+// lscmChartAngle is the cosine of the largest angle between the normal of a
+// face and the normal of the first face of its chart.
+const lscmChartAngle = 0.5
+
+// uvChartPadding is the gap left between packed charts, relative to the size
+// of the atlas.
+const uvChartPadding = 0.01
+
+// GenerateUVs replaces the texture coordinates of the buffer with ones
+// synthesized according to mode. Every face corner, those of holes
+// included, is assigned a texture coordinate in the [0, 1] range; texture
+// coordinates already present are discarded.
+func (b *ObjBuffer) GenerateUVs(mode UVMode) error {
+	var charts []uvChart
+	switch mode {
+	case UVPlanar:
+		charts = b.planarCharts()
+	case UVBox:
+		charts = b.boxCharts()
+	case UVLSCM:
+		charts = b.lscmCharts()
+	default:
+		return fmt.Errorf("Unknown UV mode %d", mode)
+	}
+
+	b.VT = b.VT[:0]
+	for _, c := range charts {
+		first := len(b.VT)
+		b.VT = append(b.VT, c.uvs...)
+		for _, fi := range c.faces {
+			for _, loop := range b.F[fi].loops() {
+				for j := range loop {
+					loop[j].TexcoordIndex = first + c.local[loop[j].VertexIndex]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// uvChart is a set of faces mapped to a connected region of texture space.
+// local maps the vertex indices of the faces to indices into uvs.
+type uvChart struct {
+	faces []int
+	local map[int]int
+	uvs   []vec2.T
+}
+
+// newUVChart returns a chart of faces, projected onto the plane with the
+// given normal.
+func (b *ObjBuffer) newUVChart(faces []int, normal dvec3.T) uvChart {
+	c := uvChart{faces: faces, local: make(map[int]int)}
+	u, v := planeBasis(normal)
+	for _, fi := range faces {
+		for _, loop := range b.F[fi].loops() {
+			for _, corner := range loop {
+				if _, ok := c.local[corner.VertexIndex]; ok {
+					continue
+				}
+				p := b.uvPosition(corner.VertexIndex)
+				c.local[corner.VertexIndex] = len(c.uvs)
+				c.uvs = append(c.uvs, vec2.T{float32(dvec3.Dot(&p, &u)), float32(dvec3.Dot(&p, &v))})
+			}
+		}
+	}
+	return c
+}
+
+func (b *ObjBuffer) planarCharts() []uvChart {
+	faces := make([]int, len(b.F))
+	normal := dvec3.T{}
+	for i := range b.F {
+		faces[i] = i
+		n := b.faceNormal(i)
+		normal.Add(&n)
+	}
+	if normal.Length() == 0 {
+		normal = dvec3.UnitZ
+	}
+	normal.Normalize()
+	charts := []uvChart{b.newUVChart(faces, normal)}
+	fitUVCharts(charts)
+	return charts
+}
+
+func (b *ObjBuffer) boxCharts() []uvChart {
+	var faces [3][]int
+	for i := range b.F {
+		n := b.faceNormal(i)
+		axis := 2
+		if math.Abs(n[0]) >= math.Abs(n[1]) && math.Abs(n[0]) >= math.Abs(n[2]) {
+			axis = 0
+		} else if math.Abs(n[1]) >= math.Abs(n[2]) {
+			axis = 1
+		}
+		faces[axis] = append(faces[axis], i)
+	}
+
+	var charts []uvChart
+	for axis, f := range faces {
+		if len(f) == 0 {
+			continue
+		}
+		normal := dvec3.T{}
+		normal[axis] = 1
+		charts = append(charts, b.newUVChart(f, normal))
+	}
+
+	// All projections share the bounds of the mesh so that the same texel
+	// density is used along every axis.
+	box := uvBounds(charts)
+	scale := uvScale(box)
+	for _, c := range charts {
+		for i := range c.uvs {
+			c.uvs[i] = vec2.T{(c.uvs[i][0] - box.Min[0]) * scale, (c.uvs[i][1] - box.Min[1]) * scale}
+		}
+	}
+	return charts
+}
+
+func (b *ObjBuffer) lscmCharts() []uvChart {
+	normals := make([]dvec3.T, len(b.F))
+	for i := range b.F {
+		normals[i] = b.faceNormal(i)
+	}
+
+	edgeFaces := make(map[Edge][]int)
+	for i := range b.F {
+		for _, corners := range b.F[i].loops() {
+			for j := range corners {
+				e := NewEdge(corners[j].VertexIndex, corners[(j+1)%len(corners)].VertexIndex)
+				edgeFaces[e] = append(edgeFaces[e], i)
+			}
+		}
+	}
+
+	chartOf := make([]int, len(b.F))
+	FillIntSlice(chartOf, -1)
+	var charts []uvChart
+	for seed := range b.F {
+		if chartOf[seed] != -1 {
+			continue
+		}
+		id := len(charts)
+		chartOf[seed] = id
+		faces := []int{seed}
+		for k := 0; k < len(faces); k++ {
+			for _, corners := range b.F[faces[k]].loops() {
+				for j := range corners {
+					e := NewEdge(corners[j].VertexIndex, corners[(j+1)%len(corners)].VertexIndex)
+					for _, fi := range edgeFaces[e] {
+						if chartOf[fi] == -1 && dvec3.Dot(&normals[fi], &normals[seed]) > lscmChartAngle {
+							chartOf[fi] = id
+							faces = append(faces, fi)
+						}
+					}
+				}
+			}
+		}
+		sort.Ints(faces)
+		c := b.newUVChart(faces, normals[seed])
+		b.flattenChart(&c)
+		charts = append(charts, c)
+	}
+	packUVCharts(charts)
+	return charts
+}
+
+// flattenChart replaces the projected texture coordinates of the chart with
+// a least squares conformal map, keeping the projection as the starting
+// point of the solver and as the fallback for degenerate charts.
+func (b *ObjBuffer) flattenChart(c *uvChart) {
+	if len(c.uvs) < 3 {
+		return
+	}
+
+	// Pin the two vertices furthest apart along the longest extent of the
+	// projection, keeping their projected positions.
+	box := uvBounds([]uvChart{*c})
+	axis := 0
+	if box.Max[1]-box.Min[1] > box.Max[0]-box.Min[0] {
+		axis = 1
+	}
+	pin0, pin1 := 0, 0
+	for i, uv := range c.uvs {
+		if uv[axis] < c.uvs[pin0][axis] {
+			pin0 = i
+		}
+		if uv[axis] > c.uvs[pin1][axis] {
+			pin1 = i
+		}
+	}
+	if pin0 == pin1 {
+		return
+	}
+
+	// Unknowns are the u and v of every vertex that is not pinned.
+	column := make([]int, len(c.uvs))
+	n := 0
+	for i := range column {
+		if i == pin0 || i == pin1 {
+			column[i] = -1
+			continue
+		}
+		column[i] = n
+		n += 2
+	}
+	x := make([]float64, n)
+	for i, col := range column {
+		if col >= 0 {
+			x[col] = float64(c.uvs[i][0])
+			x[col+1] = float64(c.uvs[i][1])
+		}
+	}
+
+	var rows []sparseRow
+	inChart := make(map[int]bool, len(c.faces))
+	for _, fi := range c.faces {
+		inChart[fi] = true
+	}
+	b.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		if !inChart[faceIdx] {
+			return true
+		}
+		var p [3]dvec3.T
+		var local [3]int
+		for k := range corners {
+			p[k] = b.uvPosition(corners[k].VertexIndex)
+			local[k] = c.local[corners[k].VertexIndex]
+		}
+		re, im, ok := lscmRows(p, local, column, c.uvs)
+		if ok {
+			rows = append(rows, re, im)
+		}
+		return true
+	})
+
+	if n == 0 || len(rows) == 0 {
+		return
+	}
+	solveLeastSquares(rows, x)
+	for i, col := range column {
+		if col >= 0 {
+			c.uvs[i] = vec2.T{float32(x[col]), float32(x[col+1])}
+		}
+	}
+}
+
+// sparseRow is a row of a sparse linear system.
+type sparseRow struct {
+	cols []int
+	vals []float64
+	rhs  float64
+}
+
+// lscmRows returns the real and imaginary rows of the conformal energy of a
+// triangle. Pinned vertices, marked by a negative column, are moved to the
+// right hand side using their texture coordinates.
+func lscmRows(p [3]dvec3.T, local [3]int, column []int, uvs []vec2.T) (re, im sparseRow, ok bool) {
+	// Express the triangle in a 2D frame of its own plane, counterclockwise.
+	e1 := dvec3.Sub(&p[1], &p[0])
+	e2 := dvec3.Sub(&p[2], &p[0])
+	l1 := e1.Length()
+	cross := dvec3.Cross(&e1, &e2)
+	area2 := cross.Length()
+	if l1 == 0 || area2 == 0 {
+		return re, im, false
+	}
+	xs := [3]float64{0, l1, dvec3.Dot(&e2, &e1) / l1}
+	ys := [3]float64{0, 0, area2 / l1}
+
+	scale := 1 / math.Sqrt(area2)
+	for j := 0; j < 3; j++ {
+		k, l := (j+1)%3, (j+2)%3
+		a := (xs[l] - xs[k]) * scale
+		bb := (ys[l] - ys[k]) * scale
+		// (a + ib)(u + iv) = (au - bv) + i(av + bu)
+		if col := column[local[j]]; col >= 0 {
+			re.cols = append(re.cols, col, col+1)
+			re.vals = append(re.vals, a, -bb)
+			im.cols = append(im.cols, col, col+1)
+			im.vals = append(im.vals, bb, a)
+		} else {
+			u, v := float64(uvs[local[j]][0]), float64(uvs[local[j]][1])
+			re.rhs -= a*u - bb*v
+			im.rhs -= a*v + bb*u
+		}
+	}
+	return re, im, true
+}
+
+// solveLeastSquares minimizes the residual of the rows with the conjugate
+// gradient method on the normal equations, starting from x.
+func solveLeastSquares(rows []sparseRow, x []float64) {
+	r := make([]float64, len(rows))
+	for i := range rows {
+		r[i] = rows[i].rhs - rows[i].dot(x)
+	}
+	s := make([]float64, len(x))
+	transposeMul(rows, r, s)
+	p := append([]float64(nil), s...)
+	q := make([]float64, len(rows))
+	gamma := dotFloats(s, s)
+	tolerance := gamma * 1e-20
+
+	for iter := 0; iter < 4*len(x)+100 && gamma > tolerance; iter++ {
+		for i := range rows {
+			q[i] = rows[i].dot(p)
+		}
+		qq := dotFloats(q, q)
+		if qq == 0 {
+			break
+		}
+		alpha := gamma / qq
+		for i := range x {
+			x[i] += alpha * p[i]
+		}
+		for i := range r {
+			r[i] -= alpha * q[i]
+		}
+		transposeMul(rows, r, s)
+		next := dotFloats(s, s)
+		beta := next / gamma
+		gamma = next
+		for i := range p {
+			p[i] = s[i] + beta*p[i]
+		}
+	}
+}
+
+func (row *sparseRow) dot(x []float64) float64 {
+	sum := 0.0
+	for i, col := range row.cols {
+		sum += row.vals[i] * x[col]
+	}
+	return sum
+}
+
+// transposeMul sets out to the product of the transposed rows with r.
+func transposeMul(rows []sparseRow, r, out []float64) {
+	for i := range out {
+		out[i] = 0
+	}
+	for i := range rows {
+		for j, col := range rows[i].cols {
+			out[col] += rows[i].vals[j] * r[i]
+		}
+	}
+}
+
+func dotFloats(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// packUVCharts places the charts next to each other on shelves, tallest
+// first, and scales the result into the unit square.
+func packUVCharts(charts []uvChart) {
+	if len(charts) == 0 {
+		return
+	}
+	boxes := make([]vec2.Rect, len(charts))
+	area := 0.0
+	widest := float32(0)
+	for i := range charts {
+		boxes[i] = uvBounds(charts[i : i+1])
+		w, h := boxes[i].Max[0]-boxes[i].Min[0], boxes[i].Max[1]-boxes[i].Min[1]
+		area += float64(w * h)
+		if w > widest {
+			widest = w
+		}
+	}
+	order := make([]int, len(charts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		hi := boxes[order[i]].Max[1] - boxes[order[i]].Min[1]
+		hj := boxes[order[j]].Max[1] - boxes[order[j]].Min[1]
+		return hi > hj
+	})
+
+	width := float32(math.Sqrt(area))
+	if width < widest {
+		width = widest
+	}
+	padding := width * uvChartPadding
+	var x, y, shelf float32
+	for _, i := range order {
+		w, h := boxes[i].Max[0]-boxes[i].Min[0], boxes[i].Max[1]-boxes[i].Min[1]
+		if x > 0 && x+w > width {
+			x, y, shelf = 0, y+shelf+padding, 0
+		}
+		for k := range charts[i].uvs {
+			charts[i].uvs[k][0] += x - boxes[i].Min[0]
+			charts[i].uvs[k][1] += y - boxes[i].Min[1]
+		}
+		x += w + padding
+		if h > shelf {
+			shelf = h
+		}
+	}
+	fitUVCharts(charts)
+}
+
+// fitUVCharts scales the charts uniformly into the unit square.
+func fitUVCharts(charts []uvChart) {
+	box := uvBounds(charts)
+	scale := uvScale(box)
+	for _, c := range charts {
+		for i := range c.uvs {
+			c.uvs[i] = vec2.T{(c.uvs[i][0] - box.Min[0]) * scale, (c.uvs[i][1] - box.Min[1]) * scale}
+		}
+	}
+}
+
+func uvBounds(charts []uvChart) vec2.Rect {
+	box := vec2.Rect{Min: vec2.MaxVal, Max: vec2.MinVal}
+	for _, c := range charts {
+		for _, uv := range c.uvs {
+			box.Min = vec2.Min(&box.Min, &uv)
+			box.Max = vec2.Max(&box.Max, &uv)
+		}
+	}
+	return box
+}
+
+func uvScale(box vec2.Rect) float32 {
+	size := box.Max[0] - box.Min[0]
+	if h := box.Max[1] - box.Min[1]; h > size {
+		size = h
+	}
+	if size <= 0 {
+		return 1
+	}
+	return 1 / size
+}
+
+// faceNormal returns the unit normal of face i computed with Newell's
+// method, or the zero vector for degenerate faces.
+func (b *ObjBuffer) faceNormal(i int) dvec3.T {
+	n := b.newellNormal(i)
+	if n.Length() == 0 {
+		return n
+	}
+	return *n.Normalize()
+}
+
+// newellNormal returns the normal of face i computed with Newell's method,
+// whose length is twice the area of the face.
+func (b *ObjBuffer) newellNormal(i int) dvec3.T {
+	var n dvec3.T
+	corners := b.F[i].Corners
+	for j := range corners {
+		p := b.uvPosition(corners[j].VertexIndex)
+		q := b.uvPosition(corners[(j+1)%len(corners)].VertexIndex)
+		n[0] += (p[1] - q[1]) * (p[2] + q[2])
+		n[1] += (p[2] - q[2]) * (p[0] + q[0])
+		n[2] += (p[0] - q[0]) * (p[1] + q[1])
+	}
+	return n
+}
+
+// uvPosition returns vertex i, or the origin when the index is out of range.
+func (b *ObjBuffer) uvPosition(i int) dvec3.T {
+	if i < 0 || i >= len(b.V) {
+		return dvec3.T{}
+	}
+	return b.positionD(i)
+}
+
+// planeBasis returns two unit vectors spanning the plane with the given
+// normal.
+func planeBasis(normal dvec3.T) (u, v dvec3.T) {
+	helper := dvec3.UnitX
+	if math.Abs(normal[0]) > 0.9 {
+		helper = dvec3.UnitY
+	}
+	u = dvec3.Cross(&helper, &normal)
+	u.Normalize()
+	v = dvec3.Cross(&normal, &u)
+	return u, v
+}
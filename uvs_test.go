@@ -0,0 +1,135 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func assertUVsInUnitSquare(t *testing.T, b *ObjBuffer) {
+	for _, f := range b.F {
+		for _, c := range f.Corners {
+			if assert.True(t, c.TexcoordIndex >= 0 && c.TexcoordIndex < len(b.VT)) {
+				uv := b.VT[c.TexcoordIndex]
+				assert.True(t, uv[0] >= -1e-6 && uv[0] <= 1+1e-6 && uv[1] >= -1e-6 && uv[1] <= 1+1e-6, "%v", uv)
+			}
+		}
+	}
+}
+
+func uvDistance(a, b vec2.T) float32 {
+	d := vec2.Sub(&a, &b)
+	return d.Length()
+}
+
+func TestObjBuffer_GenerateUVs_Planar_ProjectsOntoAveragePlane(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 5\nv 2 0 5\nv 2 1 5\nv 0 1 5\nf 1 2 3 4\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+
+	// Act
+	err := loader.GenerateUVs(UVPlanar)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(loader.VT))
+	assertUVsInUnitSquare(t, &loader.ObjBuffer)
+	uv := func(corner int) vec2.T { return loader.VT[loader.F[0].Corners[corner].TexcoordIndex] }
+	assert.InDelta(t, 1, uvDistance(uv(0), uv(1)), 1e-5)
+	assert.InDelta(t, 0.5, uvDistance(uv(1), uv(2)), 1e-5)
+}
+
+func TestObjBuffer_GenerateUVs_Box_AssignsEveryCorner(t *testing.T) {
+	// Arrange
+	cube := createCube(vec3.T{0, 0, 0}, vec3.T{1, 2, 4}, "")
+
+	// Act
+	err := cube.GenerateUVs(UVBox)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 24, len(cube.VT))
+	assertUVsInUnitSquare(t, cube)
+}
+
+func TestObjBuffer_GenerateUVs_LSCM_CubeChartsDoNotOverlap(t *testing.T) {
+	// Arrange
+	cube := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "")
+
+	// Act
+	err := cube.GenerateUVs(UVLSCM)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 24, len(cube.VT))
+	assertUVsInUnitSquare(t, cube)
+	rects := make([]vec2.Rect, len(cube.F))
+	for i, f := range cube.F {
+		rects[i] = vec2.Rect{Min: vec2.MaxVal, Max: vec2.MinVal}
+		for _, c := range f.Corners {
+			uv := cube.VT[c.TexcoordIndex]
+			rects[i].Min = vec2.Min(&rects[i].Min, &uv)
+			rects[i].Max = vec2.Max(&rects[i].Max, &uv)
+		}
+	}
+	for i := range rects {
+		for j := i + 1; j < len(rects); j++ {
+			assert.False(t, rects[i].Intersects(&rects[j]), "charts %d and %d overlap", i, j)
+		}
+	}
+}
+
+func TestObjBuffer_GenerateUVs_LSCM_UnfoldsFoldedStrip(t *testing.T) {
+	// Arrange: two unit squares folded by 30 degrees along a shared edge.
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\n" +
+		"v 1.866025 0 0.5\nv 1.866025 1 0.5\n" +
+		"f 1 2 3 4\nf 2 5 6 3\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+
+	// Act
+	err := loader.GenerateUVs(UVLSCM)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 6, len(loader.VT))
+	uv := func(face, corner int) vec2.T { return loader.VT[loader.F[face].Corners[corner].TexcoordIndex] }
+	p0, p1, p5, p6 := uv(0, 0), uv(0, 1), uv(1, 1), uv(1, 2)
+	length := uvDistance(p0, p5)
+	assert.InDelta(t, 1, length, 1e-4)
+	assert.InDelta(t, length/2, uvDistance(p0, p1), 1e-4)
+	assert.InDelta(t, length/2, uvDistance(p5, p6), 1e-4)
+}
+
+func TestObjBuffer_GenerateUVs_UnknownMode_ReturnsError(t *testing.T) {
+	buffer := ObjBuffer{}
+
+	assert.Error(t, buffer.GenerateUVs(UVMode(42)))
+}
+
+func TestObjBuffer_GenerateUVs_FaceWithHole_AssignsHoleCorners(t *testing.T) {
+	for _, mode := range []UVMode{UVPlanar, UVBox, UVLSCM} {
+		// Arrange
+		buffer := createSquareWithHole()
+
+		// Act
+		err := buffer.GenerateUVs(mode)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 8, len(buffer.VT))
+		for _, loop := range buffer.F[0].loops() {
+			for _, c := range loop {
+				assert.Equal(t, buffer.F[0].Corners[0].TexcoordIndex+c.VertexIndex, c.TexcoordIndex)
+			}
+		}
+		for _, c := range buffer.F[0].Holes[0] {
+			uv := buffer.VT[c.TexcoordIndex]
+			assert.True(t, uv[0] > 0.2 && uv[0] < 0.8 && uv[1] > 0.2 && uv[1] < 0.8, "hole uv %v", uv)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package obj
+
+import (
+	"math"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+)
+
+// vertexCacheSize is the number of vertices OptimizeVertexCache assumes the
+// post-transform cache of the GPU holds.
+const vertexCacheSize = 32
+
+// OptimizeVertexCache reorders the faces so that faces sharing vertices are
+// drawn close to each other, which lets the GPU reuse the vertices it just
+// transformed instead of processing them again. It uses the algorithm of
+// Tom Forsyth, "Linear-Speed Vertex Cache Optimisation", on the corners of
+// the faces, a corner being a distinct combination of vertex, texture
+// coordinate and normal as in index buffers.
+//
+// Faces only move within runs of faces sharing a group, a material and a
+// smoothing group, so that the groups and the written file keep their
+// structure. Faces keep their corners and their metadata.
+func (b *ObjBuffer) OptimizeVertexCache() {
+	groupOf := b.groupOfFaces()
+	faces := make([]Face, 0, len(b.F))
+	for start := 0; start < len(b.F); {
+		end := start + 1
+		for end < len(b.F) && groupOf[end] == groupOf[start] &&
+			b.F[end].Material == b.F[start].Material && b.F[end].SmoothingGroup == b.F[start].SmoothingGroup {
+			end++
+		}
+		for _, i := range forsythOrder(b.F[start:end]) {
+			faces = append(faces, b.F[start+i])
+		}
+		start = end
+	}
+	b.F = faces
+}
+
+// forsythVertexScore returns the score of a vertex at the given position of
+// the cache, or -1 if it is not cached, used by remaining faces not drawn
+// yet. Faces with the highest sum of scores are drawn first.
+func forsythVertexScore(position, remaining int) float64 {
+	if remaining == 0 {
+		return -1
+	}
+	score := 0.0
+	switch {
+	case position < 0:
+	case position < 3:
+		// The vertices of the last face drawn get a fixed score, so that
+		// strips do not favor any direction.
+		score = 0.75
+	default:
+		score = math.Pow(1-float64(position-3)/float64(vertexCacheSize-3), 1.5)
+	}
+	// Favor vertices with few faces left, to finish them and avoid drawing
+	// lone faces at the end.
+	return score + 2*math.Pow(float64(remaining), -0.5)
+}
+
+// forsythOrder returns the order in which to draw the faces.
+func forsythOrder(faces []Face) []int {
+	ids := make(map[FaceCorner]int)
+	corners := make([][]int, len(faces))
+	var facesOf [][]int
+	for f := range faces {
+		for _, loop := range faces[f].loops() {
+			for _, c := range loop {
+				id, ok := ids[c]
+				if !ok {
+					id = len(ids)
+					ids[c] = id
+					facesOf = append(facesOf, nil)
+				}
+				if n := len(facesOf[id]); n > 0 && facesOf[id][n-1] == f {
+					continue
+				}
+				corners[f] = append(corners[f], id)
+				facesOf[id] = append(facesOf[id], f)
+			}
+		}
+	}
+
+	remaining := make([]int, len(facesOf))
+	position := make([]int, len(facesOf))
+	scores := make([]float64, len(facesOf))
+	for v := range facesOf {
+		remaining[v] = len(facesOf[v])
+		position[v] = -1
+		scores[v] = forsythVertexScore(-1, remaining[v])
+	}
+	drawn := make([]bool, len(faces))
+	faceScores := make([]float64, len(faces))
+	for f := range faces {
+		for _, v := range corners[f] {
+			faceScores[f] += scores[v]
+		}
+	}
+
+	order := make([]int, 0, len(faces))
+	var cache []int
+	next := 0
+	best := -1
+	for len(order) < len(faces) {
+		if best == -1 {
+			// Nothing shares a cached vertex: start over from the first face
+			// left.
+			for drawn[next] {
+				next++
+			}
+			best = next
+		}
+		order = append(order, best)
+		drawn[best] = true
+
+		// Move the corners of the face to the front of the cache; the
+		// vertices pushed out of it are rescored too.
+		updated := append([]int(nil), corners[best]...)
+		for _, v := range cache {
+			if !containsInt(corners[best], v) {
+				updated = append(updated, v)
+			}
+		}
+		for _, v := range corners[best] {
+			remaining[v]--
+		}
+		for i, v := range updated {
+			position[v] = i
+			if i >= vertexCacheSize {
+				position[v] = -1
+			}
+		}
+		if len(updated) > vertexCacheSize {
+			cache = updated[:vertexCacheSize]
+		} else {
+			cache = updated
+		}
+
+		best = -1
+		bestScore := 0.0
+		for _, v := range updated {
+			score := forsythVertexScore(position[v], remaining[v])
+			delta := score - scores[v]
+			scores[v] = score
+			for _, f := range facesOf[v] {
+				if drawn[f] {
+					continue
+				}
+				faceScores[f] += delta
+			}
+		}
+		for _, v := range cache {
+			for _, f := range facesOf[v] {
+				if !drawn[f] && (best == -1 || faceScores[f] > bestScore) {
+					best, bestScore = f, faceScores[f]
+				}
+			}
+		}
+	}
+	return order
+}
+
+// containsInt reports whether values holds v.
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// OptimizeVertexFetch reorders the vertices, texture coordinates and
+// normals in the order the faces first reference them, then the lines, so
+// that drawing the faces reads them in sequence. Entries nothing references
+// go last, in their original order. Call it after OptimizeVertexCache,
+// which decides the order of the faces.
+func (b *ObjBuffer) OptimizeVertexFetch() {
+	vertices := make([]int, len(b.V))
+	normals := make([]int, len(b.VN))
+	texcoords := make([]int, len(b.VT))
+	var vertexOrder, normalOrder, texcoordOrder []int
+	FillIntSlice(vertices, -1)
+	FillIntSlice(normals, -1)
+	FillIntSlice(texcoords, -1)
+	use := func(mapping []int, order *[]int, idx int) {
+		if idx >= 0 && idx < len(mapping) && mapping[idx] == -1 {
+			mapping[idx] = len(*order)
+			*order = append(*order, idx)
+		}
+	}
+	for i := range b.F {
+		for _, loop := range b.F[i].loops() {
+			for _, c := range loop {
+				use(vertices, &vertexOrder, c.VertexIndex)
+				use(normals, &normalOrder, c.NormalIndex)
+				use(texcoords, &texcoordOrder, c.TexcoordIndex)
+			}
+		}
+	}
+	for _, l := range b.L {
+		for _, v := range l.Corners {
+			use(vertices, &vertexOrder, v)
+		}
+	}
+	for i := range vertices {
+		use(vertices, &vertexOrder, i)
+	}
+	for i := range normals {
+		use(normals, &normalOrder, i)
+	}
+	for i := range texcoords {
+		use(texcoords, &texcoordOrder, i)
+	}
+
+	remap := func(mapping []int, idx int) int {
+		if idx < 0 || idx >= len(mapping) {
+			return idx
+		}
+		return mapping[idx]
+	}
+	for i := range b.F {
+		for _, loop := range b.F[i].loops() {
+			for j := range loop {
+				loop[j].VertexIndex = remap(vertices, loop[j].VertexIndex)
+				loop[j].NormalIndex = remap(normals, loop[j].NormalIndex)
+				loop[j].TexcoordIndex = remap(texcoords, loop[j].TexcoordIndex)
+			}
+		}
+	}
+	for _, l := range b.L {
+		for j := range l.Corners {
+			l.Corners[j] = remap(vertices, l.Corners[j])
+		}
+	}
+
+	double := b.hasDoublePrecision()
+	colors := b.hasVertexColors()
+	V := make([]vec3.T, len(vertexOrder))
+	var VD []dvec3.T
+	var VC []vec3.T
+	if double {
+		VD = make([]dvec3.T, len(vertexOrder))
+	}
+	if colors {
+		VC = make([]vec3.T, len(vertexOrder))
+	}
+	attributes := make(map[string]AttributeBuffer, len(b.Attributes))
+	for name, a := range b.Attributes {
+		attributes[name] = a.emptyCopy()
+	}
+	for j, i := range vertexOrder {
+		V[j] = b.V[i]
+		if double {
+			VD[j] = b.VD[i]
+		}
+		if colors {
+			VC[j] = b.VC[i]
+		}
+		for name, a := range b.Attributes {
+			attributes[name] = attributes[name].appendVertex(a, i)
+		}
+	}
+	b.V = V
+	if double {
+		b.VD = VD
+	}
+	if colors {
+		b.VC = VC
+	}
+	if b.Attributes != nil {
+		b.Attributes = attributes
+	}
+	VN := make([]vec3.T, len(normalOrder))
+	for j, i := range normalOrder {
+		VN[j] = b.VN[i]
+	}
+	b.VN = VN
+	VT := make([]vec2.T, len(texcoordOrder))
+	for j, i := range texcoordOrder {
+		VT[j] = b.VT[i]
+	}
+	b.VT = VT
+}
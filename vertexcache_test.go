@@ -0,0 +1,116 @@
+package obj
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+// missRatio returns the number of vertices a FIFO cache of the given size
+// misses per triangle when drawing the faces in order.
+func missRatio(faces []Face, size int) float64 {
+	var cache []int
+	misses := 0
+	for _, f := range faces {
+		for _, c := range f.Corners {
+			if containsInt(cache, c.VertexIndex) {
+				continue
+			}
+			misses++
+			cache = append(cache, c.VertexIndex)
+			if len(cache) > size {
+				cache = cache[1:]
+			}
+		}
+	}
+	return float64(misses) / float64(len(faces))
+}
+
+// createShuffledGrid returns a triangulated grid of n x n quads whose
+// triangles are in random order.
+func createShuffledGrid(n int) *ObjBuffer {
+	buffer := createGrid(float32(n), n)
+	buffer.Triangulate()
+	rng := rand.New(rand.NewSource(1))
+	rng.Shuffle(len(buffer.F), func(i, j int) { buffer.F[i], buffer.F[j] = buffer.F[j], buffer.F[i] })
+	return buffer
+}
+
+func TestObjBuffer_OptimizeVertexCache_ShuffledGrid_LowersMisses(t *testing.T) {
+	// Arrange
+	buffer := createShuffledGrid(30)
+	before := missRatio(buffer.F, 16)
+	faces := make(map[[3]int]bool)
+	for _, f := range buffer.F {
+		faces[[3]int{f.Corners[0].VertexIndex, f.Corners[1].VertexIndex, f.Corners[2].VertexIndex}] = true
+	}
+
+	// Act
+	buffer.OptimizeVertexCache()
+
+	// Assert
+	after := missRatio(buffer.F, 16)
+	assert.True(t, before > 2, "shuffled grid misses %v vertices per triangle", before)
+	assert.True(t, after < 0.8, "optimized grid misses %v vertices per triangle", after)
+	assert.Len(t, buffer.F, len(faces))
+	for _, f := range buffer.F {
+		assert.True(t, faces[[3]int{f.Corners[0].VertexIndex, f.Corners[1].VertexIndex, f.Corners[2].VertexIndex}])
+	}
+	assert.Equal(t, len(buffer.F), buffer.G[0].FaceCount)
+}
+
+func TestObjBuffer_OptimizeVertexCache_Materials_StayInRuns(t *testing.T) {
+	// Arrange
+	buffer := createShuffledGrid(4)
+	for i := range buffer.F {
+		if i >= 10 {
+			buffer.F[i].Material = "wood"
+		}
+	}
+	buffer.FaceGroup = faceGroupsOf(buffer.F)
+
+	// Act
+	buffer.OptimizeVertexCache()
+
+	// Assert
+	for i, f := range buffer.F {
+		if i < 10 {
+			assert.Equal(t, "", f.Material)
+		} else {
+			assert.Equal(t, "wood", f.Material)
+		}
+	}
+}
+
+func TestObjBuffer_OptimizeVertexFetch_OrdersByFirstUse(t *testing.T) {
+	// Arrange
+	buffer := createShuffledGrid(3)
+	buffer.V = append(buffer.V, vec3.T{9, 9, 9})
+	buffer.VC = make([]vec3.T, len(buffer.V))
+	for i := range buffer.VC {
+		buffer.VC[i] = buffer.V[i]
+	}
+	var before [][3]vec3.T
+	for _, f := range buffer.F {
+		before = append(before, [3]vec3.T{buffer.V[f.Corners[0].VertexIndex], buffer.V[f.Corners[1].VertexIndex], buffer.V[f.Corners[2].VertexIndex]})
+	}
+
+	// Act
+	buffer.OptimizeVertexFetch()
+
+	// Assert
+	next := 0
+	for i, f := range buffer.F {
+		for k, c := range f.Corners {
+			assert.Equal(t, before[i][k], buffer.V[c.VertexIndex])
+			assert.True(t, c.VertexIndex <= next)
+			if c.VertexIndex == next {
+				next++
+			}
+		}
+	}
+	assert.Equal(t, vec3.T{9, 9, 9}, buffer.V[len(buffer.V)-1])
+	assert.Equal(t, buffer.V, buffer.VC)
+}
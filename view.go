@@ -0,0 +1,141 @@
+package obj
+
+import (
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+)
+
+// MeshView is an immutable snapshot of an ObjBuffer. It holds its own copy of
+// the data together with derived data computed once, such as the bounding
+// box and the vertex to face adjacency, and can be shared between
+// goroutines without locking.
+type MeshView struct {
+	v  []vec3.T
+	vn []vec3.T
+	vt []vec2.T
+	f  []Face
+	g  []Group
+
+	mtl    string
+	bounds vec3.Box
+
+	// vertexFaceOffsets and vertexFaces store the faces around each vertex:
+	// the faces of vertex i are
+	// vertexFaces[vertexFaceOffsets[i]:vertexFaceOffsets[i+1]].
+	vertexFaceOffsets []int
+	vertexFaces       []int
+}
+
+// NewMeshView creates a view of b. Later changes to b do not affect the
+// view.
+func NewMeshView(b *ObjBuffer) *MeshView {
+	m := &MeshView{
+		v:      append([]vec3.T(nil), b.V...),
+		vn:     append([]vec3.T(nil), b.VN...),
+		vt:     append([]vec2.T(nil), b.VT...),
+		f:      cloneFaces(b.F),
+		g:      append([]Group(nil), b.G...),
+		mtl:    b.MTL,
+		bounds: b.BoundingBox(),
+	}
+	m.buildVertexFaces()
+	return m
+}
+
+// cloneFaces returns a copy of faces that shares no corners or metadata
+// with it.
+func cloneFaces(faces []Face) []Face {
+	if faces == nil {
+		return nil
+	}
+	total := 0
+	for i := range faces {
+		total += len(faces[i].Corners)
+	}
+	corners := make([]FaceCorner, total)
+	clone := make([]Face, len(faces))
+	for i, f := range faces {
+		n := copy(corners, f.Corners)
+		clone[i] = Face{Corners: corners[:n:n], Material: f.Material, SmoothingGroup: f.SmoothingGroup, Metadata: cloneMetadata(f.Metadata)}
+		corners = corners[n:]
+		for _, hole := range f.Holes {
+			clone[i].Holes = append(clone[i].Holes, append([]FaceCorner(nil), hole...))
+		}
+	}
+	return clone
+}
+
+func (m *MeshView) buildVertexFaces() {
+	m.vertexFaceOffsets = make([]int, len(m.v)+1)
+	for _, f := range m.f {
+		for _, c := range f.Corners {
+			if c.VertexIndex >= 0 && c.VertexIndex < len(m.v) {
+				m.vertexFaceOffsets[c.VertexIndex+1]++
+			}
+		}
+	}
+	for i := 1; i < len(m.vertexFaceOffsets); i++ {
+		m.vertexFaceOffsets[i] += m.vertexFaceOffsets[i-1]
+	}
+	m.vertexFaces = make([]int, m.vertexFaceOffsets[len(m.v)])
+	next := append([]int(nil), m.vertexFaceOffsets[:len(m.v)]...)
+	for i, f := range m.f {
+		for _, c := range f.Corners {
+			if c.VertexIndex >= 0 && c.VertexIndex < len(m.v) {
+				m.vertexFaces[next[c.VertexIndex]] = i
+				next[c.VertexIndex]++
+			}
+		}
+	}
+}
+
+// MTL returns the material library referenced by the mesh.
+func (m *MeshView) MTL() string { return m.mtl }
+
+// NumVertices returns the number of vertices.
+func (m *MeshView) NumVertices() int { return len(m.v) }
+
+// Vertex returns the position of vertex i.
+func (m *MeshView) Vertex(i int) vec3.T { return m.v[i] }
+
+// NumNormals returns the number of normals.
+func (m *MeshView) NumNormals() int { return len(m.vn) }
+
+// Normal returns normal i.
+func (m *MeshView) Normal(i int) vec3.T { return m.vn[i] }
+
+// NumTexCoords returns the number of texture coordinates.
+func (m *MeshView) NumTexCoords() int { return len(m.vt) }
+
+// TexCoord returns texture coordinate i.
+func (m *MeshView) TexCoord(i int) vec2.T { return m.vt[i] }
+
+// NumFaces returns the number of faces.
+func (m *MeshView) NumFaces() int { return len(m.f) }
+
+// FaceCorners returns a copy of the corners of face i.
+func (m *MeshView) FaceCorners(i int) []FaceCorner {
+	return append([]FaceCorner(nil), m.f[i].Corners...)
+}
+
+// FaceMaterial returns the material of face i.
+func (m *MeshView) FaceMaterial(i int) string { return m.f[i].Material }
+
+// Groups returns a copy of the groups.
+func (m *MeshView) Groups() []Group { return append([]Group(nil), m.g...) }
+
+// BoundingBox returns the bounding box computed when the view was created.
+func (m *MeshView) BoundingBox() vec3.Box { return m.bounds }
+
+// VertexFaces returns the indices of the faces using vertex i. A face using
+// the vertex in several corners is listed once per corner.
+func (m *MeshView) VertexFaces(i int) []int {
+	return append([]int(nil), m.vertexFaces[m.vertexFaceOffsets[i]:m.vertexFaceOffsets[i+1]]...)
+}
+
+// EachTriangle calls fn for every triangle of the mesh like
+// ObjBuffer.EachTriangle.
+func (m *MeshView) EachTriangle(fn func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool) {
+	b := ObjBuffer{V: m.v, F: m.f}
+	b.EachTriangle(fn)
+}
@@ -0,0 +1,86 @@
+package obj
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeshView_IsIndependentOfSourceBuffer(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n")))
+	view := NewMeshView(&loader.ObjBuffer)
+
+	// Act
+	loader.V[0] = vec3.T{9, 9, 9}
+	loader.F[0].Corners[0].VertexIndex = 2
+	corners := view.FaceCorners(0)
+	corners[1].VertexIndex = 0
+
+	// Assert
+	assert.Equal(t, vec3.T{0, 0, 0}, view.Vertex(0))
+	assert.Equal(t, 0, view.FaceCorners(0)[0].VertexIndex)
+	assert.Equal(t, 1, view.FaceCorners(0)[1].VertexIndex)
+	assert.Equal(t, vec3.Box{Min: vec3.T{0, 0, 0}, Max: vec3.T{1, 1, 0}}, view.BoundingBox())
+}
+
+func TestMeshView_VertexFaces_ReturnsAdjacentFaces(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\nv 1 1 0\nf 1 2 3\nf 2 4 3\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+
+	// Act
+	view := NewMeshView(&loader.ObjBuffer)
+
+	// Assert
+	assert.Equal(t, []int{0}, view.VertexFaces(0))
+	assert.Equal(t, []int{0, 1}, view.VertexFaces(1))
+	assert.Equal(t, []int{0, 1}, view.VertexFaces(2))
+	assert.Equal(t, []int{1}, view.VertexFaces(3))
+}
+
+func TestMeshView_ConcurrentReaders(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader("v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nf 1 2 3 4\n")))
+	view := NewMeshView(&loader.ObjBuffer)
+
+	// Act
+	var wg sync.WaitGroup
+	counts := make([]int, 8)
+	for i := range counts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			view.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+				counts[i]++
+				return true
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	// Assert
+	for _, c := range counts {
+		assert.Equal(t, 2, c)
+	}
+}
+
+func TestMeshView_SourceMetadataChanged_ViewKeepsMetadata(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	assert.NoError(t, loader.Read(strings.NewReader("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n#fm building=7\n")))
+	view := NewMeshView(&loader.ObjBuffer)
+
+	// Act
+	loader.F[0].Metadata["building"] = 8
+	loader.F[0].Metadata["floor"] = 1
+
+	// Assert
+	assert.Equal(t, map[string]uint32{"building": 7}, view.f[0].Metadata)
+}
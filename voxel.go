@@ -0,0 +1,235 @@
+package obj
+
+import (
+	"fmt"
+	"math"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec3"
+)
+
+// VoxelMode selects which cells Voxelize marks as occupied.
+type VoxelMode int
+
+const (
+	// VoxelSolid marks the cells touched by the surface and the cells
+	// enclosed by it. The mesh must be closed for the interior to be found.
+	VoxelSolid VoxelMode = iota
+	// VoxelSurface only marks the cells touched by the surface.
+	VoxelSurface
+)
+
+// MaxVoxelCells is the largest number of cells Voxelize allocates, a byte
+// each.
+const MaxVoxelCells = 1 << 28
+
+// VoxelGrid is an occupancy grid of cubic cells. Cell (x, y, z) spans from
+// Origin + (x, y, z) * CellSize to Origin + (x+1, y+1, z+1) * CellSize, in
+// the frame of the buffer it was built from.
+type VoxelGrid struct {
+	Origin   dvec3.T
+	CellSize float64
+	Size     [3]int
+
+	cells []bool
+}
+
+// Voxelize returns the occupancy grid of the buffer with cells of the given
+// size, covering the bounding box of its vertices. Cell sizes that would
+// need more than MaxVoxelCells cells return an error.
+func (b *ObjBuffer) Voxelize(cellSize float64, mode VoxelMode) (*VoxelGrid, error) {
+	if cellSize <= 0 || math.IsNaN(cellSize) || math.IsInf(cellSize, 0) {
+		return nil, fmt.Errorf("Invalid voxel cell size %g", cellSize)
+	}
+	grid := &VoxelGrid{CellSize: cellSize}
+	if len(b.V) == 0 {
+		return grid, nil
+	}
+	box := dvec3.Box{Min: b.positionD(0), Max: b.positionD(0)}
+	for i := range b.V {
+		p := b.positionD(i)
+		box.Extend(&p)
+	}
+	grid.Origin = box.Min
+	// Count in floating point, which does not overflow.
+	total := 1.0
+	for axis := 0; axis < 3; axis++ {
+		n := math.Max(math.Ceil((box.Max[axis]-box.Min[axis])/cellSize), 1)
+		total *= n
+		if total > MaxVoxelCells {
+			return nil, fmt.Errorf("Voxel cell size %g needs more than %d cells", cellSize, MaxVoxelCells)
+		}
+		grid.Size[axis] = int(n)
+	}
+	grid.cells = make([]bool, grid.Size[0]*grid.Size[1]*grid.Size[2])
+
+	b.EachTriangle(func(tri [3]vec3.T, corners [3]FaceCorner, faceIdx int) bool {
+		var t [3]dvec3.T
+		for k, c := range corners {
+			t[k] = b.positionD(c.VertexIndex)
+		}
+		grid.addTriangle(t)
+		return true
+	})
+	if mode == VoxelSolid {
+		grid.fillInterior()
+	}
+	return grid, nil
+}
+
+// At reports whether cell (x, y, z) is occupied. Cells outside the grid are
+// empty.
+func (g *VoxelGrid) At(x, y, z int) bool {
+	if x < 0 || y < 0 || z < 0 || x >= g.Size[0] || y >= g.Size[1] || z >= g.Size[2] {
+		return false
+	}
+	return g.cells[g.index(x, y, z)]
+}
+
+// Count returns the number of occupied cells.
+func (g *VoxelGrid) Count() int {
+	n := 0
+	for _, occupied := range g.cells {
+		if occupied {
+			n++
+		}
+	}
+	return n
+}
+
+// Volume returns the total volume of the occupied cells.
+func (g *VoxelGrid) Volume() float64 {
+	return float64(g.Count()) * g.CellSize * g.CellSize * g.CellSize
+}
+
+// CellCenter returns the center of cell (x, y, z).
+func (g *VoxelGrid) CellCenter(x, y, z int) dvec3.T {
+	return dvec3.T{
+		g.Origin[0] + (float64(x)+0.5)*g.CellSize,
+		g.Origin[1] + (float64(y)+0.5)*g.CellSize,
+		g.Origin[2] + (float64(z)+0.5)*g.CellSize,
+	}
+}
+
+func (g *VoxelGrid) index(x, y, z int) int {
+	return (z*g.Size[1]+y)*g.Size[0] + x
+}
+
+// cellRange returns the range of cells along axis covering [lo, hi].
+func (g *VoxelGrid) cellRange(axis int, lo, hi float64) (int, int) {
+	first := clampInt(int(math.Floor((lo-g.Origin[axis])/g.CellSize)), 0, g.Size[axis]-1)
+	last := clampInt(int(math.Floor((hi-g.Origin[axis])/g.CellSize)), 0, g.Size[axis]-1)
+	return first, last
+}
+
+// addTriangle marks the cells overlapping the triangle.
+func (g *VoxelGrid) addTriangle(t [3]dvec3.T) {
+	var lo, hi [3]int
+	for axis := 0; axis < 3; axis++ {
+		min := math.Min(t[0][axis], math.Min(t[1][axis], t[2][axis]))
+		max := math.Max(t[0][axis], math.Max(t[1][axis], t[2][axis]))
+		lo[axis], hi[axis] = g.cellRange(axis, min, max)
+	}
+	half := dvec3.T{g.CellSize / 2, g.CellSize / 2, g.CellSize / 2}
+	for z := lo[2]; z <= hi[2]; z++ {
+		for y := lo[1]; y <= hi[1]; y++ {
+			for x := lo[0]; x <= hi[0]; x++ {
+				i := g.index(x, y, z)
+				if !g.cells[i] && triangleOverlapsBox(t, g.CellCenter(x, y, z), half) {
+					g.cells[i] = true
+				}
+			}
+		}
+	}
+}
+
+// fillInterior marks the cells that cannot be reached from outside the grid
+// without crossing an occupied cell.
+func (g *VoxelGrid) fillInterior() {
+	outside := make([]bool, len(g.cells))
+	var stack [][3]int
+	push := func(x, y, z int) {
+		if x < 0 || y < 0 || z < 0 || x >= g.Size[0] || y >= g.Size[1] || z >= g.Size[2] {
+			return
+		}
+		i := g.index(x, y, z)
+		if g.cells[i] || outside[i] {
+			return
+		}
+		outside[i] = true
+		stack = append(stack, [3]int{x, y, z})
+	}
+	for z := 0; z < g.Size[2]; z++ {
+		for y := 0; y < g.Size[1]; y++ {
+			for x := 0; x < g.Size[0]; x++ {
+				if x == 0 || y == 0 || z == 0 || x == g.Size[0]-1 || y == g.Size[1]-1 || z == g.Size[2]-1 {
+					push(x, y, z)
+				}
+			}
+		}
+	}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		push(c[0]-1, c[1], c[2])
+		push(c[0]+1, c[1], c[2])
+		push(c[0], c[1]-1, c[2])
+		push(c[0], c[1]+1, c[2])
+		push(c[0], c[1], c[2]-1)
+		push(c[0], c[1], c[2]+1)
+	}
+	for i := range g.cells {
+		if !outside[i] {
+			g.cells[i] = true
+		}
+	}
+}
+
+// triangleOverlapsBox reports whether the triangle intersects the axis
+// aligned box with the given center and half size, using the separating
+// axis test of Akenine-Möller.
+func triangleOverlapsBox(t [3]dvec3.T, center, half dvec3.T) bool {
+	var v [3]dvec3.T
+	for k := range t {
+		v[k] = dvec3.Sub(&t[k], &center)
+	}
+	edges := [3]dvec3.T{
+		dvec3.Sub(&v[1], &v[0]),
+		dvec3.Sub(&v[2], &v[1]),
+		dvec3.Sub(&v[0], &v[2]),
+	}
+
+	// The nine cross products of the box axes with the triangle edges.
+	for _, e := range edges {
+		for axis := 0; axis < 3; axis++ {
+			var unit dvec3.T
+			unit[axis] = 1
+			a := dvec3.Cross(&unit, &e)
+			if separatesOnAxis(v, half, a) {
+				return false
+			}
+		}
+	}
+	// The box normals.
+	for axis := 0; axis < 3; axis++ {
+		min := math.Min(v[0][axis], math.Min(v[1][axis], v[2][axis]))
+		max := math.Max(v[0][axis], math.Max(v[1][axis], v[2][axis]))
+		if min > half[axis] || max < -half[axis] {
+			return false
+		}
+	}
+	// The triangle normal.
+	normal := dvec3.Cross(&edges[0], &edges[1])
+	return !separatesOnAxis(v, half, normal)
+}
+
+// separatesOnAxis reports whether axis separates the triangle v from the box
+// centered at the origin.
+func separatesOnAxis(v [3]dvec3.T, half, axis dvec3.T) bool {
+	if axis.IsZero() {
+		return false
+	}
+	p0, p1, p2 := dvec3.Dot(&v[0], &axis), dvec3.Dot(&v[1], &axis), dvec3.Dot(&v[2], &axis)
+	r := half[0]*math.Abs(axis[0]) + half[1]*math.Abs(axis[1]) + half[2]*math.Abs(axis[2])
+	return math.Min(p0, math.Min(p1, p2)) > r || math.Max(p0, math.Max(p1, p2)) < -r
+}
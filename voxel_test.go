@@ -0,0 +1,81 @@
+package obj
+
+import (
+	"testing"
+
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_Voxelize_Solid_FillsInterior(t *testing.T) {
+	// Arrange
+	cube := createCube(vec3.T{0, 0, 0}, vec3.T{4, 4, 4}, "")
+
+	// Act
+	grid, err := cube.Voxelize(0.5, VoxelSolid)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, [3]int{8, 8, 8}, grid.Size)
+	assert.Equal(t, 512, grid.Count())
+	assert.InDelta(t, 64, grid.Volume(), 1e-9)
+}
+
+func TestObjBuffer_Voxelize_Surface_LeavesInteriorEmpty(t *testing.T) {
+	// Arrange
+	cube := createCube(vec3.T{0, 0, 0}, vec3.T{4, 4, 4}, "")
+
+	// Act
+	grid, err := cube.Voxelize(0.5, VoxelSurface)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 512-6*6*6, grid.Count())
+	assert.True(t, grid.At(0, 3, 3))
+	assert.False(t, grid.At(3, 3, 3))
+	assert.False(t, grid.At(-1, 0, 0))
+	assert.Equal(t, [3]float64{0.25, 1.75, 3.75}, [3]float64(grid.CellCenter(0, 3, 7)))
+}
+
+func TestObjBuffer_Voxelize_DiagonalTriangle_MarksOnlyCrossedCells(t *testing.T) {
+	// Arrange
+	buffer := &ObjBuffer{
+		V: []vec3.T{{0, 0, 0}, {4, 4, 0}, {0, 0, 4}},
+		F: []Face{{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {2, -1, -1}}}},
+	}
+
+	// Act
+	grid, err := buffer.Voxelize(1, VoxelSurface)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, grid.At(0, 0, 0))
+	assert.True(t, grid.At(2, 2, 1))
+	assert.False(t, grid.At(3, 0, 0))
+	assert.False(t, grid.At(0, 3, 0))
+}
+
+func TestObjBuffer_Voxelize_InvalidCellSize_ReturnsError(t *testing.T) {
+	cube := createCube(vec3.T{0, 0, 0}, vec3.T{1, 1, 1}, "")
+
+	_, err := cube.Voxelize(0, VoxelSolid)
+
+	assert.Error(t, err)
+}
+
+func TestObjBuffer_Voxelize_TinyCellSize_ReturnsError(t *testing.T) {
+	// Arrange
+	quad := &ObjBuffer{
+		V: []vec3.T{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}},
+		F: []Face{{Corners: []FaceCorner{{0, -1, -1}, {1, -1, -1}, {2, -1, -1}, {3, -1, -1}}}},
+	}
+
+	// Act
+	grid, err := quad.Voxelize(1e-9, VoxelSurface)
+	_, errLarge := quad.Voxelize(1e-5, VoxelSurface)
+
+	// Assert
+	assert.Nil(t, grid)
+	assert.EqualError(t, err, "Voxel cell size 1e-09 needs more than 268435456 cells")
+	assert.Error(t, errLarge)
+}
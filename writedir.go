@@ -0,0 +1,67 @@
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteGroupsToDir writes the faces of every group name of the buffer to
+// its own OBJ file in dir, which is created if needed, and returns the
+// paths of the files in the order of GroupNames. Each file holds only the
+// elements its faces reference, with indices remapped, and refers to the
+// material library of the buffer, so all files share one MTL. Files are
+// named after their group, with characters that are not portable in file
+// names replaced by underscores.
+func (b *ObjBuffer) WriteGroupsToDir(dir string, options WriteOptions) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var paths []string
+	used := make(map[string]bool)
+	for _, name := range b.GroupNames() {
+		base := sanitizeFileName(name)
+		file := base + ".obj"
+		for i := 2; used[strings.ToLower(file)]; i++ {
+			file = fmt.Sprintf("%s_%d.obj", base, i)
+		}
+		used[strings.ToLower(file)] = true
+
+		path := filepath.Join(dir, file)
+		if err := b.ExtractGroups(name).writeFile(path, options); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// writeFile writes the buffer to the file at path.
+func (b *ObjBuffer) writeFile(path string, options WriteOptions) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	if err = b.WriteWith(w, options); err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// sanitizeFileName replaces the characters of name that are not portable in
+// file names by underscores.
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, name)
+}
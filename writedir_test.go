@@ -0,0 +1,50 @@
+package obj
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_WriteGroupsToDir_WritesOneFilePerGroup(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "mtllib scene.mtl\nv 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nv 2 0 0\nv 2 1 0\n" +
+		"g left\nusemtl red\nf 1 2 3 4\ng right\nusemtl blue\nf 2 5 6 3\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+	dir := filepath.Join(t.TempDir(), "groups")
+
+	// Act
+	paths, err := loader.WriteGroupsToDir(dir, WriteOptions{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "left.obj"), filepath.Join(dir, "right.obj")}, paths)
+	right, err := ReadFile(paths[1], ReadOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "scene.mtl", right.MTL)
+	assert.Len(t, right.V, 4)
+	assert.Equal(t, []string{"right"}, right.GroupNames())
+	assert.Equal(t, "blue", right.F[0].Material)
+	for _, c := range right.F[0].Corners {
+		assert.True(t, c.VertexIndex >= 0 && c.VertexIndex < 4)
+	}
+}
+
+func TestObjBuffer_WriteGroupsToDir_ConflictingNames_NumbersFiles(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 1 1 0\n" +
+		"g a/b\nf 1 2 3\ng a_b\nf 3 2 1\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+	dir := t.TempDir()
+
+	// Act
+	paths, err := loader.WriteGroupsToDir(dir, WriteOptions{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a_b.obj"), filepath.Join(dir, "a_b_2.obj")}, paths)
+}
@@ -3,27 +3,82 @@ package obj
 import (
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 
+	dvec3 "github.com/flywave/go3d/float64/vec3"
 	"github.com/flywave/go3d/vec2"
 	"github.com/flywave/go3d/vec3"
 )
 
 func (b *ObjBuffer) Write(w io.Writer) error {
-	var err error
-	_, err = io.WriteString(w,
-		fmt.Sprintf("# Exported using RenderDB\n"+
-			"# %d vertices, %d normals, %d faces\n",
-			len(b.V), len(b.VN), len(b.F)))
+	return b.WriteWith(w, WriteOptions{})
+}
+
+// WriteWith writes the buffer like Write, using the given options.
+func (b *ObjBuffer) WriteWith(w io.Writer, options WriteOptions) error {
+	return b.writeWith(w, options, nil)
+}
+
+// writeWith writes the buffer like WriteWith, with an "o" statement before
+// the first group of every object.
+func (b *ObjBuffer) writeWith(w io.Writer, options WriteOptions, objects []objectStart) (err error) {
+	metrics := startMetrics(options.Metrics, "write")
+	if metrics != nil {
+		counting := &countingWriter{w: w}
+		w = counting
+		defer func() { metrics.finish(counting.n, err) }()
+	}
+	if options.Lossless {
+		err = b.writeStatements(w)
+		metrics.endStage("statements")
+		return err
+	}
+
+	if options.PreserveFaces {
+		if err := b.validateFaces(); err != nil {
+			return err
+		}
+	}
+
+	if options.Header != "" {
+		err = writeComments(w, strings.Split(options.Header, "\n"))
+	} else if !options.OmitBanner {
+		generator := options.Generator
+		if generator == "" {
+			generator = DefaultGenerator
+		}
+		_, err = io.WriteString(w,
+			fmt.Sprintf("# Exported using %s\n"+
+				"# %d vertices, %d normals, %d texcoords, %d faces, %d lines\n",
+				generator, len(b.V), len(b.VN), len(b.VT), len(b.F), len(b.L)))
+	}
 	if err != nil {
 		return err
 	}
+	for _, c := range b.Comments {
+		if err = writeComments(w, []string{c.Text}); err != nil {
+			return err
+		}
+	}
+	if options.Offset == OffsetComment && !b.Offset.IsZero() {
+		_, err = io.WriteString(w,
+			fmt.Sprintf("# offset %g %g %g\n", b.Offset[0], b.Offset[1], b.Offset[2]))
+		if err != nil {
+			return err
+		}
+	}
+	if err = b.writeAttributeHeader(w); err != nil {
+		return err
+	}
 	if b.MTL != "" {
 		_, err = io.WriteString(w, fmt.Sprintf("mtllib %s\n", b.MTL))
 		if err != nil {
 			return err
 		}
 	}
-	if err = b.writeVertices(w); err != nil {
+	metrics.endStage("header")
+	if err = b.writeVertices(w, options); err != nil {
 		return err
 	}
 	if err = b.writeNormals(w); err != nil {
@@ -32,17 +87,70 @@ func (b *ObjBuffer) Write(w io.Writer) error {
 	if err = b.writeTexcoords(w); err != nil {
 		return err
 	}
+	metrics.endStage("elements")
+	var relative *elementCounts
+	if options.RelativeIndices {
+		counts := b.counts()
+		relative = &counts
+	}
+	materials := b.newMaterialTracker()
 	for _, g := range b.G {
-		if err = b.writeGroup(w, g); err != nil {
+		for len(objects) > 0 && objects[0].FirstFaceIndex <= g.FirstFaceIndex {
+			if _, err = io.WriteString(w, fmt.Sprintf("o %s\n", objects[0].Name)); err != nil {
+				return err
+			}
+			objects = objects[1:]
+		}
+		if err = b.writeGroup(w, g, materials, relative); err != nil {
 			return err
 		}
 	}
+	for _, l := range b.L {
+		if err = writeLine(w, l, relative); err != nil {
+			return err
+		}
+	}
+	metrics.endStage("faces")
+	return nil
+}
 
+func (b *ObjBuffer) writeVertices(w io.Writer, options WriteOptions) error {
+	var offset *dvec3.T
+	if options.Offset == OffsetApply && !b.Offset.IsZero() {
+		offset = &b.Offset
+	}
+	for i := range b.V {
+		if _, err := io.WriteString(w, b.formatVertex(i, offset)+"\n"); err != nil {
+			return err
+		}
+		if attributes := b.formatAttributes(i); attributes != "" {
+			if _, err := io.WriteString(w, attributes+"\n"); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
-func (b *ObjBuffer) writeVertices(w io.Writer) error {
-	return writeVectors(w, "v %g %g %g\n", b.V)
+// formatVertex returns the statement declaring vertex i, moved by offset if
+// it is not nil, including its color if the buffer has vertex colors.
+func (b *ObjBuffer) formatVertex(i int, offset *dvec3.T) string {
+	var s string
+	if offset != nil || b.hasDoublePrecision() {
+		v := b.positionD(i)
+		if offset != nil {
+			v.Add(offset)
+		}
+		s = fmt.Sprintf("v %g %g %g", v[0], v[1], v[2])
+	} else {
+		v := b.V[i]
+		s = fmt.Sprintf("v %g %g %g", v[0], v[1], v[2])
+	}
+	if b.hasVertexColors() {
+		c := b.VC[i]
+		s += fmt.Sprintf(" %g %g %g", c[0], c[1], c[2])
+	}
+	return s
 }
 
 func (b *ObjBuffer) writeNormals(w io.Writer) error {
@@ -53,7 +161,56 @@ func (b *ObjBuffer) writeTexcoords(w io.Writer) error {
 	return writeVectors2(w, "vt %g %g\n", b.VT)
 }
 
-func writeFace(w io.Writer, f face) error {
+func writeComments(w io.Writer, lines []string) error {
+	for _, line := range lines {
+		var err error
+		if line == "" {
+			_, err = io.WriteString(w, "#\n")
+		} else {
+			_, err = io.WriteString(w, fmt.Sprintf("# %s\n", line))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateFaces checks that the faces written by the groups can be written
+// as they are, for WriteOptions.PreserveFaces.
+func (b *ObjBuffer) validateFaces() error {
+	for _, g := range b.G {
+		for i := g.FirstFaceIndex; i < g.FirstFaceIndex+g.FaceCount; i++ {
+			f := &b.F[i]
+			if len(f.Corners) < 3 {
+				return badStatement(ErrBadFace, "Face %d has %d corners", i+1, len(f.Corners))
+			}
+			if len(f.Holes) > 0 {
+				return badStatement(ErrBadFace, "Face %d has holes", i+1)
+			}
+			for _, c := range f.Corners {
+				if c.VertexIndex < 0 || c.VertexIndex >= len(b.V) {
+					return badStatement(ErrBadIndex, "Face %d references vertex %d of %d", i+1, c.VertexIndex+1, len(b.V))
+				}
+				if c.TexcoordIndex < -1 || c.TexcoordIndex >= len(b.VT) {
+					return badStatement(ErrBadIndex, "Face %d references texture coordinate %d of %d", i+1, c.TexcoordIndex+1, len(b.VT))
+				}
+				if c.NormalIndex < -1 || c.NormalIndex >= len(b.VN) {
+					return badStatement(ErrBadIndex, "Face %d references normal %d of %d", i+1, c.NormalIndex+1, len(b.VN))
+				}
+				if (c.TexcoordIndex == -1) != (f.Corners[0].TexcoordIndex == -1) ||
+					(c.NormalIndex == -1) != (f.Corners[0].NormalIndex == -1) {
+					return badStatement(ErrBadFace, "Face %d mixes corner formats", i+1)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeFace writes face f. Its references are written relative to the
+// elements counted by relative if it is not nil, and absolute otherwise.
+func writeFace(w io.Writer, f Face, relative *elementCounts) error {
 	var err error
 
 	_, err = io.WriteString(w, "f")
@@ -61,20 +218,26 @@ func writeFace(w io.Writer, f face) error {
 		return err
 	}
 
+	var counts elementCounts
+	if relative != nil {
+		counts = *relative
+	}
 	for _, c := range f.Corners {
+		v := reference(c.VertexIndex, counts.v, relative != nil)
 		if c.NormalIndex != -1 {
+			n := reference(c.NormalIndex, counts.vn, relative != nil)
 			if c.TexcoordIndex != -1 {
 				_, err = io.WriteString(w,
-					fmt.Sprintf(" %d/%d/%d", c.VertexIndex+1, c.TexcoordIndex+1, c.NormalIndex+1))
+					fmt.Sprintf(" %d/%d/%d", v, reference(c.TexcoordIndex, counts.vt, relative != nil), n))
 			} else {
 				_, err = io.WriteString(w,
-					fmt.Sprintf(" %d//%d", c.VertexIndex+1, c.NormalIndex+1))
+					fmt.Sprintf(" %d//%d", v, n))
 			}
 		} else if c.TexcoordIndex != -1 {
 			_, err = io.WriteString(w,
-				fmt.Sprintf(" %d/%d", c.VertexIndex+1, c.TexcoordIndex+1))
+				fmt.Sprintf(" %d/%d", v, reference(c.TexcoordIndex, counts.vt, relative != nil)))
 		} else {
-			_, err = io.WriteString(w, fmt.Sprintf(" %d", c.VertexIndex+1))
+			_, err = io.WriteString(w, fmt.Sprintf(" %d", v))
 		}
 		if err != nil {
 			return err
@@ -84,6 +247,38 @@ func writeFace(w io.Writer, f face) error {
 	return err
 }
 
+// writeLine writes line l, like writeFace.
+func writeLine(w io.Writer, l line, relative *elementCounts) error {
+	var err error
+
+	_, err = io.WriteString(w, "l")
+	if err != nil {
+		return err
+	}
+	vertices := 0
+	if relative != nil {
+		vertices = relative.v
+	}
+	for _, c := range l.Corners {
+		_, err = io.WriteString(w, fmt.Sprintf(" %d", reference(c, vertices, relative != nil)))
+		if err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// reference returns the reference to the 0-based element index as written
+// to a file: 1-based, or negative relative to the count elements written
+// so far.
+func reference(index, count int, relative bool) int {
+	if relative {
+		return index - count
+	}
+	return index + 1
+}
+
 func writeVectors(w io.Writer, format string, vectors []vec3.T) error {
 	for _, v := range vectors {
 		_, err := io.WriteString(w, fmt.Sprintf(format, v[0], v[1], v[2]))
@@ -104,17 +299,115 @@ func writeVectors2(w io.Writer, format string, vectors []vec2.T) error {
 	return nil
 }
 
-func (b *ObjBuffer) writeGroup(w io.Writer, g group) error {
+func (b *ObjBuffer) writeGroup(w io.Writer, g Group, materials *materialTracker, relative *elementCounts) error {
 	var err error
 	_, err = io.WriteString(w, fmt.Sprintf("g %s\n", g.Name))
 	if err != nil {
 		return err
 	}
 	for i := g.FirstFaceIndex; i < g.FirstFaceIndex+g.FaceCount; i++ {
-		if err = writeFace(w, b.F[i]); err != nil {
+		if material, changed := materials.switchTo(i); changed {
+			if err = writeUseMaterial(w, material); err != nil {
+				return err
+			}
+		}
+		if group, changed := materials.switchSmoothingGroup(i); changed {
+			if err = writeSmoothingGroup(w, group); err != nil {
+				return err
+			}
+		}
+		f := b.F[i]
+		if len(f.Holes) > 0 {
+			// OBJ has no holes, write them bridged to the outer loop.
+			f.Corners = f.Outline(b.V)
+		}
+		if err = writeFace(w, f, relative); err != nil {
+			return err
+		}
+		if err = writeFaceMetadata(w, b.F[i]); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// materialTracker follows the material and the smoothing group of the faces
+// being written, so that usemtl and s are only written when they change.
+type materialTracker struct {
+	buffer *ObjBuffer
+	// useFaceGroups is set when the face groups cover all faces in order,
+	// in which case they are used instead of the materials of the faces.
+	useFaceGroups bool
+	current       string
+	smoothing     uint32
+}
+
+func (b *ObjBuffer) newMaterialTracker() *materialTracker {
+	t := &materialTracker{buffer: b, useFaceGroups: len(b.FaceGroup) > 0}
+	next := 0
+	for _, fg := range b.FaceGroup {
+		if fg.Offset != next || fg.Size < 0 {
+			t.useFaceGroups = false
+			break
+		}
+		next += fg.Size
+	}
+	if next != len(b.F) {
+		t.useFaceGroups = false
+	}
+	return t
+}
+
+// switchTo returns the material of face i and whether it differs from the
+// material of the previously written face. Faces without a material
+// following one with a material are a change, which resets the material.
+func (t *materialTracker) switchTo(i int) (string, bool) {
+	material := t.buffer.F[i].Material
+	if t.useFaceGroups {
+		groups := t.buffer.FaceGroup
+		j := sort.Search(len(groups), func(j int) bool {
+			return groups[j].Offset+groups[j].Size > i
+		})
+		material = groups[j].Material
+	}
+	if material == t.current {
+		return material, false
+	}
+	t.current = material
+	return material, true
+}
+
+// switchSmoothingGroup returns the smoothing group of face i and whether it
+// differs from the one of the previously written face.
+func (t *materialTracker) switchSmoothingGroup(i int) (uint32, bool) {
+	group := t.buffer.F[i].SmoothingGroup
+	if group == t.smoothing {
+		return group, false
+	}
+	t.smoothing = group
+	return group, true
+}
+
+// writeUseMaterial writes the usemtl statement selecting material, or
+// resetting it without a name when material is empty.
+func writeUseMaterial(w io.Writer, material string) error {
+	var err error
+	if material == "" {
+		_, err = io.WriteString(w, "usemtl\n")
+	} else {
+		_, err = io.WriteString(w, fmt.Sprintf("usemtl %s\n", material))
+	}
+	return err
+}
+
+// writeSmoothingGroup writes the s statement selecting group.
+func writeSmoothingGroup(w io.Writer, group uint32) error {
+	var err error
+	if group == 0 {
+		_, err = io.WriteString(w, "s off\n")
+	} else {
+		_, err = io.WriteString(w, fmt.Sprintf("s %d\n", group))
+	}
+	return err
+}
@@ -0,0 +1,262 @@
+package obj
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	dvec3 "github.com/flywave/go3d/float64/vec3"
+	"github.com/flywave/go3d/vec2"
+	"github.com/flywave/go3d/vec3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjBuffer_Write_DoublePrecision_RoundTripsCoordinates(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	loader.SetOptions(ReadOptions{DoublePrecision: true})
+	err := loader.Read(strings.NewReader("v 500000.123 5400000.456 12.5\n"))
+	assert.NoError(t, err)
+
+	// Act
+	var out bytes.Buffer
+	err = loader.Write(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "v 500000.123 5.400000456e+06 12.5\n")
+}
+
+func TestObjBuffer_WriteWith_OffsetComment_WritesHeader(t *testing.T) {
+	// Arrange
+	buffer := ObjBuffer{}
+	buffer.V = []vec3.T{{1, 2, 3}}
+	buffer.Offset = dvec3.T{500000, 5400000, 0}
+
+	// Act
+	var out bytes.Buffer
+	err := buffer.WriteWith(&out, WriteOptions{Offset: OffsetComment})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "# offset 500000 5.4e+06 0\n")
+	assert.Contains(t, out.String(), "v 1 2 3\n")
+}
+
+func TestObjBuffer_WriteWith_OffsetApply_AddsOffset(t *testing.T) {
+	// Arrange
+	buffer := ObjBuffer{}
+	buffer.V = []vec3.T{{1, 2, 3}}
+	buffer.Offset = dvec3.T{500000, 100, 0}
+
+	// Act
+	var out bytes.Buffer
+	err := buffer.WriteWith(&out, WriteOptions{Offset: OffsetApply})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotContains(t, out.String(), "# offset")
+	assert.Contains(t, out.String(), "v 500001 102 3\n")
+}
+
+func TestObjBuffer_Write_VertexColors_WritesColors(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	err := loader.Read(strings.NewReader("v 0.1 0 0 1 0 0\nv 0 1 0\n"))
+	assert.NoError(t, err)
+
+	// Act
+	var out bytes.Buffer
+	err = loader.Write(&out)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "v 0.1 0 0 1 0 0\nv 0 1 0 1 1 1\n")
+}
+
+func TestObjBuffer_WriteWith_Header_ReplacesBanner(t *testing.T) {
+	// Arrange
+	buffer := ObjBuffer{}
+	buffer.Comments = []Comment{{Line: 1, Text: "CRS: EPSG:32633"}}
+
+	// Act
+	var out bytes.Buffer
+	err := buffer.WriteWith(&out, WriteOptions{Header: "City model\n\nv2"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "# City model\n#\n# v2\n# CRS: EPSG:32633\n", out.String())
+}
+
+func TestObjBuffer_WriteWith_Generator_BrandsBannerWithCounts(t *testing.T) {
+	// Arrange
+	buffer := ObjBuffer{}
+	buffer.V = []vec3.T{{0, 0, 0}, {1, 0, 0}}
+	buffer.VT = []vec2.T{{0, 0}}
+	buffer.L = []line{{Corners: []int{0, 1}}}
+
+	// Act
+	var out bytes.Buffer
+	err := buffer.WriteWith(&out, WriteOptions{Generator: "TileForge 2.1"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out.String(),
+		"# Exported using TileForge 2.1\n"+
+			"# 2 vertices, 0 normals, 1 texcoords, 0 faces, 1 lines\n"))
+	assert.Contains(t, out.String(), "l 1 2\n")
+}
+
+func TestObjBuffer_WriteWith_OmitBanner_WritesNoBanner(t *testing.T) {
+	buffer := ObjBuffer{}
+	buffer.V = []vec3.T{{1, 2, 3}}
+
+	var out bytes.Buffer
+	err := buffer.WriteWith(&out, WriteOptions{OmitBanner: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "v 1 2 3\n", out.String())
+}
+
+func TestObjBuffer_Write_FaceGroups_WritesUsemtlOnMaterialChange(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\n" +
+		"g a\nusemtl red\nf 1 2 3\nf 1 2 3\nusemtl blue\nf 1 2 3\n" +
+		"g b\nf 1 2 3\nusemtl red\nf 1 2 3\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+
+	// Act
+	var out bytes.Buffer
+	err := loader.WriteWith(&out, WriteOptions{OmitBanner: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "v 0 0 0\nv 1 0 0\nv 0 1 0\n"+
+		"g a\nusemtl red\nf 1 2 3\nf 1 2 3\nusemtl blue\nf 1 2 3\n"+
+		"g b\nf 1 2 3\nusemtl red\nf 1 2 3\n", out.String())
+}
+
+func TestObjBuffer_Write_NoFaceGroups_UsesFaceMaterials(t *testing.T) {
+	// Arrange
+	buffer := ObjBuffer{}
+	buffer.V = []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}
+	buffer.F = []Face{createFace("red", 0, 1, 2), createFace("red", 0, 1, 2), createFace("blue", 0, 1, 2)}
+	buffer.G = []Group{{Name: "all", FirstFaceIndex: 0, FaceCount: 3}}
+
+	// Act
+	var out bytes.Buffer
+	err := buffer.WriteWith(&out, WriteOptions{OmitBanner: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "g all\nusemtl red\nf 1/1/1 2/1/2 3/1/3\nf 1/1/1 2/1/2 3/1/3\nusemtl blue\n")
+}
+
+func TestObjBuffer_WriteWith_RelativeIndices_WritesNegativeIndices(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\nv 1 1 0\nvt 0 0\nvt 1 1\nvn 0 0 1\n" +
+		"g quad\nf 1/1/1 2/2/1 3/1/1\nf 2//1 4//1 3//1\nl 1 4\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+
+	// Act
+	var out bytes.Buffer
+	err := loader.WriteWith(&out, WriteOptions{OmitBanner: true, RelativeIndices: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "g quad\nf -4/-2/-1 -3/-1/-1 -2/-2/-1\nf -3//-1 -1//-1 -2//-1\nl -4 -1\n")
+
+	reread := ObjReader{}
+	assert.NoError(t, reread.Read(strings.NewReader(out.String())))
+	assert.Equal(t, loader.F, reread.F)
+	assert.Equal(t, loader.L, reread.L)
+}
+
+func TestObjBuffer_Write_SmoothingGroups_WritesChanges(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 0 1 0\ng a\ns 1\nf 1 2 3\nf 3 2 1\ns off\nf 1 3 2\ns 2\nf 2 1 3\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+	var out bytes.Buffer
+
+	// Act
+	err := loader.WriteWith(&out, WriteOptions{OmitBanner: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{1, 1, 0, 2}, []uint32{
+		loader.F[0].SmoothingGroup, loader.F[1].SmoothingGroup, loader.F[2].SmoothingGroup, loader.F[3].SmoothingGroup})
+	assert.Contains(t, out.String(), "g a\ns 1\nf 1 2 3\nf 3 2 1\ns off\nf 1 3 2\ns 2\nf 2 1 3\n")
+}
+
+func TestObjBuffer_WriteWith_PreserveFaces_KeepsQuadsAndNgons(t *testing.T) {
+	// Arrange
+	loader := ObjReader{}
+	input := "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nv 0.5 1.5 0\nvn 0 0 1\n" +
+		"g quads\nf 1//1 2//1 3//1 4//1\nf 1 2 3 5 4\n"
+	assert.NoError(t, loader.Read(strings.NewReader(input)))
+	var out bytes.Buffer
+
+	// Act
+	err := loader.WriteWith(&out, WriteOptions{OmitBanner: true, PreserveFaces: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "g quads\nf 1//1 2//1 3//1 4//1\nf 1 2 3 5 4\n")
+}
+
+func TestObjBuffer_WriteWith_PreserveFacesInconsistent_Fails(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		edit func(b *ObjBuffer)
+		kind error
+	}{
+		{"vertex", func(b *ObjBuffer) { b.F[0].Corners[2].VertexIndex = 9 }, ErrBadIndex},
+		{"normal", func(b *ObjBuffer) { b.F[0].Corners[2].NormalIndex = 1 }, ErrBadIndex},
+		{"mixed", func(b *ObjBuffer) { b.F[0].Corners[2].NormalIndex = -1 }, ErrBadFace},
+		{"corners", func(b *ObjBuffer) { b.F[0].Corners = b.F[0].Corners[:2] }, ErrBadFace},
+		{"holes", func(b *ObjBuffer) { b.F[0].Holes = [][]FaceCorner{b.F[0].Corners} }, ErrBadFace},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			loader := ObjReader{}
+			input := "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nvn 0 0 1\ng quads\nf 1//1 2//1 3//1 4//1\n"
+			assert.NoError(t, loader.Read(strings.NewReader(input)))
+			tc.edit(&loader.ObjBuffer)
+			var out bytes.Buffer
+
+			// Act
+			err := loader.WriteWith(&out, WriteOptions{PreserveFaces: true})
+
+			// Assert
+			assert.True(t, errors.Is(err, tc.kind), "got %v", err)
+			assert.Empty(t, out.String())
+		})
+	}
+}
+
+func TestObjBuffer_Write_MaterialThenNone_ResetsMaterialOnRoundTrip(t *testing.T) {
+	// Arrange
+	buffer := ObjBuffer{}
+	buffer.V = []vec3.T{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}
+	buffer.F = []Face{createFace("red", 0, 1, 2), createFace("", 0, 1, 2)}
+	buffer.G = []Group{{Name: "all", FirstFaceIndex: 0, FaceCount: 2}}
+
+	// Act
+	var out bytes.Buffer
+	err := buffer.WriteWith(&out, WriteOptions{OmitBanner: true})
+	loader := ObjReader{}
+	readErr := loader.Read(strings.NewReader(out.String()))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "usemtl red\nf 1/1/1 2/1/2 3/1/3\nusemtl\nf 1/1/1 2/1/2 3/1/3\n")
+	assert.NoError(t, readErr)
+	if assert.Equal(t, 2, len(loader.F)) {
+		assert.Equal(t, "red", loader.F[0].Material)
+		assert.Equal(t, "", loader.F[1].Material)
+	}
+}